@@ -0,0 +1,32 @@
+package customerimporter
+
+import "strings"
+
+// Strip display names and angle brackets from emails before validation, e.g.
+// "Mildred Hernandez <mhernandez@github.io>" is counted as
+// mhernandez@github.io. Values without angle brackets are not changed.
+func StripDisplayNames() Option { return func(f *CustomerImporter) { f.stripDisplayNames = true } }
+
+// Trim whitespace and trailing punctuation of email fields before
+// validation, e.g. " mhernandez@github.io " and "mhernandez@github.io," left
+// by copying and pasting are counted as mhernandez@github.io.
+func TrimEmailField() Option { return func(f *CustomerImporter) { f.trimEmailField = true } }
+
+// trimmedPunctuation is trimmed from the end of email fields by TrimEmailField
+const trimmedPunctuation = ",;:."
+
+// returns value without surrounding whitespace and trailing punctuation
+func trimEmail(value string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(value), trimmedPunctuation))
+}
+
+// returns address from the angle brackets at the end of the value, the
+// display name before them may contain any characters, e.g. quoted brackets
+func bareAddress(value string) string {
+	trimmed := strings.TrimSpace(value)
+	start := strings.LastIndexByte(trimmed, '<')
+	if start < 0 || !strings.HasSuffix(trimmed, ">") {
+		return value
+	}
+	return strings.TrimSpace(trimmed[start+1 : len(trimmed)-1])
+}
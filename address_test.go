@@ -0,0 +1,110 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrimEmailField(t *testing.T) {
+	data := []struct {
+		value    string
+		expected string
+	}{
+		{" mhernandez@github.io ", "mhernandez@github.io"},
+		{"mhernandez@github.io,", "mhernandez@github.io"},
+		{"\tmhernandez@github.io.;\u00a0", "mhernandez@github.io"},
+		{"mhernandez@github.io , ", "mhernandez@github.io"},
+		{" , ", ""},
+		{"mhernandez@github.io", "mhernandez@github.io"},
+	}
+
+	t.Log("Should trim whitespace and trailing punctuation")
+	for _, d := range data {
+		t.Logf("Case: %q", d.value)
+
+		if email := trimEmail(d.value); email != d.expected {
+			t.Errorf("should return %q, but got %q", d.expected, email)
+		}
+	}
+
+	records := "name,email\n" +
+		"A, mhernandez@github.io \n" +
+		"B,\"mhernandez@github.io,\"\n" +
+		"C,\"Ortiz <bortiz@cyberchimps.com>;\"\n" +
+		"D, \n"
+
+	t.Log("Should count trimmed emails")
+	result, err := Import(strings.NewReader(records), "email", TrimEmailField(), StripDisplayNames(), SkipErrDuplicateEmails(), SkipEmptyEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "cyberchimps.com", EmailsCount: 1, Share: 0.5},
+		{Domain: "github.io", EmailsCount: 1, Share: 0.5},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should result with: %v, but got %v", expected, *result)
+	}
+
+	t.Log("Should reject untrimmed emails by default")
+	if _, err := Import(strings.NewReader(records), "email"); Code(err) != CodeInvalidEmail {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailIsNotValid, err)
+	}
+}
+
+func TestStripDisplayNames(t *testing.T) {
+	data := []struct {
+		value    string
+		expected string
+	}{
+		// display names
+		{"Mildred Hernandez <mhernandez@github.io>", "mhernandez@github.io"},
+		{`"Hernandez, Mildred" <mhernandez@github.io>`, "mhernandez@github.io"},
+		{`"Mildred <M>" <mhernandez@github.io>`, "mhernandez@github.io"},
+		{" <mhernandez@github.io> ", "mhernandez@github.io"},
+		{"Mildred < mhernandez@github.io >", "mhernandez@github.io"},
+		{"Mildred <>", ""},
+
+		// values without angle brackets are not changed
+		{"mhernandez@github.io", "mhernandez@github.io"},
+		{"Mildred <mhernandez@github.io", "Mildred <mhernandez@github.io"},
+	}
+
+	t.Log("Should extract bare address of the value")
+	for _, d := range data {
+		t.Logf("Case: %v", d.value)
+
+		if address := bareAddress(d.value); address != d.expected {
+			t.Errorf("should return %q, but got %q", d.expected, address)
+		}
+	}
+
+	records := "name,email\n" +
+		"A,Mildred Hernandez <mhernandez@github.io>\n" +
+		"B,mhernandez@github.io\n" +
+		"C,\"\"\"Ortiz, Bonnie\"\" <bortiz@cyberchimps.com>\"\n"
+
+	t.Log("Should count emails with display names as bare addresses")
+	result, err := Import(strings.NewReader(records), "email", StripDisplayNames(), SkipErrDuplicateEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "cyberchimps.com", EmailsCount: 1, Share: 0.5},
+		{Domain: "github.io", EmailsCount: 1, Share: 0.5},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should result with: %v, but got %v", expected, *result)
+	}
+
+	t.Log("Should treat emails with display names as invalid by default")
+	if _, err := Import(strings.NewReader(records), "email"); Code(err) != CodeInvalidEmail {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailIsNotValid, err)
+	}
+
+	t.Log("Should treat empty angle brackets as empty email")
+	if _, err := Import(strings.NewReader("email\nMildred <>\n"), "email", StripDisplayNames()); Code(err) != CodeEmptyEmail {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailEmpty, err)
+	}
+}
@@ -0,0 +1,22 @@
+package customerimporter
+
+import "strings"
+
+// Count emails of the aliased domains by the domain they map to, e.g.
+// googlemail.com -> gmail.com merges both domains into gmail.com. Emails are
+// rewritten to the mapped domain, so the same mailbox written with both
+// domains is counted once. Domains are matched case-insensitively after
+// conversion by WithIDNForm.
+func WithDomainAliases(aliases map[string]string) Option {
+	return func(f *CustomerImporter) {
+		f.domainAliases = make(map[string]string, len(aliases))
+		for alias, domain := range aliases {
+			f.domainAliases[normalizeDomain(alias)] = normalizeDomain(domain)
+		}
+	}
+}
+
+// returns domain name in lower case without the trailing dot
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
+}
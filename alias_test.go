@@ -0,0 +1,52 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithDomainAliases(t *testing.T) {
+	records := "email\n" +
+		"user@gmail.com\n" +
+		"user@googlemail.com\n" +
+		"other@GoogleMail.com.\n" +
+		"user@hotmail.co.uk\n" +
+		"user@github.io\n"
+	aliases := map[string]string{
+		"googlemail.com": "gmail.com",
+		"Hotmail.co.uk.": "HOTMAIL.com",
+	}
+
+	data := []struct {
+		options []Option
+		result  EmailsByDomainQtyList
+	}{
+		// aliased domains are merged
+		{[]Option{WithDomainAliases(aliases)}, EmailsByDomainQtyList{
+			{Domain: "github.io", EmailsCount: 1, Share: 1.0 / 4},
+			{Domain: "gmail.com", EmailsCount: 2, Share: 2.0 / 4},
+			{Domain: "hotmail.com", EmailsCount: 1, Share: 1.0 / 4},
+		}},
+
+		// aliases are applied before grouping
+		{[]Option{WithDomainAliases(aliases), GroupByTLD()}, EmailsByDomainQtyList{
+			{Domain: "com", EmailsCount: 3, Share: 3.0 / 4},
+			{Domain: "io", EmailsCount: 1, Share: 1.0 / 4},
+		}},
+	}
+
+	t.Log("Should count emails of aliased domains by the mapped domain")
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := Import(strings.NewReader(records), "email", append(d.options, SkipErrDuplicateEmails())...)
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(*result, d.result) {
+			t.Errorf("should result with: %v, but got %v", d.result, *result)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package customerimporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ErrUnknownArchive is raised when the file isn't a zip or tar archive
+var ErrUnknownArchive = errors.New("File is not a zip or tar archive")
+
+// IsArchive reports whether the file is an archive imported by
+// ImportFromArchive, by its extension
+func IsArchive(fileName string) bool {
+	_, ok := archiveCompression(fileName)
+	return ok || strings.EqualFold(path.Ext(fileName), ".zip")
+}
+
+// imports csv files of the zip or tar archive and returns emails counted
+// across all of them
+func ImportFromArchive(fileName, pattern, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	// import and get result
+	result, err := ImportFromArchiveWithStats(fileName, pattern, emailFieldName, options...)
+	if result == nil {
+		return nil, err
+	}
+
+	return &result.Domains, err
+}
+
+// imports csv files of the archive and returns ImportResult with statistics of
+// all files. Archives are .zip, .tar, .tar.gz, .tgz, .tar.zst and .tzst files.
+// Entries are read in the order of the archive, only entries matching the glob
+// pattern by their path or base name are imported, e.g. daily/*.csv or *.csv,
+// empty pattern matches all files. Compression of every entry is detected by
+// its extension like by ImportFromFiles.
+func ImportFromArchiveWithStats(fileName, pattern, emailFieldName string, options ...Option) (result *ImportResult, err error) {
+	c := newCustomerImporter(nil, emailFieldName, options...)
+	defer c.closeSpill()
+	defer func() { c.notifyCompletion(result, err) }()
+	endImport := c.traceImport()
+	defer func() { endImport(result, err) }()
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	var next func() (entry, error)
+	if compression, ok := archiveCompression(fileName); ok {
+		file, err := os.Open(fileName)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r, err := decompressWith(file, compression)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		next = tarEntries(tar.NewReader(r))
+	} else if strings.EqualFold(path.Ext(fileName), ".zip") {
+		zr, err := zip.OpenReader(fileName)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		next = zipEntries(zr.File)
+	} else {
+		return nil, fmt.Errorf("%w %s", ErrUnknownArchive, fileName)
+	}
+
+	return c.importEntries(matchEntries(next, pattern))
+}
+
+// returns compression of the tar archive by its extension, false if the file
+// isn't a tar archive
+func archiveCompression(fileName string) (Compression, bool) {
+	name := strings.ToLower(fileName)
+	switch {
+	case strings.HasSuffix(name, ".tar"):
+		return CompressionNone, true
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return CompressionGzip, true
+	case strings.HasSuffix(name, ".tar.zst"), strings.HasSuffix(name, ".tzst"):
+		return CompressionZstd, true
+	}
+	return CompressionAuto, false
+}
+
+// returns regular files of the zip archive one by one
+func zipEntries(files []*zip.File) func() (entry, error) {
+	return func() (entry, error) {
+		for len(files) > 0 {
+			f := files[0]
+			files = files[1:]
+			if f.Mode().IsRegular() {
+				return entry{name: f.Name, open: f.Open}, nil
+			}
+		}
+		return entry{}, io.EOF
+	}
+}
+
+// returns regular files of the tar archive one by one, data of an entry can
+// be read until the next one is returned
+func tarEntries(tr *tar.Reader) func() (entry, error) {
+	return func() (entry, error) {
+		for {
+			hdr, err := tr.Next()
+			if err != nil {
+				return entry{}, err
+			}
+			if hdr.Typeflag == tar.TypeReg {
+				return entry{name: hdr.Name, open: func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }}, nil
+			}
+		}
+	}
+}
+
+// returns entries of next matching the pattern by path or base name, fails
+// with ErrNoFilesMatched when no entry matches
+func matchEntries(next func() (entry, error), pattern string) func() (entry, error) {
+	matched := false
+	return func() (entry, error) {
+		for {
+			e, err := next()
+			if err == io.EOF && !matched {
+				if pattern == "" {
+					pattern = "*"
+				}
+				return entry{}, fmt.Errorf("%w %s", ErrNoFilesMatched, pattern)
+			}
+			if err != nil {
+				return entry{}, err
+			}
+
+			if pattern != "" {
+				ok, _ := path.Match(pattern, e.name)
+				if !ok {
+					ok, _ = path.Match(pattern, path.Base(e.name))
+				}
+				if !ok {
+					continue
+				}
+			}
+			matched = true
+			return e, nil
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package customerimporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// entries of the test archives, directories have no data
+var archiveEntries = []struct {
+	name string
+	data []byte
+}{
+	{"daily/", nil},
+	{"daily/day1.csv", []byte("email\na@a.io\nb@a.io\n")},
+	{"daily/day2.csv", []byte("name,email\nA,a@a.io\nC,a@b.io\n")},
+	{"README.txt", []byte("customers of the week\n")},
+}
+
+// writes entries to zip archive in the temporary directory
+func writeZip(t *testing.T, dir string) string {
+	fileName := filepath.Join(dir, "customers.zip")
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	for _, e := range archiveEntries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+// writes entries to tar.gz archive in the temporary directory
+func writeTarGz(t *testing.T, dir string) string {
+	fileName := filepath.Join(dir, "customers.tar.gz")
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+	for _, e := range archiveEntries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.data)), Typeflag: tar.TypeReg}
+		if e.data == nil {
+			hdr.Mode, hdr.Typeflag = 0o755, tar.TypeDir
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestImportFromArchive(t *testing.T) {
+	dir := t.TempDir()
+	zipFile, tarFile := writeZip(t, dir), writeTarGz(t, dir)
+	plain := filepath.Join(dir, "customers.csv")
+	if err := os.WriteFile(plain, []byte("email\na@a.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	acrossEntries := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	tests := []struct {
+		name     string
+		fileName string
+		pattern  string
+		options  []Option
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{"zip", zipFile, "*.csv", []Option{SkipErrDuplicateEmails()}, acrossEntries, nil},
+		{"tar.gz", tarFile, "*.csv", []Option{SkipErrDuplicateEmails()}, acrossEntries, nil},
+		{"path pattern", zipFile, "daily/day1.csv", nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 1},
+		}, nil},
+		{"per entry", tarFile, "*.csv", []Option{DedupPerFile()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 3.0 / 4},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 4},
+		}, nil},
+		{"duplicate across entries", zipFile, "*.csv", nil, nil, ErrEmailDuplicate},
+		{"all entries", zipFile, "", []Option{SkipErrDuplicateEmails()}, nil, ErrFieldNotExists},
+		{"no matches", tarFile, "*.xlsx", nil, nil, ErrNoFilesMatched},
+		{"bad pattern", zipFile, "[", nil, nil, path.ErrBadPattern},
+		{"not archive", plain, "", nil, nil, ErrUnknownArchive},
+		{"missing archive", filepath.Join(dir, "missing.zip"), "", nil, nil, os.ErrNotExist},
+	}
+
+	t.Log("Should import emails from matching entries of the archive")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.name)
+
+		result, err := ImportFromArchive(test.fileName, test.pattern, "email", test.options...)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("should raise error: %v, but got error %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, test.expected) {
+			t.Errorf("should return %v, but got %v", test.expected, *result)
+		}
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	data := []struct {
+		fileName string
+		expected bool
+	}{
+		{"customers.zip", true},
+		{"customers.ZIP", true},
+		{"customers.tar", true},
+		{"customers.tar.gz", true},
+		{"customers.tgz", true},
+		{"customers.tar.zst", true},
+		{"customers.csv.gz", false},
+		{"customers.csv", false},
+	}
+
+	t.Log("Should detect archives by extension")
+	for _, d := range data {
+		t.Logf("Case: %v", d.fileName)
+
+		if got := IsArchive(d.fileName); got != d.expected {
+			t.Errorf("should return %v, but got %v", d.expected, got)
+		}
+	}
+}
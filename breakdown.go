@@ -0,0 +1,48 @@
+package customerimporter
+
+// Count emails of every domain also by value of the field, e.g. "country" or
+// "gender", to cross-tabulate domains with other column in one pass. The
+// counts are returned in EmailsByDomainQty Breakdown. The option requires the
+// header, so it's ignored together with WithColumnIndex.
+func WithBreakdown(fieldName string) Option {
+	return func(f *CustomerImporter) { f.breakdownField = fieldName }
+}
+
+// determine index of the breakdown column by WithBreakdown
+func (c *CustomerImporter) determineBreakdownColumnIndex(headerRecord []string) error {
+	c.breakdownColumnIndex = -1
+	if c.breakdownField == "" {
+		return nil
+	}
+
+	index, err := c.fieldIndex(headerRecord, c.breakdownField)
+	if err != nil {
+		return err
+	}
+	c.breakdownColumnIndex = index
+	return nil
+}
+
+// increments count of the domain by value of the breakdown field
+func (c *CustomerImporter) countBreakdown(r parsedRecord) {
+	counts := c.breakdown[r.domain]
+	if counts == nil {
+		counts = make(map[string]int)
+		c.breakdown[r.domain] = counts
+	}
+	counts[r.key]++
+}
+
+// adds breakdown counts of src to dst, dst is allocated if needed
+func mergeBreakdown(dst, src map[string]int) map[string]int {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]int, len(src))
+	}
+	for key, count := range src {
+		dst[key] += count
+	}
+	return dst
+}
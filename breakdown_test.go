@@ -0,0 +1,77 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithBreakdown(t *testing.T) {
+	records := "email,backup,country\n" +
+		"a@a.io,,PL\n" +
+		"b@a.io,c@b.io,DE\n" +
+		"c@a.io,,PL\n" +
+		"invalid,,FR\n" +
+		"d@b.io,,\n"
+
+	data := []struct {
+		name     string
+		options  []Option
+		expected EmailsByDomainQtyList
+	}{
+		{"by country", nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6, Breakdown: map[string]int{"PL": 2, "DE": 1}},
+			{Domain: "b.io", EmailsCount: 2, Share: 0.4, Breakdown: map[string]int{"DE": 1, "": 1}},
+		}},
+		{"collapsed domains", []Option{TopN(1, true)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6, Breakdown: map[string]int{"PL": 2, "DE": 1}},
+			{Domain: OtherDomain, EmailsCount: 2, Share: 0.4, Breakdown: map[string]int{"DE": 1, "": 1}},
+		}},
+		{"with workers", []Option{WithWorkers(2)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6, Breakdown: map[string]int{"PL": 2, "DE": 1}},
+			{Domain: "b.io", EmailsCount: 2, Share: 0.4, Breakdown: map[string]int{"DE": 1, "": 1}},
+		}},
+	}
+
+	t.Log("Should count emails of every domain by value of the field")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		options := append([]Option{WithBreakdown("country"), WithEmailFields("backup"), SkipErrInvalidEmails()}, d.options...)
+		result, err := Import(strings.NewReader(records), "email", options...)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		if !reflect.DeepEqual(*result, d.expected) {
+			t.Errorf("should result with: %v, but got %v", d.expected, *result)
+		}
+	}
+
+	t.Log("Should raise error if the field doesn't exist")
+	if _, err := Import(strings.NewReader(records), "email", WithBreakdown("region")); err == nil || !strings.Contains(err.Error(), ErrFieldNotExists.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrFieldNotExists, err)
+	}
+
+	t.Log("Should ignore the option without header")
+	result, err := Import(strings.NewReader("a@a.io,PL\n"), "", WithColumnIndex(0), WithBreakdown("country"))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if (*result)[0].Breakdown != nil {
+		t.Errorf("should not count breakdown, but got %v", (*result)[0].Breakdown)
+	}
+}
+
+func TestMergeBreakdown(t *testing.T) {
+	t.Log("Should sum breakdowns of merged domains")
+
+	p := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Breakdown: map[string]int{"PL": 2}}}
+	other := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Breakdown: map[string]int{"PL": 1, "DE": 1}}}
+	expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 4, Share: 1, Breakdown: map[string]int{"PL": 3, "DE": 1}}}
+	if merged := p.Merge(other); !reflect.DeepEqual(merged, expected) {
+		t.Errorf("should merge to %v, but got %v", expected, merged)
+	}
+	if p[0].Breakdown["PL"] != 2 {
+		t.Errorf("should not modify merged list, but got %v", p[0].Breakdown)
+	}
+}
@@ -0,0 +1,56 @@
+package customerimporter
+
+import "strings"
+
+// Treat tagged and dotted addresses of well-known providers as the same
+// address when deduplicating, e.g. user+tag@gmail.com and u.s.e.r@gmail.com
+// are duplicates of user@gmail.com. Emails are counted by the domain they are
+// written with, addresses of other providers are not changed.
+func CanonicalizeEmails() Option { return func(f *CustomerImporter) { f.canonicalizeEmails = true } }
+
+// canonicalRule describes addressing of a provider
+type canonicalRule struct {
+	domain     string // canonical domain of the provider
+	tag        byte   // separator of the subaddress tag
+	ignoreDots bool   // dots in local part are ignored
+}
+
+// canonicalRules of the providers by domain, local parts of all of them are
+// case-insensitive
+var canonicalRules = map[string]canonicalRule{
+	"gmail.com":      {"gmail.com", '+', true},
+	"googlemail.com": {"gmail.com", '+', true},
+	"outlook.com":    {"outlook.com", '+', false},
+	"hotmail.com":    {"hotmail.com", '+', false},
+	"live.com":       {"live.com", '+', false},
+	"icloud.com":     {"icloud.com", '+', false},
+	"me.com":         {"icloud.com", '+', false},
+	"mac.com":        {"icloud.com", '+', false},
+	"fastmail.com":   {"fastmail.com", '+', false},
+	"protonmail.com": {"proton.me", '+', false},
+	"proton.me":      {"proton.me", '+', false},
+	"yahoo.com":      {"yahoo.com", '-', false},
+}
+
+// returns canonical address of the normalized email by the rules of its
+// provider, emails of unknown providers and values without @ are returned as
+// is
+func canonicalEmail(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return email
+	}
+	rule, ok := canonicalRules[email[at+1:]]
+	if !ok {
+		return email
+	}
+
+	local := strings.ToLower(email[:at])
+	if i := strings.IndexByte(local, rule.tag); i > 0 {
+		local = local[:i]
+	}
+	if rule.ignoreDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + rule.domain
+}
@@ -0,0 +1,78 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalizeEmails(t *testing.T) {
+	data := []struct {
+		email     string
+		canonical string
+	}{
+		// gmail ignores tags, dots and case
+		{"user@gmail.com", "user@gmail.com"},
+		{"user+news@gmail.com", "user@gmail.com"},
+		{"u.s.e.r@gmail.com", "user@gmail.com"},
+		{"U.Ser+a+b@googlemail.com", "user@gmail.com"},
+
+		// other providers keep dots
+		{"first.last+tag@outlook.com", "first.last@outlook.com"},
+		{"user+tag@me.com", "user@icloud.com"},
+		{"user-tag@yahoo.com", "user@yahoo.com"},
+		{"user+tag@yahoo.com", "user+tag@yahoo.com"},
+
+		// local part starting with the separator is kept
+		{"+user@gmail.com", "+user@gmail.com"},
+
+		// unknown providers are not changed
+		{"First.Last+tag@github.io", "First.Last+tag@github.io"},
+
+		// values without @ are not changed
+		{"gmail.com", "gmail.com"},
+		{"", ""},
+	}
+
+	t.Log("Should return canonical address")
+	for _, d := range data {
+		t.Logf("Case: %v", d.email)
+
+		if canonical := canonicalEmail(d.email); canonical != d.canonical {
+			t.Errorf("should return %v, but got %v", d.canonical, canonical)
+		}
+	}
+
+	records := "email\n" +
+		"user@gmail.com\n" +
+		"user+news@gmail.com\n" +
+		"u.ser@googlemail.com\n" +
+		"user+tag@github.io\n" +
+		"user@github.io\n"
+
+	t.Log("Should count canonical duplicates once by domain they are written with")
+	result, err := ImportWithStats(strings.NewReader(records), "email", CanonicalizeEmails(), SkipErrDuplicateEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "github.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "gmail.com", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	if !reflect.DeepEqual(result.Domains, expected) {
+		t.Errorf("should result with: %v, but got %v", expected, result.Domains)
+	}
+	if result.DuplicateEmails != 2 {
+		t.Errorf("should skip 2 duplicate emails, but got %v", result.DuplicateEmails)
+	}
+
+	t.Log("Should skip invalid field equal to domain of a provider")
+	result, err = ImportWithStats(strings.NewReader("email\ngmail.com\nuser@gmail.com\n"), "email", CanonicalizeEmails(), SkipErrInvalidEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected = EmailsByDomainQtyList{{Domain: "gmail.com", EmailsCount: 1, Share: 1}}
+	if !reflect.DeepEqual(result.Domains, expected) || result.InvalidEmails != 1 {
+		t.Errorf("should result with: %v and 1 invalid email, but got %v and %v", expected, result.Domains, result.InvalidEmails)
+	}
+}
@@ -0,0 +1,53 @@
+package customerimporter
+
+import (
+	"context"
+	"io"
+)
+
+// DomainEvent reports emails count of the domain updated by a counted email
+type DomainEvent struct {
+	Domain      string // domain of the counted email
+	EmailsCount int    // amount of emails of the domain counted so far
+	New         bool   // domain is counted for the first time
+	Line        int    // line of the record
+}
+
+// imports from reader in a new goroutine sending updated count of the domain
+// for every counted email, e.g. to push live progress to a UI. The events
+// channel is closed when the import finishes, then the error channel receives
+// error aborting the import or is closed without value. Canceling ctx aborts
+// the import with ctx.Err().
+func ImportChan(ctx context.Context, r io.Reader, emailFieldName string, options ...Option) (<-chan DomainEvent, <-chan error) {
+	events := make(chan DomainEvent)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+
+		counts := make(map[string]int)
+		err := ImportStream(r, emailFieldName, func(ev Event) error {
+			// stop when the consumer is gone
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if ev.Type != EventValidEmail {
+				return nil
+			}
+
+			counts[ev.Domain]++
+			select {
+			case events <- DomainEvent{Domain: ev.Domain, EmailsCount: counts[ev.Domain], New: counts[ev.Domain] == 1, Line: ev.Line}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, options...)
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return events, errs
+}
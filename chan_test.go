@@ -0,0 +1,50 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportChan(t *testing.T) {
+	records := "email\na@a.io\nb@a.io\ninvalid\na@b.io\na@a.io\n"
+
+	t.Log("Should send updated count of the domain for every counted email")
+	events, errs := ImportChan(context.Background(), strings.NewReader(records), "email", SkipErrInvalidEmails(), SkipErrDuplicateEmails())
+	var received []DomainEvent
+	for ev := range events {
+		received = append(received, ev)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	expected := []DomainEvent{
+		{Domain: "a.io", EmailsCount: 1, New: true, Line: 2},
+		{Domain: "a.io", EmailsCount: 2, Line: 3},
+		{Domain: "b.io", EmailsCount: 1, New: true, Line: 5},
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("should send %v, but got %v", expected, received)
+	}
+
+	t.Log("Should send error aborting the import")
+	events, errs = ImportChan(context.Background(), strings.NewReader(records), "email")
+	for range events {
+	}
+	if err := <-errs; !errors.Is(err, ErrEmailIsNotValid) {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailIsNotValid, err)
+	}
+
+	t.Log("Should abort the import when context is canceled")
+	ctx, cancel := context.WithCancel(context.Background())
+	events, errs = ImportChan(ctx, strings.NewReader(records), "email", SkipErrInvalidEmails())
+	<-events
+	cancel()
+	for range events {
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("should raise error: %v, but got error %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,444 @@
+package customerimporter
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+)
+
+var (
+	// ErrCheckpointMismatch is raised when the checkpoint was saved by import
+	// of other input or with other options
+	ErrCheckpointMismatch = errors.New("Checkpoint doesn't match the import")
+	// ErrCheckpointUnsupported is raised when the dedup store can't be saved
+	ErrCheckpointUnsupported = errors.New("Dedup store can't be saved in checkpoint")
+)
+
+// version of the checkpoint file format
+const checkpointVersion = 2
+
+// Save state of the import to the file at path after every n records and
+// resume the import from the file if it exists, so an interrupted import
+// doesn't start from the beginning. Seekable inputs, e.g. files, which are
+// neither compressed nor decoded by WithEncoding are resumed from the saved
+// offset, records of other inputs up to the saved line are read again but not
+// counted. Counted emails are saved with the default dedup store and
+// WithBloomDedup, other stores raise ErrCheckpointUnsupported. Emails of the
+// default store are appended to the file at path with ".emails" suffix, so
+// the checkpoint doesn't rewrite all of them. Both files are removed when the
+// import succeeds.
+func WithCheckpoint(path string, n int) Option {
+	return func(f *CustomerImporter) {
+		f.checkpointPath = path
+		f.checkpointEvery = n
+	}
+}
+
+// checkpoint is a saved state of the import
+type checkpoint struct {
+	Version         int                       `json:"version"`
+	EmailField      string                    `json:"email_field"`
+	Header          []string                  `json:"header,omitempty"`
+	File            string                    `json:"file,omitempty"`
+	Line            int                       `json:"line"`
+	Offset          int64                     `json:"offset,omitempty"`
+	Domains         map[string]int            `json:"domains"`
+	Occurrences     map[string]int            `json:"occurrences"`
+	RoleAccounts    map[string]int            `json:"role_accounts,omitempty"`
+	Breakdown       map[string]map[string]int `json:"breakdown"`
+	Series          map[string]map[string]int `json:"series,omitempty"`
+	Samples         map[string][]Sample       `json:"samples"`
+	Typos           map[string]int            `json:"typos,omitempty"`
+	Confusables     map[string]int            `json:"confusables,omitempty"`
+	RowsRead        int                       `json:"rows_read"`
+	ValidEmails     int                       `json:"valid_emails"`
+	InvalidEmails   int                       `json:"invalid_emails"`
+	InvalidByReason map[string]int            `json:"invalid_by_reason"`
+	DuplicateEmails int                       `json:"duplicate_emails"`
+	FilteredRows    int                       `json:"filtered_rows"`
+	SampledOutRows  int                       `json:"sampled_out_rows,omitempty"`
+	ReservedEmails  int                       `json:"reserved_emails,omitempty"`
+	RoleEmails      int                       `json:"role_emails,omitempty"`
+	Suppressed      int                       `json:"suppressed_emails,omitempty"`
+	MalformedRows   int                       `json:"malformed_rows"`
+	Errors          RowErrors                 `json:"errors,omitempty"`
+	Emails          []string                  `json:"emails,omitempty"`
+	EmailsStart     int64                     `json:"emails_start,omitempty"`
+	EmailsEnd       int64                     `json:"emails_end,omitempty"`
+	Bloom           []uint64                  `json:"bloom,omitempty"`
+}
+
+// offsetReader counts offset of the input read by the csv reader
+type offsetReader struct {
+	r      io.Reader
+	offset int64 // offset of the input after the read bytes
+}
+
+func (r *offsetReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+// loads checkpoint of the interrupted import, if there is one
+func (c *CustomerImporter) loadCheckpoint() error {
+	if c.checkpointPath == "" {
+		return nil
+	}
+
+	// fail before reading records if counted emails can't be saved
+	if err := c.checkpointSupported(); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(c.checkpointPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cp := &checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return err
+	}
+	if cp.Version != checkpointVersion || cp.EmailField != c.emailFieldName {
+		return fmt.Errorf("%w %s", ErrCheckpointMismatch, c.checkpointPath)
+	}
+	c.resume = cp
+
+	return nil
+}
+
+// returns ErrCheckpointUnsupported if counted emails can't be saved, domains
+// spilled by WithMemoryLimit, sketches and counter stores are not saved either
+func (c *CustomerImporter) checkpointSupported() error {
+	if c.memoryLimit > 0 || c.sketches != nil || c.counterStore != nil {
+		return ErrCheckpointUnsupported
+	}
+	switch c.countedEmails.(type) {
+	case MemoryDedupStore, *bloomFilter:
+		return nil
+	}
+	return ErrCheckpointUnsupported
+}
+
+// restores state of the loaded checkpoint if the input is the checkpointed
+// one, records up to the checkpoint line are skipped afterwards
+func (c *CustomerImporter) resumeCheckpoint() (*checkpoint, error) {
+	cp := c.resume
+	if cp == nil || cp.File != c.fileName {
+		return nil, nil
+	}
+	c.resume = nil
+
+	if err := c.restore(cp); err != nil {
+		return nil, fmt.Errorf("%w %s", err, c.checkpointPath)
+	}
+	if err := c.restoreEmailsLog(cp); err != nil {
+		return nil, fmt.Errorf("%w %s", err, c.emailsLogPath())
+	}
+
+	c.log(slog.LevelInfo, "import resumed", "file", cp.File, "line", cp.Line, "rows", cp.RowsRead)
+
+	return cp, nil
+}
+
+// restores counted emails, counters and statistics of the checkpoint, returns
+// ErrCheckpointMismatch if counted emails were saved by other dedup store
+func (c *CustomerImporter) restore(cp *checkpoint) error {
+	// restore counted emails
+	switch s := c.countedEmails.(type) {
+	case MemoryDedupStore:
+		if cp.Bloom != nil {
+			return ErrCheckpointMismatch
+		}
+		for _, email := range cp.Emails {
+			s[email] = struct{}{}
+		}
+	case *bloomFilter:
+		if len(cp.Bloom) != len(s.bits) {
+			return ErrCheckpointMismatch
+		}
+		copy(s.bits, cp.Bloom)
+	}
+
+	// restore counter and statistics
+	c.domainCounter = cp.Domains
+	if c.domainCounter == nil {
+		c.domainCounter = make(map[string]int, 10)
+	}
+	c.occurrences = cp.Occurrences
+	if c.occurrences == nil {
+		c.occurrences = make(map[string]int, 10)
+	}
+	c.roleAccounts = cp.RoleAccounts
+	if c.roleAccounts == nil {
+		c.roleAccounts = make(map[string]int, 10)
+	}
+	c.breakdown = cp.Breakdown
+	if c.breakdown == nil {
+		c.breakdown = make(map[string]map[string]int, 10)
+	}
+	c.series = cp.Series
+	if c.series == nil {
+		c.series = make(map[string]map[string]int, 10)
+	}
+	c.samples = cp.Samples
+	if c.samples == nil {
+		c.samples = make(map[string][]Sample, 10)
+	}
+	if c.typos != nil {
+		c.typos.restore(cp.Typos)
+	}
+	if c.confusables != nil {
+		c.confusables.restore(cp.Confusables)
+	}
+	c.rowsRead = cp.RowsRead
+	c.validEmails = cp.ValidEmails
+	c.invalidEmails = cp.InvalidEmails
+	c.invalidByReason = cp.InvalidByReason
+	if c.invalidByReason == nil {
+		c.invalidByReason = make(map[string]int)
+	}
+	c.duplicateEmails = cp.DuplicateEmails
+	c.filteredRows = cp.FilteredRows
+	c.sampledOutRows = cp.SampledOutRows
+	c.reservedEmails = cp.ReservedEmails
+	c.roleAccountsCount = cp.RoleEmails
+	c.suppressedEmails = cp.Suppressed
+	c.malformedRows = cp.MalformedRows
+	c.rowErrors = cp.Errors
+
+	return nil
+}
+
+// checks the header of the resumed input and seeks to the checkpointed
+// offset or starts skipping records
+func (c *CustomerImporter) skipCheckpointed(cp *checkpoint) error {
+	if !slices.Equal(cp.Header, c.header) {
+		return fmt.Errorf("%w %s", ErrCheckpointMismatch, c.checkpointPath)
+	}
+	c.resumeLine = cp.Line
+	if cp.Offset > 0 && c.seekReader != nil && cp.Line > c.line {
+		return c.seekCheckpointed(cp)
+	}
+	return nil
+}
+
+// continues reading of the input at the checkpointed offset by a new csv
+// reader configured as the current one
+func (c *CustomerImporter) seekCheckpointed(cp *checkpoint) error {
+	if _, err := c.input.(io.Seeker).Seek(cp.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := csv.NewReader(c.input)
+	reader.Comma, reader.Comment = c.seekReader.Comma, c.seekReader.Comment
+	reader.FieldsPerRecord, reader.LazyQuotes = c.seekReader.FieldsPerRecord, c.seekReader.LazyQuotes
+	reader.TrimLeadingSpace, reader.ReuseRecord = c.seekReader.TrimLeadingSpace, c.seekReader.ReuseRecord
+	c.reader, c.seekReader, c.offsetBase, c.line = reader, reader, cp.Offset, cp.Line
+
+	c.log(slog.LevelDebug, "input seeked", "line", cp.Line, "offset", cp.Offset)
+	return nil
+}
+
+// returns offset of seekable input if the import is checkpointed and the
+// input isn't limited, records of the input can be seeked then
+func (c *CustomerImporter) inputStart() (int64, bool) {
+	seeker, ok := c.input.(io.Seeker)
+	if !ok || c.checkpointPath == "" || c.encoding != "" || c.maxBytes > 0 || c.maxRows > 0 {
+		return 0, false
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+	return start, true
+}
+
+// returns offset of the input after the last read record, 0 if the input
+// can't be seeked
+func (c *CustomerImporter) readOffset() int64 {
+	if c.seekReader == nil {
+		return 0
+	}
+	return c.offsetBase + c.seekReader.InputOffset()
+}
+
+// saves checkpoint after every n records if WithCheckpoint is used
+func (c *CustomerImporter) checkpoint() error {
+	if c.checkpointEvery < 1 || c.rowsRead%c.checkpointEvery != 0 {
+		return nil
+	}
+
+	// counted emails are appended to the log before the checkpoint refers to
+	// them
+	if err := c.appendEmailsLog(); err != nil {
+		return err
+	}
+
+	cp := c.snapshot()
+	cp.Header, cp.File, cp.Line, cp.Offset = c.header, c.fileName, c.line, c.offset
+	cp.EmailsStart, cp.EmailsEnd = c.emailsStart, c.emailsEnd
+	if bloom, ok := c.countedEmails.(*bloomFilter); ok {
+		cp.Bloom = bloom.bits
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	// replace the previous checkpoint atomically, so it's valid if the
+	// import is killed while writing
+	tmp := c.checkpointPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.checkpointPath); err != nil {
+		return err
+	}
+
+	c.log(slog.LevelDebug, "checkpoint saved", "path", c.checkpointPath, "line", c.line, "rows", c.rowsRead)
+	return nil
+}
+
+// returns checkpoint of counters and statistics, counted emails are saved
+// separately by SaveState and checkpoint
+func (c *CustomerImporter) snapshot() *checkpoint {
+	cp := &checkpoint{
+		Version:         checkpointVersion,
+		EmailField:      c.emailFieldName,
+		Domains:         c.domainCounter,
+		Occurrences:     c.occurrences,
+		RoleAccounts:    c.roleAccounts,
+		Breakdown:       c.breakdown,
+		Series:          c.series,
+		Samples:         c.samples,
+		RowsRead:        c.rowsRead,
+		ValidEmails:     c.validEmails,
+		InvalidEmails:   c.invalidEmails,
+		InvalidByReason: c.invalidByReason,
+		DuplicateEmails: c.duplicateEmails,
+		FilteredRows:    c.filteredRows,
+		SampledOutRows:  c.sampledOutRows,
+		ReservedEmails:  c.reservedEmails,
+		RoleEmails:      c.roleAccountsCount,
+		Suppressed:      c.suppressedEmails,
+		MalformedRows:   c.malformedRows,
+		Errors:          c.rowErrors,
+	}
+	if c.typos != nil {
+		cp.Typos = c.typos.counts
+	}
+	if c.confusables != nil {
+		cp.Confusables = c.confusables.counts
+	}
+	return cp
+}
+
+// returns path of the log of emails counted by the checkpointed import
+func (c *CustomerImporter) emailsLogPath() string { return c.checkpointPath + ".emails" }
+
+// keeps email added to the default dedup store for the next checkpoint
+func (c *CustomerImporter) logEmail(email string) {
+	if _, ok := c.countedEmails.(MemoryDedupStore); ok {
+		c.newEmails = append(c.newEmails, email)
+	}
+}
+
+// starts log of emails of the new dedup store, emails of the previous store
+// stay in the log but aren't restored
+func (c *CustomerImporter) resetEmailsLog() {
+	c.emailsStart, c.newEmails = c.emailsEnd, c.newEmails[:0]
+}
+
+// appends emails counted after the previous checkpoint to the log, every
+// email is prefixed by its length. Emails written by interrupted import after
+// its last checkpoint are overwritten.
+func (c *CustomerImporter) appendEmailsLog() error {
+	if len(c.newEmails) == 0 {
+		return nil
+	}
+
+	var data []byte
+	for _, email := range c.newEmails {
+		data = binary.AppendUvarint(data, uint64(len(email)))
+		data = append(data, email...)
+	}
+
+	f, err := os.OpenFile(c.emailsLogPath(), os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	end := c.emailsEnd + int64(len(data))
+	if _, err := f.WriteAt(data, c.emailsEnd); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Truncate(end); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	c.emailsEnd, c.newEmails = end, c.newEmails[:0]
+	return nil
+}
+
+// adds emails of the log saved by the checkpoint to the dedup store
+func (c *CustomerImporter) restoreEmailsLog(cp *checkpoint) error {
+	c.emailsStart, c.emailsEnd = cp.EmailsStart, cp.EmailsEnd
+	if cp.EmailsEnd == cp.EmailsStart {
+		return nil
+	}
+	store, ok := c.countedEmails.(MemoryDedupStore)
+	if !ok || cp.EmailsStart > cp.EmailsEnd {
+		return ErrCheckpointMismatch
+	}
+
+	f, err := os.Open(c.emailsLogPath())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(io.NewSectionReader(f, cp.EmailsStart, cp.EmailsEnd-cp.EmailsStart))
+	for {
+		n, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrCheckpointMismatch, err)
+		}
+		email := make([]byte, n)
+		if _, err := io.ReadFull(r, email); err != nil {
+			return fmt.Errorf("%w: %w", ErrCheckpointMismatch, err)
+		}
+		store[string(email)] = struct{}{}
+	}
+}
+
+// removes checkpoint and emails log of the finished import
+func (c *CustomerImporter) removeCheckpoint() error {
+	if c.checkpointPath == "" {
+		return nil
+	}
+	for _, path := range []string{c.checkpointPath, c.emailsLogPath()} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package customerimporter
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCheckpoint(t *testing.T) {
+	head := "name,email\nA,a@a.io\nB,b@a.io\nC,invalid\nD,a@b.io\nE,a@a.io\n"
+	tail := "F,c@a.io\nG,b@b.io\nH,a@b.io\n"
+	errInterrupted := errors.New("interrupted")
+
+	data := []struct {
+		name    string
+		options []Option
+	}{
+		{"in-memory dedup", nil},
+		{"bloom dedup", []Option{WithBloomDedup(100, 0.01)}},
+		{"workers", []Option{WithWorkers(2)}},
+	}
+
+	t.Log("Should resume interrupted import from the checkpoint")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		options := append([]Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails()}, d.options...)
+		expected, err := ImportWithStats(strings.NewReader(head+tail), "email", options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// interrupt the import after the head
+		path := filepath.Join(t.TempDir(), "import.checkpoint")
+		options = append(options, WithCheckpoint(path, 2))
+		interrupted := io.MultiReader(strings.NewReader(head), iotest.ErrReader(errInterrupted))
+		if _, err := ImportWithStats(interrupted, "email", options...); !errors.Is(err, errInterrupted) {
+			t.Fatalf("should raise error: %v, but got error %v", errInterrupted, err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("should save checkpoint, but got error %v", err)
+		}
+
+		// resume the import
+		result, err := ImportWithStats(strings.NewReader(head+tail), "email", options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Domains, expected.Domains) {
+			t.Errorf("should return %v, but got %v", expected.Domains, result.Domains)
+		}
+		if result.RowsRead != expected.RowsRead || result.DuplicateEmails != expected.DuplicateEmails || result.InvalidEmails != expected.InvalidEmails {
+			t.Errorf("should return statistics %+v, but got %+v", expected, result)
+		}
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("should remove checkpoint of finished import, but got error %v", err)
+		}
+	}
+}
+
+func TestCheckpointHeaderless(t *testing.T) {
+	t.Log("Should not count the first record of input without header twice")
+
+	records := "a@a.io\nb@a.io\na@b.io\n"
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+	interrupted := io.MultiReader(strings.NewReader("a@a.io\nb@a.io\n"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	if _, err := Import(interrupted, "", WithColumnIndex(0), WithCheckpoint(path, 1)); err == nil {
+		t.Fatal("should raise error of interrupted import")
+	}
+
+	result, err := Import(strings.NewReader(records), "", WithColumnIndex(0), WithCheckpoint(path, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("should return %v, but got %v", expected, result)
+	}
+}
+
+func TestCheckpointFiles(t *testing.T) {
+	t.Log("Should resume import of several files from the checkpointed file")
+
+	dir := t.TempDir()
+	day1 := filepath.Join(dir, "day1.csv")
+	day2 := filepath.Join(dir, "day2.csv")
+	if err := os.WriteFile(day1, []byte("email\na@a.io\nb@a.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(day2, []byte("email\na@b.io\na@a.io\nc@a.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the import fails on the duplicate after the checkpoint in day2.csv
+	path := filepath.Join(dir, "import.checkpoint")
+	if _, err := ImportFromFiles([]string{day1, day2}, "email", WithCheckpoint(path, 3)); !errors.Is(err, ErrEmailDuplicate) {
+		t.Fatalf("should raise error: %v, but got error %v", ErrEmailDuplicate, err)
+	}
+
+	// day1.csv is skipped, the duplicate is read again
+	result, err := ImportFromFilesWithStats([]string{day1, day2}, "email", WithCheckpoint(path, 3), SkipErrDuplicateEmails())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 3, Share: 3.0 / 4},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 4},
+	}
+	if !reflect.DeepEqual(result.Domains, expected) || result.RowsRead != 5 || result.DuplicateEmails != 1 {
+		t.Errorf("should return %v, but got %+v", expected, result)
+	}
+}
+
+func TestCheckpointErrors(t *testing.T) {
+	dir := t.TempDir()
+	records := "name,email\nA,a@a.io\nB,a@a.io\n"
+
+	// save checkpoint of the import failing on the duplicate
+	path := filepath.Join(dir, "import.checkpoint")
+	if _, err := Import(strings.NewReader(records), "email", WithCheckpoint(path, 1)); !errors.Is(err, ErrEmailDuplicate) {
+		t.Fatalf("should raise error: %v, but got error %v", ErrEmailDuplicate, err)
+	}
+
+	store, err := OpenFileDedupStore(filepath.Join(dir, "dedup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	data := []struct {
+		name    string
+		records string
+		field   string
+		options []Option
+		err     error
+	}{
+		{"other header", "email,name\na@a.io,A\n", "email", nil, ErrCheckpointMismatch},
+		{"other email field", records, "name", nil, ErrCheckpointMismatch},
+		{"other bloom filter", records, "email", []Option{WithBloomDedup(1000, 0.01)}, ErrCheckpointMismatch},
+		{"unsupported dedup store", records, "email", []Option{WithDedupStore(store)}, ErrCheckpointUnsupported},
+		{"memory limit", records, "email", []Option{WithMemoryLimit(1 << 20)}, ErrCheckpointUnsupported},
+	}
+
+	t.Log("Should raise error if the checkpoint can't be resumed")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		options := append([]Option{WithCheckpoint(path, 1)}, d.options...)
+		_, err := Import(strings.NewReader(d.records), d.field, options...)
+		if err == nil || !strings.Contains(err.Error(), d.err.Error()) {
+			t.Errorf("should raise error: %v, but got error %v", d.err, err)
+		}
+	}
+}
+
+func TestCheckpointOffset(t *testing.T) {
+	// records counted before the checkpoint are replaced by the same amount of
+	// bytes which can't be parsed, so they must not be read again
+	records := "name,email\nA,a@a.io\nB,b@a.io\nC,a@a.io\nD,c@b.io\n"
+	resumed := "name,email\nA,a\"a.io\nB,b\"a.io\nC,a@a.io\nD,c@b.io\n"
+
+	data := []struct {
+		name     string
+		preamble string
+		options  []Option
+	}{
+		{"sequential", "", nil},
+		{"workers", "", []Option{WithWorkers(2)}},
+		{"byte order mark and skipped rows", "\xEF\xBB\xBFPartner export\n", []Option{SkipRows(1)}},
+	}
+
+	t.Log("Should resume seekable input from the checkpointed offset")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		// the import fails on the duplicate after the checkpoint
+		path := filepath.Join(t.TempDir(), "import.checkpoint")
+		options := append([]Option{WithCheckpoint(path, 2)}, d.options...)
+		if _, err := Import(strings.NewReader(d.preamble+records), "email", options...); !errors.Is(err, ErrEmailDuplicate) {
+			t.Fatalf("should raise error: %v, but got error %v", ErrEmailDuplicate, err)
+		}
+
+		result, err := ImportWithStats(strings.NewReader(d.preamble+resumed), "email", append(options, SkipErrDuplicateEmails())...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}
+		if !reflect.DeepEqual(result.Domains, expected) || result.RowsRead != 4 || result.DuplicateEmails != 1 {
+			t.Errorf("should return %v, but got %+v", expected, result)
+		}
+	}
+}
+
+func TestCheckpointEmailsLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+	records := "name,email\nA,a@a.io\nB,b@a.io\nC,a@a.io\n"
+	if _, err := Import(strings.NewReader(records), "email", WithCheckpoint(path, 1)); !errors.Is(err, ErrEmailDuplicate) {
+		t.Fatalf("should raise error: %v, but got error %v", ErrEmailDuplicate, err)
+	}
+
+	t.Log("Should append counted emails to the log instead of the checkpoint")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "a@a.io") {
+		t.Errorf("should not save emails in the checkpoint, but got %s", data)
+	}
+	log, err := os.ReadFile(path + ".emails")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "\x06a@a.io\x06b@a.io"; string(log) != expected {
+		t.Errorf("should log %q, but got %q", expected, log)
+	}
+
+	t.Log("Should remove the log of finished import")
+	if _, err := Import(strings.NewReader(records), "email", WithCheckpoint(path, 1), SkipErrDuplicateEmails()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".emails"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("should remove the log, but got error %v", err)
+	}
+}
@@ -0,0 +1,350 @@
+package customerimporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// amount of shards of emails deduplicated by chunks
+const dedupShards = 64
+
+// errChunkStopped stops reading of the chunk after a previous chunk failed
+var errChunkStopped = errors.New("Chunk stopped")
+
+// Import files of ImportFromFile and ImportFromFiles in n chunks processed in
+// parallel, e.g. to saturate throughput of fast disks with large exports. The
+// file is split into byte ranges aligned to line boundaries, every chunk is
+// counted separately and the counters are merged when all chunks are read,
+// so records must not contain line breaks in quoted fields. Files are split
+// only if duplicates are skipped by SkipErrDuplicateEmails, which of
+// duplicate emails in different chunks is skipped isn't deterministic, the
+// counts are the same. Compressed and non-UTF-8 files, files without header,
+// with skipped rows or sep= directive are imported sequentially, so are
+// imports with checkpoint, events, WithRejectWriter, OnError, WithMaxErrors,
+// WithMemoryLimit, input limits, LimitValidEmails or a dedup store other than
+// MemoryDedupStore. If n < 1, runtime.GOMAXPROCS(0) is used.
+func WithChunks(n int) Option {
+	return func(f *CustomerImporter) {
+		if n < 1 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		f.chunks = n
+	}
+}
+
+// returns the input file and offset of its unread part if the file can be
+// split into chunks, duplicate in a chunk would fail the import depending on
+// which chunk counts the email first
+func (c *CustomerImporter) chunkedFile() (*os.File, int64, bool) {
+	file, ok := c.input.(*os.File)
+	if !ok || c.chunks < 2 || !c.skipErrDupEmails || c.headerless || c.skipRows > 0 || c.encoding != "" || c.checkpointPath != "" ||
+		c.handler != nil || c.rejectWriter != nil || c.onError != nil || c.limitErrors || c.memoryLimit > 0 ||
+		c.maxRows > 0 || c.maxBytes > 0 || c.maxValidEmails > 0 {
+		return nil, 0, false
+	}
+	if _, ok := c.countedEmails.(MemoryDedupStore); !ok || c.counterStore != nil {
+		return nil, 0, false
+	}
+	if info, err := file.Stat(); err != nil || !info.Mode().IsRegular() {
+		return nil, 0, false
+	}
+	start, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	// compressed files and sep= directive are handled by sequential reading
+	prefix := make([]byte, 64)
+	n, _ := file.ReadAt(prefix, start)
+	prefix = bytes.TrimPrefix(prefix[:n], utf8BOM)
+	if i := bytes.IndexByte(prefix, '\n'); i >= 0 {
+		if _, ok := sepDirective(string(prefix[:i+1])); ok {
+			return nil, 0, false
+		}
+	}
+	switch c.compression {
+	case CompressionNone:
+	case CompressionAuto:
+		if bytes.HasPrefix(prefix, gzipMagic) || bytes.HasPrefix(prefix, zstdMagic) {
+			return nil, 0, false
+		}
+	default:
+		return nil, 0, false
+	}
+
+	return file, start, true
+}
+
+// parses header of the file and its records by chunks in parallel
+func (c *CustomerImporter) parseChunks(file *os.File, start int64) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	end := info.Size()
+
+	// skip byte order mark
+	bom := make([]byte, len(utf8BOM))
+	if n, _ := file.ReadAt(bom, start); bytes.Equal(bom[:n], utf8BOM) {
+		start += int64(n)
+	}
+
+	// read header, records of the chunks follow it
+	header := c.chunkReader(io.NewSectionReader(file, start, end-start))
+	header.FieldsPerRecord = 0
+	c.reader = header
+	if err := c.parseHeader(); err != nil {
+		return err
+	}
+	headerLines, err := countLines(io.NewSectionReader(file, start, header.InputOffset()))
+	if err != nil {
+		return err
+	}
+	start += header.InputOffset()
+
+	bounds, err := chunkBounds(file, start, end, c.chunks)
+	if err != nil {
+		return err
+	}
+
+	// parse chunks, a failed chunk stops the following ones, so errors of the
+	// previous chunks are reported first as they would be by sequential reading
+	store := newShardedDedupStore(c.countedEmails.(MemoryDedupStore))
+	chunks := make([]*CustomerImporter, len(bounds)-1)
+	sections := make([]*chunkSection, len(chunks))
+	errs := make([]error, len(chunks))
+	var failed atomic.Int64
+	failed.Store(int64(len(chunks)))
+	var wg sync.WaitGroup
+	for i := range chunks {
+		sections[i] = &chunkSection{r: io.NewSectionReader(file, bounds[i], bounds[i+1]-bounds[i]), index: int64(i), failed: &failed}
+		chunks[i] = c.newChunk(store, c.chunkReader(sections[i]))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := chunks[i].parseSequentially(); err != nil {
+				errs[i] = err
+				for f := failed.Load(); int64(i) < f && !failed.CompareAndSwap(f, int64(i)); f = failed.Load() {
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// merge chunks in order, lines of every chunk follow lines of the previous
+	// ones
+	lines := headerLines
+	for i, chunk := range chunks {
+		if errs[i] != nil {
+			return shiftLines(errs[i], c.line, lines)
+		}
+		c.mergeChunk(chunk)
+		lines += sections[i].lines
+	}
+	store.copyTo(c.countedEmails.(MemoryDedupStore))
+
+	return nil
+}
+
+// returns csv reader of the chunk configured by the options, records must
+// have the same amount of fields as the header
+func (c *CustomerImporter) chunkReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	c.configureCSV(reader, 0)
+	if !c.variableFieldCount {
+		reader.FieldsPerRecord = len(c.header)
+	}
+	reader.ReuseRecord = c.fastPath
+	return reader
+}
+
+// returns importer of a chunk sharing options, header and dedup store with c
+func (c *CustomerImporter) newChunk(store DedupStore, reader *csv.Reader) *CustomerImporter {
+	chunk := newCustomerImporter(nil, c.emailFieldName, c.options...)
+	chunk.reader, chunk.countedEmails, chunk.fileName = reader, store, c.fileName
+	chunk.header, chunk.emailFieldName = c.header, c.emailFieldName
+	chunk.emailColumnIndex, chunk.emailColumnIndexes, chunk.breakdownColumnIndex = c.emailColumnIndex, c.emailColumnIndexes, c.breakdownColumnIndex
+	chunk.timeColumnIndex = c.timeColumnIndex
+	return chunk
+}
+
+// adds counters and statistics of the chunk read after the current line
+func (c *CustomerImporter) mergeChunk(chunk *CustomerImporter) {
+	for domain, count := range chunk.domainCounter {
+		c.domainCounter[domain] += count
+	}
+	for domain, sketch := range chunk.sketches {
+		if c.sketches[domain] == nil {
+			c.sketches[domain] = newHyperLogLog(c.hllPrecision)
+		}
+		c.sketches[domain].merge(sketch)
+	}
+	if chunk.typos != nil {
+		for domain, count := range chunk.typos.counts {
+			c.typos.counts[domain] += count
+			c.typos.suggestions[domain] = chunk.typos.suggestions[domain]
+		}
+	}
+	if chunk.confusables != nil {
+		for domain, count := range chunk.confusables.counts {
+			c.confusables.counts[domain] += count
+			c.confusables.skeletons[domain] = chunk.confusables.skeletons[domain]
+		}
+	}
+	for domain, count := range chunk.occurrences {
+		c.occurrences[domain] += count
+	}
+	for domain, count := range chunk.roleAccounts {
+		c.roleAccounts[domain] += count
+	}
+	for domain, breakdown := range chunk.breakdown {
+		c.breakdown[domain] = mergeBreakdown(c.breakdown[domain], breakdown)
+	}
+	for domain, series := range chunk.series {
+		c.series[domain] = mergeBreakdown(c.series[domain], series)
+	}
+	for domain, samples := range chunk.samples {
+		for _, s := range samples {
+			if len(c.samples[domain]) < c.samplesPerDomain {
+				s.Line += c.line
+				c.samples[domain] = append(c.samples[domain], s)
+			}
+		}
+	}
+	for reason, count := range chunk.invalidByReason {
+		c.invalidByReason[reason] += count
+	}
+	for _, e := range chunk.rowErrors {
+		e.Line += c.line
+		c.rowErrors = append(c.rowErrors, e)
+	}
+
+	c.rowsRead += chunk.rowsRead
+	c.validEmails += chunk.validEmails
+	c.invalidEmails += chunk.invalidEmails
+	c.duplicateEmails += chunk.duplicateEmails
+	c.filteredRows += chunk.filteredRows
+	c.sampledOutRows += chunk.sampledOutRows
+	c.reservedEmails += chunk.reservedEmails
+	c.roleAccountsCount += chunk.roleAccountsCount
+	c.suppressedEmails += chunk.suppressedEmails
+	c.malformedRows += chunk.malformedRows
+	c.line += chunk.rowsRead + chunk.malformedRows
+}
+
+// returns err of a chunk with lines shifted by the lines of the previous
+// chunks, records are counted in line and physical lines in csv errors
+func shiftLines(err error, line, physicalLines int) error {
+	var importError *ImportError
+	if errors.As(err, &importError) {
+		importError.Line += line
+	}
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) {
+		parseError.StartLine += physicalLines
+		parseError.Line += physicalLines
+	}
+	return err
+}
+
+// returns offsets of at most n chunks between start and end, every chunk
+// begins at the beginning of a line and ends where the next one begins
+func chunkBounds(r io.ReaderAt, start, end int64, n int) ([]int64, error) {
+	bounds := []int64{start}
+	buf := make([]byte, 4096)
+	for i := 1; i < n; i++ {
+		offset := start + (end-start)*int64(i)/int64(n)
+		if offset <= bounds[len(bounds)-1] {
+			continue
+		}
+
+		// move offset after the nearest line break
+		for pos := offset - 1; ; {
+			read, err := r.ReadAt(buf, pos)
+			if j := bytes.IndexByte(buf[:read], '\n'); j >= 0 {
+				offset = pos + int64(j) + 1
+				break
+			}
+			if err == io.EOF {
+				offset = end
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			pos += int64(read)
+		}
+		if offset < end {
+			bounds = append(bounds, offset)
+		}
+	}
+	return append(bounds, end), nil
+}
+
+// returns amount of line breaks read from r
+func countLines(r io.Reader) (int, error) {
+	section := &chunkSection{r: r, failed: &atomic.Int64{}}
+	_, err := io.Copy(io.Discard, section)
+	return section.lines, err
+}
+
+// chunkSection reads byte range of the chunk counting its lines, reading is
+// stopped when a previous chunk fails
+type chunkSection struct {
+	r      io.Reader
+	index  int64         // index of the chunk
+	failed *atomic.Int64 // index of the first failed chunk
+	lines  int           // amount of line breaks read
+}
+
+func (s *chunkSection) Read(p []byte) (int, error) {
+	if s.failed.Load() < s.index {
+		return 0, errChunkStopped
+	}
+	n, err := s.r.Read(p)
+	s.lines += bytes.Count(p[:n], []byte{'\n'})
+	return n, err
+}
+
+// shardedDedupStore is a set of emails safe for concurrent use by chunks,
+// emails are spread over shards locked separately
+type shardedDedupStore struct {
+	shards [dedupShards]struct {
+		sync.Mutex
+		emails MemoryDedupStore
+	}
+}
+
+// returns store containing the emails
+func newShardedDedupStore(emails MemoryDedupStore) *shardedDedupStore {
+	s := &shardedDedupStore{}
+	for i := range s.shards {
+		s.shards[i].emails = make(MemoryDedupStore)
+	}
+	for email := range emails {
+		s.shards[fnv1a(email)%dedupShards].emails[email] = struct{}{}
+	}
+	return s
+}
+
+func (s *shardedDedupStore) Add(email string) (bool, error) {
+	shard := &s.shards[fnv1a(email)%dedupShards]
+	shard.Lock()
+	defer shard.Unlock()
+	return shard.emails.Add(email)
+}
+
+// adds emails of all shards to the set
+func (s *shardedDedupStore) copyTo(emails MemoryDedupStore) {
+	for i := range s.shards {
+		maps.Copy(emails, s.shards[i].emails)
+	}
+}
@@ -0,0 +1,188 @@
+package customerimporter
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writes data to a temporary file and returns its name
+func writeChunkFile(t *testing.T, name, data string) string {
+	fileName := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fileName, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestWithChunks(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("\xEF\xBB\xBFname,email,country\n")
+	for i := 0; i < 200; i++ {
+		switch {
+		case i%17 == 0:
+			fmt.Fprintf(&b, "N%d,invalid%d,PL\n", i, i)
+		case i%13 == 0:
+			fmt.Fprintf(&b, "N%d,user%d@domain,DE\n", i, i)
+		default:
+			fmt.Fprintf(&b, "N%d,user%d@domain%d.io,%s\n", i, i, i%7, []string{"PL", "DE", "CZ"}[i%3])
+		}
+	}
+	records := b.String()
+	fileName := writeChunkFile(t, "customers.csv", records)
+
+	data := [][]Option{
+		{SkipErrInvalidEmails()},
+		{CollectErrors(), WithBreakdown("country"), WithSamples(3), CountOccurrences()},
+		{CollectErrors(), WithRecordFilter(FieldEquals("country", "PL")), FastPath()},
+		{SkipErrInvalidEmails(), ValidateOnly()},
+	}
+	for i := range data {
+		data[i] = append(data[i], SkipErrDuplicateEmails())
+	}
+
+	t.Log("Should return the same result as sequential import")
+	for testNumber, options := range data {
+		t.Logf("Case: %v", testNumber)
+
+		expected, expectedErr := ImportFromFileWithStats(fileName, "email", options...)
+		for _, chunks := range []int{2, 7, 1000} {
+			result, err := ImportFromFileWithStats(fileName, "email", append(options, WithChunks(chunks))...)
+			if !reflect.DeepEqual(err, expectedErr) {
+				t.Errorf("%v chunks should raise error: %v, but got error %v", chunks, expectedErr, err)
+			}
+			if result == nil {
+				continue
+			}
+			result.Elapsed, expected.Elapsed = 0, 0
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("%v chunks should result with: %+v, but got %+v", chunks, expected, result)
+			}
+		}
+	}
+
+	t.Log("Should count duplicates in different chunks once")
+	duplicates := writeChunkFile(t, "duplicates.csv", "email\n"+strings.Repeat("a@a.io\nb@a.io\nc@b.io\n", 50))
+	result, err := ImportFromFileWithStats(duplicates, "email", SkipErrDuplicateEmails(), WithChunks(8))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	if !reflect.DeepEqual(result.Domains, expected) || result.DuplicateEmails != 147 {
+		t.Errorf("should result with: %v and 147 duplicates, but got %+v", expected, result)
+	}
+
+	t.Log("Should fail on the first duplicate as sequential import")
+	for range 10 {
+		_, err := ImportFromFile(duplicates, "email", WithChunks(8))
+		var importError *ImportError
+		if !errors.As(err, &importError) || !errors.Is(err, ErrEmailDuplicate) || importError.Line != 5 {
+			t.Fatalf("should raise error: %v on line 5, but got error %v", ErrEmailDuplicate, err)
+		}
+	}
+}
+
+func TestWithChunksErrors(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("name,email\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, "N%d,user%d@a.io\n", i, i)
+	}
+	records := b.String()
+
+	data := []struct {
+		name    string
+		records string
+	}{
+		// the first error is reported with line of the whole file
+		{"invalid emails", strings.Replace(strings.Replace(records, "user80@a.io", "invalid80", 1), "user30@a.io", "invalid30", 1)},
+		{"malformed record", strings.Replace(records, "N60,user60@a.io", "N60,user60@a.io,extra", 1)},
+		{"bare quote", strings.Replace(records, "N70,user70", "N\"70,user70", 1)},
+	}
+
+	t.Log("Should report the first error as sequential import")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		fileName := writeChunkFile(t, "customers.csv", d.records)
+		_, expected := ImportFromFile(fileName, "email", SkipErrDuplicateEmails())
+		_, err := ImportFromFile(fileName, "email", SkipErrDuplicateEmails(), WithChunks(4))
+		if expected == nil || err == nil || err.Error() != expected.Error() {
+			t.Errorf("should raise error: %v, but got error %v", expected, err)
+		}
+	}
+
+	t.Log("Should shift lines of csv errors")
+	fileName := writeChunkFile(t, "customers.csv", data[2].records)
+	_, err := ImportFromFile(fileName, "email", SkipErrDuplicateEmails(), WithChunks(4))
+	var parseError *csv.ParseError
+	if !errors.As(err, &parseError) || parseError.Line != 72 {
+		t.Errorf("should raise error on line 72, but got error %v", err)
+	}
+}
+
+func TestWithChunksFallback(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"customers.csv.gz": gzipData(t, "email\na@a.io\nb@a.io\nc@b.io\n"),
+		"customers.gz":     gzipData(t, "email\na@a.io\nb@a.io\nc@b.io\n"),
+		"excel.csv":        []byte("sep=;\nemail;name\na@a.io;A\nb@a.io;B\nc@b.io;C\n"),
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+
+	t.Log("Should import files which can't be split sequentially")
+	for name, data := range files {
+		t.Logf("Case: %v", name)
+
+		fileName := filepath.Join(dir, name)
+		if err := os.WriteFile(fileName, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		result, err := ImportFromFile(fileName, "email", WithChunks(4))
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("should result with: %v, but got %v", expected, *result)
+		}
+	}
+}
+
+func TestChunkBounds(t *testing.T) {
+	data := "email\na@a.io\nb@a.io\nc@b.io\n"
+
+	tests := []struct {
+		n      int
+		bounds []int64
+	}{
+		{1, []int64{6, 27}},
+		{2, []int64{6, 20, 27}},
+		{3, []int64{6, 13, 20, 27}},
+		{100, []int64{6, 13, 20, 27}},
+	}
+
+	t.Log("Should split records at line boundaries")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.n)
+
+		bounds, err := chunkBounds(strings.NewReader(data), 6, int64(len(data)), test.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(bounds, test.bounds) {
+			t.Errorf("should return %v, but got %v", test.bounds, bounds)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package customerimporter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DomainCategory tells what kind of organization the domain belongs to
+type DomainCategory int
+
+const (
+	CategoryNotClassified DomainCategory = iota // domain wasn't classified
+	CategoryFreemail                            // free mail provider, e.g. gmail.com
+	CategoryEducation                           // school or university, e.g. mit.edu
+	CategoryGovernment                          // government or military, e.g. nasa.gov
+	CategoryCorporate                           // any other domain, usually a company
+)
+
+// String returns human readable name of the category
+func (c DomainCategory) String() string {
+	switch c {
+	case CategoryNotClassified:
+		return "not classified"
+	case CategoryFreemail:
+		return "freemail"
+	case CategoryEducation:
+		return "edu"
+	case CategoryGovernment:
+		return "gov"
+	case CategoryCorporate:
+		return "corporate"
+	}
+	return "unknown"
+}
+
+// MarshalText encodes the category as its name
+func (c DomainCategory) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText decodes the category from its name
+func (c *DomainCategory) UnmarshalText(text []byte) error {
+	for category := CategoryNotClassified; category <= CategoryCorporate; category++ {
+		if category.String() == string(text) {
+			*c = category
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid domain category %q", text)
+}
+
+// freemailDomains of well-known free mail providers
+var freemailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true,
+	"yahoo.com": true, "yahoo.co.uk": true, "yahoo.co.jp": true, "yahoo.fr": true, "yahoo.de": true, "ymail.com": true,
+	"outlook.com": true, "hotmail.com": true, "hotmail.co.uk": true, "hotmail.fr": true, "live.com": true, "msn.com": true,
+	"aol.com": true, "icloud.com": true, "me.com": true, "mac.com": true,
+	"proton.me": true, "protonmail.com": true, "tutanota.com": true, "fastmail.com": true,
+	"mail.com": true, "gmx.com": true, "gmx.de": true, "gmx.net": true, "web.de": true, "zoho.com": true,
+	"yandex.ru": true, "yandex.com": true, "mail.ru": true,
+	"qq.com": true, "163.com": true, "126.com": true, "naver.com": true,
+}
+
+// top-level domains of education and government and their second-level
+// labels under country code domains, e.g. ox.ac.uk and nic.gov.pl
+var (
+	educationTLDs    = []string{"edu"}
+	educationLabels  = []string{"edu", "ac"}
+	governmentTLDs   = []string{"gov", "mil"}
+	governmentLabels = []string{"gov", "gob", "gouv", "govt", "go", "mil", "gc"}
+)
+
+// DomainClassifier assigns categories to domains. Domains of the lists are
+// classified in addition to the built-in ones, e.g. regional free mail
+// providers, they match subdomains too.
+type DomainClassifier struct {
+	Freemail   []string // additional free mail providers
+	Education  []string // additional education domains, e.g. uni-heidelberg.de
+	Government []string // additional government domains, e.g. bund.de
+}
+
+// Classify counted domains as free mail, education, government or corporate
+// ones with the built-in lists, set category of the result entries and sum
+// their emails by category in ImportResult.Categories.
+func ClassifyDomains() Option { return ClassifyDomainsWith(&DomainClassifier{}) }
+
+// Classify counted domains with the classifier, set category of the result
+// entries and sum their emails by category in ImportResult.Categories.
+func ClassifyDomainsWith(dc *DomainClassifier) Option {
+	return func(f *CustomerImporter) { f.classifier = dc }
+}
+
+// Classify returns category of the normalized domain
+func (dc *DomainClassifier) Classify(domain string) DomainCategory {
+	switch {
+	case freemailDomains[domain] || matchesDomain(domain, dc.Freemail):
+		return CategoryFreemail
+	case hasCategoryLabel(domain, educationTLDs, educationLabels) || matchesDomain(domain, dc.Education):
+		return CategoryEducation
+	case hasCategoryLabel(domain, governmentTLDs, governmentLabels) || matchesDomain(domain, dc.Government):
+		return CategoryGovernment
+	}
+	return CategoryCorporate
+}
+
+// sets category of the entries and returns emails count by category
+func (dc *DomainClassifier) classify(result EmailsByDomainQtyList) map[DomainCategory]int {
+	categories := make(map[DomainCategory]int)
+	for i := range result {
+		result[i].Category = dc.Classify(result[i].Domain)
+		categories[result[i].Category] += result[i].EmailsCount
+	}
+	return categories
+}
+
+// reports whether domain is under one of the top-level domains, e.g. mit.edu,
+// or under one of the second-level labels of a country code domain, e.g.
+// ox.ac.uk
+func hasCategoryLabel(domain string, tlds, labels []string) bool {
+	parts := strings.Split(domain, ".")
+	if len(parts) < 2 {
+		return false
+	}
+	if slices.Contains(tlds, parts[len(parts)-1]) {
+		return true
+	}
+	return len(parts) > 2 && len(parts[len(parts)-1]) == 2 && slices.Contains(labels, parts[len(parts)-2])
+}
+
+// reports whether domain is one of the domains or their subdomain
+func matchesDomain(domain string, domains []string) bool {
+	for _, d := range domains {
+		d = normalizeDomain(d)
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
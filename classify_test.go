@@ -0,0 +1,76 @@
+package customerimporter
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDomainClassifier(t *testing.T) {
+	dc := &DomainClassifier{Freemail: []string{"Wp.pl."}, Education: []string{"uni-heidelberg.de"}, Government: []string{"bund.de"}}
+
+	tests := []struct {
+		domain   string
+		category DomainCategory
+	}{
+		{"gmail.com", CategoryFreemail},
+		{"yahoo.co.uk", CategoryFreemail},
+		{"wp.pl", CategoryFreemail},
+		{"poczta.wp.pl", CategoryFreemail},
+		{"mit.edu", CategoryEducation},
+		{"cs.stanford.edu", CategoryEducation},
+		{"ox.ac.uk", CategoryEducation},
+		{"uw.edu.pl", CategoryEducation},
+		{"mathi.uni-heidelberg.de", CategoryEducation},
+		{"nasa.gov", CategoryGovernment},
+		{"army.mil", CategoryGovernment},
+		{"nic.gov.pl", CategoryGovernment},
+		{"interieur.gouv.fr", CategoryGovernment},
+		{"bund.de", CategoryGovernment},
+		{"github.io", CategoryCorporate},
+		{"ac.com", CategoryCorporate},
+		{"edu.example.com", CategoryCorporate},
+		{"notgmail.com", CategoryCorporate},
+	}
+
+	t.Log("Should classify domains")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.domain)
+
+		if category := dc.Classify(test.domain); category != test.category {
+			t.Errorf("should classify as %v, but got %v", test.category, category)
+		}
+	}
+}
+
+func TestClassifyDomains(t *testing.T) {
+	records := "email\na@gmail.com\nb@gmail.com\nc@mit.edu\nd@github.io\ne@github.io\nf@github.io\n"
+
+	result, err := ImportWithStats(strings.NewReader(records), "email", ClassifyDomains(), TopN(1, true))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+
+	t.Log("Should sum emails of all domains by category")
+	categories := map[DomainCategory]int{CategoryFreemail: 2, CategoryEducation: 1, CategoryCorporate: 3}
+	if !reflect.DeepEqual(result.Categories, categories) {
+		t.Errorf("should result with: %v, but got %v", categories, result.Categories)
+	}
+	if result.Domains[0].Category != CategoryCorporate || result.Domains[1].Category != CategoryNotClassified {
+		t.Errorf("should set category of the top domain only, but got %+v", result.Domains)
+	}
+
+	t.Log("Should encode categories by name")
+	data, err := json.Marshal(result.Categories)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"corporate":3,"edu":1,"freemail":2}`; string(data) != expected {
+		t.Errorf("should encode as %v, but got %s", expected, data)
+	}
+	var decoded map[DomainCategory]int
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, categories) {
+		t.Errorf("should decode %v, but got %v, %v", categories, decoded, err)
+	}
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"crypto"
+	"flag"
+	"fmt"
+	"strings"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// optionFlags holds command line flags of the importer options shared by
+// subcommands
+type optionFlags struct {
+	skipInvalid     bool
+	skipDuplicate   bool
+	skipEmpty       bool
+	collectErrors   bool
+	redactErrors    bool
+	maxErrors       int
+	maxErrorRate    float64
+	delimiter       string
+	columnIndex     int
+	compression     string
+	validation      string
+	hashEmails      string
+	reserved        string
+	roleAccounts    string
+	caseInsensitive bool
+	displayNames    bool
+	trimEmail       bool
+	canonicalize    bool
+	idn             string
+	groupBy         string
+	domainAliases   string
+	sortByCount     bool
+	descending      bool
+	top             int
+	collapseRest    bool
+	minCount        int
+	verifyMX        bool
+	classify        bool
+	distribution    bool
+	providers       bool
+	detectTypos     bool
+	correctTypos    bool
+	confusables     bool
+	occurrences     bool
+	breakdown       string
+	timeSeries      string
+	period          string
+	timeLayouts     string
+	samples         int
+	workers         int
+	chunks          int
+	fastPath        bool
+	memoryLimit     int64
+	maxRows         int
+	limitEmails     int
+	maxBytes        int64
+	truncate        bool
+	partial         bool
+	approximate     int
+	bloomDedup      uint
+	bloomRate       float64
+	where           []string
+	sampleRate      float64
+	sampleSeed      uint64
+	emailFields     string
+	fieldAliases    string
+	candidates      string
+	expectedHeader  string
+	headerMatch     string
+	ignoreCase      bool
+	trimHeader      bool
+	encoding        string
+	lazyQuotes      bool
+	variableFields  bool
+	comment         string
+	skipMalformed   bool
+	skipRows        int
+	config          string
+	webhook         string
+}
+
+// registers flags of the importer options
+func (cfg *optionFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&cfg.skipInvalid, "skip-invalid", false, "skip invalid emails")
+	fs.BoolVar(&cfg.skipDuplicate, "skip-duplicates", false, "skip duplicate emails")
+	fs.BoolVar(&cfg.skipEmpty, "skip-empty", false, "skip empty emails")
+	fs.BoolVar(&cfg.collectErrors, "collect-errors", false, "skip invalid and duplicate emails and report them")
+	fs.BoolVar(&cfg.redactErrors, "redact-errors", false, "keep values of records out of errors and logs")
+	fs.IntVar(&cfg.maxErrors, "max-errors", -1, "abort after `n` skipped records, disabled if negative")
+	fs.Float64Var(&cfg.maxErrorRate, "max-error-rate", 0, "abort if more than `pct` percent of records are skipped")
+	fs.StringVar(&cfg.emailFields, "email-fields", "", "comma separated `names` of additional email columns")
+	fs.StringVar(&cfg.fieldAliases, "field-aliases", "", "comma separated accepted `names` of the email column")
+	fs.StringVar(&cfg.candidates, "field-candidates", "", "comma separated `names` of the email column tried in order if the header has no --email-field")
+	fs.StringVar(&cfg.expectedHeader, "expected-header", "", "fail unless the header has the comma separated `names`")
+	fs.StringVar(&cfg.headerMatch, "header-match", "exact", "`comparison` of the header to --expected-header: exact or subset")
+	fs.BoolVar(&cfg.ignoreCase, "ignore-header-case", false, "match header fields case-insensitively")
+	fs.BoolVar(&cfg.trimHeader, "trim-header", false, "ignore whitespace around header fields")
+	fs.StringVar(&cfg.config, "config", "", "read delimiter, encoding, field aliases, skipped rows and filters from YAML or JSON mapping `file`")
+	fs.StringVar(&cfg.delimiter, "delimiter", "", "field delimiter `char`, e.g. ';' or '\\t'")
+	fs.BoolVar(&cfg.lazyQuotes, "lazy-quotes", false, "allow quotes in unquoted fields")
+	fs.BoolVar(&cfg.variableFields, "variable-fields", false, "allow records with different amount of fields")
+	fs.StringVar(&cfg.comment, "comment", "", "ignore lines beginning with comment `char`, e.g. '#'")
+	fs.BoolVar(&cfg.skipMalformed, "skip-malformed", false, "skip records with different amount of fields")
+	fs.IntVar(&cfg.skipRows, "skip-rows", 0, "skip `n` lines before the header")
+	fs.IntVar(&cfg.columnIndex, "column-index", -1, "read file without header taking email from column `i`")
+	fs.StringVar(&cfg.encoding, "encoding", "", "input `encoding`, e.g. windows-1252 or utf-16le, UTF-8 by default")
+	fs.StringVar(&cfg.compression, "compression", "auto", "input `compression`: auto, none, gzip or zstd")
+	fs.StringVar(&cfg.validation, "validation", "standard", "email validation `level`: lenient, standard or strict")
+	fs.StringVar(&cfg.reserved, "reserved", "", "`handling` of reserved domains like example.com and synthetic addresses like test@: drop or bucket")
+	fs.StringVar(&cfg.roleAccounts, "role-accounts", "", "`handling` of role accounts like info@ or noreply@: exclude or report")
+	fs.StringVar(&cfg.hashEmails, "hash-emails", "", "keep digests instead of emails and mask them in output, `algorithm`: sha256 or sha512")
+	fs.BoolVar(&cfg.caseInsensitive, "case-insensitive", false, "compare local parts of emails case-insensitively")
+	fs.BoolVar(&cfg.displayNames, "strip-display-names", false, "take emails from values like 'Name <email>'")
+	fs.BoolVar(&cfg.trimEmail, "trim-email", false, "trim whitespace and trailing punctuation of emails")
+	fs.BoolVar(&cfg.canonicalize, "canonicalize", false, "treat tagged and dotted addresses of providers like Gmail as duplicates")
+	fs.StringVar(&cfg.idn, "idn", "", "convert internationalized domains to `form`: ascii or unicode")
+	fs.StringVar(&cfg.groupBy, "group-by", "domain", "count emails by `key`: domain, registrable or tld")
+	fs.StringVar(&cfg.domainAliases, "domain-aliases", "", "comma separated `alias=domain` pairs of equivalent domains")
+	fs.BoolVar(&cfg.sortByCount, "sort-by-count", false, "sort result by emails count")
+	fs.BoolVar(&cfg.descending, "desc", false, "sort result in descending order")
+	fs.IntVar(&cfg.top, "top", 0, "return only `n` domains with the most emails")
+	fs.IntVar(&cfg.minCount, "min-count", 0, "return only domains with at least `n` emails")
+	fs.BoolVar(&cfg.collapseRest, "collapse-rest", false, "sum emails of domains beyond --top or below --min-count into other entry")
+	fs.BoolVar(&cfg.verifyMX, "verify-mx", false, "look up MX records of counted domains")
+	fs.BoolVar(&cfg.providers, "providers", false, "infer mail providers of counted domains by MX records")
+	fs.BoolVar(&cfg.classify, "classify", false, "classify domains as freemail, edu, gov or corporate")
+	fs.BoolVar(&cfg.distribution, "distribution", false, "report how many domains have 1, 2-10, 11-100 and more emails, mean, median, top 10 share and HHI")
+	fs.BoolVar(&cfg.detectTypos, "detect-typos", false, "report likely typos of popular mail domains, e.g. gmial.com")
+	fs.BoolVar(&cfg.confusables, "confusables", false, "report domains with letters of other scripts looking like Latin ones, e.g. Cyrillic а")
+	fs.BoolVar(&cfg.correctTypos, "correct-typos", false, "count likely typos of popular mail domains as the corrected domain")
+	fs.StringVar(&cfg.breakdown, "breakdown", "", "count emails of every domain also by `field`, e.g. country")
+	fs.StringVar(&cfg.timeSeries, "time-series", "", "count emails of every domain also by period of timestamp `field`, e.g. created_at")
+	fs.StringVar(&cfg.period, "period", "day", "`period` of --time-series: day, week or month")
+	fs.StringVar(&cfg.timeLayouts, "time-layouts", "", "comma-separated Go `layouts` of --time-series timestamps, e.g. 01/02/2006, RFC 3339 and ISO dates if empty")
+	fs.IntVar(&cfg.samples, "samples", 0, "keep `n` first emails of every domain with their lines")
+	fs.BoolVar(&cfg.occurrences, "occurrences", false, "count occurrences of emails by domain, duplicates included")
+	fs.IntVar(&cfg.workers, "workers", 1, "amount of `n` goroutines parsing records, all CPUs if 0")
+	fs.IntVar(&cfg.chunks, "chunks", 1, "split files into `n` chunks imported in parallel if duplicates are skipped, all CPUs if 0")
+	fs.BoolVar(&cfg.fastPath, "fast-path", false, "reuse records and intern domains to reduce allocations")
+	fs.IntVar(&cfg.maxRows, "max-rows", 0, "read at most `n` records")
+	fs.IntVar(&cfg.limitEmails, "limit-emails", 0, "stop reading when `n` valid emails are counted")
+	fs.Int64Var(&cfg.maxBytes, "max-bytes", 0, "read at most `n` bytes of decompressed input")
+	fs.BoolVar(&cfg.truncate, "truncate", false, "return result of the records read up to --max-rows or --max-bytes instead of error")
+	fs.BoolVar(&cfg.partial, "partial", false, "print counts of the records read before an error aborting the import")
+	fs.Int64Var(&cfg.memoryLimit, "memory-limit", 0, "spill counted emails and domains to temporary files above about `bytes` of memory")
+	fs.IntVar(&cfg.approximate, "approximate", -1, "estimate unique emails by HyperLogLog sketches of 2^`precision` registers, 12 if 0")
+	fs.UintVar(&cfg.bloomDedup, "bloom-dedup", 0, "detect duplicates by Bloom filter sized for `n` emails")
+	fs.Float64Var(&cfg.bloomRate, "bloom-fp-rate", 0.001, "false positive `rate` of --bloom-dedup")
+	fs.Float64Var(&cfg.sampleRate, "sample-rate", 0, "count only deterministic sample of `fraction` of the emails, e.g. 0.01")
+	fs.Uint64Var(&cfg.sampleSeed, "sample-seed", 0, "`seed` selecting the emails of --sample-rate")
+	fs.Func("where", "count only records with `field=value`, may be repeated", func(s string) error {
+		cfg.where = append(cfg.where, s)
+		return nil
+	})
+	fs.StringVar(&cfg.webhook, "webhook", "", "post JSON summary of the finished import to `URL`")
+}
+
+// converts flags to importer options
+func (cfg *optionFlags) options() ([]customerimporter.Option, error) {
+	var options []customerimporter.Option
+
+	// error handling
+	if cfg.skipInvalid {
+		options = append(options, customerimporter.SkipErrInvalidEmails())
+	}
+	if cfg.skipDuplicate {
+		options = append(options, customerimporter.SkipErrDuplicateEmails())
+	}
+	if cfg.skipEmpty {
+		options = append(options, customerimporter.SkipEmptyEmails())
+	}
+	if cfg.collectErrors {
+		options = append(options, customerimporter.CollectErrors())
+	}
+	if cfg.redactErrors {
+		options = append(options, customerimporter.RedactErrors())
+	}
+	if cfg.maxErrors >= 0 {
+		options = append(options, customerimporter.WithMaxErrors(cfg.maxErrors))
+	}
+	if cfg.maxErrorRate > 0 {
+		options = append(options, customerimporter.WithMaxErrorRate(cfg.maxErrorRate))
+	}
+
+	// input format, flags override values of the config file
+	if cfg.config != "" {
+		config, err := customerimporter.LoadConfig(cfg.config)
+		if err != nil {
+			return nil, err
+		}
+		configOptions, err := config.Options()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, configOptions...)
+	}
+	if cfg.delimiter != "" {
+		delimiter := []rune(cfg.delimiter)
+		if cfg.delimiter == `\t` {
+			delimiter = []rune{'\t'}
+		}
+		if len(delimiter) != 1 {
+			return nil, fmt.Errorf("invalid delimiter %q", cfg.delimiter)
+		}
+		options = append(options, customerimporter.WithDelimiter(delimiter[0]))
+	}
+	if cfg.lazyQuotes {
+		options = append(options, customerimporter.LazyQuotes())
+	}
+	if cfg.variableFields {
+		options = append(options, customerimporter.VariableFieldCount())
+	}
+	if cfg.comment != "" {
+		comment := []rune(cfg.comment)
+		if len(comment) != 1 {
+			return nil, fmt.Errorf("invalid comment %q", cfg.comment)
+		}
+		options = append(options, customerimporter.WithComment(comment[0]))
+	}
+	if cfg.skipMalformed {
+		options = append(options, customerimporter.SkipMalformedRows())
+	}
+	if cfg.skipRows > 0 {
+		options = append(options, customerimporter.SkipRows(cfg.skipRows))
+	}
+	if cfg.emailFields != "" {
+		options = append(options, customerimporter.WithEmailFields(strings.Split(cfg.emailFields, ",")...))
+	}
+	if cfg.fieldAliases != "" {
+		options = append(options, customerimporter.WithFieldAliases(strings.Split(cfg.fieldAliases, ",")...))
+	}
+	if cfg.candidates != "" {
+		options = append(options, customerimporter.WithFieldCandidates(strings.Split(cfg.candidates, ",")...))
+	}
+	if cfg.expectedHeader != "" {
+		match := customerimporter.HeaderExact
+		switch cfg.headerMatch {
+		case "exact":
+		case "subset":
+			match = customerimporter.HeaderSubset
+		default:
+			return nil, fmt.Errorf("invalid header match %q", cfg.headerMatch)
+		}
+		options = append(options, customerimporter.WithExpectedHeader(strings.Split(cfg.expectedHeader, ","), match))
+	}
+	if cfg.ignoreCase {
+		options = append(options, customerimporter.CaseInsensitiveHeader())
+	}
+	if cfg.trimHeader {
+		options = append(options, customerimporter.TrimHeader())
+	}
+	if cfg.columnIndex >= 0 {
+		options = append(options, customerimporter.WithColumnIndex(cfg.columnIndex))
+	}
+	if cfg.encoding != "" {
+		options = append(options, customerimporter.WithEncoding(cfg.encoding))
+	}
+	switch cfg.compression {
+	case "auto":
+	case "none":
+		options = append(options, customerimporter.WithCompression(customerimporter.CompressionNone))
+	case "gzip":
+		options = append(options, customerimporter.WithCompression(customerimporter.CompressionGzip))
+	case "zstd":
+		options = append(options, customerimporter.WithCompression(customerimporter.CompressionZstd))
+	default:
+		return nil, fmt.Errorf("invalid compression %q", cfg.compression)
+	}
+
+	// record filters
+	for _, where := range cfg.where {
+		field, value, ok := strings.Cut(where, "=")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid filter %q", where)
+		}
+		options = append(options, customerimporter.WithRecordFilter(customerimporter.FieldEquals(field, value)))
+	}
+
+	if cfg.sampleRate != 0 {
+		if cfg.sampleRate <= 0 || cfg.sampleRate >= 1 {
+			return nil, fmt.Errorf("invalid sample rate %v", cfg.sampleRate)
+		}
+		options = append(options, customerimporter.SampleRate(cfg.sampleRate, cfg.sampleSeed))
+	}
+
+	// validation and normalization
+	switch cfg.validation {
+	case "standard":
+	case "lenient":
+		options = append(options, customerimporter.WithValidation(customerimporter.ValidationLenient))
+	case "strict":
+		options = append(options, customerimporter.WithValidation(customerimporter.ValidationStrict))
+	default:
+		return nil, fmt.Errorf("invalid validation level %q", cfg.validation)
+	}
+	if cfg.caseInsensitive {
+		options = append(options, customerimporter.CaseInsensitiveEmails())
+	}
+	if cfg.displayNames {
+		options = append(options, customerimporter.StripDisplayNames())
+	}
+	if cfg.trimEmail {
+		options = append(options, customerimporter.TrimEmailField())
+	}
+	if cfg.canonicalize {
+		options = append(options, customerimporter.CanonicalizeEmails())
+	}
+	switch cfg.idn {
+	case "":
+	case "ascii":
+		options = append(options, customerimporter.WithIDNForm(customerimporter.IDNASCII))
+	case "unicode":
+		options = append(options, customerimporter.WithIDNForm(customerimporter.IDNUnicode))
+	default:
+		return nil, fmt.Errorf("invalid IDN form %q", cfg.idn)
+	}
+	switch cfg.reserved {
+	case "":
+	case "drop":
+		options = append(options, customerimporter.DropReservedDomains())
+	case "bucket":
+		options = append(options, customerimporter.BucketReservedDomains())
+	default:
+		return nil, fmt.Errorf("invalid handling of reserved domains %q", cfg.reserved)
+	}
+	switch cfg.roleAccounts {
+	case "":
+	case "exclude":
+		options = append(options, customerimporter.ExcludeRoleAccounts())
+	case "report":
+		options = append(options, customerimporter.ReportRoleAccounts())
+	default:
+		return nil, fmt.Errorf("invalid handling of role accounts %q", cfg.roleAccounts)
+	}
+	switch cfg.hashEmails {
+	case "":
+	case "sha256":
+		options = append(options, customerimporter.HashEmails(crypto.SHA256))
+	case "sha512":
+		options = append(options, customerimporter.HashEmails(crypto.SHA512))
+	default:
+		return nil, fmt.Errorf("invalid hash algorithm %q", cfg.hashEmails)
+	}
+
+	// aggregation
+	if cfg.domainAliases != "" {
+		aliases := make(map[string]string)
+		for _, pair := range strings.Split(cfg.domainAliases, ",") {
+			alias, domain, ok := strings.Cut(pair, "=")
+			if !ok || alias == "" || domain == "" {
+				return nil, fmt.Errorf("invalid domain alias %q", pair)
+			}
+			aliases[alias] = domain
+		}
+		options = append(options, customerimporter.WithDomainAliases(aliases))
+	}
+	switch cfg.groupBy {
+	case "domain":
+	case "registrable":
+		options = append(options, customerimporter.GroupByRegistrableDomain())
+	case "tld":
+		options = append(options, customerimporter.GroupByTLD())
+	default:
+		return nil, fmt.Errorf("invalid group by key %q", cfg.groupBy)
+	}
+	if cfg.sortByCount {
+		options = append(options, customerimporter.SortByCount())
+	}
+	if cfg.descending {
+		options = append(options, customerimporter.SortDescending())
+	}
+	if cfg.minCount > 1 {
+		options = append(options, customerimporter.MinCount(cfg.minCount, cfg.collapseRest))
+	}
+	if cfg.top > 0 {
+		options = append(options, customerimporter.TopN(cfg.top, cfg.collapseRest))
+	}
+	if cfg.breakdown != "" {
+		options = append(options, customerimporter.WithBreakdown(cfg.breakdown))
+	}
+	if cfg.timeSeries != "" {
+		period := customerimporter.PeriodDay
+		switch cfg.period {
+		case "day":
+		case "week":
+			period = customerimporter.PeriodWeek
+		case "month":
+			period = customerimporter.PeriodMonth
+		default:
+			return nil, fmt.Errorf("invalid period %q", cfg.period)
+		}
+		var layouts []string
+		if cfg.timeLayouts != "" {
+			layouts = strings.Split(cfg.timeLayouts, ",")
+		}
+		options = append(options, customerimporter.WithTimeSeries(cfg.timeSeries, period, layouts...))
+	}
+	if cfg.samples > 0 {
+		options = append(options, customerimporter.WithSamples(cfg.samples))
+	}
+	if cfg.occurrences {
+		options = append(options, customerimporter.CountOccurrences())
+	}
+	if cfg.verifyMX {
+		options = append(options, customerimporter.VerifyMX())
+	}
+	if cfg.providers {
+		options = append(options, customerimporter.DetectProviders())
+	}
+	if cfg.classify {
+		options = append(options, customerimporter.ClassifyDomains())
+	}
+	if cfg.distribution {
+		options = append(options, customerimporter.ComputeDistribution())
+	}
+	if cfg.detectTypos {
+		options = append(options, customerimporter.DetectTypos())
+	}
+	if cfg.correctTypos {
+		options = append(options, customerimporter.CorrectTypos())
+	}
+	if cfg.confusables {
+		options = append(options, customerimporter.DetectConfusables())
+	}
+	if cfg.workers != 1 {
+		options = append(options, customerimporter.WithWorkers(cfg.workers))
+	}
+	if cfg.chunks != 1 {
+		options = append(options, customerimporter.WithChunks(cfg.chunks))
+	}
+	if cfg.fastPath {
+		options = append(options, customerimporter.FastPath())
+	}
+	if cfg.limitEmails > 0 {
+		options = append(options, customerimporter.LimitValidEmails(cfg.limitEmails))
+	}
+	if cfg.maxRows > 0 {
+		options = append(options, customerimporter.WithMaxRows(cfg.maxRows))
+	}
+	if cfg.maxBytes > 0 {
+		options = append(options, customerimporter.WithMaxBytes(cfg.maxBytes))
+	}
+	if cfg.truncate {
+		options = append(options, customerimporter.TruncateAtLimits())
+	}
+	if cfg.partial {
+		options = append(options, customerimporter.AllowPartialResult())
+	}
+	if cfg.memoryLimit > 0 {
+		options = append(options, customerimporter.WithMemoryLimit(cfg.memoryLimit))
+	}
+	if cfg.approximate >= 0 {
+		options = append(options, customerimporter.ApproximateCounts(cfg.approximate))
+	}
+	if cfg.bloomDedup > 0 {
+		options = append(options, customerimporter.WithBloomDedup(cfg.bloomDedup, cfg.bloomRate))
+	}
+
+	// notifications
+	if cfg.webhook != "" {
+		options = append(options, customerimporter.WithCompletionWebhook(cfg.webhook))
+	}
+
+	return options, nil
+}
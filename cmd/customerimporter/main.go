@@ -0,0 +1,358 @@
+// Command customerimporter counts customer emails by domain in a csv, xlsx or
+// parquet file, or in a csv downloaded from URL or object store (s3://, gs://
+// and azblob:// URLs) or in a Google Sheets range (gsheets:// URLs).
+//
+// Usage:
+//
+//	customerimporter --file customers.csv --email-field email --skip-invalid --format json
+//	customerimporter --file customers.xlsx --sheet Customers
+//	customerimporter --file https://example.com/customers.csv.gz --timeout 5m
+//	customerimporter import --skip-invalid s3://bucket/customers.csv
+//	customerimporter import 'gsheets://1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms/Customers!A:D'
+//	zcat customers.csv.gz | customerimporter import -
+//	customerimporter import --dedup-per-file 'exports/*.csv.gz'
+//	customerimporter import --checkpoint import.checkpoint huge.csv.zst
+//	customerimporter import --output postgres://user@host/imports customers.csv
+//	customerimporter import --entries 'daily/*.csv' exports.tar.gz
+//	customerimporter import support.mbox
+//	customerimporter import --top 5 --template @slack.tmpl customers.csv
+//	customerimporter serve --addr :8080 --max-upload-size 33554432
+//	customerimporter serve-grpc --addr :9090 --timeout 5m --schemes https
+//	customerimporter watch --pattern '*.csv' --skip-duplicates /srv/sftp/drop
+//	customerimporter watch --tail --format json signups.csv
+//
+// Object stores, Google Sheets and the database sink depend on large SDKs,
+// build tags nos3, nogcs, noazblob, nogsheets and nosqldb leave them out of
+// smaller binaries:
+//
+//	go build -tags nos3,nogcs,noazblob,nogsheets,nosqldb ./cmd/customerimporter
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// exit codes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// runs subcommand by the first argument, imports a file by default
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "import":
+			return runImport(args[1:], stdout, stderr)
+		case "serve":
+			return runServe(args[1:], stderr)
+		case "serve-grpc":
+			return runServeGRPC(args[1:], stderr)
+		case "watch":
+			return runWatch(args[1:], stdout, stderr)
+		}
+	}
+	return runImport(args, stdout, stderr)
+}
+
+// importConfig holds command line flags of the import
+type importConfig struct {
+	optionFlags
+	file       string
+	files      []string
+	perFile    bool
+	sheet      string
+	entries    string
+	emailField string
+	format     string
+	template   string
+	timeout    time.Duration
+	checkpoint string
+	every      int
+	rejects    string
+	suppress   string
+	output     string
+	validate   bool
+}
+
+// parses arguments, imports the file and prints result to stdout
+func runImport(args []string, stdout, stderr io.Writer) int {
+	cfg := &importConfig{}
+
+	fs := flag.NewFlagSet("customerimporter", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.file, "file", "", "csv, xlsx, parquet, zip, tar, mbox or vcf `path`, directory of .eml files or csv URL to import, - for stdin, may be given as arguments (required)")
+	fs.BoolVar(&cfg.perFile, "dedup-per-file", false, "deduplicate emails within each of several files or archive entries only")
+	fs.DurationVar(&cfg.timeout, "timeout", 0, "`duration` of the download if --file is URL, unlimited if 0")
+	fs.StringVar(&cfg.sheet, "sheet", "", "`name` of the sheet of .xlsx file, the first sheet by default")
+	fs.StringVar(&cfg.entries, "entries", "", "glob `pattern` of the files of .zip or .tar.gz archive to import, all files by default")
+	fs.StringVar(&cfg.emailField, "email-field", "email", "`name` of the email column")
+	fs.StringVar(&cfg.checkpoint, "checkpoint", "", "save progress to `file` and resume interrupted import from it")
+	fs.IntVar(&cfg.every, "checkpoint-every", 100000, "save progress after every `n` records")
+	fs.BoolVar(&cfg.validate, "validate-only", false, "validate records and print only statistics of the data quality")
+	fs.StringVar(&cfg.rejects, "rejects", "", "write skipped records with the reason to csv `file`")
+	fs.StringVar(&cfg.suppress, "suppression-list", "", "skip emails or their MD5, SHA-256 or SHA-512 digests listed in `file`")
+	fs.StringVar(&cfg.output, "output", "", "also write result with statistics to database `URL`, e.g. postgres://user@host/db or sqlite://imports.db")
+	fs.StringVar(&cfg.format, "format", "text", "output `format`: text, json, csv, table, markdown, yaml or xml")
+	fs.StringVar(&cfg.template, "template", "", "write result by Go text/template `text` instead of --format, @path reads it from the file")
+	cfg.register(fs)
+
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if cfg.file != "" {
+		cfg.files = append(cfg.files, cfg.file)
+	}
+	cfg.files = append(cfg.files, fs.Args()...)
+	if len(cfg.files) == 0 {
+		fmt.Fprintln(stderr, "--file is required")
+		fs.Usage()
+		return exitUsage
+	}
+	if !validFormat(cfg.format) {
+		fmt.Fprintf(stderr, "invalid format %q\n", cfg.format)
+		return exitUsage
+	}
+	if path, ok := strings.CutPrefix(cfg.template, "@"); ok {
+		tmpl, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		cfg.template = string(tmpl)
+	}
+
+	options, err := cfg.options()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	var sink customerimporter.Sink
+	if cfg.output != "" {
+		if sink, err = customerimporter.OpenSink(cfg.output); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		defer sink.Close()
+	}
+	if cfg.checkpoint != "" {
+		options = append(options, customerimporter.WithCheckpoint(cfg.checkpoint, cfg.every))
+	}
+	if cfg.validate {
+		options = append(options, customerimporter.ValidateOnly())
+	}
+	if cfg.rejects != "" {
+		rejects, err := os.Create(cfg.rejects)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer rejects.Close()
+		options = append(options, customerimporter.WithRejectWriter(rejects))
+	}
+	if cfg.suppress != "" {
+		list, err := os.Open(cfg.suppress)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer list.Close()
+		options = append(options, customerimporter.WithSuppressionList(list))
+	}
+
+	// import and print result, collected errors and error aborting partial
+	// result are reported after it
+	result, err := importFile(cfg, options)
+	if result == nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	var writeErr error
+	switch {
+	case cfg.template != "":
+		writeErr = result.RenderTemplate(stdout, cfg.template)
+	case cfg.validate && cfg.format == "text":
+		writeErr = writeReport(stdout, result)
+	default:
+		writeErr = write(stdout, cfg.format, result)
+	}
+	if writeErr != nil {
+		fmt.Fprintln(stderr, writeErr)
+		return exitError
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+	}
+	if result.Partial {
+		return exitError
+	}
+
+	// keep history of the results in the database
+	if sink != nil {
+		run := customerimporter.Run{Input: strings.Join(cfg.files, " "), EmailField: cfg.emailField, Time: time.Now()}
+		if err := sink.Write(context.Background(), run, result); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	}
+
+	return exitOK
+}
+
+// imports the file by its location and format, several files or glob
+// patterns are imported together
+func importFile(cfg *importConfig, options []customerimporter.Option) (*customerimporter.ImportResult, error) {
+	if len(cfg.files) > 1 || strings.ContainsAny(cfg.files[0], "*?[") {
+		if cfg.perFile {
+			options = append(options, customerimporter.DedupPerFile())
+		}
+		return customerimporter.ImportFromFilesWithStats(cfg.files, cfg.emailField, options...)
+	}
+	cfg.file = cfg.files[0]
+
+	switch ext := strings.ToLower(filepath.Ext(cfg.file)); {
+	case strings.HasPrefix(cfg.file, "http://") || strings.HasPrefix(cfg.file, "https://"):
+		options = append(options, customerimporter.WithHTTPClient(&http.Client{Timeout: cfg.timeout}))
+		return customerimporter.ImportFromURLWithStats(context.Background(), cfg.file, cfg.emailField, options...)
+	case ext == ".xlsx":
+		return customerimporter.ImportFromXLSXWithStats(cfg.file, cfg.sheet, cfg.emailField, options...)
+	case ext == ".parquet":
+		return customerimporter.ImportFromParquetWithStats(cfg.file, cfg.emailField, options...)
+	case ext == ".mbox" || strings.HasSuffix(strings.ToLower(cfg.file), ".mbox.gz"):
+		return customerimporter.ImportFromMboxWithStats(cfg.file, options...)
+	case ext == ".vcf" || ext == ".vcard":
+		return customerimporter.ImportFromVCardWithStats(cfg.file, options...)
+	case isDir(cfg.file):
+		return customerimporter.ImportFromEMLDirWithStats(cfg.file, options...)
+	case customerimporter.IsArchive(cfg.file):
+		if cfg.perFile {
+			options = append(options, customerimporter.DedupPerFile())
+		}
+		return customerimporter.ImportFromArchiveWithStats(cfg.file, cfg.entries, cfg.emailField, options...)
+	}
+
+	// local file or object in object store
+	src, err := customerimporter.OpenSource(cfg.file)
+	if err != nil {
+		return nil, err
+	}
+	return customerimporter.ImportFromSourceWithStats(context.Background(), src, cfg.emailField, options...)
+}
+
+// reports whether the path is a directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// parses flags and returns exit code if the command shouldn't continue
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// reports whether the output format is supported by write
+func validFormat(format string) bool {
+	if format == "text" || format == "json" {
+		return true
+	}
+	_, err := customerimporter.NewEncoder(format)
+	return err == nil
+}
+
+// writes statistics of the data quality as text
+func writeReport(w io.Writer, result *customerimporter.ImportResult) error {
+	if _, err := fmt.Fprintf(w, "rows read: %d\nvalid emails: %d\ninvalid emails: %d\n",
+		result.RowsRead, result.ValidEmails, result.InvalidEmails); err != nil {
+		return err
+	}
+	for _, reason := range slices.Sorted(maps.Keys(result.InvalidByReason)) {
+		if _, err := fmt.Fprintf(w, "  %s: %d\n", reason, result.InvalidByReason[reason]); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "duplicate emails: %d\nfiltered rows: %d\nmalformed rows: %d\n",
+		result.DuplicateEmails, result.FilteredRows, result.MalformedRows); err != nil {
+		return err
+	}
+	if result.ReservedEmails > 0 {
+		if _, err := fmt.Fprintf(w, "reserved emails: %d\n", result.ReservedEmails); err != nil {
+			return err
+		}
+	}
+	if result.RoleAccounts > 0 {
+		if _, err := fmt.Fprintf(w, "role accounts: %d\n", result.RoleAccounts); err != nil {
+			return err
+		}
+	}
+	if result.Suppressed > 0 {
+		if _, err := fmt.Fprintf(w, "suppressed emails: %d\n", result.Suppressed); err != nil {
+			return err
+		}
+	}
+	if result.SampleRate > 0 {
+		if _, err := fmt.Fprintf(w, "sampled out rows: %d\nestimated emails: %d\n", result.SampledOutRows, result.EstimatedEmails); err != nil {
+			return err
+		}
+	}
+	if result.ErrorMargin > 0 {
+		if _, err := fmt.Fprintf(w, "error margin: %.1f%%\n", 100*result.ErrorMargin); err != nil {
+			return err
+		}
+	}
+	for _, typo := range result.Typos {
+		if _, err := fmt.Fprintf(w, "likely typo: %s of %s: %d\n", typo.Domain, typo.Suggestion, typo.EmailsCount); err != nil {
+			return err
+		}
+	}
+	for _, confusable := range result.Confusables {
+		if _, err := fmt.Fprintf(w, "confusable domain: %s looks like %s: %d\n", confusable.Domain, confusable.Skeleton, confusable.EmailsCount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writes result in the format, JSON includes statistics of the import
+func write(w io.Writer, format string, result *customerimporter.ImportResult) error {
+	switch format {
+	case "text":
+		for _, e := range result.Domains {
+			if _, err := fmt.Fprintf(w, "%s %d\n", e.Domain, e.EmailsCount); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		return result.WriteJSON(w, true)
+	}
+
+	encoder, err := customerimporter.NewEncoder(format)
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(w, result.Domains)
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// writes csv data to a temporary file
+func writeFile(t *testing.T, name, data string) string {
+	fileName := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fileName, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestRun(t *testing.T) {
+	file := writeFile(t, "customers.csv", "name,email\n"+
+		"A,a@a.io\nB,b@a.io\nC,a@b.io\nD,invalid\nE,a@a.io\n")
+	blanks := writeFile(t, "blanks.csv", "name,email\nA,a@a.io\nB,\n")
+	tsv := writeFile(t, "customers.tsv", "a@a.io\tA\na@b.io\tB\n")
+	messy := writeFile(t, "messy.csv", "# export\nname,email\nA \"Al\",a@a.io\nB,b@b.io,extra\n")
+	report := writeFile(t, "report.csv", "sep=;\nCustomers report\nname;email\nA;a@a.io\n")
+	reserved := writeFile(t, "reserved.csv", "email\na@a.io\na@example.com\ntest@a.io\n")
+	roles := writeFile(t, "roles.csv", "email\ninfo@a.io\njohn@a.io\nsales@b.io\n")
+	confusables := writeFile(t, "confusables.csv", "email\na@paypal.com\nb@pаypal.com\n")
+	suppressed := writeFile(t, "suppressed.txt", "a@b.io\n")
+	mapping := writeFile(t, "mapping.yaml", "comment: \"#\"\nlazy_quotes: true\nskip_malformed: true\n")
+	signups := writeFile(t, "signups.csv", "email,created_at\na@a.io,2024-01-31\nb@a.io,2024-02-01\n")
+	usSignups := writeFile(t, "us_signups.csv", "email,created_at\na@a.io,01/31/2024\nb@a.io,03/04/2024\n")
+	tmpl := writeFile(t, "result.tmpl", "{{.ValidEmails}} emails\n")
+	badMapping := writeFile(t, "bad.yaml", "separator: \";\"\n")
+	typos := writeFile(t, "typos.csv", "email\na@gmail.com\nb@gmial.com\nc@gmial.com\n")
+	mbox := writeFile(t, "support.mbox", "From a@a.io Mon Jan  1 00:00:00 2024\nFrom: A <a@a.io>\n\nHello\n")
+	vcf := writeFile(t, "contacts.vcf", "BEGIN:VCARD\nFN:A\nEMAIL:a@a.io\nEMAIL:a@b.io\nEND:VCARD\n")
+	eml := filepath.Dir(writeFile(t, "1.eml", "From: b@b.io\n\nHello\n"))
+	server := httptest.NewServer(http.FileServer(http.Dir(filepath.Dir(tsv))))
+	defer server.Close()
+
+	data := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		// text output
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates"}, exitOK, "a.io 2\nb.io 1\n", ""},
+
+		// csv, table, markdown and yaml output
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--format", "csv"}, exitOK,
+			"domain,count,percentage\na.io,2,66.67\nb.io,1,33.33\n", ""},
+
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--format", "table"}, exitOK,
+			"DOMAIN  COUNT  PERCENTAGE\na.io    2      66.67%\nb.io    1      33.33%\n", ""},
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--format", "markdown"}, exitOK,
+			"| Domain | Count | Percentage |\n| --- | ---: | ---: |\n| a.io | 2 | 66.67% |\n| b.io | 1 | 33.33% |\n", ""},
+
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--format", "yaml"}, exitOK,
+			"domains:\n  - domain: a.io\n    count: 2\n    share: 0.6666666666666666\n  - domain: b.io\n    count: 1\n    share: 0.3333333333333333\ntotal: 3\n", ""},
+
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--occurrences", "--format", "yaml"}, exitOK,
+			"domains:\n  - domain: a.io\n    count: 2\n    occurrences: 3\n    share: 0.6666666666666666\n  - domain: b.io\n    count: 1\n    occurrences: 1\n    share: 0.3333333333333333\ntotal: 3\n", ""},
+
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--min-count", "2"}, exitOK, "a.io 2\n", ""},
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--min-count", "2", "--collapse-rest"}, exitOK, "a.io 2\nother 1\n", ""},
+
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--breakdown", "name", "--top", "1", "--format", "yaml"}, exitOK,
+			"domains:\n  - domain: a.io\n    count: 2\n    share: 0.6666666666666666\n    breakdown:\n      A: 1\n      B: 1\ntotal: 2\n", ""},
+
+		{[]string{"--file", signups, "--time-series", "created_at", "--period", "month", "--format", "yaml"}, exitOK,
+			"domains:\n  - domain: a.io\n    count: 2\n    share: 1\n    series:\n      2024-01: 1\n      2024-02: 1\ntotal: 2\n", ""},
+		{[]string{"--file", signups, "--time-series", "created_at", "--period", "year"}, exitUsage, "", `invalid period "year"`},
+		{[]string{"--file", usSignups, "--time-series", "created_at", "--period", "month", "--time-layouts", "01/02/2006", "--format", "yaml"}, exitOK,
+			"domains:\n  - domain: a.io\n    count: 2\n    share: 1\n    series:\n      2024-01: 1\n      2024-03: 1\ntotal: 2\n", ""},
+
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--samples", "1", "--top", "1", "--format", "yaml"}, exitOK,
+			"domains:\n  - domain: a.io\n    count: 2\n    share: 0.6666666666666666\n    samples:\n      - email: a@a.io\n        line: 2\ntotal: 2\n", ""},
+
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--hash-emails", "sha256", "--samples", "1", "--top", "1", "--format", "yaml"}, exitOK,
+			"domains:\n  - domain: a.io\n    count: 2\n    share: 0.6666666666666666\n    samples:\n      - email: a***@a.io\n        line: 2\ntotal: 2\n", ""},
+		{[]string{"--file", file, "--hash-emails", "md5"}, exitUsage, "", `invalid hash algorithm "md5"`},
+
+		// templates
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--template", "{{range .Domains}}{{.Domain}}={{percent .Share}};{{end}}"}, exitOK,
+			"a.io=66.67%;b.io=33.33%;", ""},
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--template", "@" + tmpl}, exitOK, "3 emails\n", ""},
+		{[]string{"--file", file, "--template", "@missing.tmpl"}, exitUsage, "", "open missing.tmpl: no such file or directory"},
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--template", "{{.Unknown}}"}, exitError, "", "can't evaluate field Unknown"},
+
+		// data quality report
+		{[]string{"--file", file, "--validate-only"}, exitOK,
+			"rows read: 5\nvalid emails: 3\ninvalid emails: 1\n  Email is not valid: 1\nduplicate emails: 1\nfiltered rows: 0\nmalformed rows: 0\n", ""},
+
+		// reserved domains
+		{[]string{"--file", reserved, "--reserved", "drop"}, exitOK, "a.io 1\n", ""},
+		{[]string{"--file", reserved, "--reserved", "bucket"}, exitOK, "a.io 1\nreserved 2\n", ""},
+		{[]string{"--file", reserved, "--validate-only", "--reserved", "drop"}, exitOK,
+			"rows read: 3\nvalid emails: 1\ninvalid emails: 0\nduplicate emails: 0\nfiltered rows: 0\nmalformed rows: 0\nreserved emails: 2\n", ""},
+		{[]string{"--file", reserved, "--reserved", "keep"}, exitUsage, "", `invalid handling of reserved domains "keep"`},
+
+		// confusable domains
+		{[]string{"--file", confusables, "--validate-only", "--confusables"}, exitOK,
+			"rows read: 2\nvalid emails: 2\ninvalid emails: 0\nduplicate emails: 0\nfiltered rows: 0\nmalformed rows: 0\nconfusable domain: pаypal.com looks like paypal.com: 1\n", ""},
+
+		// suppression list
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--suppression-list", suppressed}, exitOK, "a.io 2\n", ""},
+		{[]string{"--file", file, "--validate-only", "--suppression-list", suppressed}, exitOK,
+			"rows read: 5\nvalid emails: 2\ninvalid emails: 1\n  Email is not valid: 1\nduplicate emails: 1\nfiltered rows: 0\nmalformed rows: 0\nsuppressed emails: 1\n", ""},
+		{[]string{"--file", file, "--suppression-list", "missing.txt"}, exitError, "", "open missing.txt: no such file or directory"},
+
+		// role accounts
+		{[]string{"--file", roles, "--role-accounts", "exclude"}, exitOK, "a.io 1\n", ""},
+		{[]string{"--file", roles, "--validate-only", "--role-accounts", "report"}, exitOK,
+			"rows read: 3\nvalid emails: 3\ninvalid emails: 0\nduplicate emails: 0\nfiltered rows: 0\nmalformed rows: 0\nrole accounts: 2\n", ""},
+		{[]string{"--file", roles, "--role-accounts", "drop"}, exitUsage, "", `invalid handling of role accounts "drop"`},
+
+		// typos of popular domains
+		{[]string{"--file", typos, "--validate-only", "--detect-typos"}, exitOK,
+			"rows read: 3\nvalid emails: 3\ninvalid emails: 0\nduplicate emails: 0\nfiltered rows: 0\nmalformed rows: 0\nlikely typo: gmial.com of gmail.com: 2\n", ""},
+		{[]string{"--file", typos, "--correct-typos"}, exitOK, "gmail.com 3\n", ""},
+
+		// input limits
+		{[]string{"--file", file, "--skip-invalid", "--max-rows", "2", "--truncate"}, exitOK, "a.io 2\n", ""},
+		{[]string{"--file", file, "--max-rows", "2"}, exitError, "", "Too many rows: more than 2 records"},
+		{[]string{"--file", file, "--limit-emails", "2"}, exitOK, "a.io 2\n", ""},
+
+		// partial result
+		{[]string{"--file", file, "--partial"}, exitError, "a.io 2\nb.io 1\n", "Email is not valid"},
+
+		// approximate counts
+		{[]string{"--file", file, "--skip-invalid", "--approximate", "0"}, exitOK, "a.io 2\nb.io 1\n", ""},
+
+		// domain aliases
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--domain-aliases", "b.io=a.io"}, exitOK, "a.io 2\n", ""},
+		{[]string{"--file", file, "--domain-aliases", "b.io"}, exitUsage, "", `invalid domain alias "b.io"`},
+
+		// sorting and top domains
+		{[]string{"--file", file, "--email-field", "email", "--skip-invalid", "--skip-duplicates",
+			"--sort-by-count", "--desc", "--top", "1", "--collapse-rest"}, exitOK, "a.io 2\nother 1\n", ""},
+
+		// additional email fields
+		{[]string{"--file", file, "--email-field", "name", "--email-fields", "email", "--skip-invalid", "--skip-duplicates"}, exitOK, "a.io 2\nb.io 1\n", ""},
+
+		// header matching
+		{[]string{"--file", file, "--email-field", "name", "--field-aliases", "E-Mail,EMAIL", "--ignore-header-case", "--skip-invalid", "--skip-duplicates"},
+			exitError, "", "CSV header contains several fields matching"},
+		{[]string{"--file", file, "--email-field", "EMAIL", "--ignore-header-case", "--trim-header", "--skip-invalid", "--skip-duplicates"}, exitOK, "a.io 2\nb.io 1\n", ""},
+
+		// relaxed csv parsing
+		{[]string{"--file", messy, "--lazy-quotes", "--comment", "#", "--skip-malformed", "--skip-invalid"}, exitOK, "a.io 1\n", ""},
+		{[]string{"--file", messy, "--comment", "//"}, exitUsage, "", `invalid comment "//"`},
+		{[]string{"--file", report, "--skip-rows", "2"}, exitOK, "a.io 1\n", ""},
+
+		{[]string{"--file", file, "--email-field", "mail", "--field-candidates", "e-mail,email", "--skip-invalid", "--skip-duplicates"}, exitOK, "a.io 2\nb.io 1\n", ""},
+
+		// header schema
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--expected-header", "name,email"}, exitOK, "a.io 2\nb.io 1\n", ""},
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--expected-header", "email", "--header-match", "subset"}, exitOK, "a.io 2\nb.io 1\n", ""},
+		{[]string{"--file", file, "--expected-header", "email,phone", "--header-match", "subset"}, exitError, "", `missing ["phone"]`},
+		{[]string{"--file", file, "--expected-header", "email", "--header-match", "any"}, exitUsage, "", `invalid header match "any"`},
+
+		// mapping config
+		{[]string{"--file", messy, "--config", mapping, "--skip-invalid"}, exitOK, "a.io 1\n", ""},
+		{[]string{"--file", messy, "--config", badMapping}, exitUsage, "", "Invalid config"},
+
+		// sampling
+		{[]string{"--file", file, "--validate-only", "--sample-rate", "0.5", "--sample-seed", "3"}, exitOK,
+			"rows read: 5\nvalid emails: 2\ninvalid emails: 0\nduplicate emails: 1\nfiltered rows: 0\nmalformed rows: 0\nsampled out rows: 2\nestimated emails: 4\n", ""},
+		{[]string{"--file", file, "--sample-rate", "2"}, exitUsage, "", "invalid sample rate 2"},
+
+		// record filter
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--where", "name=C"}, exitOK, "b.io 1\n", ""},
+		{[]string{"--file", file, "--where", "name"}, exitUsage, "", `invalid filter "name"`},
+
+		// checkpoints
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--checkpoint", filepath.Join(t.TempDir(), "import.checkpoint"), "--checkpoint-every", "1"},
+			exitOK, "a.io 2\nb.io 1\n", ""},
+
+		// approximate deduplication
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--bloom-dedup", "100"}, exitOK, "a.io 2\nb.io 1\n", ""},
+
+		// header-less tab separated file
+		{[]string{"--file", tsv, "--column-index", "0", "--delimiter", `\t`}, exitOK, "a.io 1\nb.io 1\n", ""},
+
+		// import subcommand with file argument
+		{[]string{"import", "--skip-invalid", "--skip-duplicates", file}, exitOK, "a.io 2\nb.io 1\n", ""},
+		{[]string{"import", "--skip-invalid", "--skip-duplicates", file, file}, exitOK, "a.io 2\nb.io 1\n", ""},
+		{[]string{"import", "--skip-invalid", "--skip-duplicates", "--dedup-per-file", file, tsv}, exitError, "", "doesn't contain field email"},
+		{[]string{"import", "--skip-invalid", "--skip-duplicates", filepath.Join(filepath.Dir(file), "*.csv")}, exitOK, "a.io 2\nb.io 1\n", ""},
+		{[]string{"import", "ftp://example.com/customers.csv"}, exitError, "", "Source is not registered for scheme ftp"},
+
+		// mailboxes
+		{[]string{"import", mbox}, exitOK, "a.io 1\n", ""},
+		{[]string{"import", eml}, exitOK, "b.io 1\n", ""},
+		{[]string{"import", vcf}, exitOK, "a.io 1\nb.io 1\n", ""},
+
+		// download
+		{[]string{"--file", server.URL + "/customers.tsv", "--column-index", "0", "--delimiter", `\t`, "--timeout", "5s"},
+			exitOK, "a.io 1\nb.io 1\n", ""},
+		{[]string{"--file", server.URL + "/missing.csv"}, exitError, "", "404 Not Found"},
+
+		// collected errors are reported after the result
+		{[]string{"--file", file, "--collect-errors"}, exitOK, "a.io 2\nb.io 1\n", "2 records skipped"},
+		{[]string{"--file", file, "--email-field", "mail", "--redact-errors"}, exitError, "", "CSV header doesn't contain field\n"},
+
+		// empty emails
+		{[]string{"--file", blanks, "--skip-empty"}, exitOK, "a.io 1\n", ""},
+		{[]string{"--file", blanks}, exitError, "", "Email is empty"},
+
+		// import error
+		{[]string{"--file", file}, exitError, "", "Email is not valid"},
+		{[]string{"--file", file, "--skip-invalid", "--skip-duplicates", "--max-errors", "1"}, exitError, "", "Too many invalid records"},
+		{[]string{"--file", file, "--encoding", "klingon"}, exitError, "", "Unknown encoding klingon"},
+		{[]string{"--file", "nonexisting.csv"}, exitError, "", "no such file or directory"},
+		{[]string{"--file", "nonexisting.xlsx", "--sheet", "Customers"}, exitError, "", "no such file or directory"},
+		{[]string{"--file", "nonexisting.parquet"}, exitError, "", "no such file or directory"},
+
+		// usage errors
+		{[]string{}, exitUsage, "", "--file is required"},
+		{[]string{"--file", file, "--format", "toml"}, exitUsage, "", `invalid format "toml"`},
+		{[]string{"--file", file, "--validation", "none"}, exitUsage, "", `invalid validation level "none"`},
+		{[]string{"--file", file, "--delimiter", ";;"}, exitUsage, "", `invalid delimiter ";;"`},
+		{[]string{"--unknown"}, exitUsage, "", "flag provided but not defined"},
+		{[]string{"--help"}, exitOK, "", "Usage of customerimporter"},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v %v", testNumber, d.args)
+
+		var stdout, stderr bytes.Buffer
+		code := run(d.args, &stdout, &stderr)
+		if code != d.code {
+			t.Errorf("should exit with %v, but got %v: %v", d.code, code, stderr.String())
+		}
+		if stdout.String() != d.stdout {
+			t.Errorf("should print: %q, but got %q", d.stdout, stdout.String())
+		}
+		if !strings.Contains(stderr.String(), d.stderr) {
+			t.Errorf("should report: %q, but got %q", d.stderr, stderr.String())
+		}
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	file := writeFile(t, "customers.csv", "email\na@a.io\nb@a.io\na@b.io\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--file", file, "--format", "json", "--classify", "--distribution"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("should exit with %v, but got %v: %v", exitOK, code, stderr.String())
+	}
+
+	var result customerimporter.ImportResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("should print json, but got error %v", err)
+	}
+	if result.ValidEmails != 3 || len(result.Domains) != 2 {
+		t.Errorf("should count 3 emails of 2 domains, but got %+v", result)
+	}
+	if result.Categories[customerimporter.CategoryCorporate] != 3 || result.Domains[0].Category != customerimporter.CategoryCorporate {
+		t.Errorf("should classify domains as corporate, but got %+v", result)
+	}
+	if result.Distribution == nil || result.Distribution.Median != 1.5 || len(result.Distribution.Buckets) != 2 {
+		t.Errorf("should report distribution of emails among domains, but got %+v", result.Distribution)
+	}
+}
+
+func TestRunRejects(t *testing.T) {
+	file := writeFile(t, "customers.csv", "name,email\nA,a@a.io\nB,invalid\n")
+	rejects := filepath.Join(t.TempDir(), "rejects.csv")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--file", file, "--skip-invalid", "--rejects", rejects}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("should exit with %v, but got %v: %v", exitOK, code, stderr.String())
+	}
+
+	data, err := os.ReadFile(rejects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "name,email,reason\nB,invalid,Email is not valid\n"; string(data) != expected {
+		t.Errorf("should write %q, but got %q", expected, data)
+	}
+}
+
+func TestRunOutput(t *testing.T) {
+	file := writeFile(t, "customers.csv", "name,email\nA,a@a.io\nB,a@b.io\n")
+	db := filepath.Join(t.TempDir(), "imports.db")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--file", file, "--output", "sqlite://" + db}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("should exit with %v, but got %v: %v", exitOK, code, stderr.String())
+	}
+
+	conn, err := sql.Open("sqlite", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var domains int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM domain_counts").Scan(&domains); err != nil {
+		t.Fatal(err)
+	}
+	if domains != 2 {
+		t.Errorf("should write 2 domains, but got %d", domains)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"--file", file, "--output", "mysql://localhost/db"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("should exit with %v, but got %v", exitUsage, code)
+	}
+	if expected := "Sink is not registered for scheme mysql"; !strings.Contains(stderr.String(), expected) {
+		t.Errorf("should report: %q, but got %q", expected, stderr.String())
+	}
+}
+
+func TestSources(t *testing.T) {
+	t.Log("Should register sources of object stores and Google Sheets by default")
+	for _, rawURL := range []string{"s3://bucket/customers.csv", "gs://bucket/customers.csv", "azblob://container/customers.csv", "gsheets://1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms/Customers"} {
+		t.Logf("Case: %v", rawURL)
+
+		if _, err := customerimporter.OpenSource(rawURL); errors.Is(err, customerimporter.ErrUnknownScheme) {
+			t.Errorf("should register the scheme, but got error %v", err)
+		}
+	}
+}
+
+func TestRunWebhook(t *testing.T) {
+	file := writeFile(t, "customers.csv", "name,email\nA,a@a.io\nB,a@b.io\n")
+
+	var summary customerimporter.CompletionSummary
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&summary)
+	}))
+	defer ts.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--file", file, "--webhook", ts.URL}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("should exit with %v, but got %v: %v", exitOK, code, stderr.String())
+	}
+	if summary.Status != customerimporter.CompletionSucceeded || summary.ValidEmails != 2 {
+		t.Errorf("should post summary of the import, but got %+v", summary)
+	}
+}
+
+func TestRunArchive(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "customers.zip")
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(file)
+	for name, data := range map[string]string{
+		"day1.csv":   "email\na@a.io\nb@a.io\n",
+		"day2.csv":   "email\na@b.io\n",
+		"README.txt": "customers of the week\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	data := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"import", "--entries", "*.csv", fileName}, exitOK, "a.io 2\nb.io 1\n", ""},
+		{[]string{"import", "--entries", "day2.csv", fileName}, exitOK, "b.io 1\n", ""},
+		{[]string{"import", "--entries", "*.xlsx", fileName}, exitError, "", "No files match pattern *.xlsx"},
+	}
+
+	t.Log("Should import matching files of the archive")
+	for testNumber, d := range data {
+		t.Logf("Case: %v %v", testNumber, d.args)
+
+		var stdout, stderr bytes.Buffer
+		code := run(d.args, &stdout, &stderr)
+		if code != d.code {
+			t.Errorf("should exit with %v, but got %v: %v", d.code, code, stderr.String())
+		}
+		if stdout.String() != d.stdout {
+			t.Errorf("should print: %q, but got %q", d.stdout, stdout.String())
+		}
+		if !strings.Contains(stderr.String(), d.stderr) {
+			t.Errorf("should report: %q, but got %q", d.stderr, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dreadfulangel/tw_t/httpserver"
+)
+
+// serveConfig holds command line flags of the serve subcommand
+type serveConfig struct {
+	optionFlags
+	addr          string
+	maxUploadSize int64
+	timeout       time.Duration
+	jobTimeout    time.Duration
+}
+
+// parses arguments and serves imports over HTTP until interrupted
+func runServe(args []string, stderr io.Writer) int {
+	cfg := &serveConfig{}
+
+	fs := flag.NewFlagSet("customerimporter serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.addr, "addr", ":8080", "`address` to listen on")
+	fs.Int64Var(&cfg.maxUploadSize, "max-upload-size", httpserver.DefaultMaxUploadSize, "max upload size in `bytes`")
+	fs.DurationVar(&cfg.timeout, "timeout", httpserver.DefaultTimeout, "max `duration` of an import")
+	fs.DurationVar(&cfg.jobTimeout, "job-timeout", httpserver.DefaultJobTimeout, "max `duration` of an import job")
+	cfg.register(fs)
+
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	options, err := cfg.options()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	handler := httpserver.New(httpserver.Config{
+		MaxUploadSize: cfg.maxUploadSize,
+		Timeout:       cfg.timeout,
+		Options:       options,
+		JobTimeout:    cfg.jobTimeout,
+	})
+	server := &http.Server{
+		Addr:              cfg.addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// shut down gracefully on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	defer handler.Close()
+
+	fmt.Fprintf(stderr, "listening on %s\n", cfg.addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	return exitOK
+}
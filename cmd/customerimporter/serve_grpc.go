@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	importergrpc "github.com/dreadfulangel/tw_t/grpc"
+)
+
+// serveGRPCConfig holds command line flags of the serve-grpc subcommand
+type serveGRPCConfig struct {
+	optionFlags
+	addr    string
+	timeout time.Duration
+	schemes string
+}
+
+// parses arguments and serves imports over gRPC until interrupted
+func runServeGRPC(args []string, stderr io.Writer) int {
+	cfg := &serveGRPCConfig{}
+
+	fs := flag.NewFlagSet("customerimporter serve-grpc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.addr, "addr", ":9090", "`address` to listen on")
+	fs.DurationVar(&cfg.timeout, "timeout", importergrpc.DefaultTimeout, "max `duration` of an import")
+	fs.StringVar(&cfg.schemes, "schemes", "", "comma-separated URL `schemes` ImportSource opens, e.g. https; none by default, as the server requests the URLs")
+	cfg.register(fs)
+
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	options, err := cfg.options()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	lis, err := net.Listen("tcp", cfg.addr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	grpcCfg := importergrpc.Config{Timeout: cfg.timeout, Options: options}
+	if cfg.schemes != "" {
+		grpcCfg.Schemes = strings.Split(cfg.schemes, ",")
+	}
+	server := importergrpc.NewServer(grpcCfg)
+
+	// shut down gracefully on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	fmt.Fprintf(stderr, "listening on %s\n", lis.Addr())
+	if err := server.Serve(lis); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	return exitOK
+}
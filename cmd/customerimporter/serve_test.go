@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunServe(t *testing.T) {
+	data := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{[]string{"serve", "--addr", "invalid address"}, exitError, "listen tcp"},
+		{[]string{"serve", "--validation", "none"}, exitUsage, `invalid validation level "none"`},
+		{[]string{"serve", "--timeout", "soon"}, exitUsage, "invalid value"},
+		{[]string{"serve", "--help"}, exitOK, "Usage of customerimporter serve"},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v %v", testNumber, d.args)
+
+		var stdout, stderr bytes.Buffer
+		code := run(d.args, &stdout, &stderr)
+		if code != d.code {
+			t.Errorf("should exit with %v, but got %v: %v", d.code, code, stderr.String())
+		}
+		if !strings.Contains(stderr.String(), d.stderr) {
+			t.Errorf("should report: %q, but got %q", d.stderr, stderr.String())
+		}
+	}
+}
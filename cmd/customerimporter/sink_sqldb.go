@@ -0,0 +1,6 @@
+//go:build !nosqldb
+
+package main
+
+// registers postgres:// and sqlite:// URLs of --output
+import _ "github.com/dreadfulangel/tw_t/sink/sqldb"
@@ -0,0 +1,6 @@
+//go:build !noazblob
+
+package main
+
+// registers azblob:// URLs of --file and import arguments
+import _ "github.com/dreadfulangel/tw_t/source/azblob"
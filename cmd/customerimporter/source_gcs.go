@@ -0,0 +1,6 @@
+//go:build !nogcs
+
+package main
+
+// registers gs:// URLs of --file and import arguments
+import _ "github.com/dreadfulangel/tw_t/source/gcs"
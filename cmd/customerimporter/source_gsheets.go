@@ -0,0 +1,6 @@
+//go:build !nogsheets
+
+package main
+
+// registers gsheets:// URLs of --file and import arguments
+import _ "github.com/dreadfulangel/tw_t/source/gsheets"
@@ -0,0 +1,6 @@
+//go:build !nos3
+
+package main
+
+// registers s3:// URLs of --file and import arguments
+import _ "github.com/dreadfulangel/tw_t/source/s3"
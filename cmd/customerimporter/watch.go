@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// watchConfig holds command line flags of the watch subcommand
+type watchConfig struct {
+	optionFlags
+	pattern    string
+	tail       bool
+	emailField string
+	format     string
+	settle     time.Duration
+}
+
+// parses arguments and imports files of the directory as they arrive, or
+// records appended to the file with --tail, until interrupted. Counts of all
+// imported records are printed after every update, json is printed one object
+// per line.
+func runWatch(args []string, stdout, stderr io.Writer) int {
+	cfg := &watchConfig{}
+
+	fs := flag.NewFlagSet("customerimporter watch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.pattern, "pattern", "", "glob `pattern` of the imported files of the directory, all files by default")
+	fs.BoolVar(&cfg.tail, "tail", false, "follow records appended to the csv file instead of watching a directory")
+	fs.StringVar(&cfg.emailField, "email-field", "email", "`name` of the email column")
+	fs.StringVar(&cfg.format, "format", "text", "output `format`: text, json, csv, table, markdown, yaml or xml")
+	fs.DurationVar(&cfg.settle, "settle-delay", customerimporter.DefaultSettleDelay, "import new file after no writes to it for `duration`")
+	cfg.register(fs)
+
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "directory or file to watch is required")
+		fs.Usage()
+		return exitUsage
+	}
+	if !validFormat(cfg.format) {
+		fmt.Fprintf(stderr, "invalid format %q\n", cfg.format)
+		return exitUsage
+	}
+
+	options, err := cfg.options()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	options = append(options, customerimporter.WithSettleDelay(cfg.settle))
+
+	// print counts after every update, errors of files don't stop watching
+	handler := func(update customerimporter.WatchUpdate) error {
+		if update.Err != nil {
+			fmt.Fprintln(stderr, update.Err)
+		}
+		if update.Result == nil {
+			return nil
+		}
+		if cfg.format == "json" {
+			return update.Result.WriteJSON(stdout, false)
+		}
+		if cfg.format == "text" {
+			if _, err := fmt.Fprintf(stdout, "==> %s <==\n", update.File); err != nil {
+				return err
+			}
+		}
+		return write(stdout, cfg.format, update.Result)
+	}
+
+	// stop on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.tail {
+		err = customerimporter.Tail(ctx, fs.Arg(0), cfg.emailField, handler, options...)
+	} else {
+		err = customerimporter.Watch(ctx, fs.Arg(0), cfg.pattern, cfg.emailField, handler, options...)
+	}
+	if err != nil && ctx.Err() == nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	return exitOK
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunWatch(t *testing.T) {
+	invalid := writeFile(t, "signups.csv", "email\na@a.io\nb@b.io\ninvalid\n")
+
+	data := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{[]string{"watch", "--tail", invalid}, exitError, "Email is not valid"},
+		{[]string{"watch", "--tail", filepath.Join(t.TempDir(), "missing.csv")}, exitError, "no such file or directory"},
+		{[]string{"watch", filepath.Join(t.TempDir(), "missing")}, exitError, "no such file or directory"},
+		{[]string{"watch", "--pattern", "[", t.TempDir()}, exitError, "syntax error in pattern"},
+		{[]string{"watch"}, exitUsage, "directory or file to watch is required"},
+		{[]string{"watch", "--format", "toml", t.TempDir()}, exitUsage, `invalid format "toml"`},
+		{[]string{"watch", "--settle-delay", "soon", t.TempDir()}, exitUsage, "invalid value"},
+		{[]string{"watch", "--help"}, exitOK, "Usage of customerimporter watch"},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v %v", testNumber, d.args)
+
+		var stdout, stderr bytes.Buffer
+		code := run(d.args, &stdout, &stderr)
+		if code != d.code {
+			t.Errorf("should exit with %v, but got %v: %v", d.code, code, stderr.String())
+		}
+		if !strings.Contains(stderr.String(), d.stderr) {
+			t.Errorf("should report: %q, but got %q", d.stderr, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package customerimporter
+
+import (
+	"encoding/csv"
+	"errors"
+)
+
+// ErrorCode is a stable machine-readable code of an importer error, e.g. to
+// map failures to API responses and metrics without parsing error text
+type ErrorCode string
+
+// codes of the importer errors
+const (
+	CodeUnknown               ErrorCode = "E_UNKNOWN"
+	CodeInvalidEmail          ErrorCode = "E_INVALID_EMAIL"
+	CodeEmptyEmail            ErrorCode = "E_EMPTY_EMAIL"
+	CodeDuplicateEmail        ErrorCode = "E_DUP_EMAIL"
+	CodeNoValidEmails         ErrorCode = "E_NO_VALID_EMAILS"
+	CodeEmptyFile             ErrorCode = "E_EMPTY_FILE"
+	CodeFieldMissing          ErrorCode = "E_FIELD_MISSING"
+	CodeFieldAmbiguous        ErrorCode = "E_FIELD_AMBIGUOUS"
+	CodeHeaderMismatch        ErrorCode = "E_HEADER_MISMATCH"
+	CodeColumnMissing         ErrorCode = "E_COLUMN_MISSING"
+	CodeFieldCount            ErrorCode = "E_FIELD_COUNT"
+	CodeMalformedCSV          ErrorCode = "E_MALFORMED_CSV"
+	CodeTooManyErrors         ErrorCode = "E_TOO_MANY_ERRORS"
+	CodeRowsSkipped           ErrorCode = "E_ROWS_SKIPPED"
+	CodeSheetMissing          ErrorCode = "E_SHEET_MISSING"
+	CodeUnknownScheme         ErrorCode = "E_UNKNOWN_SCHEME"
+	CodeUnknownSink           ErrorCode = "E_UNKNOWN_SINK"
+	CodeUnexpectedStatus      ErrorCode = "E_HTTP_STATUS"
+	CodeInvalidDedupFile      ErrorCode = "E_INVALID_DEDUP_FILE"
+	CodeUnknownEncoding       ErrorCode = "E_UNKNOWN_ENCODING"
+	CodeUnknownFormat         ErrorCode = "E_UNKNOWN_FORMAT"
+	CodeCheckpointMismatch    ErrorCode = "E_CHECKPOINT_MISMATCH"
+	CodeCheckpointUnsupported ErrorCode = "E_CHECKPOINT_UNSUPPORTED"
+	CodeInvalidState          ErrorCode = "E_INVALID_STATE"
+	CodeNoFilesMatched        ErrorCode = "E_NO_FILES_MATCHED"
+	CodeUnknownArchive        ErrorCode = "E_UNKNOWN_ARCHIVE"
+	CodeHashUnavailable       ErrorCode = "E_HASH_UNAVAILABLE"
+	CodeTooManyRows           ErrorCode = "E_TOO_MANY_ROWS"
+	CodeInputTooLarge         ErrorCode = "E_INPUT_TOO_LARGE"
+	CodeInvalidConfig         ErrorCode = "E_INVALID_CONFIG"
+	CodeLimitReached          ErrorCode = "E_LIMIT_REACHED"
+)
+
+// errorCodes maps sentinel errors to their codes, the first matching error
+// of the chain decides the code
+var errorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{ErrEmailIsNotValid, CodeInvalidEmail},
+	{ErrEmailEmpty, CodeEmptyEmail},
+	{ErrEmailDuplicate, CodeDuplicateEmail},
+	{ErrNoValidEmailsFound, CodeNoValidEmails},
+	{ErrEmptyFile, CodeEmptyFile},
+	{ErrFieldNotExists, CodeFieldMissing},
+	{ErrAmbiguousField, CodeFieldAmbiguous},
+	{ErrHeaderMismatch, CodeHeaderMismatch},
+	{ErrColumnNotExists, CodeColumnMissing},
+	{csv.ErrFieldCount, CodeFieldCount},
+	{ErrTooManyErrors, CodeTooManyErrors},
+	{ErrTooManyRows, CodeTooManyRows},
+	{ErrInputTooLarge, CodeInputTooLarge},
+	{ErrSheetNotExists, CodeSheetMissing},
+	{ErrUnknownScheme, CodeUnknownScheme},
+	{ErrUnknownSink, CodeUnknownSink},
+	{ErrUnexpectedStatus, CodeUnexpectedStatus},
+	{ErrInvalidDedupFile, CodeInvalidDedupFile},
+	{ErrUnknownEncoding, CodeUnknownEncoding},
+	{ErrUnknownFormat, CodeUnknownFormat},
+	{ErrCheckpointMismatch, CodeCheckpointMismatch},
+	{ErrCheckpointUnsupported, CodeCheckpointUnsupported},
+	{ErrInvalidState, CodeInvalidState},
+	{ErrNoFilesMatched, CodeNoFilesMatched},
+	{ErrUnknownArchive, CodeUnknownArchive},
+	{ErrInvalidConfig, CodeInvalidConfig},
+	{ErrLimitReached, CodeLimitReached},
+	{ErrHashUnavailable, CodeHashUnavailable},
+}
+
+// Code returns code of the importer error, CodeUnknown if err isn't raised by
+// the importer and empty code if err is nil. Collected errors of the skipped
+// records have CodeRowsSkipped, codes of the single records are returned by
+// Code of their RowError.
+func Code(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+
+	var rowErrors RowErrors
+	if errors.As(err, &rowErrors) {
+		return CodeRowsSkipped
+	}
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) {
+		return CodeMalformedCSV
+	}
+	return CodeUnknown
+}
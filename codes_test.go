@@ -0,0 +1,55 @@
+package customerimporter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCode(t *testing.T) {
+	data := []struct {
+		records string
+		field   string
+		options []Option
+		code    ErrorCode
+	}{
+		// record errors
+		{"email\ninvalid\n", "email", nil, CodeInvalidEmail},
+		{"name,email\nA,\n", "email", nil, CodeEmptyEmail},
+		{"email\na@a.io\na@a.io\n", "email", nil, CodeDuplicateEmail},
+		{"email\ninvalid\n", "email", []Option{SkipErrInvalidEmails()}, CodeNoValidEmails},
+		{"email\ninvalid\na@a.io\n", "email", []Option{CollectErrors()}, CodeRowsSkipped},
+		{"email\ninvalid\na@a.io\n", "email", []Option{WithMaxErrors(0)}, CodeTooManyErrors},
+		{"name,email\nA,a@a.io,B\n", "email", nil, CodeFieldCount},
+		{"email\n\"a@a.io\n", "email", nil, CodeMalformedCSV},
+		{"email\na@a.io\nb@a.io\n", "email", []Option{WithMaxRows(1)}, CodeTooManyRows},
+		{"email\na@a.io\nb@a.io\n", "email", []Option{WithMaxBytes(10)}, CodeInputTooLarge},
+
+		// header errors
+		{"", "email", nil, CodeEmptyFile},
+		{"name,mail\nA,a@a.io\n", "email", nil, CodeFieldMissing},
+		{"email,email\na@a.io,a@a.io\n", "email", nil, CodeFieldAmbiguous},
+		{"a@a.io\n", "", []Option{WithColumnIndex(1)}, CodeColumnMissing},
+
+		// option errors
+		{"email\na@a.io\n", "email", []Option{WithEncoding("klingon")}, CodeUnknownEncoding},
+	}
+
+	t.Log("Should return code of the import error")
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		_, err := Import(strings.NewReader(d.records), d.field, d.options...)
+		if code := Code(err); code != d.code {
+			t.Errorf("should return %v, but got %v of error %v", d.code, code, err)
+		}
+	}
+
+	t.Log("Should return empty code of nil and unknown code of other errors")
+	if code := Code(nil); code != "" {
+		t.Errorf("should return empty code, but got %v", code)
+	}
+	if code := Code(errors.New("other")); code != CodeUnknown {
+		t.Errorf("should return %v, but got %v", CodeUnknown, code)
+	}
+}
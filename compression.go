@@ -0,0 +1,84 @@
+package customerimporter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression of the input
+type Compression int
+
+const (
+	CompressionAuto Compression = iota // detect compression by magic bytes
+	CompressionNone                    // input is not compressed
+	CompressionGzip                    // input is compressed by gzip
+	CompressionZstd                    // input is compressed by zstd
+)
+
+// magic bytes of the compressed streams
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Decompress input using the compression instead of detecting it by magic
+// bytes.
+func WithCompression(compression Compression) Option {
+	return func(f *CustomerImporter) { f.compression = compression }
+}
+
+// returns compression by file extension, e.g. customers.csv.gz
+func compressionByExtension(fileName string) (Compression, bool) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".gz", ".gzip":
+		return CompressionGzip, true
+	case ".zst", ".zstd":
+		return CompressionZstd, true
+	}
+	return CompressionAuto, false
+}
+
+// returns reader decompressing r according to the compression option and
+// the detected compression
+func (c *CustomerImporter) decompress(r io.Reader) (io.ReadCloser, Compression, error) {
+	compression := c.compression
+
+	// sniff magic bytes
+	if compression == CompressionAuto {
+		br := bufio.NewReader(r)
+		magic, _ := br.Peek(len(zstdMagic))
+		switch {
+		case bytes.HasPrefix(magic, gzipMagic):
+			compression = CompressionGzip
+		case bytes.HasPrefix(magic, zstdMagic):
+			compression = CompressionZstd
+		default:
+			compression = CompressionNone
+		}
+		r = br
+	}
+
+	rc, err := decompressWith(r, compression)
+	return rc, compression, err
+}
+
+// returns reader decompressing r by the compression
+func decompressWith(r io.Reader, compression Compression) (io.ReadCloser, error) {
+	switch compression {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return io.NopCloser(r), nil
+}
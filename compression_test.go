@@ -0,0 +1,114 @@
+package customerimporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compresses data by gzip
+func gzipData(t *testing.T, data string) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// compresses data by zstd
+func zstdData(t *testing.T, data string) []byte {
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	return w.EncodeAll([]byte(data), nil)
+}
+
+func TestCompression(t *testing.T) {
+	records := "email\na@a.io\nb@a.io\na@b.io\n"
+	expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3}, {Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3}}
+
+	data := []struct {
+		input  []byte
+		option Option
+		err    bool
+	}{
+		// plain input
+		{[]byte(records), emptyOption(), false},
+		{[]byte(records), WithCompression(CompressionNone), false},
+
+		// detected compression
+		{gzipData(t, records), emptyOption(), false},
+		{zstdData(t, records), emptyOption(), false},
+
+		// explicit compression
+		{gzipData(t, records), WithCompression(CompressionGzip), false},
+		{zstdData(t, records), WithCompression(CompressionZstd), false},
+
+		// wrong compression
+		{[]byte(records), WithCompression(CompressionGzip), true},
+		{gzipData(t, records), WithCompression(CompressionZstd), true},
+		{gzipData(t, records), WithCompression(CompressionNone), true},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := Import(bytes.NewReader(d.input), "email", d.option)
+		if d.err {
+			if err == nil {
+				t.Errorf("should raise error, but got result %v", *result)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("should result with: %v, but got %v", expected, *result)
+		}
+	}
+}
+
+func TestImportFromCompressedFile(t *testing.T) {
+	records := "email\na@a.io\nb@a.io\na@b.io\n"
+	expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3}, {Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3}}
+	dir := t.TempDir()
+
+	data := []struct {
+		name  string
+		input []byte
+	}{
+		{"customers.csv.gz", gzipData(t, records)},
+		{"customers.csv.zst", zstdData(t, records)},
+	}
+
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		fileName := filepath.Join(dir, d.name)
+		if err := os.WriteFile(fileName, d.input, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := ImportFromFile(fileName, "email")
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("should result with: %v, but got %v", expected, *result)
+		}
+	}
+}
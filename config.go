@@ -0,0 +1,186 @@
+package customerimporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig is raised when mapping config can't be read or its values
+// are invalid
+var ErrInvalidConfig = errors.New("Invalid config")
+
+// Config describes source of the records, e.g. export of a partner kept next
+// to the file instead of repeating the options in every call.
+//
+//	delimiter: ";"
+//	encoding: windows-1252
+//	skip_rows: 2
+//	field_aliases: [e-mail, mail]
+//	case_insensitive_header: true
+//	filters:
+//	  status: active
+type Config struct {
+	Delimiter             string            `json:"delimiter" yaml:"delimiter"`                             // field delimiter, comma if empty
+	Encoding              string            `json:"encoding" yaml:"encoding"`                               // name of the input encoding, UTF-8 if empty
+	Comment               string            `json:"comment" yaml:"comment"`                                 // comment character, comments are not allowed if empty
+	LazyQuotes            bool              `json:"lazy_quotes" yaml:"lazy_quotes"`                         // see LazyQuotes
+	VariableFieldCount    bool              `json:"variable_fields" yaml:"variable_fields"`                 // see VariableFieldCount
+	SkipMalformedRows     bool              `json:"skip_malformed" yaml:"skip_malformed"`                   // see SkipMalformedRows
+	SkipRows              int               `json:"skip_rows" yaml:"skip_rows"`                             // amount of lines skipped before the header
+	FieldAliases          []string          `json:"field_aliases" yaml:"field_aliases"`                     // accepted names of the email field
+	CaseInsensitiveHeader bool              `json:"case_insensitive_header" yaml:"case_insensitive_header"` // see CaseInsensitiveHeader
+	TrimHeader            bool              `json:"trim_header" yaml:"trim_header"`                         // see TrimHeader
+	Filters               map[string]string `json:"filters" yaml:"filters"`                                 // values of the fields counted records must have
+}
+
+// Read options of the mapping config file, see Config. Files with .json
+// extension are read as JSON, other files as YAML. Unknown keys are invalid.
+// Options used after this option override values of the config. If the config
+// is invalid, the import fails with ErrInvalidConfig.
+func WithConfigFile(path string) Option {
+	return func(f *CustomerImporter) {
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			f.configErr = err
+			return
+		}
+		options, err := cfg.options()
+		if err != nil {
+			f.configErr = fmt.Errorf("%w %s: %w", ErrInvalidConfig, path, err)
+			return
+		}
+		for _, option := range options {
+			option(f)
+		}
+	}
+}
+
+// LoadConfig reads and validates mapping config file, files with .json
+// extension are read as JSON, other files as YAML
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrInvalidConfig, path, err)
+	}
+
+	cfg := &Config{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(cfg)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		// empty file is an empty config
+		if err = decoder.Decode(cfg); errors.Is(err, io.EOF) {
+			err = nil
+		}
+	}
+	if err == nil {
+		_, err = cfg.options()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrInvalidConfig, path, err)
+	}
+	return cfg, nil
+}
+
+// Options returns options of the config, ErrInvalidConfig is returned for
+// invalid values
+func (cfg *Config) Options() ([]Option, error) {
+	options, err := cfg.options()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return options, nil
+}
+
+// returns options of the config, error describes the invalid value
+func (cfg *Config) options() ([]Option, error) {
+	var options []Option
+
+	// csv format
+	if cfg.Delimiter != "" {
+		delimiter, err := configRune("delimiter", cfg.Delimiter)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithDelimiter(delimiter))
+	}
+	if cfg.Comment != "" {
+		comment, err := configRune("comment", cfg.Comment)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithComment(comment))
+	}
+	if cfg.Encoding != "" {
+		if _, err := htmlindex.Get(cfg.Encoding); err != nil {
+			return nil, fmt.Errorf("encoding %q is unknown", cfg.Encoding)
+		}
+		options = append(options, WithEncoding(cfg.Encoding))
+	}
+	if cfg.LazyQuotes {
+		options = append(options, LazyQuotes())
+	}
+	if cfg.VariableFieldCount {
+		options = append(options, VariableFieldCount())
+	}
+	if cfg.SkipMalformedRows {
+		options = append(options, SkipMalformedRows())
+	}
+	if cfg.SkipRows < 0 {
+		return nil, fmt.Errorf("skip_rows %d is negative", cfg.SkipRows)
+	}
+	if cfg.SkipRows > 0 {
+		options = append(options, SkipRows(cfg.SkipRows))
+	}
+
+	// header
+	if len(cfg.FieldAliases) > 0 {
+		options = append(options, WithFieldAliases(cfg.FieldAliases...))
+	}
+	if cfg.CaseInsensitiveHeader {
+		options = append(options, CaseInsensitiveHeader())
+	}
+	if cfg.TrimHeader {
+		options = append(options, TrimHeader())
+	}
+
+	// filters are added in the order of the fields
+	fields := make([]string, 0, len(cfg.Filters))
+	for field := range cfg.Filters {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	for _, field := range fields {
+		if field == "" {
+			return nil, errors.New("filter of empty field")
+		}
+		options = append(options, WithRecordFilter(FieldEquals(field, cfg.Filters[field])))
+	}
+
+	return options, nil
+}
+
+// returns the only character of the value, "\t" is accepted for tab
+func configRune(key, value string) (rune, error) {
+	if value == `\t` {
+		return '\t', nil
+	}
+	runes := []rune(value)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("%s %q is not a single character", key, value)
+	}
+	return runes[0], nil
+}
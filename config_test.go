@@ -0,0 +1,111 @@
+package customerimporter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	records := "Partner export\nName;E-Mail;Status\nA;a@a.io;active\nB;b@a.io;inactive\nC;c@b.io;active\n"
+	yamlConfig := write("partner.yaml", "delimiter: \";\"\nskip_rows: 1\nfield_aliases: [e-mail]\ncase_insensitive_header: true\nfilters:\n  Status: active\n")
+	jsonConfig := write("partner.json", `{"delimiter": ";", "skip_rows": 1, "field_aliases": ["E-Mail"], "filters": {"Status": "active"}}`)
+	emptyConfig := write("empty.yml", "")
+
+	data := []struct {
+		name     string
+		records  string
+		options  []Option
+		expected EmailsByDomainQtyList
+	}{
+		{"yaml", records, []Option{WithConfigFile(yamlConfig)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+		}},
+		{"json", records, []Option{WithConfigFile(jsonConfig)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+		}},
+		{"empty config", "email\na@a.io\n", []Option{WithConfigFile(emptyConfig)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}},
+		{"later option overrides config", "Partner export\nName,E-Mail,Status\nA,a@a.io,active\n", []Option{WithConfigFile(jsonConfig), WithDelimiter(',')}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}},
+	}
+
+	t.Log("Should import records described by the config file")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := ImportWithStats(strings.NewReader(d.records), "email", d.options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Domains, d.expected) {
+			t.Errorf("should return %v, but got %v", d.expected, result.Domains)
+		}
+	}
+
+	invalid := []struct {
+		name   string
+		path   string
+		detail string
+	}{
+		{"missing file", filepath.Join(dir, "missing.yaml"), "no such file"},
+		{"unknown key", write("unknown.yaml", "delimiter: \";\"\nseparator: \";\"\n"), "separator"},
+		{"unknown json key", write("unknown.json", `{"separator": ";"}`), "separator"},
+		{"malformed json", write("malformed.json", `{"delimiter": `), "unexpected EOF"},
+		{"long delimiter", write("delimiter.yaml", "delimiter: \";;\"\n"), "delimiter"},
+		{"unknown encoding", write("encoding.yaml", "encoding: klingon\n"), "klingon"},
+		{"negative skip rows", write("skip.yaml", "skip_rows: -1\n"), "skip_rows"},
+	}
+
+	t.Log("Should raise error of invalid config file")
+	for _, d := range invalid {
+		t.Logf("Case: %v", d.name)
+
+		_, err := Import(strings.NewReader("email\na@a.io\n"), "email", WithConfigFile(d.path))
+		if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), d.detail) {
+			t.Errorf("should raise error: %v with %q, but got error %v", ErrInvalidConfig, d.detail, err)
+		}
+		if code := Code(err); code != CodeInvalidConfig {
+			t.Errorf("should return code %v, but got %v", CodeInvalidConfig, code)
+		}
+	}
+}
+
+func TestConfigOptions(t *testing.T) {
+	t.Log("Should read tab delimiter")
+	cfg := Config{Delimiter: `\t`}
+	options, err := cfg.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Import(strings.NewReader("name\temail\nA\ta@a.io\n"), "email", options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1, Share: 1}}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should return %v, but got %v", expected, *result)
+	}
+
+	t.Log("Should raise error of invalid values")
+	cfg = Config{Filters: map[string]string{"": "active"}}
+	if _, err := cfg.Options(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("should raise error: %v, but got error %v", ErrInvalidConfig, err)
+	}
+}
@@ -0,0 +1,129 @@
+package customerimporter
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// confusables maps letters of other scripts to Latin letters they look like
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j', 'к': 'k', 'ӏ': 'l', 'о': 'o',
+	'р': 'p', 'ԛ': 'q', 'ѕ': 's', 'ԝ': 'w', 'х': 'x', 'у': 'y', 'ү': 'y',
+	// Greek
+	'α': 'a', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'υ': 'u', 'χ': 'x',
+	// Armenian
+	'օ': 'o', 'ս': 'u', 'ց': 'g', 'հ': 'h', 'ո': 'n',
+	// Latin letters which look like other ones
+	'ı': 'i', 'ɡ': 'g', 'ɑ': 'a', 'ɩ': 'i',
+}
+
+// ConfusableDomain is a counted domain which contains letters of other
+// scripts looking like Latin ones, e.g. Cyrillic "а" in pаypal.com
+type ConfusableDomain struct {
+	Domain      string `json:"domain"`   // domain as written in emails
+	Skeleton    string `json:"skeleton"` // domain with the confusable letters replaced by Latin ones
+	EmailsCount int    `json:"count"`    // amount of counted emails of the domain
+}
+
+// Report domains containing letters of other scripts which look like Latin
+// letters in ImportResult.Confusables, as they are a common trick of phishing,
+// e.g. pаypal.com with Cyrillic "а". A label of a domain is reported if it
+// mixes confusable letters with Latin ones or all its letters look like
+// Latin ones, so domains written in other scripts, e.g. яндекс.рф, are not
+// reported. Punycode domains are decoded before they are checked.
+func DetectConfusables() Option {
+	return func(f *CustomerImporter) {
+		f.confusables = &confusableDetector{skeletons: make(map[string]string), counts: make(map[string]int)}
+	}
+}
+
+// confusableDetector finds domains with confusable letters
+type confusableDetector struct {
+	skeletons map[string]string // skeleton by checked domain, empty if it isn't confusable
+	counts    map[string]int    // counted emails by confusable domain
+}
+
+// returns skeleton of the domain if it's confusable, results are cached
+func (d *confusableDetector) check(domain string) string {
+	skeleton, ok := d.skeletons[domain]
+	if ok {
+		return skeleton
+	}
+
+	skeleton = ConfusableSkeleton(domain)
+	d.skeletons[domain] = skeleton
+	return skeleton
+}
+
+// restores counts of confusable domains saved by checkpoint, skeletons are
+// checked again
+func (d *confusableDetector) restore(counts map[string]int) {
+	d.counts = make(map[string]int, len(counts))
+	for domain, count := range counts {
+		d.counts[domain] = count
+		d.check(domain)
+	}
+}
+
+// counts email of the valid record if its domain is confusable
+func (c *CustomerImporter) checkConfusable(r parsedRecord) {
+	domain := r.email[strings.LastIndexByte(r.email, '@')+1:]
+	if c.confusables.check(domain) != "" {
+		c.confusables.counts[domain]++
+	}
+}
+
+// returns confusable domains sorted by domain, nil if they are not detected
+func (d *confusableDetector) result() []ConfusableDomain {
+	if d == nil {
+		return nil
+	}
+	var domains []ConfusableDomain
+	for domain, count := range d.counts {
+		domains = append(domains, ConfusableDomain{Domain: domain, Skeleton: d.skeletons[domain], EmailsCount: count})
+	}
+	slices.SortFunc(domains, func(a, b ConfusableDomain) int { return strings.Compare(a.Domain, b.Domain) })
+	return domains
+}
+
+// ConfusableSkeleton returns the domain with confusable letters replaced by
+// Latin letters they look like, e.g. paypal.com for pаypal.com with Cyrillic
+// "а", or empty string if the domain isn't confusable as described by
+// DetectConfusables
+func ConfusableSkeleton(domain string) string {
+	if unicode, err := idna.ToUnicode(domain); err == nil {
+		domain = unicode
+	}
+
+	confusable := false
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		var skeleton strings.Builder
+		latin, mapped, other := false, false, false
+		for _, r := range label {
+			if latinRune, ok := confusables[r]; ok {
+				mapped = true
+				skeleton.WriteRune(latinRune)
+				continue
+			}
+			switch {
+			case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+				latin = true
+			case r >= 0x80:
+				other = true
+			}
+			skeleton.WriteRune(r)
+		}
+		if mapped && (latin || !other) {
+			confusable = true
+			labels[i] = skeleton.String()
+		}
+	}
+	if !confusable {
+		return ""
+	}
+	return strings.Join(labels, ".")
+}
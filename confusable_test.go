@@ -0,0 +1,71 @@
+package customerimporter
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestConfusableSkeleton(t *testing.T) {
+	tests := []struct {
+		domain   string
+		skeleton string
+	}{
+		{"pаypal.com", "paypal.com"},
+		{"аррӏе.com", "apple.com"},
+		{"xn--pypal-4ve.com", "paypal.com"},
+		{"shop.gοogle.com", "shop.google.com"},
+		{"paypal.com", ""},
+		{"яндекс.рф", ""},
+		{"münchen.de", ""},
+	}
+
+	t.Log("Should replace confusable letters of domains by Latin ones")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.domain)
+
+		if skeleton := ConfusableSkeleton(test.domain); skeleton != test.skeleton {
+			t.Errorf("should return %q, but got %q", test.skeleton, skeleton)
+		}
+	}
+}
+
+func TestDetectConfusables(t *testing.T) {
+	records := "email\na@paypal.com\nb@pаypal.com\nc@pаypal.com\nd@яндекс.рф\n"
+
+	t.Log("Should report domains with confusable letters")
+	result, err := ImportWithStats(strings.NewReader(records), "email", DetectConfusables())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := []ConfusableDomain{{Domain: "pаypal.com", Skeleton: "paypal.com", EmailsCount: 2}}
+	if !reflect.DeepEqual(result.Confusables, expected) {
+		t.Errorf("should report %+v, but got %+v", expected, result.Confusables)
+	}
+
+	t.Log("Should report confusable domains in validate-only mode")
+	result, err = ImportWithStats(strings.NewReader(records), "email", DetectConfusables(), ValidateOnly())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if !reflect.DeepEqual(result.Confusables, expected) {
+		t.Errorf("should report %+v, but got %+v", expected, result.Confusables)
+	}
+
+	t.Log("Should report confusable domains counted before the checkpoint of resumed import")
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+	interrupted := io.MultiReader(strings.NewReader("email\na@paypal.com\nb@pаypal.com\n"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	if _, err := Import(interrupted, "email", DetectConfusables(), WithCheckpoint(path, 1)); err == nil {
+		t.Fatal("should raise error of interrupted import")
+	}
+	result, err = ImportWithStats(strings.NewReader(records), "email", DetectConfusables(), WithCheckpoint(path, 1))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if !reflect.DeepEqual(result.Confusables, expected) {
+		t.Errorf("should report %+v, but got %+v", expected, result.Confusables)
+	}
+}
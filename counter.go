@@ -0,0 +1,59 @@
+package customerimporter
+
+// CounterStore keeps emails count by domain, e.g. in a database shared by
+// several imports
+type CounterStore interface {
+	// Add adds n emails to the count of the domain and returns its new count
+	Add(domain string, n int) (int, error)
+	// Counts returns emails count of every domain
+	Counts() (map[string]int, error)
+}
+
+// MemoryCounterStore is an in-memory map of emails count by domain, it's used
+// by default
+type MemoryCounterStore map[string]int
+
+// NewMemoryCounterStore returns empty in-memory store
+func NewMemoryCounterStore() MemoryCounterStore { return make(MemoryCounterStore, 10) }
+
+func (s MemoryCounterStore) Add(domain string, n int) (int, error) {
+	s[domain] += n
+	return s[domain], nil
+}
+
+func (s MemoryCounterStore) Counts() (map[string]int, error) { return s, nil }
+
+// Count emails by domain in the store instead of the in-memory map, e.g. in
+// Redis when several workers import shards of the input. Counts of the store
+// are returned in the result, shares are computed of all emails of the store.
+// Checkpoints and WithMemoryLimit of the domains are not supported and files
+// aren't split by WithChunks.
+func WithCounterStore(store CounterStore) Option {
+	return func(f *CustomerImporter) { f.counterStore = store }
+}
+
+// adds email to the count of the domain and returns its new count
+func (c *CustomerImporter) countDomain(domain string) (int, error) {
+	if c.counterStore != nil {
+		return c.counterStore.Add(domain, 1)
+	}
+	c.domainCounter[domain]++
+	return c.domainCounter[domain], nil
+}
+
+// returns emails count by domain and sum of the counts
+func (c *CustomerImporter) domainCounts() (map[string]int, int, error) {
+	if c.counterStore == nil {
+		return c.domainCounter, c.validEmails, nil
+	}
+
+	counts, err := c.counterStore.Counts()
+	if err != nil {
+		return nil, 0, err
+	}
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return counts, total, nil
+}
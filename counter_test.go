@@ -0,0 +1,55 @@
+package customerimporter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// failingCounterStore fails to count emails, it's used in tests
+type failingCounterStore struct{ err error }
+
+func (s failingCounterStore) Add(string, int) (int, error)    { return 0, s.err }
+func (s failingCounterStore) Counts() (map[string]int, error) { return nil, s.err }
+
+func TestWithCounterStore(t *testing.T) {
+	store := NewMemoryCounterStore()
+
+	t.Log("Should count emails of several imports in the store")
+	for _, records := range []string{"email\na@a.io\nb@a.io\n", "email\nc@a.io\na@b.io\n"} {
+		if _, err := Import(strings.NewReader(records), "email", WithCounterStore(store)); err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+	}
+	if expected := (MemoryCounterStore{"a.io": 3, "b.io": 1}); !reflect.DeepEqual(store, expected) {
+		t.Errorf("should count %v, but got %v", expected, store)
+	}
+
+	t.Log("Should return counts of the store with shares of all its emails")
+	result, err := ImportWithStats(strings.NewReader("email\nd@b.io\n"), "email", WithCounterStore(store))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 3, Share: 0.6},
+		{Domain: "b.io", EmailsCount: 2, Share: 0.4},
+	}
+	if !reflect.DeepEqual(result.Domains, expected) || result.ValidEmails != 1 {
+		t.Errorf("should return %v, but got %+v", expected, result)
+	}
+
+	t.Log("Should abort import when the store fails")
+	storeErr := errors.New("store failed")
+	_, err = Import(strings.NewReader("email\na@a.io\n"), "email", WithCounterStore(failingCounterStore{storeErr}))
+	if !errors.Is(err, storeErr) {
+		t.Errorf("should return %v, but got %v", storeErr, err)
+	}
+
+	t.Log("Should not support checkpoints")
+	_, err = Import(strings.NewReader("email\na@a.io\n"), "email", WithCounterStore(store),
+		WithCheckpoint(t.TempDir()+"/import.checkpoint", 1))
+	if !errors.Is(err, ErrCheckpointUnsupported) {
+		t.Errorf("should return %v, but got %v", ErrCheckpointUnsupported, err)
+	}
+}
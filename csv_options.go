@@ -0,0 +1,51 @@
+package customerimporter
+
+import (
+	"encoding/csv"
+	"errors"
+	"log/slog"
+)
+
+// Allow quotes in unquoted fields and non-doubled quotes in quoted fields,
+// like LazyQuotes of csv.Reader.
+func LazyQuotes() Option { return func(f *CustomerImporter) { f.lazyQuotes = true } }
+
+// Allow records to have different amount of fields than the header. Records
+// without the email column are handled as records with empty email.
+func VariableFieldCount() Option { return func(f *CustomerImporter) { f.variableFieldCount = true } }
+
+// Ignore lines beginning with the comment character, e.g. '#'.
+func WithComment(comment rune) Option { return func(f *CustomerImporter) { f.comment = comment } }
+
+// Skip records with different amount of fields than the header instead of
+// aborting the import. Skipped records are logged and counted in
+// MalformedRows.
+func SkipMalformedRows() Option { return func(f *CustomerImporter) { f.skipMalformedRows = true } }
+
+// reports whether the read error is of malformed record skipped by
+// SkipMalformedRows
+func (c *CustomerImporter) malformed(err error) bool {
+	return err != nil && c.skipMalformedRows && errors.Is(err, csv.ErrFieldCount)
+}
+
+// skips malformed record read on the line
+func (c *CustomerImporter) skipMalformed(line int, record []string) error {
+	c.malformedRows++
+	c.log(slog.LevelDebug, "malformed record skipped", "line", line, "reason", csv.ErrFieldCount)
+	return c.reject(record, csv.ErrFieldCount)
+}
+
+// applies csv options to the reader, sep is delimiter of Excel sep= directive
+// used unless WithDelimiter is set
+func (c *CustomerImporter) configureCSV(reader *csv.Reader, sep rune) {
+	if c.delimiter != 0 {
+		reader.Comma = c.delimiter
+	} else if sep != 0 {
+		reader.Comma = sep
+	}
+	reader.Comment = c.comment
+	reader.LazyQuotes = c.lazyQuotes
+	if c.variableFieldCount {
+		reader.FieldsPerRecord = -1
+	}
+}
@@ -0,0 +1,76 @@
+package customerimporter
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVOptions(t *testing.T) {
+	data := []struct {
+		name      string
+		records   string
+		options   []Option
+		expected  EmailsByDomainQtyList
+		malformed int
+		err       error
+	}{
+		{"lazy quotes", "name,email\nA \"Al\" B,a@a.io\n", []Option{LazyQuotes()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}, 0, nil},
+		{"strict quotes", "name,email\nA \"Al\" B,a@a.io\n", nil, nil, 0, csv.ErrBareQuote},
+		{"variable field count", "name,email\nA,a@a.io,extra\nB\nC,a@b.io\n", []Option{VariableFieldCount(), SkipErrInvalidEmails()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+		}, 0, nil},
+		{"comments", "# exported by CRM\nname,email\nA,a@a.io\n# B,b@b.io\n", []Option{WithComment('#')}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}, 0, nil},
+		{"skip malformed rows", "name,email\nA,a@a.io,extra\nB\nC,a@b.io\n", []Option{SkipMalformedRows()}, EmailsByDomainQtyList{
+			{Domain: "b.io", EmailsCount: 1, Share: 1},
+		}, 2, nil},
+		{"skip malformed rows with workers", "name,email\nA,a@a.io,extra\nB\nC,a@b.io\n", []Option{SkipMalformedRows(), WithWorkers(2)}, EmailsByDomainQtyList{
+			{Domain: "b.io", EmailsCount: 1, Share: 1},
+		}, 2, nil},
+		{"malformed rows", "name,email\nA,a@a.io,extra\n", nil, nil, 0, csv.ErrFieldCount},
+	}
+
+	t.Log("Should parse csv according to the options")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := ImportWithStats(strings.NewReader(d.records), "email", d.options...)
+		if d.err != nil {
+			if !errors.Is(err, d.err) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Domains, d.expected) {
+			t.Errorf("should return %v, but got %v", d.expected, result.Domains)
+		}
+		if result.MalformedRows != d.malformed {
+			t.Errorf("should skip %v malformed records, but got %v", d.malformed, result.MalformedRows)
+		}
+	}
+}
+
+func TestSkipMalformedRowsLines(t *testing.T) {
+	t.Log("Should report lines of records after skipped malformed records")
+
+	for _, workers := range []int{1, 2} {
+		t.Logf("Case: %v", workers)
+
+		records := "name,email\nA,a@a.io,extra\nB,invalid\nC,c@c.io\n"
+		result, err := ImportWithStats(strings.NewReader(records), "email", SkipMalformedRows(), CollectErrors(), WithWorkers(workers))
+		var rowErrors RowErrors
+		if !errors.As(err, &rowErrors) || len(rowErrors) != 1 || rowErrors[0].Line != 3 {
+			t.Errorf("should report invalid email on line 3, but got %v, %+v", err, result)
+		}
+	}
+}
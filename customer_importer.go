@@ -1,19 +1,30 @@
 package customerimporter
 
 import (
+	"context"
+	"crypto"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net/http"
 	"os"
+	"slices"
 	"sort"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	ErrEmptyFile          = errors.New("File is empty")
 	ErrFieldNotExists     = errors.New("CSV header doesn't contain field")
+	ErrColumnNotExists    = errors.New("CSV record doesn't contain column")
 	ErrEmailIsNotValid    = errors.New("Email is not valid")
+	ErrEmailEmpty         = errors.New("Email is empty")
 	ErrEmailDuplicate     = errors.New("Email already added")
 	ErrNoValidEmailsFound = errors.New("No valid emails found")
 )
@@ -25,14 +36,79 @@ type Option func(f *CustomerImporter)
 func SkipErrDuplicateEmails() Option { return func(f *CustomerImporter) { f.skipErrDupEmails = true } }
 
 // Don't raise error if email is invalid, just skip it.
-func SkipErrInvalidEmails() Option { return func(f *CustomerImporter) { f.skipErrInvalidEmails = true } }
+func SkipErrInvalidEmails() Option {
+	return func(f *CustomerImporter) { f.skipErrInvalidEmails = true }
+}
+
+// Don't raise error if email field is empty, just skip it. Invalid emails
+// still raise error unless SkipErrInvalidEmails is used, which skips empty
+// emails too.
+func SkipEmptyEmails() Option { return func(f *CustomerImporter) { f.skipEmptyEmails = true } }
+
+// Treat local parts of emails case-insensitively, so MHernandez@github.io and
+// mhernandez@github.io are counted once. Domain names are always compared
+// case-insensitively.
+func CaseInsensitiveEmails() Option {
+	return func(f *CustomerImporter) { f.caseInsensitiveEmails = true }
+}
+
+// Validate emails according to the level instead of ValidationStandard.
+func WithValidation(level ValidationLevel) Option {
+	return func(f *CustomerImporter) { f.validation = level }
+}
+
+// Use delimiter instead of comma to separate fields, e.g. '\t' for TSV or ';'
+// for exports of European Excel.
+func WithDelimiter(delimiter rune) Option {
+	return func(f *CustomerImporter) { f.delimiter = delimiter }
+}
+
+// Treat every record as data instead of reading the header, email is taken
+// from the column with index i (starting from 0).
+func WithColumnIndex(i int) Option {
+	return func(f *CustomerImporter) {
+		f.headerless = true
+		f.emailColumnIndex = i
+	}
+}
+
+// Count emails of the fields in addition to the email field, e.g. primary
+// and alternate addresses. Empty fields are ignored, the same email in several
+// fields of a record is counted once. The option requires the header, so it's
+// ignored together with WithColumnIndex.
+func WithEmailFields(fields ...string) Option {
+	return func(f *CustomerImporter) { f.emailFieldNames = append(f.emailFieldNames, fields...) }
+}
+
+// Count occurrences of valid emails by domain in addition to the unique
+// emails, so skipped duplicates are still reflected in EmailsByDomainQty
+// Occurrences, e.g. for engagement analysis.
+func CountOccurrences() Option { return func(f *CustomerImporter) { f.countOccurrences = true } }
+
+// Sort results by emails count instead of domain name. Domains with equal
+// count are sorted by name.
+func SortByCount() Option { return func(f *CustomerImporter) { f.sortByCount = true } }
+
+// Sort results in descending order, e.g. the most popular domains first when
+// used together with SortByCount.
+func SortDescending() Option { return func(f *CustomerImporter) { f.sortDescending = true } }
 
 // EmailsByDomainQtyList data structure is used to return data
 type EmailsByDomainQtyList []EmailsByDomainQty
 
 type EmailsByDomainQty struct {
-	Domain      string // domain name
-	EmailsCount int    // amount of emails counted
+	Domain       string         `json:"domain" yaml:"domain"`                                   // domain name
+	EmailsCount  int            `json:"count" yaml:"count"`                                     // amount of emails counted
+	Occurrences  int            `json:"occurrences,omitempty" yaml:"occurrences,omitempty"`     // amount of valid emails read, set by CountOccurrences
+	RoleAccounts int            `json:"role_accounts,omitempty" yaml:"role_accounts,omitempty"` // amount of emails of role accounts, set by ReportRoleAccounts
+	RoleShare    float64        `json:"role_share,omitempty" yaml:"role_share,omitempty"`       // fraction of emails of role accounts, set by ReportRoleAccounts
+	Share        float64        `json:"share" yaml:"share"`                                     // fraction of all counted emails
+	MX           MXStatus       `json:"mx,omitempty" yaml:"mx,omitempty"`                       // whether domain can receive mail, set by VerifyMX
+	Breakdown    map[string]int `json:"breakdown,omitempty" yaml:"breakdown,omitempty"`         // emails count by value of the field, set by WithBreakdown
+	Series       map[string]int `json:"series,omitempty" yaml:"series,omitempty"`               // emails count by period of the timestamp, set by WithTimeSeries
+	Samples      []Sample       `json:"samples,omitempty" yaml:"samples,omitempty"`             // first counted emails, set by WithSamples
+	Category     DomainCategory `json:"category,omitempty" yaml:"category,omitempty"`           // kind of the domain, set by ClassifyDomains
+	Provider     MailProvider   `json:"provider,omitempty" yaml:"provider,omitempty"`           // mail provider by MX records, set by DetectProviders
 }
 
 // EmailsByDomainQtyList sorting methods
@@ -40,200 +116,1086 @@ func (p EmailsByDomainQtyList) Len() int           { return len(p) }
 func (p EmailsByDomainQtyList) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
 func (p EmailsByDomainQtyList) Less(i, j int) bool { return p[i].Domain < p[j].Domain }
 
+// ImportResult contains imported data together with the import statistics
+type ImportResult struct {
+	Domains         EmailsByDomainQtyList  `json:"domains"`                     // emails count by domain
+	RowsRead        int                    `json:"rows_read"`                   // amount of records read, header excluded
+	ValidEmails     int                    `json:"valid_emails"`                // amount of counted emails
+	InvalidEmails   int                    `json:"invalid_emails"`              // amount of skipped invalid emails
+	InvalidByReason map[string]int         `json:"invalid_by_reason,omitempty"` // amount of skipped invalid emails by reason
+	DuplicateEmails int                    `json:"duplicate_emails"`            // amount of skipped duplicate emails
+	FilteredRows    int                    `json:"filtered_rows"`               // amount of records skipped by WithRecordFilter
+	ReservedEmails  int                    `json:"reserved_emails,omitempty"`   // amount of emails of reserved domains, see DropReservedDomains
+	RoleAccounts    int                    `json:"role_accounts,omitempty"`     // amount of emails of role accounts, see ExcludeRoleAccounts
+	Suppressed      int                    `json:"suppressed_emails,omitempty"` // amount of emails skipped by WithSuppressionList
+	MalformedRows   int                    `json:"malformed_rows"`              // amount of records skipped by SkipMalformedRows
+	SampleRate      float64                `json:"sample_rate,omitempty"`       // fraction of the emails counted, set by SampleRate
+	SampledOutRows  int                    `json:"sampled_out_rows,omitempty"`  // amount of records out of the sample, see SampleRate
+	EstimatedEmails int                    `json:"estimated_emails,omitempty"`  // amount of valid emails of the whole input estimated by the sample, see SampleRate
+	DistinctDomains int                    `json:"distinct_domains"`            // amount of distinct domains
+	Elapsed         time.Duration          `json:"elapsed_ns"`                  // time spent on import
+	ErrorMargin     float64                `json:"error_margin,omitempty"`      // relative standard error of emails counts, set by ApproximateCounts
+	Truncated       bool                   `json:"truncated,omitempty"`         // input is read up to a limit, see TruncateAtLimits
+	Partial         bool                   `json:"partial,omitempty"`           // import is aborted by error, see AllowPartialResult
+	Categories      map[DomainCategory]int `json:"categories,omitempty"`        // emails count by category of domains, set by ClassifyDomains
+	Distribution    *Distribution          `json:"distribution,omitempty"`      // distribution of emails among domains, set by ComputeDistribution
+	Typos           []DomainTypo           `json:"typos,omitempty"`             // likely typos of well-known domains, set by DetectTypos
+	Confusables     []ConfusableDomain     `json:"confusables,omitempty"`       // domains with letters looking like Latin ones, set by DetectConfusables
+	Errors          RowErrors              `json:"errors,omitempty"`            // skipped records, set by CollectErrors
+}
+
 // CustomerImporter stores data to operate with csv file
 type CustomerImporter struct {
-	emailFieldName   string          // name of the email field
-	emailColumnIndex int             // index of the email column
-	domainCounter    map[string]int  // used internally for fast increments
-	countedEmails    map[string]bool // used to catch duplicates
-	line             int             // used to keep track of the processing line
-	reader           *csv.Reader     // csv reader
+	emailFieldName       string                    // name of the email field
+	emailColumnIndex     int                       // index of the email column
+	emailFieldNames      []string                  // names of additional email fields
+	emailColumnIndexes   []int                     // indexes of additional email columns
+	breakdownColumnIndex int                       // index of the breakdown column, -1 if not set
+	timeColumnIndex      int                       // index of the timestamp column, -1 if not set
+	header               []string                  // header record, nil if there is no header
+	domainCounter        MemoryCounterStore        // used internally for fast increments
+	interned             map[string]string         // shared copies of domains, see FastPath
+	sketches             map[string]*hyperLogLog   // unique emails by domain, see ApproximateCounts
+	occurrences          map[string]int            // valid emails read by domain, duplicates included
+	roleAccounts         map[string]int            // counted emails of role accounts by domain
+	breakdown            map[string]map[string]int // emails count by domain and value of the breakdown field
+	series               map[string]map[string]int // emails count by domain and period of the timestamp field
+	samples              map[string][]Sample       // first counted emails by domain
+	spilledDomains       []*os.File                // domain counters spilled by WithMemoryLimit
+	countedEmails        DedupStore                // used to catch duplicates
+	line                 int                       // used to keep track of the processing line
+	input                io.Reader                 // source of the csv data
+	reader               RecordReader              // csv reader or other reader of records
+	started              time.Time                 // used to measure import duration
+	handler              EventHandler              // called for every event, if set
+	workers              int                       // amount of goroutines parsing records
+	chunks               int                       // amount of chunks of files parsed in parallel
+	fileName             string                    // name of the file read by ImportFromFiles
+	rejects              rejects                   // writer of the records rejected by WithRejectWriter
+	resume               *checkpoint               // checkpoint of the interrupted import, nil once it's reached
+	resumeLine           int                       // records up to the line are already counted
+	seekReader           *csv.Reader               // csv reader of seekable input, nil if it can't be seeked
+	offsetBase           int64                     // offset of the input where seekReader started
+	offset               int64                     // offset of the input after the current line, 0 if unknown
+	newEmails            []string                  // emails counted after the last checkpoint
+	emailsStart          int64                     // offset of emails of the current dedup store in the emails log
+	emailsEnd            int64                     // length of the emails log of the last checkpoint
+	inputRows            int                       // records read from all inputs, see WithMaxRows
+	inputBytes           int64                     // bytes read from all inputs, see WithMaxBytes
+	truncated            bool                      // input is read up to a limit, see TruncateAtLimits
+
+	// statistics
+	rowsRead          int            // amount of records read
+	validEmails       int            // amount of counted emails
+	invalidEmails     int            // amount of skipped invalid emails
+	invalidByReason   map[string]int // amount of skipped invalid emails by reason
+	duplicateEmails   int            // amount of skipped duplicate emails
+	filteredRows      int            // amount of records skipped by filters
+	sampledOutRows    int            // amount of records out of the sample
+	reservedEmails    int            // amount of emails of reserved domains and synthetic addresses
+	roleAccountsCount int            // amount of emails of role accounts, excluded or counted
+	suppressedEmails  int            // amount of emails skipped by the suppression list
+	malformedRows     int            // amount of records skipped by SkipMalformedRows
+	rowErrors         RowErrors      // collected errors of skipped records
 
 	// options
-	skipErrDupEmails     bool // don't raise error if email is already counted
-	skipErrInvalidEmails bool // don't raise error if email is invalid
+	skipErrDupEmails      bool                // don't raise error if email is already counted
+	skipErrInvalidEmails  bool                // don't raise error if email is invalid
+	skipEmptyEmails       bool                // don't raise error if email field is empty
+	collectErrors         bool                // skip invalid records and collect their errors
+	validateOnly          bool                // validate records without counting domains
+	redactErrors          bool                // keep values of records out of errors and logs
+	rejectWriter          io.Writer           // destination of skipped records, if set
+	onError               ErrorHandler        // decides handling of skipped records, if set
+	dedupPerFile          bool                // deduplicate emails within each file of ImportFromFiles
+	limitErrors           bool                // abort if amount of skipped records exceeds maxErrors
+	maxErrors             int                 // max amount of skipped records
+	maxErrorRate          float64             // max percentage of skipped records, disabled if 0
+	caseInsensitiveEmails bool                // lowercase local part of emails
+	stripDisplayNames     bool                // take emails from angle brackets
+	trimEmailField        bool                // trim whitespace and punctuation of emails
+	canonicalizeEmails    bool                // deduplicate canonical addresses of providers
+	hash                  crypto.Hash         // algorithm of email digests, plain emails are kept if 0
+	countOccurrences      bool                // count valid emails by domain, duplicates included
+	samplesPerDomain      int                 // amount of samples kept for every domain
+	validation            ValidationLevel     // strictness of email validation
+	caseInsensitiveHeader bool                // match header fields case-insensitively
+	trimHeader            bool                // ignore whitespace around header fields
+	fieldAliases          []string            // accepted names of the email field
+	fieldCandidates       []string            // names of the email field used if the header has neither the name nor aliases, the first found wins
+	expectedHeader        []string            // fields of the header, checked if set
+	headerMatch           HeaderMatch         // comparison of the header to expectedHeader
+	breakdownField        string              // name of the field counted by domain, if set
+	timeField             string              // name of the timestamp field counted by domain and period, if set
+	period                Period              // length of the periods of the timestamp field
+	timeLayouts           []string            // layouts the timestamp field is parsed by
+	recordFilters         []RecordFilter      // records are counted only if they pass all filters
+	sampleRate            float64             // fraction of the emails counted, all if 0
+	sampleSeed            uint64              // seed of the hash selecting the sampled emails
+	idnForm               IDNForm             // canonical form of internationalized domains
+	domainAliases         map[string]string   // equivalent domains mapped to their domain
+	reservedHandling      int                 // whether emails of reserved domains are dropped or bucketed
+	excludeRoleAccounts   bool                // emails of role accounts are not counted
+	reportRoleAccounts    bool                // count emails of role accounts by domain
+	suppression           *suppressionList    // emails which are not counted, if set
+	counterStore          CounterStore        // emails count by domain outside the importer, if set
+	typos                 *typoDetector       // finds typos of well-known domains, if set
+	correctTypos          bool                // count emails of typos by the suggested domain
+	confusables           *confusableDetector // finds domains with confusable letters, if set
+	fastPath              bool                // reuse records and intern domains
+	memoryLimit           int64               // approximate memory of counted emails and domains, unlimited if 0
+	hllPrecision          int                 // precision of sketches of ApproximateCounts
+	options               []Option            // options the importer was created with
+	groupBy               func(string) string // maps domain to the counted group, if set
+	compression           Compression         // compression of the input
+	encoding              string              // name of the input encoding, UTF-8 if empty
+	configErr             error               // error of the config file of WithConfigFile, returned by the import
+	httpClient            *http.Client        // downloads input of ImportFromURL
+	webhookURL            string              // URL posted when the import finishes, see WithCompletionWebhook
+	settleDelay           time.Duration       // time without writes before Watch imports a file, see WithSettleDelay
+	delimiter             rune                // field delimiter, comma if not set
+	comment               rune                // comment character, comments are not allowed if not set
+	lazyQuotes            bool                // allow quotes in unquoted fields
+	variableFieldCount    bool                // allow records with different amount of fields
+	skipMalformedRows     bool                // skip records with different amount of fields
+	skipRows              int                 // amount of lines skipped before the header
+	checkpointPath        string              // file of the saved import state, if set
+	checkpointEvery       int                 // amount of records between checkpoints
+	headerless            bool                // csv has no header, column index is set
+	countValues           bool                // count records by value of the field instead of email domain
+	mxVerifier            *MXVerifier         // looks up MX records of counted domains
+	detectProviders       bool                // infer mail providers of domains by MX records
+	classifier            *DomainClassifier   // classifies counted domains, if set
+	logger                *slog.Logger        // logs skipped records and statistics, if set
+	tracer                trace.Tracer        // traces the import, see WithTracerProvider
+	traceCtx              context.Context     // parent of the spans, span of the import when it's started
+	traced                bool                // span of the import is started
+	sortByCount           bool                // sort results by emails count
+	sortDescending        bool                // sort results in descending order
+	topN                  int                 // amount of returned domains, all if < 1
+	collapseRest          bool                // sum emails of the rest domains into other entry
+	minCount              int                 // least amount of emails of returned domains, all if < 2
+	collapseBelow         bool                // sum emails of domains below minCount into other entry
+	distribution          bool                // compute distribution of emails among domains
+	maxRows               int                 // max amount of records of all inputs, unlimited if < 1
+	maxValidEmails        int                 // amount of counted emails the import stops at, unlimited if < 1
+	maxBytes              int64               // max amount of bytes of all inputs, unlimited if < 1
+	truncateAtLimits      bool                // return result of the records read up to a limit
+	allowPartialResult    bool                // return counts of the records read before error
 }
 
 // imports from the file and returns EmailsByDomainQtyList
 func ImportFromFile(fileName string, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
-	// open file
-	file, err := os.Open(fileName)
-	if err != nil {
+	// import and get result
+	result, err := ImportFromFileWithStats(fileName, emailFieldName, options...)
+	if result == nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	// import and get result
-	result, err := Import(file, emailFieldName, options...)
-	if err != nil {
-		return nil, err
+	return &result.Domains, err
+}
+
+// imports from the file and returns ImportResult with statistics. Files with
+// .gz and .zst extensions are decompressed unless WithCompression is used.
+// File name "-" means standard input.
+func ImportFromFileWithStats(fileName string, emailFieldName string, options ...Option) (*ImportResult, error) {
+	// detect compression by extension, explicit option takes precedence
+	if compression, ok := compressionByExtension(fileName); ok {
+		options = append([]Option{WithCompression(compression)}, options...)
 	}
 
-	return result, nil
+	// open file, import and get result
+	return newCustomerImporter(nil, emailFieldName, options...).runSource(context.Background(), FileSource(fileName))
+}
+
+// opens the file, "-" is standard input which is left open
+func openFile(fileName string) (io.ReadCloser, error) {
+	if fileName == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(fileName)
 }
 
 // imports from reader
 func Import(r io.Reader, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
-	// initialize csv reader
-	reader := csv.NewReader(r)
+	// import and get result
+	result, err := ImportWithStats(r, emailFieldName, options...)
+	if result == nil {
+		return nil, err
+	}
 
-	// initialize CustomerImporter
-	c := CustomerImporter{reader: reader, emailFieldName: emailFieldName}
+	return &result.Domains, err
+}
 
-	// initialize maps
-	c.domainCounter = make(map[string]int, 10)
-	c.countedEmails = make(map[string]bool, 10)
+// imports from reader and returns ImportResult with statistics. If errors
+// are collected, the result is returned together with RowErrors.
+func ImportWithStats(r io.Reader, emailFieldName string, options ...Option) (*ImportResult, error) {
+	return newCustomerImporter(r, emailFieldName, options...).run()
+}
 
-	// set options
-	for _, option := range options {
-		option(&c)
+// RecordReader reads records of tabular data, *csv.Reader implements it. The
+// first record is the header unless WithColumnIndex is used.
+type RecordReader interface {
+	Read() (record []string, err error)
+}
+
+// imports from reader of records, e.g. XLSXReader
+func ImportRecords(rr RecordReader, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	// import and get result
+	result, err := ImportRecordsWithStats(rr, emailFieldName, options...)
+	if result == nil {
+		return nil, err
 	}
 
-	// parse records
+	return &result.Domains, err
+}
+
+// imports from reader of records and returns ImportResult with statistics.
+// Options of the csv format and compression are not applied.
+func ImportRecordsWithStats(rr RecordReader, emailFieldName string, options ...Option) (*ImportResult, error) {
+	c := newCustomerImporter(nil, emailFieldName, options...)
+	c.reader = rr
+
+	return c.run()
+}
+
+// parses records and returns result
+func (c *CustomerImporter) run() (result *ImportResult, err error) {
+	defer c.closeSpill()
+	defer func() { c.notifyCompletion(result, err) }()
+	endImport := c.traceImport()
+	defer func() { endImport(result, err) }()
+
+	// parse records, counts of the records read before error may be returned
 	if err := c.parse(); err != nil {
-		return nil, err
+		return c.partialResult(err)
 	}
 
+	return c.result()
+}
+
+// returns result, collected errors are returned together with it
+func (c *CustomerImporter) result() (*ImportResult, error) {
 	// get result
 	result, err := c.getResult()
 	if err != nil {
 		return nil, err
 	}
 
+	// return collected errors
+	if len(result.Errors) > 0 {
+		return result, result.Errors
+	}
+
 	return result, nil
 }
 
+// initializes CustomerImporter reading from r
+func newCustomerImporter(r io.Reader, emailFieldName string, options ...Option) *CustomerImporter {
+	// initialize CustomerImporter
+	c := &CustomerImporter{input: r, emailFieldName: emailFieldName, breakdownColumnIndex: -1, timeColumnIndex: -1, started: time.Now(), options: options}
+
+	// initialize maps
+	c.domainCounter = make(map[string]int, 10)
+	c.occurrences = make(map[string]int, 10)
+	c.roleAccounts = make(map[string]int, 10)
+	c.breakdown = make(map[string]map[string]int, 10)
+	c.series = make(map[string]map[string]int, 10)
+	c.samples = make(map[string][]Sample, 10)
+	c.invalidByReason = make(map[string]int)
+	c.countedEmails = make(MemoryDedupStore, 10)
+
+	// set options
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
 // parses csv and updates counter
 func (c *CustomerImporter) parse() error {
-	for {
-		// increment line
-		c.line++
+	// load state of the interrupted import
+	if err := c.loadCheckpoint(); err != nil {
+		return err
+	}
+
+	// read records
+	if err := c.parseInput(); err != nil {
+		return err
+	}
+
+	// check rate of skipped records
+	if err := c.checkErrorRate(); err != nil {
+		return err
+	}
+
+	// import is finished, it won't be resumed
+	if err := c.removeCheckpoint(); err != nil {
+		return err
+	}
+
+	c.log(slog.LevelInfo, "import finished",
+		"rows", c.rowsRead,
+		"valid_emails", c.validEmails,
+		"invalid_emails", c.invalidEmails,
+		"duplicate_emails", c.duplicateEmails,
+		"domains", len(c.domainCounter),
+		"elapsed", time.Since(c.started),
+	)
+
+	return nil
+}
+
+// reads header and records of the input and updates counter
+func (c *CustomerImporter) parseInput() error {
+	// inputs after the truncated one are not read
+	if c.truncated {
+		return nil
+	}
 
+	// options of invalid config file are not applied
+	if c.configErr != nil {
+		return c.configErr
+	}
+
+	// read suppressed emails before the first input
+	if c.suppression != nil {
+		if err := c.suppression.load(); err != nil {
+			return err
+		}
+	}
+
+	// split large file into chunks parsed in parallel
+	if file, start, ok := c.chunkedFile(); ok && c.reader == nil {
+		span := c.startSpan("customerimporter.Parse", attribute.Int("customerimporter.chunks", c.chunks))
+		err := c.parseChunks(file, start)
+		endSpan(span, err, c.countAttributes()...)
+		return err
+	}
+
+	// read csv from the input unless records are read by other reader
+	c.seekReader, c.offset = nil, 0
+	if c.reader == nil {
+		// offset of seekable input is read before the input is buffered
+		start, seekable := c.inputStart()
+
+		// decompress input
+		r, compression, err := c.decompress(c.input)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		// count offset of the records of uncompressed input
+		var input io.Reader = r
+		var counter *offsetReader
+		if seekable && compression == CompressionNone {
+			counter = &offsetReader{r: r, offset: start}
+			input = counter
+		}
+
+		// decode to UTF-8
+		decoded, err := c.decode(c.limitBytes(input))
+		if err != nil {
+			return err
+		}
+
+		// skip lines before the header
+		sep, err := c.skipPreamble(decoded)
+		if err != nil {
+			return err
+		}
+
+		// initialize csv reader
+		reader := csv.NewReader(decoded)
+		c.configureCSV(reader, sep)
+		reader.ReuseRecord = c.fastPath && c.workers <= 1
+		c.reader = reader
+		if counter != nil {
+			c.seekReader, c.offsetBase = reader, counter.offset-int64(decoded.Buffered())
+		}
+	}
+	c.reader = c.limitRows(c.reader)
+
+	// restore state of the interrupted import, the first record of input
+	// without header may be already counted
+	cp, err := c.resumeCheckpoint()
+	if err != nil {
+		return err
+	}
+	if cp != nil && c.headerless {
+		c.resumeLine = cp.Line
+	}
+
+	// read header
+	if err := c.parseHeader(); err != nil {
+		return err
+	}
+
+	// skip records counted before the checkpoint
+	if cp != nil {
+		if err := c.skipCheckpointed(cp); err != nil {
+			return err
+		}
+	}
+
+	// record without header may reach the emails limit
+	if c.enoughEmails() {
+		return nil
+	}
+
+	// process records by the pipeline if workers are enabled
+	span := c.startSpan("customerimporter.Parse", attribute.Int("customerimporter.workers", max(c.workers, 1)))
+	if c.workers > 1 {
+		err = c.parseConcurrently()
+	} else {
+		err = c.parseSequentially()
+	}
+	endSpan(span, err, c.countAttributes()...)
+	return err
+}
+
+// reads records one by one and updates counter
+func (c *CustomerImporter) parseSequentially() error {
+	for {
 		// read record
-		record, err := c.reader.Read()
+		record, err := c.readRecord()
+		c.offset = c.readOffset()
 
 		// handle end of file
 		if err == io.EOF {
-			if c.line == 1 {
-				return c.error(ErrEmptyFile)
-			}
 			return nil
 		}
 
+		// skip malformed record
+		if c.malformed(err) {
+			if err := c.skipMalformed(c.line, record); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// handle errors
 		if err != nil {
 			return err
 		}
 
-		// if it's the first line, read header
-		if c.line == 1 {
-			// determine email column index
-			if err := c.determineEmailColumnIndex(record); err != nil {
-				return c.error(err)
-			}
-			continue
+		// read records, update domain counter
+		c.rowsRead++
+		if err := c.updateDomainCounter(c.parseRecord(c.line, record)); err != nil {
+			return err
+		}
+		if c.enoughEmails() {
+			return nil
 		}
 
-		// if it's not the first line, read records, update domain counter
-		err = c.updateDomainCounter(record)
-		if err != nil {
-			return c.error(err)
+		// save progress
+		if err := c.checkpoint(); err != nil {
+			return err
+		}
+	}
+}
+
+// reads header record and determines email column index
+func (c *CustomerImporter) parseHeader() (err error) {
+	span := c.startSpan("customerimporter.ParseHeader")
+	defer func() {
+		endSpan(span, err, attribute.Int("customerimporter.email_column", c.emailColumnIndex),
+			attribute.Int("customerimporter.fields", len(c.header)))
+	}()
+
+	record, err := c.readRecord()
+
+	// handle end of file, the header of input may be cut by TruncateAtLimits
+	if err == io.EOF {
+		if c.truncated {
+			return nil
+		}
+		return c.error(ErrEmptyFile)
+	}
+
+	// handle errors
+	if err != nil {
+		return err
+	}
+
+	// if there is no header, check the email column and count the record
+	if c.headerless {
+		if c.emailColumnIndex < 0 || c.emailColumnIndex >= len(record) {
+			return c.error(fmt.Errorf("%w %d", ErrColumnNotExists, c.emailColumnIndex))
+		}
+		c.log(slog.LevelDebug, "header skipped", "column", c.emailColumnIndex)
+		c.rowsRead++
+		return c.updateDomainCounter(c.parseRecord(c.line, record))
+	}
+
+	// check schema of the header before looking for the email column
+	if err := c.checkHeader(record); err != nil {
+		return c.error(err)
+	}
+
+	// determine email column index
+	if err := c.determineEmailColumnIndex(record); err != nil {
+		return c.error(err)
+	}
+	if err := c.determineEmailColumnIndexes(record); err != nil {
+		return c.error(err)
+	}
+	if err := c.determineBreakdownColumnIndex(record); err != nil {
+		return c.error(err)
+	}
+	if err := c.determineTimeColumnIndex(record); err != nil {
+		return c.error(err)
+	}
+	c.header = slices.Clone(record)
+	c.log(slog.LevelDebug, "email column detected", "field", c.emailFieldName, "column", c.emailColumnIndex, "header_field", record[c.emailColumnIndex])
+
+	return nil
+}
+
+// reads next record and increments line, input truncated at a limit ends
+// by io.EOF
+func (c *CustomerImporter) readRecord() ([]string, error) {
+	record, err := c.nextRecord(&c.line)
+	if err == errTruncated {
+		c.truncated = true
+		return nil, io.EOF
+	}
+	return record, err
+}
+
+// reads next record skipping records counted before the checkpoint, line is
+// incremented for every read record. The importer isn't changed as records
+// are read by the producer of the pipeline, errTruncated is returned at a
+// limit of TruncateAtLimits.
+func (c *CustomerImporter) nextRecord(line *int) ([]string, error) {
+	for {
+		*line++
+		record, err := c.reader.Read()
+		if c.truncateAtLimits && isLimitError(err) {
+			return nil, errTruncated
 		}
+		if *line <= c.resumeLine && (err == nil || errors.Is(err, csv.ErrFieldCount)) {
+			continue
+		}
+		return record, err
 	}
 }
 
 // transforms domain counter to sorted EmailsByDomainQtyList data structure
-func (c *CustomerImporter) getResult() (*EmailsByDomainQtyList, error) {
+// and collects statistics
+func (c *CustomerImporter) getResult() (res *ImportResult, err error) {
+	span := c.startSpan("customerimporter.Result")
+	defer func() {
+		if res != nil {
+			span.SetAttributes(attribute.Int("customerimporter.distinct_domains", res.DistinctDomains))
+		}
+		endSpan(span, err)
+	}()
+
+	// domains are not counted in validate-only mode
+	if c.validateOnly {
+		return c.newResult(nil, 0), nil
+	}
+
+	// add counts of domains spilled by WithMemoryLimit
+	if err := c.mergeSpilledDomains(); err != nil {
+		return nil, err
+	}
+
+	// counts may be kept in a store shared with other imports, unique emails
+	// are estimated by ApproximateCounts
+	counts, total, err := c.domainCounts()
+	if err != nil {
+		return nil, err
+	}
+	validEmails, duplicateEmails := c.validEmails, c.duplicateEmails
+	if c.approximate() {
+		counts, validEmails, duplicateEmails = c.estimateCounts()
+		total = validEmails
+	}
+
 	var result EmailsByDomainQtyList
 
 	// transform domain counter map to sortable list
-	for domain, emailsQuantity := range c.domainCounter {
-		result = append(result, EmailsByDomainQty{Domain: domain, EmailsCount: emailsQuantity})
+	for domain, emailsQuantity := range counts {
+		result = append(result, EmailsByDomainQty{Domain: domain, EmailsCount: emailsQuantity, Occurrences: c.occurrences[domain], RoleAccounts: c.roleAccounts[domain], Breakdown: c.breakdown[domain], Series: c.series[domain], Samples: c.samples[domain]})
 	}
 
-	// sort
-	sort.Sort(result)
-
 	// if there are no records return error
 	if len(result) < 1 {
-		return nil, c.error(ErrNoValidEmailsFound)
+		return nil, ErrNoValidEmailsFound
 	}
+	distinctDomains := len(result)
 
-	return &result, nil
+	// classify all domains before the rest are collapsed
+	var categories map[DomainCategory]int
+	if c.classifier != nil {
+		categories = c.classifier.classify(result)
+	}
+
+	// describe distribution of all domains before the rest are collapsed
+	var dist *Distribution
+	if c.distribution {
+		dist = distribution(result)
+	}
+
+	// keep domains with enough emails and top domains
+	result, other := c.keepDomains(result)
+
+	// sort
+	c.sortResult(result)
+
+	// verify domains can receive mail
+	if c.mxVerifier != nil {
+		c.mxVerifier.verify(context.Background(), result, c.detectProviders)
+	}
+
+	// collapsed domains go last
+	if other != nil {
+		result = append(result, *other)
+	}
+
+	// compute fraction of all counted emails and of role accounts of the
+	// domain
+	for i := range result {
+		result[i].Share = float64(result[i].EmailsCount) / float64(total)
+		if result[i].RoleAccounts > 0 {
+			result[i].RoleShare = float64(result[i].RoleAccounts) / float64(result[i].EmailsCount)
+		}
+	}
+
+	importResult := c.newResult(result, distinctDomains)
+	importResult.ValidEmails, importResult.DuplicateEmails = validEmails, duplicateEmails
+	importResult.EstimatedEmails = c.extrapolate(validEmails)
+	importResult.ErrorMargin = c.errorMargin()
+	importResult.Categories = categories
+	importResult.Distribution = dist
+	return importResult, nil
+}
+
+// returns result with the domains and statistics of the import
+func (c *CustomerImporter) newResult(domains EmailsByDomainQtyList, distinctDomains int) *ImportResult {
+	return &ImportResult{
+		Domains:         domains,
+		RowsRead:        c.rowsRead,
+		ValidEmails:     c.validEmails,
+		InvalidEmails:   c.invalidEmails,
+		InvalidByReason: c.invalidByReason,
+		DuplicateEmails: c.duplicateEmails,
+		FilteredRows:    c.filteredRows,
+		ReservedEmails:  c.reservedEmails,
+		RoleAccounts:    c.roleAccountsCount,
+		Suppressed:      c.suppressedEmails,
+		MalformedRows:   c.malformedRows,
+		SampleRate:      c.sampleRate,
+		SampledOutRows:  c.sampledOutRows,
+		EstimatedEmails: c.extrapolate(c.validEmails),
+		DistinctDomains: distinctDomains,
+		Elapsed:         time.Since(c.started),
+		Errors:          c.rowErrors,
+		Truncated:       c.truncated,
+		Typos:           c.typos.result(),
+		Confusables:     c.confusables.result(),
+	}
 }
 
-// determine email column index by email field name
+// sorts result by domain name or emails count according to the options
+func (c *CustomerImporter) sortResult(result EmailsByDomainQtyList) {
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+
+		// domains with equal count are always sorted by name
+		if c.sortByCount && a.EmailsCount == b.EmailsCount {
+			return a.Domain < b.Domain
+		}
+
+		// swap operands to reverse the order
+		if c.sortDescending {
+			a, b = b, a
+		}
+
+		if c.sortByCount {
+			return a.EmailsCount < b.EmailsCount
+		}
+		return a.Domain < b.Domain
+	})
+}
+
+// determine email column index by email field name or its aliases, the first
+// candidate found is used if the header has none of them
 func (c *CustomerImporter) determineEmailColumnIndex(headerRecord []string) error {
-	// try to get index of field by name
-	for index, r := range headerRecord {
-		if r == c.emailFieldName {
-			c.emailColumnIndex = index
-			return nil
+	index, err := c.fieldIndex(headerRecord, append([]string{c.emailFieldName}, c.fieldAliases...)...)
+	for _, candidate := range c.fieldCandidates {
+		if !errors.Is(err, ErrFieldNotExists) {
+			break
+		}
+		if i, candidateErr := c.fieldIndex(headerRecord, candidate); !errors.Is(candidateErr, ErrFieldNotExists) {
+			index, err = i, candidateErr
 		}
 	}
-	// if the field is not found, return an error
-	return errors.New(ErrFieldNotExists.Error() + fmt.Sprintf(" %s field", c.emailFieldName))
+	if err != nil {
+		return err
+	}
+	c.emailColumnIndex = index
+	return nil
 }
 
-// updates domain counter
-func (c *CustomerImporter) updateDomainCounter(record []string) error {
+// determine indexes of additional email columns by WithEmailFields
+func (c *CustomerImporter) determineEmailColumnIndexes(headerRecord []string) error {
+	c.emailColumnIndexes = nil
+	for _, field := range c.emailFieldNames {
+		index, err := c.fieldIndex(headerRecord, field)
+		if err != nil {
+			return err
+		}
+		if index != c.emailColumnIndex && !slices.Contains(c.emailColumnIndexes, index) {
+			c.emailColumnIndexes = append(c.emailColumnIndexes, index)
+		}
+	}
+	return nil
+}
+
+// parsedRecord holds data extracted from the record
+type parsedRecord struct {
+	line      int            // line of the record
+	filtered  bool           // record is skipped by filters
+	unsampled bool           // record is out of the sample, see SampleRate
+	malformed bool           // record is skipped by SkipMalformedRows
+	column    int            // column of the email
+	value     string         // email field of the record as is
+	key       string         // value of the breakdown field
+	period    string         // period of the timestamp field, set by WithTimeSeries
+	record    []string       // fields of the record, set by WithRejectWriter and for malformed records
+	email     string         // normalized email
+	domain    string         // domain name of the email
+	err       error          // error of the domain name extraction
+	reserved  bool           // email is of reserved domain or synthetic, set by DropReservedDomains and BucketReservedDomains
+	more      []parsedRecord // emails of additional email fields
+}
+
+// extracts email and domain name from the record, it doesn't change the state
+// of the importer and is safe for concurrent use
+func (c *CustomerImporter) parseRecord(line int, record []string) parsedRecord {
+	// skip filtered record
+	if !c.filterRecord(record) {
+		return parsedRecord{line: line, filtered: true}
+	}
+
+	// skip record out of the sample
+	if c.sampleRate > 0 && !c.sampled(field(record, c.emailColumnIndex)) {
+		return parsedRecord{line: line, unsampled: true}
+	}
+
 	// retrieve email field from record
-	email := record[c.emailColumnIndex]
+	r := c.parseEmail(line, c.emailColumnIndex, field(record, c.emailColumnIndex))
+	if c.breakdownColumnIndex >= 0 {
+		r.key = field(record, c.breakdownColumnIndex)
+	}
+	if c.timeColumnIndex >= 0 {
+		r.period = c.periodOf(field(record, c.timeColumnIndex))
+	}
+	if c.rejectWriter != nil || c.onError != nil {
+		r.record = record
+	}
+	if len(c.emailColumnIndexes) == 0 {
+		return r
+	}
+
+	// collect populated email fields, the email field is kept if all are empty
+	var emails []parsedRecord
+	if r.value != "" {
+		emails = append(emails, r)
+	}
+	for _, column := range c.emailColumnIndexes {
+		if field(record, column) == "" {
+			continue
+		}
+		e := c.parseEmail(line, column, record[column])
+		e.key, e.period, e.record = r.key, r.period, r.record
+		if e.err == nil && slices.ContainsFunc(emails, func(o parsedRecord) bool { return o.email == e.email }) {
+			continue
+		}
+		emails = append(emails, e)
+	}
+	if len(emails) == 0 {
+		return r
+	}
+
+	emails[0].more = emails[1:]
+	return emails[0]
+}
+
+// returns field of the record, empty if the record is shorter
+func field(record []string, column int) string {
+	if column >= len(record) {
+		return ""
+	}
+	return record[column]
+}
+
+// extracts email and domain name from the value of the column
+func (c *CustomerImporter) parseEmail(line, column int, value string) parsedRecord {
+	r := parsedRecord{line: line, column: column, value: value}
 
-	// check if email was already added
-	err := c.handleDuplicates(email)
+	// value itself is counted by ImportGroupBy
+	if c.countValues {
+		r.domain = value
+		return r
+	}
+
+	r.email = r.value
+	if c.trimEmailField {
+		r.email = trimEmail(r.email)
+	}
+	if c.stripDisplayNames {
+		r.email = bareAddress(r.email)
+	}
+
+	// empty field is distinguished from invalid email
+	if r.email == "" {
+		r.err = ErrEmailEmpty
+		return r
+	}
+
+	// extract domain name from email
+	r.domain, r.err = getDomainNameFromEmail(r.email, c.validation)
+	if r.err == nil {
+		r.email, r.domain, r.err = c.normalize(r.email, r.domain)
+	}
+
+	// mark emails of reserved domains and synthetic addresses
+	if r.err == nil && c.reservedHandling != keepReserved && IsReservedEmail(r.email) {
+		r.reserved = true
+		if c.reservedHandling == bucketReserved {
+			r.domain = ReservedDomain
+		}
+	}
+
+	return r
+}
+
+// normalizes valid email and its domain name, so the same address written
+// differently is counted once
+func (c *CustomerImporter) normalize(email, domain string) (string, string, error) {
+	// domain names are case-insensitive and may be fully qualified
+	domain = normalizeDomain(domain)
+
+	// convert internationalized domain names
+	domain, err := c.idnForm.convert(domain)
 	if err != nil {
-		if c.skipErrDupEmails {
+		return "", "", ErrEmailIsNotValid
+	}
+
+	// merge equivalent domains
+	if alias, ok := c.domainAliases[domain]; ok {
+		domain = alias
+	}
+
+	// local part is case-sensitive unless the option is set
+	at := strings.LastIndexByte(email, '@')
+	local := email[:at]
+	if c.caseInsensitiveEmails {
+		local = strings.ToLower(local)
+	}
+
+	// email is rebuilt only if it was changed
+	if local != email[:at] || domain != email[at+1:] {
+		email = local + "@" + domain
+	}
+
+	// count emails by group of the domain
+	if c.groupBy != nil {
+		domain = c.groupBy(domain)
+	}
+
+	return email, domain, nil
+}
+
+// updates domain counter
+func (c *CustomerImporter) updateDomainCounter(r parsedRecord) error {
+	// filtered records are neither validated nor counted
+	if r.filtered {
+		c.filteredRows++
+		c.log(slog.LevelDebug, "record filtered", "line", r.line)
+		return nil
+	}
+	if r.unsampled {
+		c.sampledOutRows++
+		return nil
+	}
+
+	// count email of every email field
+	if err := c.countEmail(r); err != nil {
+		return c.recordError(r, err)
+	}
+	for _, e := range r.more {
+		if c.enoughEmails() {
+			break
+		}
+		if err := c.countEmail(e); err != nil {
+			return c.recordError(e, err)
+		}
+	}
+	return nil
+}
+
+// validates, deduplicates and counts email
+func (c *CustomerImporter) countEmail(r parsedRecord) error {
+	if c.fastPath {
+		r.domain = c.intern(r.domain)
+	}
+
+	// keep counted emails and domains within the memory limit
+	if err := c.limitMemory(); err != nil {
+		return err
+	}
+
+	// drop emails of reserved domains, role accounts and suppressed emails
+	// before they are deduplicated
+	if r.reserved {
+		c.reservedEmails++
+		if c.reservedHandling == dropReserved {
 			return nil
 		}
+	}
+	role := c.isRoleAccount(r)
+	if role && c.excludeRoleAccounts {
+		c.roleAccountsCount++
+		return nil
+	}
+	if c.suppression != nil && r.err == nil && !c.countValues && c.suppression.contains(r.email) {
+		c.suppressedEmails++
+		return nil
+	}
+
+	// correct typos of well-known domains before the email is deduplicated
+	var typo string
+	if c.typos != nil && r.err == nil && !r.reserved && !c.countValues {
+		typo = c.checkTypo(&r)
+	}
+
+	// check if email was already added, failure of the store aborts import,
+	// invalid and empty emails are not deduplicated
+	var err error
+	if !c.countValues && !c.approximate() && r.err == nil {
+		email := r.email
+		if c.canonicalizeEmails {
+			email = canonicalEmail(email)
+		}
+		err = c.handleDuplicates(email)
+	}
+	if err != nil && !errors.Is(err, ErrEmailDuplicate) {
 		return err
 	}
 
-	// extract domain name from email
-	domainName, err := getDomainNameFromEmail(email)
+	// count occurrence of valid email, duplicates included
+	if c.countOccurrences && r.err == nil {
+		c.occurrences[r.domain]++
+	}
+
 	if err != nil {
-		if c.skipErrInvalidEmails {
-			return nil
+		if err := c.emit(Event{Type: EventDuplicateEmail, Line: r.line, Email: r.email, Err: err}); err != nil {
+			return err
+		}
+		return c.handleRowError(r, err)
+	}
+
+	// check if domain name was extracted
+	if r.err != nil {
+		if err := c.emit(Event{Type: EventInvalidEmail, Line: r.line, Email: r.email, Err: r.err}); err != nil {
+			return err
 		}
+		return c.handleRowError(r, r.err)
+	}
+
+	// increment domain counter, only valid emails are counted in
+	// validate-only mode
+	c.validEmails++
+	if typo != "" {
+		c.typos.counts[typo]++
+	}
+	if role {
+		c.roleAccountsCount++
+	}
+	if c.confusables != nil {
+		c.checkConfusable(r)
+	}
+	if c.validateOnly {
+		return nil
+	}
+	count, err := c.countDomain(r.domain)
+	if err != nil {
 		return err
 	}
+	if role {
+		c.roleAccounts[r.domain]++
+	}
+	if c.approximate() {
+		c.addToSketch(r.email, r.domain)
+	}
+	if c.breakdownColumnIndex >= 0 {
+		c.countBreakdown(r)
+	}
+	if c.timeColumnIndex >= 0 {
+		c.countSeries(r)
+	}
+	if c.samplesPerDomain > 0 {
+		c.sample(r)
+	}
 
-	// increment domain counter
-	c.domainCounter[domainName]++
+	// notify about counted email and newly found domain
+	if err := c.emit(Event{Type: EventValidEmail, Line: r.line, Email: r.email, Domain: r.domain}); err != nil {
+		return err
+	}
+	if count == 1 {
+		return c.emit(Event{Type: EventNewDomain, Line: r.line, Email: r.email, Domain: r.domain})
+	}
 
 	return nil
 }
 
 // checks if email was counted and updates counted state
 func (c *CustomerImporter) handleDuplicates(email string) error {
-	// check if email was counted
-	if _, isCounted := c.countedEmails[email]; isCounted {
-		return ErrEmailDuplicate
+	key, err := c.dedupKey(email)
+	if err != nil {
+		return err
 	}
 
-	// update email counted state
-	c.countedEmails[email] = true
+	// check if email was counted and update email counted state
+	isCounted, err := c.countedEmails.Add(key)
+	if err != nil {
+		return err
+	}
+	if isCounted {
+		return ErrEmailDuplicate
+	}
+	if c.checkpointEvery > 0 {
+		c.logEmail(key)
+	}
 
 	return nil
 }
 
-// error creates new csv.ParseError based on err.
+// error creates new ImportError of the current line based on err.
 func (c *CustomerImporter) error(err error) error {
-	return &csv.ParseError{
+	return &ImportError{
 		Line:   c.line,
-		Column: c.emailColumnIndex,
+		Column: -1,
+		Field:  c.emailFieldName,
 		Err:    err,
 	}
 }
 
 // extracts domain name from email address
-func getDomainNameFromEmail(email string) (string, error) {
+func getDomainNameFromEmail(email string, level ValidationLevel) (string, error) {
 	// validate email
-	if !IsValidEmail(email) {
+	if !level.IsValidEmail(email) {
 		return "", ErrEmailIsNotValid
 	}
-	// get domain part of the email
-	domainName := strings.Split(email, "@")[1]
+	// get domain part of the email, quoted local part may contain @
+	domainName := email[strings.LastIndexByte(email, '@')+1:]
 
 	return domainName, nil
 }
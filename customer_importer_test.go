@@ -1,6 +1,7 @@
 package customerimporter
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -8,6 +9,7 @@ import (
 
 	"encoding/csv"
 
+	"os"
 	"reflect"
 )
 
@@ -31,7 +33,7 @@ func TestImport(t *testing.T) {
 		{[]string{"Mildred,Hernandez,mhernandez@github.io,Female,38.194.51.128"},
 			emptyOption(),
 			nil,
-			EmailsByDomainQtyList{{"github.io", 1}},
+			EmailsByDomainQtyList{{Domain: "github.io", EmailsCount: 1, Share: 1}},
 		},
 
 		// working sorting case
@@ -44,10 +46,10 @@ func TestImport(t *testing.T) {
 			emptyOption(),
 			nil,
 			EmailsByDomainQtyList{
-				{"a.io", 1},
-				{"b.io", 1},
-				{"c.io", 1},
-				{"d.io", 1},
+				{Domain: "a.io", EmailsCount: 1, Share: 0.25},
+				{Domain: "b.io", EmailsCount: 1, Share: 0.25},
+				{Domain: "c.io", EmailsCount: 1, Share: 0.25},
+				{Domain: "d.io", EmailsCount: 1, Share: 0.25},
 			},
 		},
 
@@ -61,6 +63,35 @@ func TestImport(t *testing.T) {
 		// case with empty email
 		{[]string{"Mildred,Hernandez,,Female,38.194.51.128"},
 			emptyOption(),
+			ErrEmailEmpty,
+			nil,
+		},
+
+		// case with empty email but with SkipEmptyEmails option enabled
+		{[]string{"Mildred,Hernandez,,Female,38.194.51.128",
+			"Bonnie,Ortiz,bortiz@cyberchimps.com,Female,197.54.209.129"},
+			SkipEmptyEmails(),
+			nil,
+			EmailsByDomainQtyList{
+				{Domain: "cyberchimps.com", EmailsCount: 1, Share: 1},
+			},
+		},
+
+		// case with several empty emails and SkipEmptyEmails option enabled,
+		// empty emails are not duplicates
+		{[]string{"Mildred,Hernandez,,Female,38.194.51.128",
+			"Bonnie,Ortiz,,Female,197.54.209.129",
+			"Bonnie,Ortiz,bortiz@cyberchimps.com,Female,197.54.209.129"},
+			SkipEmptyEmails(),
+			nil,
+			EmailsByDomainQtyList{
+				{Domain: "cyberchimps.com", EmailsCount: 1, Share: 1},
+			},
+		},
+
+		// case with invalid email and SkipEmptyEmails option enabled
+		{[]string{"Mildred,Hernandez,mhernandezgithub.io,Female,38.194.51.128"},
+			SkipEmptyEmails(),
 			ErrEmailIsNotValid,
 			nil,
 		},
@@ -85,7 +116,21 @@ func TestImport(t *testing.T) {
 			"Mildred,Hernandez,mhernandez0@github.io,Female,38.194.51.128"},
 			SkipErrDuplicateEmails(),
 			nil,
-			EmailsByDomainQtyList{{"github.io", 1}},
+			EmailsByDomainQtyList{{Domain: "github.io", EmailsCount: 1, Share: 1}},
+		},
+
+		// case with email accepted only by lenient validation
+		{[]string{"Mildred,Hernandez,m hernandez@github.io,Female,38.194.51.128"},
+			WithValidation(ValidationLenient),
+			nil,
+			EmailsByDomainQtyList{{Domain: "github.io", EmailsCount: 1, Share: 1}},
+		},
+
+		// case with email rejected by strict validation
+		{[]string{"Mildred,Hernandez,mhernandez@github.io.,Female,38.194.51.128"},
+			WithValidation(ValidationStrict),
+			ErrEmailIsNotValid,
+			nil,
 		},
 
 		// case with wrong number of fields
@@ -98,7 +143,7 @@ func TestImport(t *testing.T) {
 		// error should contain correct line and column
 		{[]string{"Mildred,Hernandez,mhernandezgithub.io,Female,38.194.51.128"},
 			emptyOption(),
-			&csv.ParseError{2, 2, ErrEmailIsNotValid},
+			&ImportError{Line: 2, Column: 2, Err: ErrEmailIsNotValid},
 			nil,
 		},
 	}
@@ -139,7 +184,7 @@ func TestImport(t *testing.T) {
 	result, err := Import(b, "invalid field")
 
 	// check for correct error handling
-	if err != nil && !strings.Contains(err.Error(), ErrFieldNotExists.Error()) {
+	if !errors.Is(err, ErrFieldNotExists) {
 		t.Errorf("should raise error: %v, but got error %v ", err, ErrFieldNotExists)
 	}
 	// check for empty result
@@ -148,6 +193,276 @@ func TestImport(t *testing.T) {
 	}
 }
 
+// test sorting options
+func TestImportSorting(t *testing.T) {
+	records := "email\n" +
+		"a@b.io\nb@b.io\n" +
+		"a@c.io\n" +
+		"a@a.io\nb@a.io\nc@a.io\n" +
+		"a@d.io\n"
+
+	data := []struct {
+		options []Option
+		result  EmailsByDomainQtyList
+	}{
+		// default sorting by domain name
+		{nil, EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 3, Share: 3.0 / 7}, {Domain: "b.io", EmailsCount: 2, Share: 2.0 / 7}, {Domain: "c.io", EmailsCount: 1, Share: 1.0 / 7}, {Domain: "d.io", EmailsCount: 1, Share: 1.0 / 7}}},
+
+		// descending sorting by domain name
+		{[]Option{SortDescending()},
+			EmailsByDomainQtyList{{Domain: "d.io", EmailsCount: 1, Share: 1.0 / 7}, {Domain: "c.io", EmailsCount: 1, Share: 1.0 / 7}, {Domain: "b.io", EmailsCount: 2, Share: 2.0 / 7}, {Domain: "a.io", EmailsCount: 3, Share: 3.0 / 7}},
+		},
+
+		// ascending sorting by count, equal counts sorted by name
+		{[]Option{SortByCount()},
+			EmailsByDomainQtyList{{Domain: "c.io", EmailsCount: 1, Share: 1.0 / 7}, {Domain: "d.io", EmailsCount: 1, Share: 1.0 / 7}, {Domain: "b.io", EmailsCount: 2, Share: 2.0 / 7}, {Domain: "a.io", EmailsCount: 3, Share: 3.0 / 7}},
+		},
+
+		// descending sorting by count, equal counts still sorted by name
+		{[]Option{SortByCount(), SortDescending()},
+			EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 3, Share: 3.0 / 7}, {Domain: "b.io", EmailsCount: 2, Share: 2.0 / 7}, {Domain: "c.io", EmailsCount: 1, Share: 1.0 / 7}, {Domain: "d.io", EmailsCount: 1, Share: 1.0 / 7}},
+		},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := Import(strings.NewReader(records), "email", d.options...)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+
+		if !reflect.DeepEqual(*result, d.result) {
+			t.Errorf("should result with: %v, but got %v", d.result, *result)
+		}
+	}
+}
+
+// test import statistics
+func TestImportWithStats(t *testing.T) {
+	records := "email\n" +
+		"a@a.io\nb@a.io\n" +
+		"a@b.io\n" +
+		"a@a.io\n" +
+		"invalid\n" +
+		"\n" + // blank lines are ignored by csv reader
+		"a@c.io\n"
+
+	result, err := ImportWithStats(
+		strings.NewReader(records),
+		"email",
+		SkipErrInvalidEmails(),
+		SkipErrDuplicateEmails(),
+	)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+
+	// check counted domains
+	domains := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Share: 0.5}, {Domain: "b.io", EmailsCount: 1, Share: 0.25}, {Domain: "c.io", EmailsCount: 1, Share: 0.25}}
+	if !reflect.DeepEqual(result.Domains, domains) {
+		t.Errorf("should result with: %v, but got %v", domains, result.Domains)
+	}
+
+	// check statistics
+	data := []struct {
+		name     string
+		value    int
+		expected int
+	}{
+		{"RowsRead", result.RowsRead, 6},
+		{"ValidEmails", result.ValidEmails, 4},
+		{"InvalidEmails", result.InvalidEmails, 1},
+		{"DuplicateEmails", result.DuplicateEmails, 1},
+		{"DistinctDomains", result.DistinctDomains, 3},
+	}
+	for _, d := range data {
+		if d.value != d.expected {
+			t.Errorf("%s should be %v, but got %v", d.name, d.expected, d.value)
+		}
+	}
+
+	if result.Elapsed < 0 {
+		t.Errorf("Elapsed should not be negative, but got %v", result.Elapsed)
+	}
+}
+
+// test delimiter option
+func TestImportWithDelimiter(t *testing.T) {
+	expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3}, {Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3}}
+
+	data := []struct {
+		records   string
+		delimiter rune
+	}{
+		{"name\temail\nA\ta@a.io\nB\tb@a.io\nC\tc@b.io\n", '\t'},
+		{"name;email\nA;a@a.io\nB;b@a.io\nC;c@b.io\n", ';'},
+		{"name|email\nA|a@a.io\nB|b@a.io\nC|c@b.io\n", '|'},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := Import(strings.NewReader(d.records), "email", WithDelimiter(d.delimiter))
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("should result with: %v, but got %v", expected, *result)
+		}
+	}
+
+	// test invalid delimiter
+	t.Log("Test invalid delimiter")
+	_, err := Import(strings.NewReader("email\na@a.io\n"), "email", WithDelimiter('\n'))
+	if err == nil {
+		t.Error("should raise error for invalid delimiter")
+	}
+}
+
+// test header-less mode
+func TestImportWithColumnIndex(t *testing.T) {
+	data := []struct {
+		records string
+		index   int
+		err     error
+		result  EmailsByDomainQtyList
+	}{
+		// first record is counted
+		{"A,a@a.io\nB,b@a.io\nC,c@b.io\n", 1, nil, EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3}, {Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3}}},
+
+		// first column
+		{"a@a.io,A\n", 0, nil, EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1, Share: 1}}},
+
+		// header is treated as invalid email
+		{"name,email\nA,a@a.io\n", 1, ErrEmailIsNotValid, nil},
+
+		// column out of range
+		{"A,a@a.io\n", 2, ErrColumnNotExists, nil},
+		{"A,a@a.io\n", -1, ErrColumnNotExists, nil},
+
+		// empty file
+		{"", 0, ErrEmptyFile, nil},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := Import(strings.NewReader(d.records), "", WithColumnIndex(d.index))
+		if d.err != nil {
+			if !errors.Is(err, d.err) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(*result, d.result) {
+			t.Errorf("should result with: %v, but got %v", d.result, *result)
+		}
+	}
+}
+
+// test email and domain normalization
+func TestImportNormalization(t *testing.T) {
+	records := "email\n" +
+		"MHernandez@GitHub.IO\n" +
+		"mhernandez@github.io\n" +
+		"mhernandez@github.io.\n" +
+		"other@GITHUB.io\n"
+
+	data := []struct {
+		option Option
+		err    error
+		result EmailsByDomainQtyList
+	}{
+		// domains are merged, local parts are case-sensitive
+		{SkipErrDuplicateEmails(), nil, EmailsByDomainQtyList{{Domain: "github.io", EmailsCount: 3, Share: 1}}},
+
+		// local parts are case-insensitive
+		{CaseInsensitiveEmails(), ErrEmailDuplicate, nil},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := Import(strings.NewReader(records), "email", d.option)
+		if d.err != nil {
+			if err == nil || !strings.Contains(err.Error(), d.err.Error()) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(*result, d.result) {
+			t.Errorf("should result with: %v, but got %v", d.result, *result)
+		}
+	}
+
+	// test case-insensitive duplicates are skipped
+	t.Log("Test case-insensitive duplicates are skipped")
+	result, err := ImportWithStats(strings.NewReader(records), "email", CaseInsensitiveEmails(), SkipErrDuplicateEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if expected := (EmailsByDomainQtyList{{Domain: "github.io", EmailsCount: 2, Share: 1}}); !reflect.DeepEqual(result.Domains, expected) {
+		t.Errorf("should result with: %v, but got %v", expected, result.Domains)
+	}
+	if result.DuplicateEmails != 2 {
+		t.Errorf("should skip 2 duplicates, but skipped %v", result.DuplicateEmails)
+	}
+}
+
+func TestCountOccurrences(t *testing.T) {
+	records := "email\n" +
+		"a@a.io\n" +
+		"a@a.io\n" +
+		"b@a.io\n" +
+		"invalid\n" +
+		"invalid\n" +
+		"a@b.io\n" +
+		"a@c.io\n" +
+		"a@c.io\n"
+
+	data := []struct {
+		options []Option
+		result  EmailsByDomainQtyList
+	}{
+		// duplicates are counted as occurrences, invalid emails are not
+		{nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Occurrences: 3, Share: 0.5},
+			{Domain: "b.io", EmailsCount: 1, Occurrences: 1, Share: 0.25},
+			{Domain: "c.io", EmailsCount: 1, Occurrences: 2, Share: 0.25},
+		}},
+
+		// occurrences of collapsed domains are summed
+		{[]Option{TopN(1, true)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Occurrences: 3, Share: 0.5},
+			{Domain: OtherDomain, EmailsCount: 2, Occurrences: 3, Share: 0.5},
+		}},
+	}
+
+	t.Log("Should count unique emails and occurrences by domain")
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		options := append([]Option{CountOccurrences(), SkipErrDuplicateEmails(), SkipErrInvalidEmails()}, d.options...)
+		result, err := Import(strings.NewReader(records), "email", options...)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		if !reflect.DeepEqual(*result, d.result) {
+			t.Errorf("should result with: %v, but got %v", d.result, *result)
+		}
+	}
+}
+
 // test with files
 func TestImportFromFile(t *testing.T) {
 	// test with existing file
@@ -162,6 +477,20 @@ func TestImportFromFile(t *testing.T) {
 		t.Errorf("should pass the test")
 	}
 
+	// test statistics with existing file
+	t.Log("Test statistics with existing file")
+	result, err := ImportFromFileWithStats(
+		"./customers.csv",
+		"email",
+		SkipErrInvalidEmails(),
+		SkipErrDuplicateEmails(),
+	)
+	if err != nil {
+		t.Errorf("should pass the test")
+	} else if result.RowsRead != result.ValidEmails+result.InvalidEmails+result.DuplicateEmails {
+		t.Errorf("all read rows should be accounted for: %+v", result)
+	}
+
 	// test with non existing file
 	t.Log("Test non existing file")
 	_, err = ImportFromFile(
@@ -174,4 +503,75 @@ func TestImportFromFile(t *testing.T) {
 	if !strings.Contains(err.Error(), "no such file or directory") {
 		t.Errorf("should raise the error")
 	}
+
+	// test with standard input
+	t.Log("Test standard input")
+	stdin, err := os.Open("./customers.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	defer func(stdin *os.File) { os.Stdin = stdin }(os.Stdin)
+	os.Stdin = stdin
+
+	fromStdin, err := ImportFromFileWithStats("-", "email", SkipErrInvalidEmails(), SkipErrDuplicateEmails())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromStdin.Domains, result.Domains) {
+		t.Errorf("should return %v, but got %v", result.Domains, fromStdin.Domains)
+	}
+}
+
+func TestWithEmailFields(t *testing.T) {
+	records := "name,email,work_email,secondary_email\n" +
+		"A,a@a.io,a@b.io,\n" +
+		"B,,b@b.io,b@c.io\n" +
+		"C,c@a.io,C@A.IO,c@a.io\n" +
+		"D,,,\n" +
+		"E,e@a.io,invalid,a@b.io\n"
+
+	data := []struct {
+		name    string
+		fields  []string
+		options []Option
+		result  EmailsByDomainQtyList
+		errors  int
+		err     error
+	}{
+		{"all fields", []string{"email", "work_email", "secondary_email"}, []Option{CollectErrors()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 4, Share: 4.0 / 7},
+			{Domain: "b.io", EmailsCount: 2, Share: 2.0 / 7},
+			{Domain: "c.io", EmailsCount: 1, Share: 1.0 / 7},
+		}, 3, nil},
+		{"one field", []string{"secondary_email"}, []Option{SkipErrInvalidEmails()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.2},
+			{Domain: "c.io", EmailsCount: 1, Share: 0.2},
+		}, 0, nil},
+		{"missing field", []string{"phone"}, nil, nil, 0, ErrFieldNotExists},
+	}
+
+	t.Log("Should count emails of all email fields")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		options := append([]Option{WithEmailFields(d.fields...), SortByCount(), SortDescending()}, d.options...)
+		result, err := ImportWithStats(strings.NewReader(records), "email", options...)
+		if d.err != nil {
+			if err == nil || !strings.Contains(err.Error(), d.err.Error()) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if result == nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Domains, d.result) {
+			t.Errorf("should return %v, but got %v", d.result, result.Domains)
+		}
+		if len(result.Errors) != d.errors {
+			t.Errorf("should collect %v errors, but got %v", d.errors, result.Errors)
+		}
+	}
 }
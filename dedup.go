@@ -0,0 +1,101 @@
+package customerimporter
+
+import "math"
+
+// DedupStore remembers counted emails to catch duplicates
+type DedupStore interface {
+	// Add adds email to the store and reports whether it was added before
+	Add(email string) (bool, error)
+}
+
+// MemoryDedupStore is an exact in-memory set of emails, it's used by default
+type MemoryDedupStore map[string]struct{}
+
+// NewMemoryDedupStore returns empty in-memory store
+func NewMemoryDedupStore() MemoryDedupStore { return make(MemoryDedupStore) }
+
+func (s MemoryDedupStore) Add(email string) (bool, error) {
+	if _, ok := s[email]; ok {
+		return true, nil
+	}
+	s[email] = struct{}{}
+	return false, nil
+}
+
+// Remember counted emails in the store instead of the in-memory set, e.g. in
+// FileDedupStore when the emails don't fit in memory. The store may be shared
+// by several imports to catch duplicates across them.
+func WithDedupStore(store DedupStore) Option {
+	return func(f *CustomerImporter) { f.countedEmails = store }
+}
+
+// Detect duplicate emails with a Bloom filter sized for expectedItems emails
+// instead of keeping all emails in memory. Memory usage is constant, but with
+// falsePositiveRate probability a new email is taken for a duplicate, so the
+// counts may be slightly lower. The rate is effective as long as amount of
+// emails doesn't exceed expectedItems.
+func WithBloomDedup(expectedItems uint, falsePositiveRate float64) Option {
+	return func(f *CustomerImporter) { f.countedEmails = newBloomFilter(expectedItems, falsePositiveRate) }
+}
+
+// bloomFilter is an approximate set of emails
+type bloomFilter struct {
+	bits   []uint64 // bit array
+	size   uint64   // amount of bits
+	hashes uint64   // amount of hash functions
+}
+
+// returns bloom filter with optimal size and amount of hash functions for n
+// items and false positive rate p
+func newBloomFilter(n uint, p float64) *bloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+
+	// m = -n*ln(p)/ln(2)^2, k = m/n*ln(2)
+	size := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	size = (size + 63) / 64 * 64
+	hashes := uint64(math.Max(1, math.Round(float64(size)/float64(n)*math.Ln2)))
+
+	return &bloomFilter{bits: make([]uint64, size/64), size: size, hashes: hashes}
+}
+
+func (f *bloomFilter) Add(email string) (bool, error) {
+	// derive hash functions from two hashes (Kirsch-Mitzenmacher)
+	h1 := fnv1a(email)
+	h2 := mix64(h1) | 1
+
+	present := true
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % f.size
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if f.bits[word]&mask == 0 {
+			present = false
+			f.bits[word] |= mask
+		}
+	}
+	return present, nil
+}
+
+// returns 64-bit FNV-1a hash of s without allocations
+func fnv1a(s string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+// returns splitmix64 finalizer of h, used as the second independent hash
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
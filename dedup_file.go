@@ -0,0 +1,212 @@
+package customerimporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// layout of the file dedup store: header with the magic string followed by
+// the open addressing hash table of email digests, zero slot is empty
+const (
+	fileDedupMagic        = "CIDEDUP1"
+	fileDedupHeaderSize   = 16
+	fileDedupSlotSize     = 16
+	fileDedupInitialSlots = 1 << 16
+	fileDedupBlockSlots   = 256 // amount of slots read at once
+)
+
+// ErrInvalidDedupFile is raised when the file is not a dedup store
+var ErrInvalidDedupFile = errors.New("File is not a dedup store")
+
+// FileDedupStore keeps digests of counted emails in an on-disk hash table, so
+// memory usage doesn't depend on the amount of emails. Emails are compared by
+// 128 bits of their SHA-256 digest. The store is not safe for concurrent use.
+type FileDedupStore struct {
+	path  string   // path of the table file
+	file  *os.File // table file
+	slots uint64   // capacity of the table, power of two
+	count uint64   // amount of stored digests
+	block []byte   // buffer of the read slots
+}
+
+// OpenFileDedupStore opens the store at path, the file is created if it doesn't
+// exist, so digests added by previous imports are kept
+func OpenFileDedupStore(path string) (*FileDedupStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileDedupStore{path: path, file: file, block: make([]byte, fileDedupBlockSlots*fileDedupSlotSize)}
+	if err := s.init(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// initializes new table or counts digests of the existing one
+func (s *FileDedupStore) init() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// new table
+	if info.Size() == 0 {
+		s.slots = fileDedupInitialSlots
+		return initDedupFile(s.file, s.slots)
+	}
+
+	// existing table
+	header := make([]byte, fileDedupHeaderSize)
+	if _, err := s.file.ReadAt(header, 0); err != nil {
+		return ErrInvalidDedupFile
+	}
+	s.slots = uint64(info.Size()-fileDedupHeaderSize) / fileDedupSlotSize
+	if !bytes.HasPrefix(header, []byte(fileDedupMagic)) || s.slots == 0 || s.slots&(s.slots-1) != 0 {
+		return ErrInvalidDedupFile
+	}
+	return s.scan(s.file, s.slots, func([]byte) error {
+		s.count++
+		return nil
+	})
+}
+
+// Add adds digest of the email to the store and reports whether it was added
+// before, the table is grown when it's half full
+func (s *FileDedupStore) Add(email string) (bool, error) {
+	digest := sha256.Sum256([]byte(email))
+	slot := digest[:fileDedupSlotSize]
+	if isZero(slot) {
+		slot[0] = 1
+	}
+
+	found, err := s.insert(s.file, s.slots, slot)
+	if err != nil || found {
+		return found, err
+	}
+
+	s.count++
+	if s.count*2 > s.slots {
+		return false, s.grow()
+	}
+	return false, nil
+}
+
+// Close closes the table file, it's kept on disk
+func (s *FileDedupStore) Close() error { return s.file.Close() }
+
+// inserts digest to the table of the file, reports whether it's found instead
+func (s *FileDedupStore) insert(file *os.File, slots uint64, digest []byte) (bool, error) {
+	index := binary.LittleEndian.Uint64(digest) & (slots - 1)
+	for {
+		// read block of slots starting at index
+		n := min(fileDedupBlockSlots, slots-index)
+		block := s.block[:n*fileDedupSlotSize]
+		if _, err := file.ReadAt(block, slotOffset(index)); err != nil {
+			return false, err
+		}
+
+		// linear probing
+		for i := uint64(0); i < n; i++ {
+			slot := block[i*fileDedupSlotSize : (i+1)*fileDedupSlotSize]
+			if bytes.Equal(slot, digest) {
+				return true, nil
+			}
+			if isZero(slot) {
+				_, err := file.WriteAt(digest, slotOffset(index+i))
+				return false, err
+			}
+		}
+		index = (index + n) & (slots - 1)
+	}
+}
+
+// doubles capacity of the table by rehashing digests to a new file
+func (s *FileDedupStore) grow() error {
+	tmpPath := s.path + ".grow"
+	file, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	slots := s.slots * 2
+	if err := initDedupFile(file, slots); err != nil {
+		file.Close()
+		return err
+	}
+
+	// digests are copied as the block buffer is reused by insert
+	err = s.scan(s.file, s.slots, func(slot []byte) error {
+		_, err := s.insert(file, slots, append([]byte(nil), slot...))
+		return err
+	})
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	// replace the table file
+	if err := s.file.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.slots = file, slots
+
+	return nil
+}
+
+// calls fn for every non-empty slot of the table
+func (s *FileDedupStore) scan(file *os.File, slots uint64, fn func(slot []byte) error) error {
+	block := make([]byte, fileDedupBlockSlots*fileDedupSlotSize)
+	for index := uint64(0); index < slots; index += fileDedupBlockSlots {
+		n := min(fileDedupBlockSlots, slots-index)
+		if _, err := file.ReadAt(block[:n*fileDedupSlotSize], slotOffset(index)); err != nil && err != io.EOF {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			slot := block[i*fileDedupSlotSize : (i+1)*fileDedupSlotSize]
+			if isZero(slot) {
+				continue
+			}
+			if err := fn(slot); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writes header and allocates empty table in the file
+func initDedupFile(file *os.File, slots uint64) error {
+	header := make([]byte, fileDedupHeaderSize)
+	copy(header, fileDedupMagic)
+	if _, err := file.WriteAt(header, 0); err != nil {
+		return err
+	}
+	return file.Truncate(slotOffset(slots))
+}
+
+// returns offset of the slot in the file
+func slotOffset(index uint64) int64 {
+	return fileDedupHeaderSize + int64(index)*fileDedupSlotSize
+}
+
+// reports whether all bytes are zero
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFileDedupStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup")
+	store, err := OpenFileDedupStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// add enough emails to grow the table
+	const n = fileDedupInitialSlots
+	for i := 0; i < n; i++ {
+		found, err := store.Add(fmt.Sprintf("user%d@example.com", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found {
+			t.Fatalf("email %v should not be found before adding", i)
+		}
+	}
+	if store.slots <= fileDedupInitialSlots {
+		t.Errorf("table should grow, but has %v slots", store.slots)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// digests are kept after reopening
+	store, err = OpenFileDedupStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if store.count != n {
+		t.Errorf("should count %v digests, but got %v", n, store.count)
+	}
+	for i := 0; i < n; i += 97 {
+		found, err := store.Add(fmt.Sprintf("user%d@example.com", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found {
+			t.Fatalf("email %v should be found", i)
+		}
+	}
+	if found, _ := store.Add("new@example.com"); found {
+		t.Error("new email should not be found")
+	}
+
+	// invalid file
+	invalid := filepath.Join(t.TempDir(), "invalid")
+	if err := os.WriteFile(invalid, []byte("email\na@a.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileDedupStore(invalid); !errors.Is(err, ErrInvalidDedupFile) {
+		t.Errorf("should raise error: %v, but got error %v", ErrInvalidDedupFile, err)
+	}
+}
+
+func TestWithDedupStore(t *testing.T) {
+	records := generateRecords(2000)
+	options := []Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails()}
+
+	expected, err := ImportWithStats(strings.NewReader(records), "email", options...)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+
+	stores := []struct {
+		name  string
+		store func() DedupStore
+	}{
+		{"memory", func() DedupStore { return NewMemoryDedupStore() }},
+		{"file", func() DedupStore {
+			store, err := OpenFileDedupStore(filepath.Join(t.TempDir(), "dedup"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { store.Close() })
+			return store
+		}},
+	}
+
+	for _, s := range stores {
+		t.Logf("Case: %v", s.name)
+		store := s.store()
+
+		result, err := ImportWithStats(strings.NewReader(records), "email", append(options, WithDedupStore(store))...)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		result.Elapsed = expected.Elapsed
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("should result with: %+v, but got %+v", expected, result)
+		}
+
+		// shared store catches duplicates across imports
+		_, err = Import(strings.NewReader("email\nuser1@domain1.io\n"), "email", WithDedupStore(store))
+		if err == nil || !strings.Contains(err.Error(), ErrEmailDuplicate.Error()) {
+			t.Errorf("should raise error: %v, but got error %v", ErrEmailDuplicate, err)
+		}
+	}
+
+	// failure of the store aborts import even if duplicates are skipped
+	t.Log("Test failure of the store aborts import")
+	store, err := OpenFileDedupStore(filepath.Join(t.TempDir(), "dedup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	_, err = Import(strings.NewReader(records), "email", append(options, WithDedupStore(store))...)
+	if !errors.Is(err, os.ErrClosed) {
+		t.Errorf("should raise error: %v, but got error %v", os.ErrClosed, err)
+	}
+}
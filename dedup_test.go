@@ -0,0 +1,70 @@
+package customerimporter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithBloomDedup(t *testing.T) {
+	records := generateRecords(5000)
+	options := []Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails()}
+
+	expected, err := ImportWithStats(strings.NewReader(records), "email", options...)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+
+	// with a low false positive rate the result is exact for small input
+	result, err := ImportWithStats(strings.NewReader(records), "email", append(options, WithBloomDedup(5000, 1e-9))...)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	result.Elapsed = expected.Elapsed
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("should result with: %+v, but got %+v", expected, result)
+	}
+
+	// duplicates are still reported as errors
+	_, err = Import(strings.NewReader("email\na@a.io\na@a.io\n"), "email", WithBloomDedup(10, 0.01))
+	if err == nil || !strings.Contains(err.Error(), ErrEmailDuplicate.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailDuplicate, err)
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	const n = 10000
+	const rate = 0.01
+	f := newBloomFilter(n, rate)
+
+	// new items may only be taken for present with false positive rate
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		if present, _ := f.Add(fmt.Sprintf("user%d@example.com", i)); present {
+			falsePositives++
+		}
+	}
+	if fillRate := float64(falsePositives) / n; fillRate > rate {
+		t.Errorf("false positive rate while filling should be below 0.01, but got %v", fillRate)
+	}
+
+	// added items are always found
+	for i := 0; i < n; i++ {
+		if present, _ := f.Add(fmt.Sprintf("user%d@example.com", i)); !present {
+			t.Fatalf("item %v should be present", i)
+		}
+	}
+
+	// false positive rate is close to the configured one, only a few new
+	// items are added, so the filter is not overfilled
+	falsePositives = 0
+	for i := 0; i < n/10; i++ {
+		if present, _ := f.Add(fmt.Sprintf("other%d@example.com", i)); present {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / (n / 10); rate > 0.02 {
+		t.Errorf("false positive rate should be about 0.01, but got %v", rate)
+	}
+}
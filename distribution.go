@@ -0,0 +1,75 @@
+package customerimporter
+
+import "slices"
+
+// Distribution describes how emails are distributed among the domains
+type Distribution struct {
+	Buckets []DistributionBucket `json:"buckets"` // domains by their emails count: 1, 2-10, 11-100 and so on
+	Mean    float64              `json:"mean"`    // mean emails per domain
+	Median  float64              `json:"median"`  // median emails per domain
+
+	// concentration of emails in few domains, e.g. lists of business customers
+	// are spread among many company domains, lists of consumers concentrate in
+	// a few free mail providers
+	TopShare float64 `json:"top10_share"` // fraction of emails of the 10 domains with the most emails
+	HHI      float64 `json:"hhi"`         // Herfindahl-Hirschman index, sum of squared shares of the domains from near 0 to 1 for a single domain
+}
+
+// amount of domains of TopShare of Distribution
+const distributionTop = 10
+
+// DistributionBucket counts domains with emails count in the range
+type DistributionBucket struct {
+	Min     int `json:"min"`     // least emails count of the range
+	Max     int `json:"max"`     // greatest emails count of the range
+	Domains int `json:"domains"` // amount of domains in the range
+	Emails  int `json:"emails"`  // amount of emails of the domains
+}
+
+// Compute distribution of emails among the domains, e.g. how many domains
+// have a single email, and its concentration metrics, returned in
+// Distribution of ImportResult. Domains are bucketed by powers of ten: 1,
+// 2-10, 11-100 and so on up to the largest count. All domains are included,
+// also the ones left out by TopN and MinCount.
+func ComputeDistribution() Option { return func(f *CustomerImporter) { f.distribution = true } }
+
+// returns distribution of emails among the domains of the result
+func distribution(result EmailsByDomainQtyList) *Distribution {
+	counts := make([]int, len(result))
+	total, squares := 0, 0.0
+	for i, e := range result {
+		counts[i] = e.EmailsCount
+		total += e.EmailsCount
+		squares += float64(e.EmailsCount) * float64(e.EmailsCount)
+	}
+	slices.Sort(counts)
+
+	top := 0
+	for _, count := range counts[max(0, len(counts)-distributionTop):] {
+		top += count
+	}
+
+	d := &Distribution{
+		Mean:     float64(total) / float64(len(counts)),
+		TopShare: float64(top) / float64(total),
+		HHI:      squares / (float64(total) * float64(total)),
+	}
+	if n := len(counts); n%2 == 1 {
+		d.Median = float64(counts[n/2])
+	} else {
+		d.Median = float64(counts[n/2-1]+counts[n/2]) / 2
+	}
+
+	// counts are sorted, so buckets are filled in order
+	bucket := DistributionBucket{Min: 1, Max: 1}
+	for _, count := range counts {
+		for count > bucket.Max {
+			d.Buckets = append(d.Buckets, bucket)
+			bucket = DistributionBucket{Min: bucket.Max + 1, Max: bucket.Max * 10}
+		}
+		bucket.Domains++
+		bucket.Emails += count
+	}
+	d.Buckets = append(d.Buckets, bucket)
+	return d
+}
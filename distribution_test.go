@@ -0,0 +1,103 @@
+package customerimporter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComputeDistribution(t *testing.T) {
+	// emails counts of the domains
+	counts := func(counts ...int) string {
+		var b strings.Builder
+		b.WriteString("email\n")
+		for domain, count := range counts {
+			for i := 0; i < count; i++ {
+				fmt.Fprintf(&b, "%d@%d.io\n", i, domain)
+			}
+		}
+		return b.String()
+	}
+
+	data := []struct {
+		name     string
+		records  string
+		options  []Option
+		expected *Distribution
+	}{
+		{"single domain", counts(1), nil, &Distribution{
+			Buckets:  []DistributionBucket{{Min: 1, Max: 1, Domains: 1, Emails: 1}},
+			Mean:     1,
+			Median:   1,
+			TopShare: 1,
+			HHI:      1,
+		}},
+		{"buckets", counts(1, 1, 2, 10, 11, 150), nil, &Distribution{
+			Buckets: []DistributionBucket{
+				{Min: 1, Max: 1, Domains: 2, Emails: 2},
+				{Min: 2, Max: 10, Domains: 2, Emails: 12},
+				{Min: 11, Max: 100, Domains: 1, Emails: 11},
+				{Min: 101, Max: 1000, Domains: 1, Emails: 150},
+			},
+			Mean:     175.0 / 6,
+			Median:   6,
+			TopShare: 1,
+			HHI:      22727.0 / 30625,
+		}},
+		{"empty buckets kept", counts(1, 120, 3), nil, &Distribution{
+			Buckets: []DistributionBucket{
+				{Min: 1, Max: 1, Domains: 1, Emails: 1},
+				{Min: 2, Max: 10, Domains: 1, Emails: 3},
+				{Min: 11, Max: 100},
+				{Min: 101, Max: 1000, Domains: 1, Emails: 120},
+			},
+			Mean:     124.0 / 3,
+			Median:   3,
+			TopShare: 1,
+			HHI:      14410.0 / 15376,
+		}},
+		{"top domains share", counts(1, 1, 1, 1, 1, 9, 1, 1, 1, 1, 1, 1), nil, &Distribution{
+			Buckets: []DistributionBucket{
+				{Min: 1, Max: 1, Domains: 11, Emails: 11},
+				{Min: 2, Max: 10, Domains: 1, Emails: 9},
+			},
+			Mean:     20.0 / 12,
+			Median:   1,
+			TopShare: 0.9,
+			HHI:      92.0 / 400,
+		}},
+		{"all domains with top domains", counts(1, 2, 3), []Option{TopN(1, false), MinCount(3, false)}, &Distribution{
+			Buckets: []DistributionBucket{
+				{Min: 1, Max: 1, Domains: 1, Emails: 1},
+				{Min: 2, Max: 10, Domains: 2, Emails: 5},
+			},
+			Mean:     2,
+			Median:   2,
+			TopShare: 1,
+			HHI:      14.0 / 36,
+		}},
+	}
+
+	t.Log("Should compute distribution and concentration of emails among domains")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := ImportWithStats(strings.NewReader(d.records), "email", append(d.options, ComputeDistribution())...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Distribution, d.expected) {
+			t.Errorf("should return %+v, but got %+v", d.expected, result.Distribution)
+		}
+	}
+
+	t.Log("Should not compute distribution without the option")
+	result, err := ImportWithStats(strings.NewReader(counts(1, 2)), "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Distribution != nil {
+		t.Errorf("should not return distribution, but got %+v", result.Distribution)
+	}
+}
@@ -0,0 +1,137 @@
+package customerimporter
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownFormat is raised when there is no encoder of the format
+var ErrUnknownFormat = errors.New("Unknown output format")
+
+// Encoder writes the result in a format, e.g. YAMLEncoder
+type Encoder interface {
+	Encode(w io.Writer, result EmailsByDomainQtyList) error
+}
+
+// NewEncoder returns encoder of the format: csv, json, table, markdown, yaml
+// or xml. Percentages are included and JSON and XML are indented.
+func NewEncoder(format string) (Encoder, error) {
+	switch format {
+	case "csv":
+		return CSVEncoder{WithShare: true}, nil
+	case "json":
+		return JSONEncoder{Pretty: true}, nil
+	case "table":
+		return TableEncoder{WithShare: true}, nil
+	case "markdown":
+		return MarkdownEncoder{WithShare: true}, nil
+	case "yaml":
+		return YAMLEncoder{}, nil
+	case "xml":
+		return XMLEncoder{Indent: true}, nil
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnknownFormat, format)
+}
+
+// CSVEncoder writes the result by WriteCSV
+type CSVEncoder struct {
+	WithShare bool // write percentage column
+}
+
+func (e CSVEncoder) Encode(w io.Writer, result EmailsByDomainQtyList) error {
+	return result.WriteCSV(w, e.WithShare)
+}
+
+// JSONEncoder writes the result by WriteJSON
+type JSONEncoder struct {
+	Pretty bool // indent JSON
+}
+
+func (e JSONEncoder) Encode(w io.Writer, result EmailsByDomainQtyList) error {
+	return result.WriteJSON(w, e.Pretty)
+}
+
+// TableEncoder writes the result by WriteTable
+type TableEncoder struct {
+	WithShare bool // write percentage column
+}
+
+func (e TableEncoder) Encode(w io.Writer, result EmailsByDomainQtyList) error {
+	return result.WriteTable(w, e.WithShare)
+}
+
+// MarkdownEncoder writes the result by WriteMarkdown
+type MarkdownEncoder struct {
+	WithShare bool // write percentage column
+}
+
+func (e MarkdownEncoder) Encode(w io.Writer, result EmailsByDomainQtyList) error {
+	return result.WriteMarkdown(w, e.WithShare)
+}
+
+// YAMLEncoder writes the result as YAML document with domains and total keys
+// like the JSON written by WriteJSON
+type YAMLEncoder struct{}
+
+func (e YAMLEncoder) Encode(w io.Writer, result EmailsByDomainQtyList) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(struct {
+		Domains EmailsByDomainQtyList `yaml:"domains"`
+		Total   int                   `yaml:"total"`
+	}{result.nonNil(), result.total()})
+	if closeErr := encoder.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// XMLEncoder writes the result as <result total="N"> element containing
+// <domain name="" count="" share=""/> element of every domain
+type XMLEncoder struct {
+	Indent bool // indent elements
+}
+
+// xmlResult is XML representation of the result
+type xmlResult struct {
+	XMLName xml.Name    `xml:"result"`
+	Total   int         `xml:"total,attr"`
+	Domains []xmlDomain `xml:"domain"`
+}
+
+// xmlDomain is XML representation of EmailsByDomainQty
+type xmlDomain struct {
+	Name         string         `xml:"name,attr"`
+	Count        int            `xml:"count,attr"`
+	Occurrences  int            `xml:"occurrences,attr,omitempty"`
+	RoleAccounts int            `xml:"role_accounts,attr,omitempty"`
+	RoleShare    float64        `xml:"role_share,attr,omitempty"`
+	Share        float64        `xml:"share,attr"`
+	MX           MXStatus       `xml:"mx,attr,omitempty"`
+	Category     DomainCategory `xml:"category,attr,omitempty"`
+	Provider     MailProvider   `xml:"provider,attr,omitempty"`
+}
+
+func (e XMLEncoder) Encode(w io.Writer, result EmailsByDomainQtyList) error {
+	v := xmlResult{Total: result.total()}
+	for _, d := range result {
+		v.Domains = append(v.Domains, xmlDomain{Name: d.Domain, Count: d.EmailsCount, Occurrences: d.Occurrences, RoleAccounts: d.RoleAccounts, RoleShare: d.RoleShare, Share: d.Share, MX: d.MX, Category: d.Category, Provider: d.Provider})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	if e.Indent {
+		encoder.Indent("", "  ")
+	}
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,72 @@
+package customerimporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncoders(t *testing.T) {
+	result := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 3, Share: 0.75, MX: MXFound},
+		{Domain: "b&c.io", EmailsCount: 1, Share: 0.25},
+	}
+
+	data := []struct {
+		format   string
+		expected string
+	}{
+		{"csv", "domain,count,percentage\na.io,3,75.00\nb&c.io,1,25.00\n"},
+		{"markdown", "| Domain | Count | Percentage |\n| --- | ---: | ---: |\n| a.io | 3 | 75.00% |\n| b&c.io | 1 | 25.00% |\n"},
+		{"yaml", "" +
+			"domains:\n" +
+			"  - domain: a.io\n" +
+			"    count: 3\n" +
+			"    share: 0.75\n" +
+			"    mx: found\n" +
+			"  - domain: b&c.io\n" +
+			"    count: 1\n" +
+			"    share: 0.25\n" +
+			"total: 4\n"},
+		{"xml", "" +
+			"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+			"<result total=\"4\">\n" +
+			"  <domain name=\"a.io\" count=\"3\" share=\"0.75\" mx=\"found\"></domain>\n" +
+			"  <domain name=\"b&amp;c.io\" count=\"1\" share=\"0.25\"></domain>\n" +
+			"</result>\n"},
+	}
+
+	t.Log("Should encode result in the format")
+	for _, d := range data {
+		t.Logf("Case: %v", d.format)
+
+		encoder, err := NewEncoder(d.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := encoder.Encode(&buf, result); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != d.expected {
+			t.Errorf("should write %q, but got %q", d.expected, buf.String())
+		}
+	}
+
+	t.Log("Should encode empty result")
+	for _, format := range []string{"csv", "json", "table", "markdown", "yaml", "xml"} {
+		encoder, err := NewEncoder(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := encoder.Encode(&bytes.Buffer{}, nil); err != nil {
+			t.Errorf("should encode empty result as %v, but got error %v", format, err)
+		}
+	}
+
+	t.Log("Should raise error for unknown format")
+	if _, err := NewEncoder("toml"); err == nil || !strings.Contains(err.Error(), ErrUnknownFormat.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrUnknownFormat, err)
+	}
+}
@@ -0,0 +1,43 @@
+package customerimporter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// ErrUnknownEncoding is raised when the encoding set by WithEncoding is unknown
+var ErrUnknownEncoding = errors.New("Unknown encoding")
+
+// byte order mark of UTF-8 written by Excel
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Decode input from the encoding to UTF-8, e.g. "windows-1252", "iso-8859-2"
+// or "utf-16le". Names are looked up by the WHATWG Encoding Standard. Byte
+// order mark is always removed.
+func WithEncoding(name string) Option {
+	return func(f *CustomerImporter) { f.encoding = name }
+}
+
+// returns reader decoding r to UTF-8 without byte order mark
+func (c *CustomerImporter) decode(r io.Reader) (*bufio.Reader, error) {
+	if c.encoding != "" {
+		enc, err := htmlindex.Get(c.encoding)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s", ErrUnknownEncoding, c.encoding)
+		}
+		r = transform.NewReader(r, enc.NewDecoder())
+	}
+
+	// skip byte order mark
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(len(utf8BOM)); bytes.Equal(bom, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	return br, nil
+}
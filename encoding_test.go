@@ -0,0 +1,54 @@
+package customerimporter
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithEncoding(t *testing.T) {
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+		{Domain: "bücher.de", EmailsCount: 1, Share: 0.5},
+	}
+
+	// UTF-16LE with byte order mark
+	utf16 := []byte{0xFF, 0xFE}
+	for _, r := range "name,email\nA,a@a.io\nB,b@bücher.de\n" {
+		utf16 = append(utf16, byte(r), byte(r>>8))
+	}
+
+	data := []struct {
+		name     string
+		input    []byte
+		options  []Option
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{"UTF-8 with BOM", []byte("\xef\xbb\xbfemail,name\na@a.io,A\nb@bücher.de,B\n"), nil, expected, nil},
+		{"windows-1252", []byte("email,name\na@a.io,A\nb@b\xfccher.de,B\n"), []Option{WithEncoding("windows-1252")}, expected, nil},
+		{"latin1 alias", []byte("email,name\na@a.io,A\nb@b\xfccher.de,B\n"), []Option{WithEncoding("latin1")}, expected, nil},
+		{"utf-16le", utf16, []Option{WithEncoding("utf-16le")}, expected, nil},
+		{"unknown", []byte("email\na@a.io\n"), []Option{WithEncoding("klingon")}, nil, ErrUnknownEncoding},
+	}
+
+	t.Log("Should decode input to UTF-8")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := Import(bytes.NewReader(d.input), "email", d.options...)
+		if d.err != nil {
+			if err == nil || !strings.Contains(err.Error(), d.err.Error()) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, d.expected) {
+			t.Errorf("should return %v, but got %v", d.expected, *result)
+		}
+	}
+}
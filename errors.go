@@ -0,0 +1,260 @@
+package customerimporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// ErrTooManyErrors is raised when amount of skipped records exceeds the limit
+var ErrTooManyErrors = errors.New("Too many invalid records")
+
+// Skip up to n records with invalid or already counted emails, the import is
+// aborted with ErrTooManyErrors when more records are skipped.
+func WithMaxErrors(n int) Option {
+	return func(f *CustomerImporter) {
+		f.limitErrors = true
+		f.maxErrors = n
+	}
+}
+
+// Skip records with invalid or already counted emails, the import is aborted
+// with ErrTooManyErrors if more than pct percent of records are skipped. The
+// rate is checked when all records are read.
+func WithMaxErrorRate(pct float64) Option {
+	return func(f *CustomerImporter) { f.maxErrorRate = pct }
+}
+
+// Don't raise error if email is invalid or already counted, skip the record
+// and collect its error instead. The result is returned together with
+// RowErrors listing all skipped records.
+func CollectErrors() Option { return func(f *CustomerImporter) { f.collectErrors = true } }
+
+// Decision tells the importer how to handle the record with invalid or
+// already counted email
+type Decision int
+
+const (
+	DecisionDefault Decision = iota // decided by the options, e.g. SkipErrInvalidEmails
+	DecisionSkip                    // skip the record
+	DecisionAbort                   // abort the import with the error
+)
+
+// ErrorHandler decides handling of the record read on the line with invalid
+// or already counted email
+type ErrorHandler func(line int, record []string, err error) Decision
+
+// Call fn for every record with invalid or already counted email to decide
+// whether the record is skipped or the import is aborted. Skipped records are
+// counted, collected and rejected the same way as records skipped by the
+// options, which decide the records fn returns DecisionDefault for.
+func OnError(fn ErrorHandler) Option {
+	return func(f *CustomerImporter) { f.onError = fn }
+}
+
+// Return counts of the records read before an error aborting the import
+// together with the error instead of nil result, e.g. to explore a large
+// export with a broken record near its end. The result is marked as partial,
+// it's not returned if no emails were counted before the error.
+func AllowPartialResult() Option { return func(f *CustomerImporter) { f.allowPartialResult = true } }
+
+// returns result of the records counted before err aborted the import if
+// partial results are allowed, err is returned together with it
+func (c *CustomerImporter) partialResult(err error) (*ImportResult, error) {
+	if !c.allowPartialResult {
+		return nil, err
+	}
+	result, resultErr := c.getResult()
+	if resultErr != nil {
+		return nil, err
+	}
+	result.Partial = true
+	return result, err
+}
+
+// Keep values of records out of errors and logs, so they can be surfaced
+// without leaking personal data. Collected errors contain only the line,
+// column and reason, errors of the header don't contain its fields.
+func RedactErrors() Option { return func(f *CustomerImporter) { f.redactErrors = true } }
+
+// returns err with the detail appended, err itself if errors are redacted
+func (c *CustomerImporter) detailedError(err error, detail string) error {
+	if c.redactErrors {
+		return err
+	}
+	return fmt.Errorf("%w%s", err, detail)
+}
+
+// returns value of the skipped record kept in its error, empty if errors are
+// redacted
+func (c *CustomerImporter) errorValue(r parsedRecord) string {
+	if c.redactErrors {
+		return ""
+	}
+	return c.display(r.value)
+}
+
+// RowError describes a skipped record
+type RowError struct {
+	File   string // name of the file, set by ImportFromFiles
+	Line   int    // line of the record
+	Column int    // column of the email
+	Value  string // offending value, empty if RedactErrors is used
+	Err    error  // reason, e.g. ErrEmailIsNotValid
+}
+
+func (e *RowError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s: record on line %d, column %d: %v", e.File, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("record on line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error { return e.Err }
+
+// ImportError describes the error which aborted the import, e.g. an invalid
+// email or a missing header field. The reason is kept in Err, so it can be
+// checked by errors.Is.
+type ImportError struct {
+	Line   int    // line of the record
+	Column int    // column of the value, -1 if the error isn't related to a column
+	Field  string // header field of the column
+	Value  string // offending value, empty if RedactErrors is used
+	Err    error  // reason, e.g. ErrEmailIsNotValid
+}
+
+func (e *ImportError) Error() string {
+	if e.Column < 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("record on line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ImportError) Unwrap() error { return e.Err }
+
+// returns ImportError of the record r
+func (c *CustomerImporter) recordError(r parsedRecord, err error) error {
+	e := &ImportError{Line: r.line, Column: r.column, Value: c.errorValue(r), Err: err}
+	if r.column < len(c.header) {
+		e.Field = c.header[r.column]
+	}
+	return e
+}
+
+// rowErrorJSON is JSON representation of RowError
+type rowErrorJSON struct {
+	File   string    `json:"file,omitempty"`
+	Line   int       `json:"line"`
+	Column int       `json:"column"`
+	Value  string    `json:"value"`
+	Err    string    `json:"error"`
+	Code   ErrorCode `json:"code,omitempty"`
+}
+
+// MarshalJSON encodes the error with the reason as a string
+func (e *RowError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rowErrorJSON{File: e.File, Line: e.Line, Column: e.Column, Value: e.Value, Err: e.Err.Error(), Code: Code(e.Err)})
+}
+
+// UnmarshalJSON decodes the error, the reason is decoded as a new error
+func (e *RowError) UnmarshalJSON(data []byte) error {
+	var v rowErrorJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*e = RowError{File: v.File, Line: v.Line, Column: v.Column, Value: v.Value, Err: errors.New(v.Err)}
+	return nil
+}
+
+// GobEncode encodes the error like MarshalJSON, so it's saved by SaveState
+func (e *RowError) GobEncode() ([]byte, error) { return e.MarshalJSON() }
+
+// GobDecode decodes the error encoded by GobEncode
+func (e *RowError) GobDecode(data []byte) error { return e.UnmarshalJSON(data) }
+
+// RowErrors lists errors of the skipped records
+type RowErrors []*RowError
+
+func (e RowErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d records skipped: %s", len(e), strings.Join(messages, "; "))
+}
+
+// decides whether the record with err is skipped or the import is aborted,
+// returned error aborts the import
+func (c *CustomerImporter) handleRowError(r parsedRecord, err error) error {
+	duplicate := errors.Is(err, ErrEmailDuplicate)
+	skip := c.collectErrors || c.limitErrors || c.maxErrorRate > 0 ||
+		(duplicate && c.skipErrDupEmails) ||
+		(errors.Is(err, ErrEmailEmpty) && c.skipEmptyEmails) ||
+		(!duplicate && c.skipErrInvalidEmails)
+	if c.onError != nil {
+		switch c.onError(r.line, r.record, err) {
+		case DecisionSkip:
+			skip = true
+		case DecisionAbort:
+			skip = false
+		}
+	}
+	if !skip {
+		return err
+	}
+
+	c.log(slog.LevelDebug, "record skipped", "line", r.line, "column", r.column, "value", c.errorValue(r), "reason", err)
+
+	// write the record to the rejected ones
+	if err := c.reject(r.record, err); err != nil {
+		return err
+	}
+
+	// update statistics
+	if duplicate {
+		c.duplicateEmails++
+	} else {
+		c.invalidEmails++
+		c.invalidByReason[err.Error()]++
+	}
+
+	// collect error
+	if c.collectErrors {
+		c.rowErrors = append(c.rowErrors, &RowError{
+			File:   c.fileName,
+			Line:   r.line,
+			Column: r.column,
+			Value:  c.errorValue(r),
+			Err:    err,
+		})
+	}
+
+	// abort if too many records are skipped
+	if c.limitErrors && c.invalidEmails+c.duplicateEmails > c.maxErrors {
+		return c.tooManyErrors()
+	}
+
+	return nil
+}
+
+// returns ErrTooManyErrors if rate of skipped records exceeds the limit
+func (c *CustomerImporter) checkErrorRate() error {
+	if c.maxErrorRate <= 0 || c.rowsRead == 0 {
+		return nil
+	}
+	if rate := float64(c.invalidEmails+c.duplicateEmails) / float64(c.rowsRead) * 100; rate > c.maxErrorRate {
+		return c.tooManyErrors()
+	}
+	return nil
+}
+
+// returns ErrTooManyErrors summarizing skipped records
+func (c *CustomerImporter) tooManyErrors() error {
+	return fmt.Errorf("%w: %d invalid and %d duplicate emails in %d records",
+		ErrTooManyErrors, c.invalidEmails, c.duplicateEmails, c.rowsRead)
+}
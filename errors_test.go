@@ -0,0 +1,349 @@
+package customerimporter
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCollectErrors(t *testing.T) {
+	records := "name,email\n" +
+		"A,a@a.io\n" +
+		"B,invalid\n" +
+		"C,a@a.io\n" +
+		"D,\n" +
+		"E,b@b.io\n"
+
+	result, err := ImportWithStats(strings.NewReader(records), "email", CollectErrors())
+
+	// check collected errors
+	var rowErrors RowErrors
+	if !errors.As(err, &rowErrors) {
+		t.Fatalf("should raise RowErrors, but got error %v", err)
+	}
+	expected := RowErrors{
+		{Line: 3, Column: 1, Value: "invalid", Err: ErrEmailIsNotValid},
+		{Line: 4, Column: 1, Value: "a@a.io", Err: ErrEmailDuplicate},
+		{Line: 5, Column: 1, Value: "", Err: ErrEmailEmpty},
+	}
+	if !reflect.DeepEqual(rowErrors, expected) {
+		t.Errorf("should collect: %v, but got %v", expected, rowErrors)
+	}
+	if !errors.Is(rowErrors[0], ErrEmailIsNotValid) {
+		t.Errorf("row error should wrap %v", ErrEmailIsNotValid)
+	}
+
+	// check partial result
+	if result == nil {
+		t.Fatal("should return result together with errors")
+	}
+	if !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("result should contain errors: %v, but got %v", expected, result.Errors)
+	}
+	domains := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+		{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+	}
+	if !reflect.DeepEqual(result.Domains, domains) {
+		t.Errorf("should result with: %v, but got %v", domains, result.Domains)
+	}
+	if result.InvalidEmails != 2 || result.DuplicateEmails != 1 {
+		t.Errorf("should skip 2 invalid and 1 duplicate emails, but got %+v", result)
+	}
+
+	// check Import returns both result and errors
+	t.Log("Test Import returns both result and errors")
+	list, err := Import(strings.NewReader(records), "email", CollectErrors())
+	if list == nil || !reflect.DeepEqual(*list, domains) {
+		t.Errorf("should result with: %v, but got %v", domains, list)
+	}
+	if !errors.As(err, &rowErrors) || len(rowErrors) != 3 {
+		t.Errorf("should raise 3 row errors, but got %v", err)
+	}
+
+	// check no error is returned for clean input
+	t.Log("Test no error is returned for clean input")
+	_, err = Import(strings.NewReader("email\na@a.io\n"), "email", CollectErrors())
+	if err != nil {
+		t.Errorf("should pass the test, but got error %v", err)
+	}
+}
+
+func TestMaxErrors(t *testing.T) {
+	records := "email\n" +
+		"a@a.io\n" +
+		"invalid\n" +
+		"a@a.io\n" +
+		"b@a.io\n" +
+		"\"\"\n" +
+		"c@a.io\n"
+
+	data := []struct {
+		options []Option
+		err     string
+	}{
+		// skipped records within the limit
+		{[]Option{WithMaxErrors(3)}, ""},
+		{[]Option{WithMaxErrorRate(50)}, ""},
+		{[]Option{WithMaxErrors(3), WithMaxErrorRate(50)}, ""},
+
+		// limit is exceeded on the third skipped record
+		{[]Option{WithMaxErrors(2)}, "record on line 6, column 0: Too many invalid records: 2 invalid and 1 duplicate emails in 5 records"},
+
+		// zero tolerance
+		{[]Option{WithMaxErrors(0)}, "record on line 3, column 0: Too many invalid records: 1 invalid and 0 duplicate emails in 2 records"},
+
+		// rate is exceeded
+		{[]Option{WithMaxErrorRate(49)}, "Too many invalid records: 2 invalid and 1 duplicate emails in 6 records"},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := ImportWithStats(strings.NewReader(records), "email", d.options...)
+		if d.err == "" {
+			if err != nil {
+				t.Errorf("should pass the test, but got error %v", err)
+			} else if result.InvalidEmails != 2 || result.DuplicateEmails != 1 {
+				t.Errorf("should skip 2 invalid and 1 duplicate emails, but got %+v", result)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrTooManyErrors) || !strings.Contains(err.Error(), d.err) {
+			t.Errorf("should raise error: %v, but got error %v", d.err, err)
+		}
+	}
+}
+
+func TestOnError(t *testing.T) {
+	records := "name,email\n" +
+		"A,a@a.io\n" +
+		"B,invalid\n" +
+		"C,a@a.io\n" +
+		"D,\n" +
+		"E,b@b.io\n"
+
+	// skips records of the names, other records are decided by the options
+	skipNames := func(names ...string) ErrorHandler {
+		return func(line int, record []string, err error) Decision {
+			if slices.Contains(names, record[0]) {
+				return DecisionSkip
+			}
+			return DecisionDefault
+		}
+	}
+	abortDuplicates := func(line int, record []string, err error) Decision {
+		if errors.Is(err, ErrEmailDuplicate) {
+			return DecisionAbort
+		}
+		return DecisionSkip
+	}
+
+	data := []struct {
+		options []Option
+		err     error
+	}{
+		// all records are skipped by the handler
+		{[]Option{OnError(skipNames("B", "C", "D"))}, nil},
+
+		// handler and options skip records together
+		{[]Option{OnError(skipNames("B", "D")), SkipErrDuplicateEmails()}, nil},
+		{[]Option{OnError(skipNames("B", "C"))}, ErrEmailEmpty},
+
+		// handler overrides the options
+		{[]Option{OnError(abortDuplicates), SkipErrDuplicateEmails()}, ErrEmailDuplicate},
+		{[]Option{OnError(abortDuplicates), CollectErrors()}, ErrEmailDuplicate},
+	}
+
+	t.Log("Should skip or abort records as the handler decides")
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := ImportWithStats(strings.NewReader(records), "email", d.options...)
+		if d.err != nil {
+			if !errors.Is(err, d.err) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if result.InvalidEmails != 2 || result.DuplicateEmails != 1 {
+			t.Errorf("should skip 2 invalid and 1 duplicate emails, but got %+v", result)
+		}
+	}
+
+	t.Log("Should pass line, record and error to the handler")
+	var lines []int
+	var errs []error
+	_, err := Import(strings.NewReader(records), "email", OnError(func(line int, record []string, err error) Decision {
+		if !reflect.DeepEqual(record, strings.Split(strings.Split(records, "\n")[line-1], ",")) {
+			t.Errorf("should pass record of line %v, but got %v", line, record)
+		}
+		lines = append(lines, line)
+		errs = append(errs, err)
+		return DecisionSkip
+	}))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if expected := []int{3, 4, 5}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("should call handler on lines %v, but got %v", expected, lines)
+	}
+	if expected := []error{ErrEmailIsNotValid, ErrEmailDuplicate, ErrEmailEmpty}; !reflect.DeepEqual(errs, expected) {
+		t.Errorf("should pass errors %v, but got %v", expected, errs)
+	}
+}
+
+func TestRowErrors(t *testing.T) {
+	errs := RowErrors{
+		{Line: 3, Column: 1, Value: "invalid", Err: ErrEmailIsNotValid},
+		{Line: 4, Column: 1, Value: "a@a.io", Err: ErrEmailDuplicate},
+	}
+
+	expected := "record on line 3, column 1: Email is not valid"
+	if message := errs[:1].Error(); message != expected {
+		t.Errorf("should be %q, but got %q", expected, message)
+	}
+
+	expected = "2 records skipped: record on line 3, column 1: Email is not valid; " +
+		"record on line 4, column 1: Email already added"
+	if message := errs.Error(); message != expected {
+		t.Errorf("should be %q, but got %q", expected, message)
+	}
+}
+
+func TestImportError(t *testing.T) {
+	records := "name,email\n" +
+		"A,a@a.io\n" +
+		"B,invalid\n"
+
+	data := []struct {
+		records string
+		field   string
+		options []Option
+		err     *ImportError
+	}{
+		// invalid record
+		{records, "email", nil, &ImportError{Line: 3, Column: 1, Field: "email", Value: "invalid", Err: ErrEmailIsNotValid}},
+		{records, "email", []Option{RedactErrors()}, &ImportError{Line: 3, Column: 1, Field: "email", Err: ErrEmailIsNotValid}},
+
+		// invalid header
+		{records, "mail", []Option{RedactErrors()}, &ImportError{Line: 1, Column: -1, Field: "mail", Err: ErrFieldNotExists}},
+		{"", "email", nil, &ImportError{Line: 1, Column: -1, Field: "email", Err: ErrEmptyFile}},
+	}
+
+	t.Log("Should describe the error which aborted the import")
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		_, err := Import(strings.NewReader(d.records), d.field, d.options...)
+		var importError *ImportError
+		if !errors.As(err, &importError) {
+			t.Errorf("should raise ImportError, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(importError, d.err) {
+			t.Errorf("should raise: %+v, but got %+v", d.err, importError)
+		}
+		if !errors.Is(err, d.err.Err) {
+			t.Errorf("should wrap %v", d.err.Err)
+		}
+	}
+}
+
+func TestRedactErrors(t *testing.T) {
+	records := "name,email\n" +
+		"A,a@a.io\n" +
+		"B,invalid\n" +
+		"C,a@a.io\n"
+
+	t.Log("Should keep values of records out of collected errors and logs")
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := Import(strings.NewReader(records), "email", CollectErrors(), RedactErrors(), WithLogger(logger))
+	var rowErrors RowErrors
+	if !errors.As(err, &rowErrors) {
+		t.Fatalf("should raise RowErrors, but got error %v", err)
+	}
+	expected := RowErrors{
+		{Line: 3, Column: 1, Err: ErrEmailIsNotValid},
+		{Line: 4, Column: 1, Err: ErrEmailDuplicate},
+	}
+	if !reflect.DeepEqual(rowErrors, expected) {
+		t.Errorf("should collect: %v, but got %v", expected, rowErrors)
+	}
+	if strings.Contains(logs.String(), "value=invalid") || strings.Contains(logs.String(), "a@a.io") {
+		t.Errorf("should not log values, but got %v", logs.String())
+	}
+
+	data := []struct {
+		records string
+		field   string
+		options []Option
+		err     error
+	}{
+		{"name,email\nA,a@a.io\n", "mail", nil, ErrFieldNotExists},
+		{"a@a.io,b@a.io\n", "mail", []Option{WithFieldAliases("a@a.io", "b@a.io")}, ErrAmbiguousField},
+	}
+
+	t.Log("Should keep fields of the header out of errors")
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		_, err := Import(strings.NewReader(d.records), d.field, append(d.options, RedactErrors())...)
+		if !errors.Is(err, d.err) {
+			t.Errorf("should raise error: %v, but got error %v", d.err, err)
+		}
+		if err != nil && strings.Contains(err.Error(), "a.io") {
+			t.Errorf("should not contain fields of the header, but got %v", err)
+		}
+	}
+}
+
+func TestAllowPartialResult(t *testing.T) {
+	records := "name,email\nA,a@a.io\nB,b@a.io\nC,c@b.io\nD,invalid\nE,e@b.io\n"
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+
+	data := []struct {
+		name    string
+		records string
+		options []Option
+		result  EmailsByDomainQtyList
+		err     error
+	}{
+		{"invalid email", records, []Option{AllowPartialResult()}, expected, ErrEmailIsNotValid},
+		{"invalid email by workers", records, []Option{AllowPartialResult(), WithWorkers(4)}, expected, ErrEmailIsNotValid},
+		{"malformed csv", strings.Replace(records, "D,invalid", "D,\"d@b.io", 1), []Option{AllowPartialResult()}, expected, nil},
+		{"error before emails are counted", "name,email\nA,invalid\nB,b@a.io\n", []Option{AllowPartialResult()}, nil, ErrEmailIsNotValid},
+		{"partial result not allowed", records, nil, nil, ErrEmailIsNotValid},
+	}
+
+	t.Log("Should return counts of the records read before error")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := ImportWithStats(strings.NewReader(d.records), "email", d.options...)
+		if err == nil || d.err != nil && !errors.Is(err, d.err) {
+			t.Errorf("should raise error: %v, but got error %v", d.err, err)
+		}
+		if d.result == nil {
+			if result != nil {
+				t.Errorf("should return nil result, but got %+v", result)
+			}
+			continue
+		}
+		if result == nil || !result.Partial || !reflect.DeepEqual(result.Domains, d.result) {
+			t.Errorf("should return partial result: %v, but got %+v", d.result, result)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package customerimporter
+
+import "strings"
+
+// Reduce allocations of every record when reading large files. Records read
+// by the csv reader reuse their slice, so records passed to filters and
+// OnError are valid only during the call, and domains are interned, so the
+// counted domains don't keep the records in memory. Slices are reused only if
+// records are parsed sequentially, see WithWorkers.
+func FastPath() Option { return func(f *CustomerImporter) { f.fastPath = true } }
+
+// returns the shared copy of the domain, the copy is made when the domain is
+// seen for the first time
+func (c *CustomerImporter) intern(domain string) string {
+	if s, ok := c.interned[domain]; ok {
+		return s
+	}
+	if c.interned == nil {
+		c.interned = make(map[string]string)
+	}
+	s := strings.Clone(domain)
+	c.interned[s] = s
+	return s
+}
@@ -0,0 +1,76 @@
+package customerimporter
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFastPath(t *testing.T) {
+	records := "name,email,country\n" +
+		"A,a@a.io,PL\n" +
+		"B,invalid,DE\n" +
+		"C,B@A.IO.,PL\n" +
+		"D,a@a.io,DE\n" +
+		"E,a@b.io,DE\n"
+
+	data := [][]Option{
+		{SkipErrInvalidEmails(), SkipErrDuplicateEmails()},
+		{SkipErrInvalidEmails(), SkipErrDuplicateEmails(), WithBreakdown("country"), WithSamples(2), CountOccurrences()},
+		{SkipErrInvalidEmails(), SkipErrDuplicateEmails(), WithRecordFilter(FieldEquals("country", "DE"))},
+		{SkipErrInvalidEmails(), SkipErrDuplicateEmails(), WithWorkers(2)},
+		{CollectErrors(), CaseInsensitiveEmails()},
+	}
+
+	t.Log("Should return the same result as without fast path")
+	for testNumber, options := range data {
+		t.Logf("Case: %v", testNumber)
+
+		var rejects, fastRejects bytes.Buffer
+		expected, expectedErr := ImportWithStats(strings.NewReader(records), "email", append(options, WithRejectWriter(&rejects))...)
+		result, err := ImportWithStats(strings.NewReader(records), "email", append(options, WithRejectWriter(&fastRejects), FastPath())...)
+		if !reflect.DeepEqual(err, expectedErr) {
+			t.Errorf("should raise error: %v, but got error %v", expectedErr, err)
+		}
+		expected.Elapsed, result.Elapsed = 0, 0
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("should result with: %+v, but got %+v", expected, result)
+		}
+		if rejects.String() != fastRejects.String() {
+			t.Errorf("should reject %q, but got %q", rejects.String(), fastRejects.String())
+		}
+	}
+}
+
+// returns csv with n records of emails of 100 domains
+func benchmarkRecords(n int) []byte {
+	var b bytes.Buffer
+	b.WriteString("first_name,last_name,email,gender,ip_address\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "Mildred,Hernandez,user%d@domain%d.example.com,Female,38.194.51.%d\n", i, i%100, i%256)
+	}
+	return b.Bytes()
+}
+
+func BenchmarkImport(b *testing.B) {
+	records := benchmarkRecords(10000)
+
+	for _, d := range []struct {
+		name    string
+		options []Option
+	}{
+		{"default", nil},
+		{"fast path", []Option{FastPath()}},
+	} {
+		b.Run(d.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := Import(bytes.NewReader(records), "email", d.options...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
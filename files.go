@@ -0,0 +1,163 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrNoFilesMatched is raised when a pattern of ImportFromFiles matches no files
+var ErrNoFilesMatched = errors.New("No files match pattern")
+
+// deduplicate emails only within each file of ImportFromFiles, emails are
+// deduplicated across all files otherwise. Every file uses its own in-memory
+// store, so WithDedupStore and WithBloomDedup are ignored, WithMemoryLimit
+// limits the store of every file.
+func DedupPerFile() Option { return func(f *CustomerImporter) { f.dedupPerFile = true } }
+
+// imports from the files and returns emails counted across all of them. Paths
+// may be glob patterns, e.g. exports/*.csv.gz.
+func ImportFromFiles(paths []string, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	// import and get result
+	result, err := ImportFromFilesWithStats(paths, emailFieldName, options...)
+	if result == nil {
+		return nil, err
+	}
+
+	return &result.Domains, err
+}
+
+// imports from the files and returns ImportResult with statistics of all
+// files. Files are read one by one in the order of paths, compression of every
+// file is detected by its extension unless WithCompression is used.
+func ImportFromFilesWithStats(paths []string, emailFieldName string, options ...Option) (result *ImportResult, err error) {
+	c := newCustomerImporter(nil, emailFieldName, options...)
+	defer c.closeSpill()
+	defer func() { c.notifyCompletion(result, err) }()
+	endImport := c.traceImport()
+	defer func() { endImport(result, err) }()
+
+	fileNames, err := expandPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	// files are read in the order of paths
+	next := func() (entry, error) {
+		if len(fileNames) == 0 {
+			return entry{}, io.EOF
+		}
+		fileName := fileNames[0]
+		fileNames = fileNames[1:]
+		return entry{name: fileName, open: func() (io.ReadCloser, error) { return openFile(fileName) }}, nil
+	}
+	return c.importEntries(next)
+}
+
+// entry is a csv file of the multi-file import, open returns its data
+type entry struct {
+	name string
+	open func() (io.ReadCloser, error)
+}
+
+// imports entries returned by next until it returns io.EOF and returns
+// emails counted across all of them
+func (c *CustomerImporter) importEntries(next func() (entry, error)) (*ImportResult, error) {
+	// load state of the interrupted import
+	if err := c.loadCheckpoint(); err != nil {
+		return nil, err
+	}
+
+	// parse entries, entries before the checkpointed one are already counted
+	compression := c.compression
+	for {
+		e, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return c.partialResult(err)
+		}
+		if c.resume != nil && c.resume.File != e.name {
+			continue
+		}
+		c.compression = compression
+		if err := c.parseEntry(e); err != nil {
+			return c.partialResult(fmt.Errorf("%s: %w", e.name, err))
+		}
+	}
+
+	if c.resume != nil {
+		return nil, fmt.Errorf("%w %s", ErrCheckpointMismatch, c.checkpointPath)
+	}
+
+	// check rate of skipped records of all entries
+	if err := c.checkErrorRate(); err != nil {
+		return c.partialResult(err)
+	}
+
+	// import is finished, it won't be resumed
+	if err := c.removeCheckpoint(); err != nil {
+		return nil, err
+	}
+
+	return c.result()
+}
+
+// parses records of the entry and updates counter
+func (c *CustomerImporter) parseEntry(e entry) error {
+	span := c.startSpan("customerimporter.Open", attribute.String("customerimporter.input", e.name))
+	file, err := e.open()
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// detect compression by extension, explicit option takes precedence
+	if c.compression == CompressionAuto {
+		if compression, ok := compressionByExtension(e.name); ok {
+			c.compression = compression
+		}
+	}
+	if c.dedupPerFile {
+		c.countedEmails = c.newFileDedupStore()
+		c.resetEmailsLog()
+	}
+
+	// start reading the file from its header
+	c.input, c.reader, c.line, c.resumeLine, c.fileName = file, nil, 0, 0, e.name
+
+	return c.parseInput()
+}
+
+// returns file names of the paths expanding glob patterns, every file is
+// returned once
+func expandPaths(paths []string) ([]string, error) {
+	var fileNames []string
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		matches := []string{path}
+		if strings.ContainsAny(path, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(path); err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%w %s", ErrNoFilesMatched, path)
+			}
+		}
+
+		for _, fileName := range matches {
+			if !seen[fileName] {
+				seen[fileName] = true
+				fileNames = append(fileNames, fileName)
+			}
+		}
+	}
+	return fileNames, nil
+}
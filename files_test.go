@@ -0,0 +1,97 @@
+package customerimporter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"day1.csv":    []byte("email\na@a.io\nb@a.io\n"),
+		"day2.csv.gz": gzipData(t, "name,email\nA,a@a.io\nC,a@b.io\n"),
+		"day3.txt":    []byte("email\na@c.io\n"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		options  []Option
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{"across files", []string{filepath.Join(dir, "day*.csv*")}, []Option{SkipErrDuplicateEmails()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, nil},
+		{"per file", []string{filepath.Join(dir, "day*.csv*")}, []Option{DedupPerFile()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 3.0 / 4},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 4},
+		}, nil},
+		{"patterns and names", []string{filepath.Join(dir, "day1.csv"), filepath.Join(dir, "*.txt"), filepath.Join(dir, "day1.csv")}, nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "c.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, nil},
+		{"duplicate across files", []string{filepath.Join(dir, "day*.csv*")}, nil, nil, ErrEmailDuplicate},
+		{"no matches", []string{filepath.Join(dir, "*.xlsx")}, nil, nil, ErrNoFilesMatched},
+		{"missing file", []string{filepath.Join(dir, "day1.csv"), filepath.Join(dir, "missing.csv")}, nil, nil, os.ErrNotExist},
+	}
+
+	t.Log("Should import emails from all files")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.name)
+
+		result, err := ImportFromFiles(test.paths, "email", test.options...)
+		if test.err != nil {
+			if !errors.Is(err, test.err) && (err == nil || !strings.Contains(err.Error(), test.err.Error())) {
+				t.Errorf("should raise error: %v, but got error %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, test.expected) {
+			t.Errorf("should return %v, but got %v", test.expected, *result)
+		}
+	}
+}
+
+func TestImportFromFilesErrors(t *testing.T) {
+	t.Log("Should report file of the collected errors")
+
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "customers.csv")
+	if err := os.WriteFile(fileName, []byte("email\na@a.io\ninvalid\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ImportFromFilesWithStats([]string{fileName}, "email", CollectErrors())
+	var rowErrors RowErrors
+	if !errors.As(err, &rowErrors) || len(rowErrors) != 1 {
+		t.Fatalf("should return one collected error, but got %v", err)
+	}
+	if rowErrors[0].File != fileName || rowErrors[0].Line != 3 {
+		t.Errorf("should report line 3 of %v, but got %v", fileName, rowErrors[0])
+	}
+	if result == nil || result.ValidEmails != 1 {
+		t.Errorf("should return partial result, but got %+v", result)
+	}
+	t.Log("Should return counts of the files read before error")
+	result, err = ImportFromFilesWithStats([]string{fileName}, "email", AllowPartialResult())
+	if !errors.Is(err, ErrEmailIsNotValid) || !strings.Contains(err.Error(), fileName) {
+		t.Errorf("should raise error: %v of %v, but got error %v", ErrEmailIsNotValid, fileName, err)
+	}
+	if result == nil || !result.Partial || result.ValidEmails != 1 {
+		t.Errorf("should return partial result, but got %+v", result)
+	}
+}
@@ -0,0 +1,38 @@
+package customerimporter
+
+// RecordFilter reports whether the record is counted, header is nil if
+// WithColumnIndex is used
+type RecordFilter func(header, record []string) bool
+
+// Count only records the filter returns true for, e.g. records of active
+// customers by other column. If the option is used several times, records
+// must pass all filters. Filtered records are not validated and are counted in
+// FilteredRows. With WithWorkers filters are called concurrently.
+func WithRecordFilter(filter RecordFilter) Option {
+	return func(f *CustomerImporter) {
+		f.recordFilters = append(f.recordFilters, filter)
+	}
+}
+
+// FieldEquals returns filter counting only records with the value in the field
+// of the header
+func FieldEquals(field, value string) RecordFilter {
+	return func(header, record []string) bool {
+		for i, name := range header {
+			if name == field && i < len(record) && record[i] == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// reports whether the record passes all filters
+func (c *CustomerImporter) filterRecord(record []string) bool {
+	for _, filter := range c.recordFilters {
+		if !filter(c.header, record) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithRecordFilter(t *testing.T) {
+	records := "email,country,status\n" +
+		"a@a.io,DE,active\n" +
+		"b@a.io,FR,active\n" +
+		"a@b.io,DE,inactive\n" +
+		"invalid,FR,active\n" +
+		"c@a.io,DE,active\n"
+
+	data := []struct {
+		name     string
+		options  []Option
+		result   EmailsByDomainQtyList
+		filtered int
+	}{
+		{"one field", []Option{WithRecordFilter(FieldEquals("country", "DE"))}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, 2},
+		{"all filters", []Option{WithRecordFilter(FieldEquals("country", "DE")), WithRecordFilter(FieldEquals("status", "active"))}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 1},
+		}, 3},
+		{"custom filter with workers", []Option{WithWorkers(4), WithRecordFilter(func(header, record []string) bool {
+			return header[2] == "status" && record[2] == "active" && record[1] == "DE"
+		})}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 1},
+		}, 3},
+		{"headerless", []Option{WithColumnIndex(0), WithRecordFilter(func(header, record []string) bool {
+			return header == nil && record[1] == "FR" && record[0] != "invalid"
+		})}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}, 5},
+	}
+
+	t.Log("Should count only records passing filters")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := ImportWithStats(strings.NewReader(records), "email", d.options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Domains, d.result) {
+			t.Errorf("should return %v, but got %v", d.result, result.Domains)
+		}
+		if result.FilteredRows != d.filtered || result.RowsRead != result.ValidEmails+result.InvalidEmails+result.DuplicateEmails+result.FilteredRows {
+			t.Errorf("should filter %v records, but got %+v", d.filtered, result)
+		}
+	}
+}
+
+func TestFieldEquals(t *testing.T) {
+	header := []string{"email", "country"}
+
+	data := []struct {
+		field, value string
+		record       []string
+		expected     bool
+	}{
+		{"country", "DE", []string{"a@a.io", "DE"}, true},
+		{"country", "DE", []string{"a@a.io", "de"}, false},
+		{"country", "DE", []string{"a@a.io"}, false},
+		{"status", "active", []string{"a@a.io", "DE"}, false},
+	}
+
+	for _, d := range data {
+		t.Logf("Case: %v=%v %v", d.field, d.value, d.record)
+
+		if matched := FieldEquals(d.field, d.value)(header, d.record); matched != d.expected {
+			t.Errorf("should return %v, but got %v", d.expected, matched)
+		}
+	}
+}
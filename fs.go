@@ -0,0 +1,40 @@
+package customerimporter
+
+import (
+	"context"
+	"io"
+	"io/fs"
+)
+
+// imports from the file of the file system, e.g. embed.FS of test fixtures
+// or fstest.MapFS
+func ImportFromFS(fsys fs.FS, name string, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	// import and get result
+	result, err := ImportFromFSWithStats(fsys, name, emailFieldName, options...)
+	if result == nil {
+		return nil, err
+	}
+
+	return &result.Domains, err
+}
+
+// imports from the file of the file system and returns ImportResult with
+// statistics. Files with .gz and .zst extensions are decompressed unless
+// WithCompression is used.
+func ImportFromFSWithStats(fsys fs.FS, name string, emailFieldName string, options ...Option) (*ImportResult, error) {
+	return ImportFromSourceWithStats(context.Background(), FSSource(fsys, name), emailFieldName, options...)
+}
+
+// FSSource returns Source of the file of the file system
+func FSSource(fsys fs.FS, name string) Source {
+	return &fsSource{fsys: fsys, name: name}
+}
+
+// fsSource is a file of fs.FS
+type fsSource struct {
+	fsys fs.FS
+	name string
+}
+
+func (s *fsSource) Open(context.Context) (io.ReadCloser, error) { return s.fsys.Open(s.name) }
+func (s *fsSource) Name() string                                { return s.name }
@@ -0,0 +1,60 @@
+package customerimporter
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestImportFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"customers.csv":            {Data: []byte("email\na@a.io\nb@a.io\na@b.io\n")},
+		"exports/customers.csv.gz": {Data: gzipData(t, "email\na@a.io\n")},
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/customers.csv", []byte("email\na@b.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		fsys     fs.FS
+		name     string
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{fsys, "customers.csv", EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, nil},
+		{fsys, "exports/customers.csv.gz", EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}, nil},
+		{os.DirFS(dir), "customers.csv", EmailsByDomainQtyList{
+			{Domain: "b.io", EmailsCount: 1, Share: 1},
+		}, nil},
+		{fsys, "missing.csv", nil, fs.ErrNotExist},
+		{os.DirFS(dir), "../customers.csv", nil, fs.ErrInvalid},
+	}
+
+	t.Log("Should import emails from the file of the file system")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := ImportFromFS(d.fsys, d.name, "email")
+		if d.err != nil {
+			if !errors.Is(err, d.err) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, d.expected) {
+			t.Errorf("should return %v, but got %v", d.expected, *result)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package customerimporter
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Count emails by registrable domain (eTLD+1) according to the public suffix
+// list, e.g. emails of mail.eu.example.co.uk are counted as example.co.uk.
+func GroupByRegistrableDomain() Option {
+	return func(f *CustomerImporter) { f.groupBy = registrableDomain }
+}
+
+// Count emails by top-level domain, e.g. emails of github.io and example.io
+// are counted as io.
+func GroupByTLD() Option {
+	return func(f *CustomerImporter) { f.groupBy = topLevelDomain }
+}
+
+// imports from reader counting records by value of the field instead of
+// domain of email, e.g. by country. Domain of the returned entries is the value,
+// records with empty value are counted too.
+func ImportGroupBy(r io.Reader, fieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	// import and get result
+	result, err := ImportGroupByWithStats(r, fieldName, options...)
+	if result == nil {
+		return nil, err
+	}
+
+	return &result.Domains, err
+}
+
+// imports from reader counting records by value of the field and returns
+// ImportResult with statistics, every counted record is a valid email. Options
+// of email validation, deduplication and grouping of domains are ignored.
+func ImportGroupByWithStats(r io.Reader, fieldName string, options ...Option) (*ImportResult, error) {
+	c := newCustomerImporter(r, fieldName, options...)
+	c.countValues = true
+	c.emailFieldNames, c.groupBy, c.mxVerifier, c.classifier, c.typos, c.confusables = nil, nil, nil, nil, nil, nil
+
+	return c.run()
+}
+
+// returns eTLD+1 of the domain, domains which are public suffixes themselves
+// are returned as is
+func registrableDomain(domain string) string {
+	registrable, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		return domain
+	}
+	return registrable
+}
+
+// returns the last label of the domain
+func topLevelDomain(domain string) string {
+	return domain[strings.LastIndexByte(domain, '.')+1:]
+}
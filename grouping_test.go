@@ -0,0 +1,115 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroupByRegistrableDomain(t *testing.T) {
+	records := "email\n" +
+		"a@mail.eu.example.co.uk\n" +
+		"b@example.co.uk\n" +
+		"c@us.example.co.uk\n" +
+		"d@github.io\n" +
+		"e@user.github.io\n" +
+		"f@mail.google.com\n" +
+		"g@co.uk\n"
+
+	expected := EmailsByDomainQtyList{
+		{Domain: "co.uk", EmailsCount: 1, Share: 1.0 / 7},
+		{Domain: "example.co.uk", EmailsCount: 3, Share: 3.0 / 7},
+		{Domain: "github.io", EmailsCount: 1, Share: 1.0 / 7},
+		{Domain: "google.com", EmailsCount: 1, Share: 1.0 / 7},
+		{Domain: "user.github.io", EmailsCount: 1, Share: 1.0 / 7},
+	}
+
+	result, err := Import(strings.NewReader(records), "email", GroupByRegistrableDomain())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should result with: %v, but got %v", expected, *result)
+	}
+}
+
+func TestGroupByTLD(t *testing.T) {
+	records := "email\n" +
+		"a@github.io\n" +
+		"b@example.io\n" +
+		"c@example.co.uk\n" +
+		"d@example.com\n" +
+		"e@example.de\n"
+
+	expected := EmailsByDomainQtyList{
+		{Domain: "com", EmailsCount: 1, Share: 0.2},
+		{Domain: "de", EmailsCount: 1, Share: 0.2},
+		{Domain: "io", EmailsCount: 2, Share: 0.4},
+		{Domain: "uk", EmailsCount: 1, Share: 0.2},
+	}
+
+	result, err := Import(strings.NewReader(records), "email", GroupByTLD())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should result with: %v, but got %v", expected, *result)
+	}
+
+	// the last grouping option wins
+	t.Log("Test the last grouping option wins")
+	result, err = Import(strings.NewReader(records), "email", GroupByTLD(), GroupByRegistrableDomain())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if len(*result) != 5 {
+		t.Errorf("should count 5 registrable domains, but got %v", *result)
+	}
+}
+
+func TestImportGroupBy(t *testing.T) {
+	records := "name,email,country\n" +
+		"A,a@a.io,PL\n" +
+		"B,a@a.io,DE\n" +
+		"C,invalid,PL\n" +
+		"D,d@b.io,\n"
+
+	data := []struct {
+		field    string
+		options  []Option
+		expected EmailsByDomainQtyList
+	}{
+		// emails are neither validated nor deduplicated
+		{"country", []Option{SortByCount(), SortDescending()}, EmailsByDomainQtyList{
+			{Domain: "PL", EmailsCount: 2, Share: 0.5},
+			{Domain: "", EmailsCount: 1, Share: 0.25},
+			{Domain: "DE", EmailsCount: 1, Share: 0.25},
+		}},
+		{"email", []Option{GroupByTLD(), VerifyMX()}, EmailsByDomainQtyList{
+			{Domain: "a@a.io", EmailsCount: 2, Share: 0.5},
+			{Domain: "d@b.io", EmailsCount: 1, Share: 0.25},
+			{Domain: "invalid", EmailsCount: 1, Share: 0.25},
+		}},
+		{"country", []Option{WithRecordFilter(FieldEquals("name", "A"))}, EmailsByDomainQtyList{
+			{Domain: "PL", EmailsCount: 1, Share: 1},
+		}},
+	}
+
+	t.Log("Should count records by value of the field")
+	for _, d := range data {
+		t.Logf("Case: %v", d.field)
+
+		result, err := ImportGroupBy(strings.NewReader(records), d.field, d.options...)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		if !reflect.DeepEqual(*result, d.expected) {
+			t.Errorf("should result with: %v, but got %v", d.expected, *result)
+		}
+	}
+
+	t.Log("Should raise error if the field doesn't exist")
+	if _, err := ImportGroupBy(strings.NewReader(records), "region"); err == nil || !strings.Contains(err.Error(), ErrFieldNotExists.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrFieldNotExists, err)
+	}
+}
@@ -0,0 +1,5 @@
+// Package importerpb contains Go code of the Importer gRPC service generated
+// from importer.proto by protoc-gen-go and protoc-gen-go-grpc.
+package importerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative importer.proto
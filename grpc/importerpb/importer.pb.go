@@ -0,0 +1,539 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        v5.29.3
+// source: importer.proto
+
+package importerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Options of the import
+type ImportOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name of the email field, "email" if empty
+	EmailField string `protobuf:"bytes,1,opt,name=email_field,json=emailField,proto3" json:"email_field,omitempty"`
+	// skip invalid emails instead of aborting the import
+	SkipInvalid bool `protobuf:"varint,2,opt,name=skip_invalid,json=skipInvalid,proto3" json:"skip_invalid,omitempty"`
+	// skip duplicate emails instead of aborting the import
+	SkipDuplicates bool `protobuf:"varint,3,opt,name=skip_duplicates,json=skipDuplicates,proto3" json:"skip_duplicates,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ImportOptions) Reset() {
+	*x = ImportOptions{}
+	mi := &file_importer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportOptions) ProtoMessage() {}
+
+func (x *ImportOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_importer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportOptions.ProtoReflect.Descriptor instead.
+func (*ImportOptions) Descriptor() ([]byte, []int) {
+	return file_importer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ImportOptions) GetEmailField() string {
+	if x != nil {
+		return x.EmailField
+	}
+	return ""
+}
+
+func (x *ImportOptions) GetSkipInvalid() bool {
+	if x != nil {
+		return x.SkipInvalid
+	}
+	return false
+}
+
+func (x *ImportOptions) GetSkipDuplicates() bool {
+	if x != nil {
+		return x.SkipDuplicates
+	}
+	return false
+}
+
+// Chunk of the uploaded csv data
+type ImportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// options of the import, read from the first message only
+	Options *ImportOptions `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	// next bytes of the csv data
+	Chunk         []byte `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRequest) Reset() {
+	*x = ImportRequest{}
+	mi := &file_importer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRequest) ProtoMessage() {}
+
+func (x *ImportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_importer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRequest.ProtoReflect.Descriptor instead.
+func (*ImportRequest) Descriptor() ([]byte, []int) {
+	return file_importer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ImportRequest) GetOptions() *ImportOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ImportRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+// Emails count of a domain
+type DomainCount struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Domain      string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	EmailsCount int64                  `protobuf:"varint,2,opt,name=emails_count,json=emailsCount,proto3" json:"emails_count,omitempty"`
+	// share of the domain in all counted emails
+	Share         float64 `protobuf:"fixed64,3,opt,name=share,proto3" json:"share,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DomainCount) Reset() {
+	*x = DomainCount{}
+	mi := &file_importer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DomainCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DomainCount) ProtoMessage() {}
+
+func (x *DomainCount) ProtoReflect() protoreflect.Message {
+	mi := &file_importer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DomainCount.ProtoReflect.Descriptor instead.
+func (*DomainCount) Descriptor() ([]byte, []int) {
+	return file_importer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DomainCount) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *DomainCount) GetEmailsCount() int64 {
+	if x != nil {
+		return x.EmailsCount
+	}
+	return 0
+}
+
+func (x *DomainCount) GetShare() float64 {
+	if x != nil {
+		return x.Share
+	}
+	return 0
+}
+
+// Result of the import
+type ImportResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// emails count by domain sorted by domain
+	Domains         []*DomainCount `protobuf:"bytes,1,rep,name=domains,proto3" json:"domains,omitempty"`
+	RowsRead        int64          `protobuf:"varint,2,opt,name=rows_read,json=rowsRead,proto3" json:"rows_read,omitempty"`
+	ValidEmails     int64          `protobuf:"varint,3,opt,name=valid_emails,json=validEmails,proto3" json:"valid_emails,omitempty"`
+	InvalidEmails   int64          `protobuf:"varint,4,opt,name=invalid_emails,json=invalidEmails,proto3" json:"invalid_emails,omitempty"`
+	DuplicateEmails int64          `protobuf:"varint,5,opt,name=duplicate_emails,json=duplicateEmails,proto3" json:"duplicate_emails,omitempty"`
+	DistinctDomains int64          `protobuf:"varint,6,opt,name=distinct_domains,json=distinctDomains,proto3" json:"distinct_domains,omitempty"`
+	ElapsedMs       int64          `protobuf:"varint,7,opt,name=elapsed_ms,json=elapsedMs,proto3" json:"elapsed_ms,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ImportResponse) Reset() {
+	*x = ImportResponse{}
+	mi := &file_importer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportResponse) ProtoMessage() {}
+
+func (x *ImportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_importer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportResponse.ProtoReflect.Descriptor instead.
+func (*ImportResponse) Descriptor() ([]byte, []int) {
+	return file_importer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ImportResponse) GetDomains() []*DomainCount {
+	if x != nil {
+		return x.Domains
+	}
+	return nil
+}
+
+func (x *ImportResponse) GetRowsRead() int64 {
+	if x != nil {
+		return x.RowsRead
+	}
+	return 0
+}
+
+func (x *ImportResponse) GetValidEmails() int64 {
+	if x != nil {
+		return x.ValidEmails
+	}
+	return 0
+}
+
+func (x *ImportResponse) GetInvalidEmails() int64 {
+	if x != nil {
+		return x.InvalidEmails
+	}
+	return 0
+}
+
+func (x *ImportResponse) GetDuplicateEmails() int64 {
+	if x != nil {
+		return x.DuplicateEmails
+	}
+	return 0
+}
+
+func (x *ImportResponse) GetDistinctDomains() int64 {
+	if x != nil {
+		return x.DistinctDomains
+	}
+	return 0
+}
+
+func (x *ImportResponse) GetElapsedMs() int64 {
+	if x != nil {
+		return x.ElapsedMs
+	}
+	return 0
+}
+
+// Import of a source
+type ImportSourceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// URL of the source, e.g. https://example.com/customers.csv.gz
+	Url           string         `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Options       *ImportOptions `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportSourceRequest) Reset() {
+	*x = ImportSourceRequest{}
+	mi := &file_importer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportSourceRequest) ProtoMessage() {}
+
+func (x *ImportSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_importer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportSourceRequest.ProtoReflect.Descriptor instead.
+func (*ImportSourceRequest) Descriptor() ([]byte, []int) {
+	return file_importer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ImportSourceRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ImportSourceRequest) GetOptions() *ImportOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// Progress of the import
+type ImportProgress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// line of the last processed record
+	Line            int64 `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	ValidEmails     int64 `protobuf:"varint,2,opt,name=valid_emails,json=validEmails,proto3" json:"valid_emails,omitempty"`
+	InvalidEmails   int64 `protobuf:"varint,3,opt,name=invalid_emails,json=invalidEmails,proto3" json:"invalid_emails,omitempty"`
+	DuplicateEmails int64 `protobuf:"varint,4,opt,name=duplicate_emails,json=duplicateEmails,proto3" json:"duplicate_emails,omitempty"`
+	DistinctDomains int64 `protobuf:"varint,5,opt,name=distinct_domains,json=distinctDomains,proto3" json:"distinct_domains,omitempty"`
+	// result of the finished import, set in the last message only
+	Result        *ImportResponse `protobuf:"bytes,6,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportProgress) Reset() {
+	*x = ImportProgress{}
+	mi := &file_importer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportProgress) ProtoMessage() {}
+
+func (x *ImportProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_importer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportProgress.ProtoReflect.Descriptor instead.
+func (*ImportProgress) Descriptor() ([]byte, []int) {
+	return file_importer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ImportProgress) GetLine() int64 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *ImportProgress) GetValidEmails() int64 {
+	if x != nil {
+		return x.ValidEmails
+	}
+	return 0
+}
+
+func (x *ImportProgress) GetInvalidEmails() int64 {
+	if x != nil {
+		return x.InvalidEmails
+	}
+	return 0
+}
+
+func (x *ImportProgress) GetDuplicateEmails() int64 {
+	if x != nil {
+		return x.DuplicateEmails
+	}
+	return 0
+}
+
+func (x *ImportProgress) GetDistinctDomains() int64 {
+	if x != nil {
+		return x.DistinctDomains
+	}
+	return 0
+}
+
+func (x *ImportProgress) GetResult() *ImportResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+var File_importer_proto protoreflect.FileDescriptor
+
+const file_importer_proto_rawDesc = "" +
+	"\n" +
+	"\x0eimporter.proto\x12\x13customerimporter.v1\"|\n" +
+	"\rImportOptions\x12\x1f\n" +
+	"\vemail_field\x18\x01 \x01(\tR\n" +
+	"emailField\x12!\n" +
+	"\fskip_invalid\x18\x02 \x01(\bR\vskipInvalid\x12'\n" +
+	"\x0fskip_duplicates\x18\x03 \x01(\bR\x0eskipDuplicates\"c\n" +
+	"\rImportRequest\x12<\n" +
+	"\aoptions\x18\x01 \x01(\v2\".customerimporter.v1.ImportOptionsR\aoptions\x12\x14\n" +
+	"\x05chunk\x18\x02 \x01(\fR\x05chunk\"^\n" +
+	"\vDomainCount\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12!\n" +
+	"\femails_count\x18\x02 \x01(\x03R\vemailsCount\x12\x14\n" +
+	"\x05share\x18\x03 \x01(\x01R\x05share\"\xa8\x02\n" +
+	"\x0eImportResponse\x12:\n" +
+	"\adomains\x18\x01 \x03(\v2 .customerimporter.v1.DomainCountR\adomains\x12\x1b\n" +
+	"\trows_read\x18\x02 \x01(\x03R\browsRead\x12!\n" +
+	"\fvalid_emails\x18\x03 \x01(\x03R\vvalidEmails\x12%\n" +
+	"\x0einvalid_emails\x18\x04 \x01(\x03R\rinvalidEmails\x12)\n" +
+	"\x10duplicate_emails\x18\x05 \x01(\x03R\x0fduplicateEmails\x12)\n" +
+	"\x10distinct_domains\x18\x06 \x01(\x03R\x0fdistinctDomains\x12\x1d\n" +
+	"\n" +
+	"elapsed_ms\x18\a \x01(\x03R\telapsedMs\"e\n" +
+	"\x13ImportSourceRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12<\n" +
+	"\aoptions\x18\x02 \x01(\v2\".customerimporter.v1.ImportOptionsR\aoptions\"\x81\x02\n" +
+	"\x0eImportProgress\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x03R\x04line\x12!\n" +
+	"\fvalid_emails\x18\x02 \x01(\x03R\vvalidEmails\x12%\n" +
+	"\x0einvalid_emails\x18\x03 \x01(\x03R\rinvalidEmails\x12)\n" +
+	"\x10duplicate_emails\x18\x04 \x01(\x03R\x0fduplicateEmails\x12)\n" +
+	"\x10distinct_domains\x18\x05 \x01(\x03R\x0fdistinctDomains\x12;\n" +
+	"\x06result\x18\x06 \x01(\v2#.customerimporter.v1.ImportResponseR\x06result2\xc0\x01\n" +
+	"\bImporter\x12S\n" +
+	"\x06Import\x12\".customerimporter.v1.ImportRequest\x1a#.customerimporter.v1.ImportResponse(\x01\x12_\n" +
+	"\fImportSource\x12(.customerimporter.v1.ImportSourceRequest\x1a#.customerimporter.v1.ImportProgress0\x01B/Z-github.com/dreadfulangel/tw_t/grpc/importerpbb\x06proto3"
+
+var (
+	file_importer_proto_rawDescOnce sync.Once
+	file_importer_proto_rawDescData []byte
+)
+
+func file_importer_proto_rawDescGZIP() []byte {
+	file_importer_proto_rawDescOnce.Do(func() {
+		file_importer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_importer_proto_rawDesc), len(file_importer_proto_rawDesc)))
+	})
+	return file_importer_proto_rawDescData
+}
+
+var file_importer_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_importer_proto_goTypes = []any{
+	(*ImportOptions)(nil),       // 0: customerimporter.v1.ImportOptions
+	(*ImportRequest)(nil),       // 1: customerimporter.v1.ImportRequest
+	(*DomainCount)(nil),         // 2: customerimporter.v1.DomainCount
+	(*ImportResponse)(nil),      // 3: customerimporter.v1.ImportResponse
+	(*ImportSourceRequest)(nil), // 4: customerimporter.v1.ImportSourceRequest
+	(*ImportProgress)(nil),      // 5: customerimporter.v1.ImportProgress
+}
+var file_importer_proto_depIdxs = []int32{
+	0, // 0: customerimporter.v1.ImportRequest.options:type_name -> customerimporter.v1.ImportOptions
+	2, // 1: customerimporter.v1.ImportResponse.domains:type_name -> customerimporter.v1.DomainCount
+	0, // 2: customerimporter.v1.ImportSourceRequest.options:type_name -> customerimporter.v1.ImportOptions
+	3, // 3: customerimporter.v1.ImportProgress.result:type_name -> customerimporter.v1.ImportResponse
+	1, // 4: customerimporter.v1.Importer.Import:input_type -> customerimporter.v1.ImportRequest
+	4, // 5: customerimporter.v1.Importer.ImportSource:input_type -> customerimporter.v1.ImportSourceRequest
+	3, // 6: customerimporter.v1.Importer.Import:output_type -> customerimporter.v1.ImportResponse
+	5, // 7: customerimporter.v1.Importer.ImportSource:output_type -> customerimporter.v1.ImportProgress
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_importer_proto_init() }
+func file_importer_proto_init() {
+	if File_importer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_importer_proto_rawDesc), len(file_importer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_importer_proto_goTypes,
+		DependencyIndexes: file_importer_proto_depIdxs,
+		MessageInfos:      file_importer_proto_msgTypes,
+	}.Build()
+	File_importer_proto = out.File
+	file_importer_proto_goTypes = nil
+	file_importer_proto_depIdxs = nil
+}
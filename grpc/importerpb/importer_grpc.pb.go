@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v5.29.3
+// source: importer.proto
+
+package importerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Importer_Import_FullMethodName       = "/customerimporter.v1.Importer/Import"
+	Importer_ImportSource_FullMethodName = "/customerimporter.v1.Importer/ImportSource"
+)
+
+// ImporterClient is the client API for Importer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Importer counts customer emails by domain in csv data
+type ImporterClient interface {
+	// Import reads csv data uploaded in chunks and returns emails count by
+	// domain when the client closes the stream. Options are read from the
+	// first message.
+	Import(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportRequest, ImportResponse], error)
+	// ImportSource imports csv from URL of a source, e.g. https:// or s3://,
+	// and streams progress of the import. The last message contains the result.
+	ImportSource(ctx context.Context, in *ImportSourceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ImportProgress], error)
+}
+
+type importerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewImporterClient(cc grpc.ClientConnInterface) ImporterClient {
+	return &importerClient{cc}
+}
+
+func (c *importerClient) Import(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportRequest, ImportResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Importer_ServiceDesc.Streams[0], Importer_Import_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportRequest, ImportResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Importer_ImportClient = grpc.ClientStreamingClient[ImportRequest, ImportResponse]
+
+func (c *importerClient) ImportSource(ctx context.Context, in *ImportSourceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ImportProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Importer_ServiceDesc.Streams[1], Importer_ImportSource_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportSourceRequest, ImportProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Importer_ImportSourceClient = grpc.ServerStreamingClient[ImportProgress]
+
+// ImporterServer is the server API for Importer service.
+// All implementations must embed UnimplementedImporterServer
+// for forward compatibility.
+//
+// Importer counts customer emails by domain in csv data
+type ImporterServer interface {
+	// Import reads csv data uploaded in chunks and returns emails count by
+	// domain when the client closes the stream. Options are read from the
+	// first message.
+	Import(grpc.ClientStreamingServer[ImportRequest, ImportResponse]) error
+	// ImportSource imports csv from URL of a source, e.g. https:// or s3://,
+	// and streams progress of the import. The last message contains the result.
+	ImportSource(*ImportSourceRequest, grpc.ServerStreamingServer[ImportProgress]) error
+	mustEmbedUnimplementedImporterServer()
+}
+
+// UnimplementedImporterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedImporterServer struct{}
+
+func (UnimplementedImporterServer) Import(grpc.ClientStreamingServer[ImportRequest, ImportResponse]) error {
+	return status.Error(codes.Unimplemented, "method Import not implemented")
+}
+func (UnimplementedImporterServer) ImportSource(*ImportSourceRequest, grpc.ServerStreamingServer[ImportProgress]) error {
+	return status.Error(codes.Unimplemented, "method ImportSource not implemented")
+}
+func (UnimplementedImporterServer) mustEmbedUnimplementedImporterServer() {}
+func (UnimplementedImporterServer) testEmbeddedByValue()                  {}
+
+// UnsafeImporterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ImporterServer will
+// result in compilation errors.
+type UnsafeImporterServer interface {
+	mustEmbedUnimplementedImporterServer()
+}
+
+func RegisterImporterServer(s grpc.ServiceRegistrar, srv ImporterServer) {
+	// If the following call panics, it indicates UnimplementedImporterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Importer_ServiceDesc, srv)
+}
+
+func _Importer_Import_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ImporterServer).Import(&grpc.GenericServerStream[ImportRequest, ImportResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Importer_ImportServer = grpc.ClientStreamingServer[ImportRequest, ImportResponse]
+
+func _Importer_ImportSource_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ImportSourceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ImporterServer).ImportSource(m, &grpc.GenericServerStream[ImportSourceRequest, ImportProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Importer_ImportSourceServer = grpc.ServerStreamingServer[ImportProgress]
+
+// Importer_ServiceDesc is the grpc.ServiceDesc for Importer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Importer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "customerimporter.v1.Importer",
+	HandlerType: (*ImporterServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Import",
+			Handler:       _Importer_Import_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ImportSource",
+			Handler:       _Importer_ImportSource_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "importer.proto",
+}
@@ -0,0 +1,263 @@
+// Package grpc serves customer imports over gRPC by the Importer service of
+// importerpb. Csv data is uploaded in chunks by the Import call, ImportSource
+// imports from URL of a source and streams progress of the import.
+//
+//	lis, err := net.Listen("tcp", ":9090")
+//	if err != nil {
+//		return err
+//	}
+//	return grpc.NewServer(grpc.Config{Timeout: 5 * time.Minute}).Serve(lis)
+//
+// Errors caused by the imported data are returned with InvalidArgument status
+// and exceeded limits with ResourceExhausted, code of the importer error is set
+// as Reason of errdetails.ErrorInfo in status details.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+	"github.com/dreadfulangel/tw_t/grpc/importerpb"
+)
+
+// default limits of the server
+const (
+	DefaultTimeout       = time.Minute
+	DefaultProgressEvery = 10000 // lines
+)
+
+// domain of errdetails.ErrorInfo of importer errors
+const errorDomain = "customerimporter"
+
+// Config of the server
+type Config struct {
+	Timeout       time.Duration             // max duration of an import, DefaultTimeout if < 1
+	MaxRows       int                       // max amount of records of an import, unlimited if < 1
+	MaxInputSize  int64                     // max size of decompressed csv data in bytes, unlimited if < 1
+	ProgressEvery int                       // lines between progress messages of ImportSource, DefaultProgressEvery if < 1
+	Schemes       []string                  // URL schemes ImportSource opens, none if empty
+	Options       []customerimporter.Option // options applied to every import
+}
+
+// Server implements the Importer service
+type Server struct {
+	importerpb.UnimplementedImporterServer
+
+	cfg Config
+}
+
+// New returns service with the config, it's registered by
+// importerpb.RegisterImporterServer
+func New(cfg Config) *Server {
+	if cfg.Timeout < 1 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.ProgressEvery < 1 {
+		cfg.ProgressEvery = DefaultProgressEvery
+	}
+
+	return &Server{cfg: cfg}
+}
+
+// NewServer returns gRPC server with the Importer service of the config
+// registered, ready to Serve
+func NewServer(cfg Config, opts ...grpclib.ServerOption) *grpclib.Server {
+	s := grpclib.NewServer(opts...)
+	importerpb.RegisterImporterServer(s, New(cfg))
+	return s
+}
+
+// Import imports csv data uploaded in chunks with options of the first chunk
+func (s *Server) Import(stream importerpb.Importer_ImportServer) error {
+	ctx, cancel := context.WithTimeout(stream.Context(), s.cfg.Timeout)
+	defer cancel()
+
+	// options are read from the first message, empty upload is imported as
+	// empty file
+	first, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	emailField, options := s.importOptions(first.GetOptions())
+
+	r := &chunkReader{ctx: ctx, stream: stream, chunk: first.GetChunk(), eof: err == io.EOF}
+	result, err := customerimporter.ImportWithStats(r, emailField, options...)
+	if result == nil {
+		return statusError(ctx, err)
+	}
+
+	return stream.SendAndClose(response(result))
+}
+
+// ImportSource imports from URL of the source sending progress after every
+// ProgressEvery lines and the result in the last message. The URL is opened
+// by the server, so its scheme must be allowed by Config.Schemes: http and
+// https let clients request services of the server's network, file lets them
+// read files of the server.
+func (s *Server) ImportSource(req *importerpb.ImportSourceRequest, stream importerpb.Importer_ImportSourceServer) error {
+	ctx, cancel := context.WithTimeout(stream.Context(), s.cfg.Timeout)
+	defer cancel()
+
+	u, err := url.Parse(req.GetUrl())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !slices.Contains(s.cfg.Schemes, u.Scheme) {
+		return status.Errorf(codes.PermissionDenied, "scheme %q is not allowed", u.Scheme)
+	}
+	src, err := customerimporter.OpenSource(req.GetUrl())
+	if err != nil {
+		return statusError(ctx, err)
+	}
+
+	// count events of the import and send them every ProgressEvery lines
+	progress := &importerpb.ImportProgress{}
+	sent := 0
+	emailField, options := s.importOptions(req.GetOptions())
+	options = append(options, customerimporter.WithEventHandler(func(ev customerimporter.Event) error {
+		switch ev.Type {
+		case customerimporter.EventValidEmail:
+			progress.ValidEmails++
+		case customerimporter.EventInvalidEmail:
+			progress.InvalidEmails++
+		case customerimporter.EventDuplicateEmail:
+			progress.DuplicateEmails++
+		case customerimporter.EventNewDomain:
+			progress.DistinctDomains++
+		}
+		progress.Line = int64(ev.Line)
+
+		if ev.Line-sent < s.cfg.ProgressEvery {
+			return nil
+		}
+		sent = ev.Line
+		return stream.Send(progress)
+	}))
+
+	result, err := customerimporter.ImportFromSourceWithStats(ctx, src, emailField, options...)
+	if result == nil {
+		return statusError(ctx, err)
+	}
+
+	// send exact counts of the result
+	progress.ValidEmails = int64(result.ValidEmails)
+	progress.InvalidEmails = int64(result.InvalidEmails)
+	progress.DuplicateEmails = int64(result.DuplicateEmails)
+	progress.DistinctDomains = int64(result.DistinctDomains)
+	progress.Result = response(result)
+	return stream.Send(progress)
+}
+
+// returns email field and options of the import
+func (s *Server) importOptions(o *importerpb.ImportOptions) (string, []customerimporter.Option) {
+	emailField := o.GetEmailField()
+	if emailField == "" {
+		emailField = "email"
+	}
+
+	options := append([]customerimporter.Option(nil), s.cfg.Options...)
+	if s.cfg.MaxRows > 0 {
+		options = append(options, customerimporter.WithMaxRows(s.cfg.MaxRows))
+	}
+	if s.cfg.MaxInputSize > 0 {
+		options = append(options, customerimporter.WithMaxBytes(s.cfg.MaxInputSize))
+	}
+	if o.GetSkipInvalid() {
+		options = append(options, customerimporter.SkipErrInvalidEmails())
+	}
+	if o.GetSkipDuplicates() {
+		options = append(options, customerimporter.SkipErrDuplicateEmails())
+	}
+
+	return emailField, options
+}
+
+// returns response of the import result
+func response(result *customerimporter.ImportResult) *importerpb.ImportResponse {
+	domains := make([]*importerpb.DomainCount, len(result.Domains))
+	for i, e := range result.Domains {
+		domains[i] = &importerpb.DomainCount{Domain: e.Domain, EmailsCount: int64(e.EmailsCount), Share: e.Share}
+	}
+
+	return &importerpb.ImportResponse{
+		Domains:         domains,
+		RowsRead:        int64(result.RowsRead),
+		ValidEmails:     int64(result.ValidEmails),
+		InvalidEmails:   int64(result.InvalidEmails),
+		DuplicateEmails: int64(result.DuplicateEmails),
+		DistinctDomains: int64(result.DistinctDomains),
+		ElapsedMs:       result.Elapsed.Milliseconds(),
+	}
+}
+
+// returns status of the error, errors caused by the imported data have
+// InvalidArgument code and their importer code in details
+func statusError(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.InvalidArgument
+	switch {
+	case errors.Is(err, customerimporter.ErrTooManyRows), errors.Is(err, customerimporter.ErrInputTooLarge):
+		code = codes.ResourceExhausted
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		code = codes.Canceled
+	case customerimporter.Code(err) == customerimporter.CodeUnknown:
+		code = codes.Unknown
+	}
+
+	st := status.New(code, err.Error())
+	if importerCode := customerimporter.Code(err); importerCode != customerimporter.CodeUnknown {
+		if detailed, detailsErr := st.WithDetails(&errdetails.ErrorInfo{Reason: string(importerCode), Domain: errorDomain}); detailsErr == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// chunkReader reads chunks of the uploaded data, it stops reading when
+// context is done, so the import is aborted on timeout
+type chunkReader struct {
+	ctx    context.Context
+	stream importerpb.Importer_ImportServer
+	chunk  []byte // unread bytes of the last chunk
+	eof    bool   // client closed the stream
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.ctx.Err(); err != nil {
+			return 0, err
+		}
+		req, err := r.stream.Recv()
+		if err == io.EOF {
+			r.eof = true
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("receive chunk: %w", err)
+		}
+		r.chunk = req.GetChunk()
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
@@ -0,0 +1,183 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+	"github.com/dreadfulangel/tw_t/grpc/importerpb"
+)
+
+// returns client of the server with the config served in memory
+func newClient(t *testing.T, cfg Config) importerpb.ImporterClient {
+	lis := bufconn.Listen(1 << 20)
+	server := NewServer(cfg)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpclib.NewClient("passthrough:///bufconn",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return importerpb.NewImporterClient(conn)
+}
+
+// returns importer code of the status error
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestImport(t *testing.T) {
+	chunks := []string{"name,mail\nA,a@a.io\nB,b@", "a.io\nC,a@b.io\n", "D,invalid\n"}
+
+	data := []struct {
+		name    string
+		options *importerpb.ImportOptions
+		config  Config
+		valid   int64
+		code    codes.Code
+		reason  customerimporter.ErrorCode
+	}{
+		{"successful import", &importerpb.ImportOptions{EmailField: "mail", SkipInvalid: true}, Config{}, 3, codes.OK, ""},
+		{"configured options", &importerpb.ImportOptions{EmailField: "mail"}, Config{Options: []customerimporter.Option{customerimporter.SkipErrInvalidEmails()}}, 3, codes.OK, ""},
+		{"invalid email", &importerpb.ImportOptions{EmailField: "mail"}, Config{}, 0, codes.InvalidArgument, customerimporter.CodeInvalidEmail},
+		{"missing field", nil, Config{}, 0, codes.InvalidArgument, customerimporter.CodeFieldMissing},
+		{"too many rows", &importerpb.ImportOptions{EmailField: "mail"}, Config{MaxRows: 2}, 0, codes.ResourceExhausted, customerimporter.CodeTooManyRows},
+		{"timeout", &importerpb.ImportOptions{EmailField: "mail"}, Config{Timeout: time.Nanosecond}, 0, codes.DeadlineExceeded, ""},
+	}
+
+	t.Log("Should import csv data uploaded in chunks")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		stream, err := newClient(t, d.config).Import(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, chunk := range chunks {
+			req := &importerpb.ImportRequest{Chunk: []byte(chunk)}
+			if i == 0 {
+				req.Options = d.options
+			}
+			if err := stream.Send(req); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+		}
+
+		response, err := stream.CloseAndRecv()
+		if status.Code(err) != d.code {
+			t.Errorf("should respond with %v, but got error %v", d.code, err)
+		}
+		if reason := errorReason(err); reason != string(d.reason) {
+			t.Errorf("should respond with reason %v, but got %v", d.reason, reason)
+		}
+		if err != nil {
+			continue
+		}
+		if response.GetValidEmails() != d.valid {
+			t.Errorf("should count %v valid emails, but got %v", d.valid, response.GetValidEmails())
+		}
+		if len(response.GetDomains()) != 2 || response.GetDomains()[0].GetDomain() != "a.io" || response.GetDomains()[0].GetEmailsCount() != 2 {
+			t.Errorf("should count emails by domain, but got %v", response.GetDomains())
+		}
+	}
+}
+
+func TestImportEmpty(t *testing.T) {
+	t.Log("Should import empty upload as empty file")
+
+	stream, err := newClient(t, Config{}).Import(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.CloseAndRecv()
+	if reason := errorReason(err); reason != string(customerimporter.CodeEmptyFile) {
+		t.Errorf("should respond with reason %v, but got error %v", customerimporter.CodeEmptyFile, err)
+	}
+}
+
+func TestImportSource(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "email\na@a.io\nb@a.io\ninvalid\na@b.io\n")
+	}))
+	defer ts.Close()
+
+	data := []struct {
+		name     string
+		url      string
+		config   Config
+		progress int
+		code     codes.Code
+	}{
+		{"progress of every line", ts.URL, Config{ProgressEvery: 1, Schemes: []string{"http"}}, 5, codes.OK},
+		{"result only", ts.URL, Config{Schemes: []string{"http"}}, 1, codes.OK},
+		{"no scheme allowed by default", ts.URL, Config{}, 0, codes.PermissionDenied},
+		{"scheme not allowed", "file:///etc/passwd", Config{Schemes: []string{"http", "https"}}, 0, codes.PermissionDenied},
+		{"scheme not registered", "ftp://example.com/customers.csv", Config{Schemes: []string{"ftp"}}, 0, codes.InvalidArgument},
+	}
+
+	t.Log("Should stream progress of the source import")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		stream, err := newClient(t, d.config).ImportSource(context.Background(), &importerpb.ImportSourceRequest{
+			Url:     d.url,
+			Options: &importerpb.ImportOptions{SkipInvalid: true},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var messages []*importerpb.ImportProgress
+		for {
+			progress, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if status.Code(err) != d.code {
+					t.Errorf("should respond with %v, but got error %v", d.code, err)
+				}
+				break
+			}
+			messages = append(messages, progress)
+		}
+		if len(messages) != d.progress {
+			t.Errorf("should send %v messages, but got %v", d.progress, len(messages))
+		}
+		if len(messages) == 0 {
+			continue
+		}
+
+		last := messages[len(messages)-1]
+		if last.GetResult().GetValidEmails() != 3 || last.GetInvalidEmails() != 1 || last.GetDistinctDomains() != 2 {
+			t.Errorf("should send result in the last message, but got %v", last)
+		}
+		for _, progress := range messages[:len(messages)-1] {
+			if progress.GetResult() != nil {
+				t.Errorf("should send result in the last message only, but got %v", progress)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package customerimporter
+
+import (
+	"crypto"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrHashUnavailable is raised when the algorithm of HashEmails isn't linked
+// into the binary
+var ErrHashUnavailable = errors.New("Hash algorithm is not available")
+
+// Remember digests of emails instead of the addresses to catch duplicates,
+// e.g. crypto.SHA256, and mask emails of samples, collected errors and logs
+// as m***@github.io, so plain-text emails aren't kept in memory. Events of
+// ImportStream still carry the emails. SHA-256 and SHA-512 are available,
+// other algorithms have to be imported by the caller.
+func HashEmails(algorithm crypto.Hash) Option {
+	return func(f *CustomerImporter) { f.hash = algorithm }
+}
+
+// returns key of the email in the dedup store, hex digest if HashEmails is
+// used, so it's safe to save in a checkpoint
+func (c *CustomerImporter) dedupKey(email string) (string, error) {
+	if c.hash == 0 {
+		return email, nil
+	}
+	if !c.hash.Available() {
+		return "", fmt.Errorf("%w %v", ErrHashUnavailable, c.hash)
+	}
+
+	h := c.hash.New()
+	h.Write([]byte(email))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// returns value of the email field kept in the result, masked if HashEmails
+// is used
+func (c *CustomerImporter) display(value string) string {
+	if c.hash == 0 {
+		return value
+	}
+	return maskEmail(value)
+}
+
+// masks local part of the email except its first character, values which
+// aren't emails are masked the same way
+func maskEmail(value string) string {
+	at := strings.LastIndexByte(value, '@')
+	if at < 0 {
+		at = len(value)
+	}
+	_, size := utf8.DecodeRuneInString(value[:at])
+	return value[:size] + "***" + value[at:]
+}
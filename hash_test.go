@@ -0,0 +1,84 @@
+package customerimporter
+
+import (
+	"crypto"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestHashEmails(t *testing.T) {
+	records := "email\n" +
+		"mhernandez@github.io\n" +
+		"MHernandez@GitHub.io\n" +
+		"invalid\n" +
+		"bortiz@github.io\n"
+
+	t.Log("Should catch duplicates by digests and mask emails")
+	result, err := ImportWithStats(strings.NewReader(records), "email",
+		HashEmails(crypto.SHA256), CaseInsensitiveEmails(), CollectErrors(), WithSamples(1))
+	var rowErrors RowErrors
+	if !errors.As(err, &rowErrors) {
+		t.Fatalf("should return collected errors, but got error %v", err)
+	}
+	if result.ValidEmails != 2 || result.DuplicateEmails != 1 {
+		t.Errorf("should count 2 emails and skip 1 duplicate, but got %+v", result)
+	}
+	if len(rowErrors) != 2 || rowErrors[0].Value != "M***@GitHub.io" || rowErrors[1].Value != "i***" {
+		t.Errorf("should mask values of errors, but got %v", rowErrors)
+	}
+	if samples := result.Domains[0].Samples; len(samples) != 1 || samples[0].Email != "m***@github.io" {
+		t.Errorf("should mask samples, but got %v", samples)
+	}
+
+	t.Log("Should save digests instead of emails in checkpoint")
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+	interrupted := io.MultiReader(strings.NewReader("email\nmhernandez@github.io\n"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	if _, err := Import(interrupted, "email", HashEmails(crypto.SHA256), WithCheckpoint(path, 1)); err == nil {
+		t.Fatal("should raise error of interrupted import")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "mhernandez") {
+		t.Errorf("should not save plain-text emails, but got %s", data)
+	}
+	resumed := strings.NewReader("email\nmhernandez@github.io\nmhernandez@github.io\n")
+	if _, err := Import(resumed, "email", HashEmails(crypto.SHA256), WithCheckpoint(path, 1)); !errors.Is(err, ErrEmailDuplicate) {
+		t.Errorf("should catch duplicate of resumed import, but got error %v", err)
+	}
+
+	t.Log("Should raise error if the algorithm isn't available")
+	if !crypto.RIPEMD160.Available() {
+		if _, err := Import(strings.NewReader(records), "email", HashEmails(crypto.RIPEMD160)); err == nil || !strings.Contains(err.Error(), ErrHashUnavailable.Error()) {
+			t.Errorf("should raise error: %v, but got error %v", ErrHashUnavailable, err)
+		}
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	data := []struct {
+		value    string
+		expected string
+	}{
+		{"mhernandez@github.io", "m***@github.io"},
+		{"žofia@example.sk", "ž***@example.sk"},
+		{"@github.io", "***@github.io"},
+		{"invalid", "i***"},
+		{"", "***"},
+	}
+
+	t.Log("Should mask local part of emails")
+	for _, d := range data {
+		t.Logf("Case: %v", d.value)
+
+		if masked := maskEmail(d.value); masked != d.expected {
+			t.Errorf("should mask as %v, but got %v", d.expected, masked)
+		}
+	}
+}
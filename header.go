@@ -0,0 +1,138 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrAmbiguousField is raised when several header fields match the field name
+var ErrAmbiguousField = errors.New("CSV header contains several fields matching")
+
+// ErrHeaderMismatch is raised when the header doesn't match the header set by
+// WithExpectedHeader
+var ErrHeaderMismatch = errors.New("CSV header doesn't match expected header")
+
+// HeaderMatch is the way the header is compared to the expected header
+type HeaderMatch int
+
+const (
+	HeaderExact  HeaderMatch = iota // header has exactly the expected fields in their order
+	HeaderSubset                    // header has all expected fields in any order, other fields are allowed
+)
+
+// Match header fields case-insensitively, e.g. Email matches email field.
+func CaseInsensitiveHeader() Option {
+	return func(f *CustomerImporter) { f.caseInsensitiveHeader = true }
+}
+
+// Ignore leading and trailing whitespace of header fields.
+func TrimHeader() Option { return func(f *CustomerImporter) { f.trimHeader = true } }
+
+// Accept the aliases as the name of the email field, e.g. "e-mail" or
+// "mail". The header must contain only one field matching the name or aliases.
+func WithFieldAliases(aliases ...string) Option {
+	return func(f *CustomerImporter) { f.fieldAliases = append(f.fieldAliases, aliases...) }
+}
+
+// Use the first of the candidates found in the header as the email field if
+// the header has no field matching the email field name or its aliases, e.g.
+// "Email" and "E-mail" of files of systems that disagree on the name. Unlike
+// aliases, header may contain several candidates, the earlier candidate wins.
+func WithFieldCandidates(candidates ...string) Option {
+	return func(f *CustomerImporter) { f.fieldCandidates = append(f.fieldCandidates, candidates...) }
+}
+
+// Fail before reading records if the header doesn't match the expected
+// fields, e.g. to catch columns renamed, added or removed by the vendor of
+// the export. Header with duplicate field names never matches. Error lists
+// the missing, unexpected and duplicate fields. Fields are compared like the
+// email field, see CaseInsensitiveHeader and TrimHeader.
+func WithExpectedHeader(fields []string, match HeaderMatch) Option {
+	return func(f *CustomerImporter) {
+		f.expectedHeader = slices.Clone(fields)
+		f.headerMatch = match
+	}
+}
+
+// checks the header against the expected header
+func (c *CustomerImporter) checkHeader(headerRecord []string) error {
+	if c.expectedHeader == nil {
+		return nil
+	}
+
+	var missing, unexpected, duplicate []string
+	for _, field := range c.expectedHeader {
+		if !slices.ContainsFunc(headerRecord, func(f string) bool { return c.matchField(f, []string{field}) }) {
+			missing = append(missing, field)
+		}
+	}
+	for i, field := range headerRecord {
+		if c.headerMatch == HeaderExact && !c.matchField(field, c.expectedHeader) {
+			unexpected = append(unexpected, field)
+		}
+		if slices.ContainsFunc(headerRecord[:i], func(f string) bool { return c.matchField(f, []string{field}) }) &&
+			!slices.Contains(duplicate, field) {
+			duplicate = append(duplicate, field)
+		}
+	}
+
+	// fields of exact header must be in the expected order
+	misordered := false
+	if c.headerMatch == HeaderExact && len(missing)+len(unexpected)+len(duplicate) == 0 {
+		misordered = len(headerRecord) != len(c.expectedHeader)
+		for i := 0; i < len(headerRecord) && !misordered; i++ {
+			misordered = !c.matchField(headerRecord[i], c.expectedHeader[i:i+1])
+		}
+	}
+
+	var diff []string
+	if len(missing) > 0 {
+		diff = append(diff, fmt.Sprintf("missing %q", missing))
+	}
+	if len(unexpected) > 0 {
+		diff = append(diff, fmt.Sprintf("unexpected %q", unexpected))
+	}
+	if len(duplicate) > 0 {
+		diff = append(diff, fmt.Sprintf("duplicate %q", duplicate))
+	}
+	if misordered {
+		diff = append(diff, fmt.Sprintf("order %q, expected %q", headerRecord, c.expectedHeader))
+	}
+	if len(diff) == 0 {
+		return nil
+	}
+	return c.detailedError(ErrHeaderMismatch, ": "+strings.Join(diff, ", "))
+}
+
+// returns index of the only header field matching one of the names
+func (c *CustomerImporter) fieldIndex(headerRecord []string, names ...string) (int, error) {
+	index := -1
+	for i, field := range headerRecord {
+		if !c.matchField(field, names) {
+			continue
+		}
+		if index >= 0 {
+			return -1, c.detailedError(ErrAmbiguousField, fmt.Sprintf(" %s: %q and %q", names[0], headerRecord[index], field))
+		}
+		index = i
+	}
+	if index < 0 {
+		return -1, c.detailedError(ErrFieldNotExists, fmt.Sprintf(" %s field", names[0]))
+	}
+	return index, nil
+}
+
+// reports whether the header field matches one of the names
+func (c *CustomerImporter) matchField(field string, names []string) bool {
+	if c.trimHeader {
+		field = strings.TrimSpace(field)
+	}
+	for _, name := range names {
+		if field == name || (c.caseInsensitiveHeader && strings.EqualFold(field, name)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package customerimporter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHeaderMatching(t *testing.T) {
+	data := []struct {
+		header  string
+		options []Option
+		err     error
+	}{
+		// exact match
+		{"name,email", nil, nil},
+		{"name,Email", nil, ErrFieldNotExists},
+		{"name, email ", nil, ErrFieldNotExists},
+		{"email,email", nil, ErrAmbiguousField},
+
+		// relaxed matching
+		{"name,Email", []Option{CaseInsensitiveHeader()}, nil},
+		{"name, email ", []Option{TrimHeader()}, nil},
+		{"name, EMAIL", []Option{TrimHeader(), CaseInsensitiveHeader()}, nil},
+		{"Email,email", []Option{CaseInsensitiveHeader()}, ErrAmbiguousField},
+
+		// aliases
+		{"name,e-mail", []Option{WithFieldAliases("mail", "e-mail")}, nil},
+		{"name,Mail", []Option{WithFieldAliases("mail"), CaseInsensitiveHeader()}, nil},
+		{"email,mail", []Option{WithFieldAliases("mail")}, ErrAmbiguousField},
+		{"name,phone", []Option{WithFieldAliases("mail")}, ErrFieldNotExists},
+	}
+
+	t.Log("Should find email column by the header")
+	for _, d := range data {
+		t.Logf("Case: %v", d.header)
+
+		result, err := Import(strings.NewReader(d.header+"\nA,a@a.io\n"), "email", d.options...)
+		if d.err != nil {
+			if !errors.Is(err, d.err) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1, Share: 1}}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("should return %v, but got %v", expected, *result)
+		}
+	}
+}
+
+func TestWithExpectedHeader(t *testing.T) {
+	expected := []string{"name", "email", "status"}
+	data := []struct {
+		header  string
+		match   HeaderMatch
+		options []Option
+		detail  string
+	}{
+		// exact match
+		{"name,email,status", HeaderExact, nil, ""},
+		{"name,email", HeaderExact, nil, `missing ["status"]`},
+		{"name,email,status,phone", HeaderExact, nil, `unexpected ["phone"]`},
+		{"email,name,status", HeaderExact, nil, `order ["email" "name" "status"], expected ["name" "email" "status"]`},
+		{"name,email,status,name", HeaderExact, nil, `duplicate ["name"]`},
+		{"Name, email,status", HeaderExact, []Option{CaseInsensitiveHeader(), TrimHeader()}, ""},
+		{"Name,email", HeaderExact, nil, `missing ["name" "status"], unexpected ["Name"]`},
+
+		// subset match
+		{"status,phone,email,name", HeaderSubset, nil, ""},
+		{"phone,email", HeaderSubset, nil, `missing ["name" "status"]`},
+		{"name,email,status,phone,phone", HeaderSubset, nil, `duplicate ["phone"]`},
+	}
+
+	t.Log("Should check header against the expected header")
+	for _, d := range data {
+		t.Logf("Case: %v", d.header)
+
+		options := append([]Option{WithExpectedHeader(expected, d.match)}, d.options...)
+		_, err := Import(strings.NewReader(d.header+"\na@a.io,a@a.io,a@a.io,a@a.io,a@a.io\n"), "email", append(options, VariableFieldCount())...)
+		if d.detail == "" {
+			if err != nil {
+				t.Errorf("should not raise error, but got error %v", err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrHeaderMismatch) || !strings.Contains(err.Error(), d.detail) {
+			t.Errorf("should raise error: %v with %s, but got error %v", ErrHeaderMismatch, d.detail, err)
+		}
+	}
+
+	t.Log("Should keep fields out of redacted error")
+	_, err := Import(strings.NewReader("name,email,secret\nA,a@a.io,x\n"), "email", WithExpectedHeader(expected, HeaderExact), RedactErrors())
+	if !errors.Is(err, ErrHeaderMismatch) || strings.Contains(err.Error(), "secret") {
+		t.Errorf("should raise redacted error: %v, but got error %v", ErrHeaderMismatch, err)
+	}
+}
+
+func TestWithFieldCandidates(t *testing.T) {
+	data := []struct {
+		header  string
+		options []Option
+		column  int
+		err     error
+	}{
+		{"name,email,Email", []Option{WithFieldCandidates("Email")}, 1, nil},
+		{"name,E-mail,Email", []Option{WithFieldCandidates("Email", "E-mail")}, 2, nil},
+		{"name,E-mail,Email", []Option{WithFieldCandidates("E-mail", "Email")}, 1, nil},
+		{"name,mail,Email", []Option{WithFieldCandidates("Email"), WithFieldAliases("mail")}, 1, nil},
+		{"name,EMAIL", []Option{WithFieldCandidates("Email", "e-mail"), CaseInsensitiveHeader()}, 1, nil},
+		{"name,Email,EMAIL", []Option{WithFieldCandidates("Email"), CaseInsensitiveHeader()}, 0, ErrAmbiguousField},
+		{"name,phone", []Option{WithFieldCandidates("Email", "E-mail")}, 0, ErrFieldNotExists},
+	}
+
+	t.Log("Should use the first candidate found in the header")
+	for _, d := range data {
+		t.Logf("Case: %v", d.header)
+
+		// only the expected column contains valid email
+		fields := strings.Split(d.header, ",")
+		record := make([]string, len(fields))
+		for i := range record {
+			record[i] = "invalid"
+		}
+		record[d.column] = "a@a.io"
+
+		result, err := Import(strings.NewReader(d.header+"\n"+strings.Join(record, ",")+"\n"), "email", d.options...)
+		if d.err != nil {
+			if !errors.Is(err, d.err) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1, Share: 1}}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("should return %v, but got %v", expected, *result)
+		}
+	}
+}
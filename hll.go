@@ -0,0 +1,169 @@
+package customerimporter
+
+import (
+	"math"
+	"math/bits"
+)
+
+// bounds of the precision of HyperLogLog sketches
+const (
+	minHLLPrecision     = 4
+	maxHLLPrecision     = 16
+	defaultHLLPrecision = 12
+)
+
+// Count unique emails of every domain approximately by HyperLogLog sketches
+// of 2^precision registers instead of remembering counted emails, so memory
+// usage depends on amount of domains but not on amount of emails. Counts of
+// domains have relative standard error 1.04/sqrt(2^precision), which is
+// reported as ImportResult.ErrorMargin, e.g. 1.6% for precision 12.
+// Duplicates are not reported for records, ValidEmails is the sum of the
+// estimated counts and DuplicateEmails the rest of valid emails. Precision is
+// clamped between 4 and 16, 12 is used if it's 0. Checkpoints are not
+// supported.
+func ApproximateCounts(precision int) Option {
+	return func(f *CustomerImporter) {
+		if precision == 0 {
+			precision = defaultHLLPrecision
+		}
+		f.hllPrecision = min(max(precision, minHLLPrecision), maxHLLPrecision)
+		f.sketches = make(map[string]*hyperLogLog)
+	}
+}
+
+// reports whether emails are counted by sketches, values counted by
+// ImportGroupBy are always exact
+func (c *CustomerImporter) approximate() bool { return c.sketches != nil && !c.countValues }
+
+// adds email to the sketch of its domain
+func (c *CustomerImporter) addToSketch(email, domain string) {
+	if c.canonicalizeEmails {
+		email = canonicalEmail(email)
+	}
+	sketch, ok := c.sketches[domain]
+	if !ok {
+		sketch = newHyperLogLog(c.hllPrecision)
+		c.sketches[domain] = sketch
+	}
+	sketch.add(mix64(fnv1a(email)))
+}
+
+// returns estimated counts of the domains, amount of counted and duplicate
+// emails
+func (c *CustomerImporter) estimateCounts() (map[string]int, int, int) {
+	counts := make(map[string]int, len(c.sketches))
+	valid := 0
+	for domain, sketch := range c.sketches {
+		counts[domain] = sketch.estimate()
+		valid += counts[domain]
+	}
+	return counts, valid, c.duplicateEmails + max(c.validEmails-valid, 0)
+}
+
+// returns relative standard error of the estimated counts
+func (c *CustomerImporter) errorMargin() float64 {
+	if c.hllPrecision == 0 {
+		return 0
+	}
+	return 1.04 / math.Sqrt(float64(uint64(1)<<c.hllPrecision))
+}
+
+// hyperLogLog estimates amount of distinct hashes. Registers of small sketches
+// are kept sparse, sketch is converted to dense registers when they take
+// less memory.
+type hyperLogLog struct {
+	precision int      // amount of index bits of hash
+	sparse    []uint32 // index and value of set registers, until dense is used
+	dense     []uint8  // all registers, nil if sparse
+}
+
+// returns empty sketch of 2^precision registers
+func newHyperLogLog(precision int) *hyperLogLog {
+	return &hyperLogLog{precision: precision}
+}
+
+// adds hash to the sketch
+func (h *hyperLogLog) add(hash uint64) {
+	index := uint32(hash >> (64 - h.precision))
+	rank := uint8(bits.LeadingZeros64(hash<<h.precision|1<<(h.precision-1)) + 1)
+	h.set(index, rank)
+}
+
+// sets register to rank if it's greater
+func (h *hyperLogLog) set(index uint32, rank uint8) {
+	if h.dense != nil {
+		h.dense[index] = max(h.dense[index], rank)
+		return
+	}
+
+	for i, entry := range h.sparse {
+		if entry>>8 == index {
+			h.sparse[i] = index<<8 | uint32(max(uint8(entry), rank))
+			return
+		}
+	}
+	h.sparse = append(h.sparse, index<<8|uint32(rank))
+
+	// sparse entries take 4 bytes, dense registers 1 byte
+	if m := 1 << h.precision; len(h.sparse)*4 > m {
+		h.dense = make([]uint8, m)
+		for _, entry := range h.sparse {
+			h.dense[entry>>8] = uint8(entry)
+		}
+		h.sparse = nil
+	}
+}
+
+// adds registers of the other sketch of the same precision
+func (h *hyperLogLog) merge(other *hyperLogLog) {
+	for index, rank := range other.dense {
+		if rank > 0 {
+			h.set(uint32(index), rank)
+		}
+	}
+	for _, entry := range other.sparse {
+		h.set(entry>>8, uint8(entry))
+	}
+}
+
+// returns estimated amount of distinct hashes, linear counting is used for
+// small cardinalities
+func (h *hyperLogLog) estimate() int {
+	m := float64(uint64(1) << h.precision)
+
+	// sum of 2^-register, zero registers add 1
+	sum, zeros := 0.0, 0
+	if h.dense != nil {
+		for _, rank := range h.dense {
+			sum += math.Ldexp(1, -int(rank))
+			if rank == 0 {
+				zeros++
+			}
+		}
+	} else {
+		zeros = int(m) - len(h.sparse)
+		sum = float64(zeros)
+		for _, entry := range h.sparse {
+			sum += math.Ldexp(1, -int(uint8(entry)))
+		}
+	}
+
+	estimate := hllAlpha(m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int(math.Round(estimate))
+}
+
+// returns bias correction constant for m registers
+func hllAlpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/m)
+}
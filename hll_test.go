@@ -0,0 +1,108 @@
+package customerimporter
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApproximateCounts(t *testing.T) {
+	records := generateRecords(20000)
+	options := []Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails()}
+
+	expected, err := ImportWithStats(strings.NewReader(records), "email", options...)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+
+	t.Log("Should estimate counts within the error margin")
+	result, err := ImportWithStats(strings.NewReader(records), "email", append(options, ApproximateCounts(12))...)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if result.ErrorMargin != 1.04/64 {
+		t.Errorf("should report error margin %v, but got %v", 1.04/64, result.ErrorMargin)
+	}
+	if len(result.Domains) != len(expected.Domains) {
+		t.Fatalf("should result with %v domains, but got %v", len(expected.Domains), len(result.Domains))
+	}
+	for i, e := range expected.Domains {
+		d := result.Domains[i]
+		if d.Domain != e.Domain || math.Abs(float64(d.EmailsCount-e.EmailsCount)) > 4*result.ErrorMargin*float64(e.EmailsCount) {
+			t.Errorf("should estimate %v, but got %v", e, d)
+		}
+	}
+	if result.ValidEmails+result.DuplicateEmails != expected.ValidEmails+expected.DuplicateEmails {
+		t.Errorf("should split %v valid emails, but got %v and %v duplicates", expected.ValidEmails+expected.DuplicateEmails, result.ValidEmails, result.DuplicateEmails)
+	}
+
+	t.Log("Should estimate the same counts in chunks")
+	fileName := filepath.Join(t.TempDir(), "customers.csv")
+	if err := os.WriteFile(fileName, []byte(records), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	chunked, err := ImportFromFileWithStats(fileName, "email", append(options, ApproximateCounts(12), WithChunks(4))...)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	chunked.Elapsed = result.Elapsed
+	if !reflect.DeepEqual(chunked, result) {
+		t.Errorf("should result with: %+v, but got %+v", result, chunked)
+	}
+
+	t.Log("Should not raise error on duplicates")
+	domains, err := Import(strings.NewReader("email\na@a.io\nA@A.io\nb@a.io\n"), "email", ApproximateCounts(0), CaseInsensitiveEmails())
+	if err != nil || len(*domains) != 1 || (*domains)[0].EmailsCount != 2 {
+		t.Errorf("should count 2 emails, but got %v, %v", domains, err)
+	}
+}
+
+func TestHyperLogLog(t *testing.T) {
+	tests := []struct {
+		precision int
+		n         int
+	}{
+		{12, 1},
+		{12, 100},
+		{12, 1000},
+		{12, 100000},
+		{4, 10000},
+		{16, 100000},
+	}
+
+	t.Log("Should estimate amount of distinct items within the error")
+	for _, test := range tests {
+		t.Logf("Case: %v", test)
+
+		h := newHyperLogLog(test.precision)
+		for i := 0; i < test.n; i++ {
+			hash := mix64(fnv1a(fmt.Sprintf("user%d@example.com", i)))
+			h.add(hash)
+			h.add(hash)
+		}
+		margin := 4 * 1.04 / math.Sqrt(float64(uint64(1)<<test.precision))
+		if estimate := h.estimate(); math.Abs(float64(estimate-test.n)) > margin*float64(test.n) {
+			t.Errorf("should estimate %v, but got %v", test.n, estimate)
+		}
+	}
+
+	t.Log("Should merge sketches to sketch of all items")
+	all, a, b := newHyperLogLog(10), newHyperLogLog(10), newHyperLogLog(10)
+	for i := 0; i < 5000; i++ {
+		hash := mix64(fnv1a(fmt.Sprintf("user%d@example.com", i)))
+		all.add(hash)
+		if i%2 == 0 {
+			a.add(hash)
+		} else {
+			b.add(hash)
+		}
+	}
+	a.merge(b)
+	if a.estimate() != all.estimate() {
+		t.Errorf("should estimate %v, but got %v", all.estimate(), a.estimate())
+	}
+}
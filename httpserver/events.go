@@ -0,0 +1,107 @@
+package httpserver
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// amount of domains in progress events
+const topDomains = 10
+
+// progressJSON is JSON representation of a progress event of the job
+type progressJSON struct {
+	jobJSON
+	RowsPerSecond  float64                                `json:"rows_per_second"`
+	BytesPerSecond float64                                `json:"bytes_per_second"`
+	TopDomains     customerimporter.EmailsByDomainQtyList `json:"top_domains"`
+}
+
+// returns progress of the job with domains of the most emails counted so far
+func (j *job) progress() progressJSON {
+	p := progressJSON{jobJSON: j.snapshot()}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// throughput since the job was created
+	end := time.Now()
+	if !j.finished.IsZero() {
+		end = j.finished
+	}
+	if elapsed := end.Sub(j.created).Seconds(); elapsed > 0 {
+		p.RowsPerSecond = float64(p.Rows) / elapsed
+		p.BytesPerSecond = float64(p.BytesRead) / elapsed
+	}
+
+	p.TopDomains = make(customerimporter.EmailsByDomainQtyList, 0, len(j.domains))
+	for domain, count := range j.domains {
+		p.TopDomains = append(p.TopDomains, customerimporter.EmailsByDomainQty{Domain: domain, EmailsCount: count})
+	}
+	slices.SortFunc(p.TopDomains, func(a, b customerimporter.EmailsByDomainQty) int {
+		if c := cmp.Compare(b.EmailsCount, a.EmailsCount); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Domain, b.Domain)
+	})
+	if len(p.TopDomains) > topDomains {
+		p.TopDomains = p.TopDomains[:topDomains]
+	}
+	return p
+}
+
+// streams progress of the job as Server-Sent Events every ProgressInterval.
+// Events of the running job are named progress, the last event is named by
+// status of the finished job, done, partial or failed, and ends the stream.
+func (s *Server) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.lookupJob(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	rc := http.NewResponseController(w)
+
+	ticker := time.NewTicker(s.cfg.ProgressInterval)
+	defer ticker.Stop()
+	for {
+		p := j.progress()
+		event := "progress"
+		if p.Status != jobRunning {
+			event = p.Status
+		}
+		if err := writeEvent(w, event, p); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		if event != "progress" {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-j.done:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writes value as JSON data of the named event
+func writeEvent(w io.Writer, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,76 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func TestJobProgress(t *testing.T) {
+	t.Log("Should report domains of the most emails counted so far")
+
+	j := newJob("id", 100)
+	for i := range 12 {
+		for line := range i + 1 {
+			j.track(customerimporter.Event{Type: customerimporter.EventValidEmail, Line: 100*i + line + 1, Domain: fmt.Sprintf("d%02d.io", i)})
+		}
+	}
+
+	p := j.progress()
+	if len(p.TopDomains) != topDomains {
+		t.Fatalf("should report %v domains, but got %v", topDomains, p.TopDomains)
+	}
+	if p.TopDomains[0].Domain != "d11.io" || p.TopDomains[0].EmailsCount != 12 || p.TopDomains[9].Domain != "d02.io" {
+		t.Errorf("should report domains by emails count, but got %v", p.TopDomains)
+	}
+	if p.Rows != 78 || p.ValidEmails != 78 || p.RowsPerSecond <= 0 {
+		t.Errorf("should report processed rows, but got %v", p.jobJSON)
+	}
+}
+
+func TestJobEvents(t *testing.T) {
+	data := []struct {
+		name   string
+		finish func(j *job)
+		events []string
+	}{
+		{"done", func(j *job) { j.finish(&customerimporter.ImportResult{ValidEmails: 1}, nil) }, []string{"event: progress", "event: done", `"valid_emails":1`}},
+		{"partial", func(j *job) {
+			j.finish(&customerimporter.ImportResult{ValidEmails: 1, Partial: true}, customerimporter.ErrTooManyRows)
+		}, []string{"event: progress", "event: partial", `"valid_emails":1`, `"code":"E_TOO_MANY_ROWS"`}},
+		{"failed", func(j *job) { j.finish(nil, customerimporter.ErrEmailIsNotValid) }, []string{"event: progress", "event: failed", `"code":"E_INVALID_EMAIL"`}},
+	}
+
+	t.Log("Should stream progress until the job finishes")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		s := New(Config{ProgressInterval: time.Millisecond})
+		j := newJob("id", 100)
+		s.jobs[j.id] = j
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			d.finish(j)
+		}()
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/id/events", nil))
+		if w.Header().Get("Content-Type") != "text/event-stream" {
+			t.Errorf("should respond with event stream, but got %v", w.Header().Get("Content-Type"))
+		}
+		for _, event := range d.events {
+			if !strings.Contains(w.Body.String(), event) {
+				t.Errorf("should stream: %v, but got %v", event, w.Body.String())
+			}
+		}
+		if !strings.HasSuffix(w.Body.String(), "}\n\n") {
+			t.Errorf("should end stream with the last event, but got %v", w.Body.String())
+		}
+		s.Close()
+	}
+}
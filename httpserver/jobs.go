@@ -0,0 +1,322 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// default limits of the jobs
+const (
+	DefaultMaxJobUploadSize = 10 << 30 // 10 GiB
+	DefaultJobTimeout       = time.Hour
+	DefaultJobTTL           = time.Hour
+	DefaultProgressInterval = time.Second
+)
+
+// status of a job
+const (
+	jobRunning = "running"
+	jobDone    = "done"
+	jobPartial = "partial" // aborted by error with result of the records counted before it
+	jobFailed  = "failed"
+)
+
+// job is an import running in the background
+type job struct {
+	id         string
+	bytesTotal int64         // size of the uploaded file
+	bytesRead  atomic.Int64  // bytes of the file read by the import
+	done       chan struct{} // closed when the job finishes
+
+	mu       sync.Mutex
+	status   string
+	created  time.Time
+	finished time.Time
+	line     int                            // line of the last processed record
+	rows     int                            // records processed so far
+	valid    int                            // emails counted so far
+	domains  map[string]int                 // emails counted so far by domain
+	result   *customerimporter.ImportResult // result of the finished job
+	err      error                          // error of the failed job or returned with the result
+}
+
+// returns running job of the upload
+func newJob(id string, size int64) *job {
+	return &job{
+		id:         id,
+		bytesTotal: size,
+		done:       make(chan struct{}),
+		status:     jobRunning,
+		created:    time.Now(),
+		domains:    make(map[string]int),
+	}
+}
+
+// jobJSON is JSON representation of the job status
+type jobJSON struct {
+	ID          string                     `json:"id"`
+	Status      string                     `json:"status"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	FinishedAt  *time.Time                 `json:"finished_at,omitempty"`
+	BytesRead   int64                      `json:"bytes_read"`
+	BytesTotal  int64                      `json:"bytes_total"`
+	Line        int                        `json:"line"`
+	Rows        int                        `json:"rows_processed"`
+	ValidEmails int                        `json:"valid_emails"`
+	Error       string                     `json:"error,omitempty"`
+	Code        customerimporter.ErrorCode `json:"code,omitempty"`
+}
+
+// returns status and progress of the job
+func (j *job) snapshot() jobJSON {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	v := jobJSON{
+		ID:          j.id,
+		Status:      j.status,
+		CreatedAt:   j.created,
+		BytesRead:   j.bytesRead.Load(),
+		BytesTotal:  j.bytesTotal,
+		Line:        j.line,
+		Rows:        j.rows,
+		ValidEmails: j.valid,
+	}
+	if !j.finished.IsZero() {
+		finished := j.finished
+		v.FinishedAt = &finished
+	}
+	if j.err != nil {
+		v.Error = j.err.Error()
+		if code := customerimporter.Code(j.err); code != customerimporter.CodeUnknown {
+			v.Code = code
+		}
+	}
+	return v
+}
+
+// updates progress of the job by the event of the import
+func (j *job) track(ev customerimporter.Event) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if ev.Line != j.line {
+		j.line = ev.Line
+		j.rows++
+	}
+	if ev.Type == customerimporter.EventValidEmail {
+		j.valid++
+		j.domains[ev.Domain]++
+	}
+	return nil
+}
+
+// sets result and error of the finished import, error returned with the
+// result is kept, e.g. collected errors or error aborting partial result
+func (j *job) finish(result *customerimporter.ImportResult, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	defer close(j.done)
+
+	j.finished = time.Now()
+	j.err = err
+	if result == nil {
+		j.status = jobFailed
+		return
+	}
+	j.status, j.result, j.valid = jobDone, result, result.ValidEmails
+	if result.Partial {
+		j.status = jobPartial
+	}
+}
+
+// creates a job importing csv file uploaded as multipart form field "file" in
+// the background. The file is saved to a temporary file before the response,
+// so the import doesn't depend on the connection. Query parameters are the
+// same as of /import.
+func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	// import options
+	emailField, options, err := s.importOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// save the upload
+	r.Body = http.MaxBytesReader(w, r.Body, s.cfg.MaxJobUploadSize)
+	file, err := formFile(r)
+	if err != nil {
+		writeError(w, errorStatus(r.Context(), err, http.StatusBadRequest), err)
+		return
+	}
+	tmp, err := os.CreateTemp("", "customerimporter-job-*")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	size, err := io.Copy(tmp, file)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		writeError(w, errorStatus(r.Context(), err, http.StatusBadRequest), err)
+		return
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	j := newJob(id, size)
+	if !s.addJob(j) {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		writeError(w, http.StatusServiceUnavailable, errors.New("server is closed"))
+		return
+	}
+	go s.runJob(j, tmp, emailField, options)
+
+	w.Header().Set("Location", "/jobs/"+id)
+	writeJSON(w, http.StatusAccepted, j.snapshot())
+}
+
+// imports the uploaded file and removes it
+func (s *Server) runJob(j *job, file *os.File, emailField string, options []customerimporter.Option) {
+	defer s.wg.Done()
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.cfg.JobTimeout)
+	defer cancel()
+
+	options = append(options, customerimporter.WithEventHandler(j.track))
+	r := &contextReader{ctx: ctx, r: &countingReader{r: file, n: &j.bytesRead}}
+	j.finish(customerimporter.ImportWithStats(r, emailField, options...))
+}
+
+// responds with status and progress of the job
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.lookupJob(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, j.snapshot())
+}
+
+// responds with result of the finished job, 409 Conflict while the job is
+// running
+func (s *Server) handleJobResult(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.lookupJob(w, r)
+	if !ok {
+		return
+	}
+
+	j.mu.Lock()
+	status, result, err := j.status, j.result, j.err
+	j.mu.Unlock()
+
+	switch status {
+	case jobRunning:
+		writeError(w, http.StatusConflict, errors.New("job is running"))
+	case jobFailed:
+		writeError(w, errorStatus(context.Background(), err, http.StatusUnprocessableEntity), err)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		result.WriteJSON(w, false)
+	}
+}
+
+// returns job of the id path value, responds with error if the method isn't
+// GET or the job doesn't exist
+func (s *Server) lookupJob(w http.ResponseWriter, r *http.Request) (*job, bool) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return nil, false
+	}
+
+	s.jobsMu.Lock()
+	j, ok := s.jobs[r.PathValue("id")]
+	s.jobsMu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("job not found"))
+	}
+	return j, ok
+}
+
+// adds the job to be run and removes jobs finished longer than JobTTL ago,
+// returns false if the server is closed
+func (s *Server) addJob(j *job) bool {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	for id, other := range s.jobs {
+		other.mu.Lock()
+		expired := !other.finished.IsZero() && time.Since(other.finished) > s.cfg.JobTTL
+		other.mu.Unlock()
+		if expired {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[j.id] = j
+	s.wg.Add(1)
+	return true
+}
+
+// Close cancels running jobs and waits until they finish, new jobs are
+// rejected with 503 Service Unavailable
+func (s *Server) Close() error {
+	s.jobsMu.Lock()
+	s.closed = true
+	s.jobsMu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// returns random id of a job
+func newJobID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// countingReader counts bytes read from r
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,170 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// creates job of the upload and returns its status
+func createJob(t *testing.T, s *Server, query, data string) (int, jobJSON) {
+	body, contentType := multipartBody(t, fileField, data)
+	r := httptest.NewRequest(http.MethodPost, "/jobs"+query, body)
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	var status jobJSON
+	json.Unmarshal(w.Body.Bytes(), &status)
+	if w.Code == http.StatusAccepted && w.Header().Get("Location") != "/jobs/"+status.ID {
+		t.Errorf("should respond with location of the job, but got %v", w.Header().Get("Location"))
+	}
+	return w.Code, status
+}
+
+// returns status of the finished job
+func waitJob(t *testing.T, s *Server, id string) jobJSON {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/"+id, nil))
+		var status jobJSON
+		if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		if status.Status != jobRunning {
+			return status
+		}
+	}
+	t.Fatal("job should finish")
+	return jobJSON{}
+}
+
+func TestJobs(t *testing.T) {
+	records := "name,mail\nA,a@a.io\nB,b@a.io\nC,a@b.io\nD,invalid\n"
+
+	data := []struct {
+		name   string
+		query  string
+		config Config
+		code   int
+		status string
+		result int
+		body   string
+	}{
+		{"successful import", "?email_field=mail&skip_invalid=1", Config{}, http.StatusAccepted, jobDone, http.StatusOK, `"valid_emails":3`},
+		{"invalid email", "?email_field=mail", Config{}, http.StatusAccepted, jobFailed, http.StatusUnprocessableEntity, `"code":"E_INVALID_EMAIL"`},
+		{"too many rows", "?email_field=mail", Config{MaxRows: 2}, http.StatusAccepted, jobFailed, http.StatusRequestEntityTooLarge, `"code":"E_TOO_MANY_ROWS"`},
+		{"partial result", "?email_field=mail", Config{MaxRows: 2, Options: []customerimporter.Option{customerimporter.AllowPartialResult()}}, http.StatusAccepted, jobPartial, http.StatusOK, `"partial":true`},
+		{"invalid parameter", "?skip_invalid=maybe", Config{}, http.StatusBadRequest, "", 0, ""},
+		{"upload too large", "?email_field=mail", Config{MaxJobUploadSize: 64}, http.StatusRequestEntityTooLarge, "", 0, ""},
+	}
+
+	t.Log("Should import uploaded file in the background")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		s := New(d.config)
+		code, status := createJob(t, s, d.query, records)
+		if code != d.code {
+			t.Errorf("should respond with %v, but got %v", d.code, code)
+		}
+		if code != http.StatusAccepted {
+			s.Close()
+			continue
+		}
+
+		status = waitJob(t, s, status.ID)
+		if status.Status != d.status {
+			t.Errorf("should finish with status %v, but got %v", d.status, status)
+		}
+		if status.BytesTotal != int64(len(records)) || status.FinishedAt == nil {
+			t.Errorf("should report progress of the job, but got %v", status)
+		}
+		if d.status != jobDone && status.Code == "" {
+			t.Errorf("should report code of the error, but got %v", status)
+		}
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/"+status.ID+"/result", nil))
+		if w.Code != d.result {
+			t.Errorf("should respond with %v, but got %v: %v", d.result, w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), d.body) {
+			t.Errorf("should respond with: %v, but got %v", d.body, w.Body.String())
+		}
+		s.Close()
+	}
+}
+
+func TestJobRequests(t *testing.T) {
+	s := New(Config{})
+	defer s.Close()
+	s.jobs["running"] = &job{id: "running", status: jobRunning, created: time.Now()}
+
+	data := []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{http.MethodGet, "/jobs/running", http.StatusOK, `"status":"running"`},
+		{http.MethodGet, "/jobs/running/result", http.StatusConflict, "job is running"},
+		{http.MethodGet, "/jobs/unknown", http.StatusNotFound, "job not found"},
+		{http.MethodGet, "/jobs/unknown/result", http.StatusNotFound, "job not found"},
+		{http.MethodDelete, "/jobs/running", http.StatusMethodNotAllowed, "method not allowed"},
+		{http.MethodGet, "/jobs", http.StatusMethodNotAllowed, "method not allowed"},
+	}
+
+	t.Log("Should respond with status of the job")
+	for _, d := range data {
+		t.Logf("Case: %v %v", d.method, d.path)
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(d.method, d.path, nil))
+		if w.Code != d.status {
+			t.Errorf("should respond with %v, but got %v: %v", d.status, w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), d.body) {
+			t.Errorf("should respond with: %v, but got %v", d.body, w.Body.String())
+		}
+	}
+}
+
+func TestJobClosed(t *testing.T) {
+	t.Log("Should reject new jobs after Close")
+
+	s := New(Config{})
+	s.Close()
+	if code, _ := createJob(t, s, "", "email\na@a.io\n"); code != http.StatusServiceUnavailable {
+		t.Errorf("should respond with %v, but got %v", http.StatusServiceUnavailable, code)
+	}
+	if len(s.jobs) != 0 {
+		t.Errorf("should not add the job, but got %v", s.jobs)
+	}
+}
+
+func TestJobTTL(t *testing.T) {
+	t.Log("Should remove jobs finished longer than JobTTL ago")
+
+	s := New(Config{JobTTL: time.Minute})
+	defer s.Close()
+	s.jobs["expired"] = &job{id: "expired", status: jobDone, finished: time.Now().Add(-2 * time.Minute)}
+	s.jobs["finished"] = &job{id: "finished", status: jobDone, finished: time.Now()}
+	s.jobs["running"] = &job{id: "running", status: jobRunning}
+
+	s.addJob(&job{id: "new", status: jobRunning})
+	s.wg.Done() // the job isn't run
+	for _, id := range []string{"finished", "running", "new"} {
+		if _, ok := s.jobs[id]; !ok {
+			t.Errorf("should keep job %v", id)
+		}
+	}
+	if _, ok := s.jobs["expired"]; ok {
+		t.Error("should remove expired job")
+	}
+}
@@ -0,0 +1,237 @@
+// Package httpserver serves customer imports over HTTP. Csv files are uploaded
+// as multipart forms to the /import endpoint and domain counts are returned
+// as JSON.
+//
+// Large files are uploaded to the /jobs endpoint instead, which responds with
+// 202 Accepted and id of the job importing the file in the background. Status
+// and progress of the job are returned by /jobs/{id} and domain counts of the
+// finished job by /jobs/{id}/result. Progress of the job is streamed as
+// Server-Sent Events by /jobs/{id}/events.
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// default limits of the server
+const (
+	DefaultMaxUploadSize = 32 << 20 // 32 MiB
+	DefaultTimeout       = time.Minute
+)
+
+// name of the multipart form field containing csv file
+const fileField = "file"
+
+// Config of the server
+type Config struct {
+	MaxUploadSize int64                     // max size of request body in bytes, DefaultMaxUploadSize if < 1
+	Timeout       time.Duration             // max duration of an import, DefaultTimeout if < 1
+	MaxRows       int                       // max amount of records of an import, unlimited if < 1
+	MaxInputSize  int64                     // max size of decompressed csv data in bytes, unlimited if < 1
+	Options       []customerimporter.Option // options applied to every import
+
+	MaxJobUploadSize int64         // max size of job upload in bytes, DefaultMaxJobUploadSize if < 1
+	JobTimeout       time.Duration // max duration of a job, DefaultJobTimeout if < 1
+	JobTTL           time.Duration // how long finished jobs are kept, DefaultJobTTL if < 1
+	ProgressInterval time.Duration // interval of progress events, DefaultProgressInterval if < 1
+}
+
+// Server handles import requests
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+
+	jobsMu sync.Mutex
+	jobs   map[string]*job
+	ctx    context.Context // canceled by Close to stop running jobs
+	cancel context.CancelFunc
+	wg     sync.WaitGroup // running jobs
+	closed bool           // set by Close, guarded by jobsMu
+}
+
+// New returns server with the config
+func New(cfg Config) *Server {
+	if cfg.MaxUploadSize < 1 {
+		cfg.MaxUploadSize = DefaultMaxUploadSize
+	}
+	if cfg.Timeout < 1 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxJobUploadSize < 1 {
+		cfg.MaxJobUploadSize = DefaultMaxJobUploadSize
+	}
+	if cfg.JobTimeout < 1 {
+		cfg.JobTimeout = DefaultJobTimeout
+	}
+	if cfg.JobTTL < 1 {
+		cfg.JobTTL = DefaultJobTTL
+	}
+	if cfg.ProgressInterval < 1 {
+		cfg.ProgressInterval = DefaultProgressInterval
+	}
+
+	s := &Server{cfg: cfg, mux: http.NewServeMux(), jobs: make(map[string]*job)}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.mux.HandleFunc("/import", s.handleImport)
+	s.mux.HandleFunc("/jobs", s.handleCreateJob)
+	s.mux.HandleFunc("/jobs/{id}", s.handleJob)
+	s.mux.HandleFunc("/jobs/{id}/result", s.handleJobResult)
+	s.mux.HandleFunc("/jobs/{id}/events", s.handleJobEvents)
+
+	return s
+}
+
+// ServeHTTP dispatches request to the endpoint
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// imports csv file uploaded as multipart form field "file". Query parameters
+// email_field, skip_invalid and skip_duplicates configure the import.
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	// limit request size and import duration
+	r.Body = http.MaxBytesReader(w, r.Body, s.cfg.MaxUploadSize)
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.Timeout)
+	defer cancel()
+
+	// find file in the form
+	file, err := formFile(r)
+	if err != nil {
+		writeError(w, errorStatus(ctx, err, http.StatusBadRequest), err)
+		return
+	}
+
+	// import options
+	emailField, options, err := s.importOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// import, collected errors are returned as part of the result
+	result, err := customerimporter.ImportWithStats(&contextReader{ctx: ctx, r: file}, emailField, options...)
+	if result == nil {
+		writeError(w, errorStatus(ctx, err, http.StatusUnprocessableEntity), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	result.WriteJSON(w, false)
+}
+
+// returns options of the import by query parameters
+func (s *Server) importOptions(r *http.Request) (string, []customerimporter.Option, error) {
+	query := r.URL.Query()
+
+	emailField := query.Get("email_field")
+	if emailField == "" {
+		emailField = "email"
+	}
+
+	options := append([]customerimporter.Option(nil), s.cfg.Options...)
+	if s.cfg.MaxRows > 0 {
+		options = append(options, customerimporter.WithMaxRows(s.cfg.MaxRows))
+	}
+	if s.cfg.MaxInputSize > 0 {
+		options = append(options, customerimporter.WithMaxBytes(s.cfg.MaxInputSize))
+	}
+	flags := []struct {
+		name   string
+		option customerimporter.Option
+	}{
+		{"skip_invalid", customerimporter.SkipErrInvalidEmails()},
+		{"skip_duplicates", customerimporter.SkipErrDuplicateEmails()},
+	}
+	for _, f := range flags {
+		if query.Get(f.name) == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(query.Get(f.name))
+		if err != nil {
+			return "", nil, errors.New("invalid " + f.name + " parameter")
+		}
+		if enabled {
+			options = append(options, f.option)
+		}
+	}
+
+	return emailField, options, nil
+}
+
+// returns reader of the file field of multipart form without buffering it
+func formFile(r *http.Request) (io.Reader, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("form doesn't contain file field")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == fileField {
+			return part, nil
+		}
+	}
+}
+
+// returns status of the error, fallback is returned for errors caused by
+// the uploaded data
+func errorStatus(ctx context.Context, err error, fallback int) int {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr), errors.Is(err, customerimporter.ErrTooManyRows), errors.Is(err, customerimporter.ErrInputTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil:
+		return http.StatusServiceUnavailable
+	}
+	return fallback
+}
+
+// writes value as JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writes error as JSON response, errors of the importer contain their code
+func writeError(w http.ResponseWriter, status int, err error) {
+	response := map[string]string{"error": err.Error()}
+	if code := customerimporter.Code(err); code != customerimporter.CodeUnknown {
+		response["code"] = string(code)
+	}
+	writeJSON(w, status, response)
+}
+
+// contextReader stops reading when context is done, so the import is aborted
+// on timeout or when the client goes away
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,92 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// returns multipart form with the field
+func multipartBody(t *testing.T, field, data string) (io.Reader, string) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	part, err := w.CreateFormFile(field, "customers.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(part, data)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &b, w.FormDataContentType()
+}
+
+func TestImport(t *testing.T) {
+	records := "name,mail\nA,a@a.io\nB,b@a.io\nC,a@b.io\nD,invalid\n"
+
+	data := []struct {
+		method string
+		query  string
+		field  string
+		config Config
+		status int
+		body   string
+	}{
+		// successful import
+		{http.MethodPost, "?email_field=mail&skip_invalid=1", fileField, Config{}, http.StatusOK, `"valid_emails":3`},
+
+		// configured options apply to every import
+		{http.MethodPost, "?email_field=mail", fileField, Config{Options: []customerimporter.Option{customerimporter.SkipErrInvalidEmails()}}, http.StatusOK, `"invalid_emails":1`},
+
+		// import errors
+		{http.MethodPost, "?email_field=mail", fileField, Config{}, http.StatusUnprocessableEntity, `"code":"E_INVALID_EMAIL"`},
+		{http.MethodPost, "", fileField, Config{}, http.StatusUnprocessableEntity, "CSV header doesn't contain field"},
+		{http.MethodPost, "", fileField, Config{}, http.StatusUnprocessableEntity, `"code":"E_FIELD_MISSING"`},
+
+		// request errors
+		{http.MethodGet, "", fileField, Config{}, http.StatusMethodNotAllowed, "method not allowed"},
+		{http.MethodPost, "?email_field=mail", "other", Config{}, http.StatusBadRequest, "form doesn't contain file field"},
+		{http.MethodPost, "?skip_invalid=maybe", fileField, Config{}, http.StatusBadRequest, "invalid skip_invalid parameter"},
+		{http.MethodPost, "?email_field=mail", fileField, Config{MaxUploadSize: 64}, http.StatusRequestEntityTooLarge, "request body too large"},
+		{http.MethodPost, "?email_field=mail", fileField, Config{MaxRows: 2}, http.StatusRequestEntityTooLarge, `"code":"E_TOO_MANY_ROWS"`},
+		{http.MethodPost, "?email_field=mail", fileField, Config{MaxInputSize: 20}, http.StatusRequestEntityTooLarge, `"code":"E_INPUT_TOO_LARGE"`},
+		{http.MethodPost, "?email_field=mail", fileField, Config{Timeout: time.Nanosecond}, http.StatusServiceUnavailable, "context deadline exceeded"},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		body, contentType := multipartBody(t, d.field, records)
+		r := httptest.NewRequest(d.method, "/import"+d.query, body)
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		New(d.config).ServeHTTP(w, r)
+
+		if w.Code != d.status {
+			t.Errorf("should respond with %v, but got %v: %v", d.status, w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), d.body) {
+			t.Errorf("should respond with: %v, but got %v", d.body, w.Body.String())
+		}
+		if !json.Valid(w.Body.Bytes()) {
+			t.Errorf("should respond with JSON, but got %v", w.Body.String())
+		}
+	}
+
+	// test request which isn't multipart form
+	t.Log("Test request which isn't multipart form")
+	w := httptest.NewRecorder()
+	New(Config{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(records)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("should respond with %v, but got %v", http.StatusBadRequest, w.Code)
+	}
+}
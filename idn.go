@@ -0,0 +1,29 @@
+package customerimporter
+
+import "golang.org/x/net/idna"
+
+// IDNForm is a canonical form of internationalized domain names
+type IDNForm int
+
+const (
+	IDNUnchanged IDNForm = iota // domain names are not converted
+	IDNASCII                    // punycode, e.g. xn--mnchen-3ya.de
+	IDNUnicode                  // unicode, e.g. münchen.de
+)
+
+// Convert internationalized domain names to the form, so the same domain
+// written in punycode and unicode is counted once. Domains which can't be
+// converted are treated as invalid emails.
+func WithIDNForm(form IDNForm) Option { return func(f *CustomerImporter) { f.idnForm = form } }
+
+// converts domain name to the form using IDNA lookup profile, which also maps
+// domain to lower case
+func (form IDNForm) convert(domain string) (string, error) {
+	switch form {
+	case IDNASCII:
+		return idna.Lookup.ToASCII(domain)
+	case IDNUnicode:
+		return idna.Lookup.ToUnicode(domain)
+	}
+	return domain, nil
+}
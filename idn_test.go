@@ -0,0 +1,66 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithIDNForm(t *testing.T) {
+	records := "email\n" +
+		"user@münchen.de\n" +
+		"other@xn--mnchen-3ya.de\n" +
+		"third@MÜNCHEN.de\n" +
+		"user@github.io\n"
+
+	data := []struct {
+		form   IDNForm
+		result EmailsByDomainQtyList
+	}{
+		// domains are only lowercased
+		{IDNUnchanged, EmailsByDomainQtyList{
+			{Domain: "github.io", EmailsCount: 1, Share: 0.25},
+			{Domain: "münchen.de", EmailsCount: 2, Share: 0.5},
+			{Domain: "xn--mnchen-3ya.de", EmailsCount: 1, Share: 0.25},
+		}},
+
+		// punycode
+		{IDNASCII, EmailsByDomainQtyList{
+			{Domain: "github.io", EmailsCount: 1, Share: 0.25},
+			{Domain: "xn--mnchen-3ya.de", EmailsCount: 3, Share: 0.75},
+		}},
+
+		// unicode
+		{IDNUnicode, EmailsByDomainQtyList{
+			{Domain: "github.io", EmailsCount: 1, Share: 0.25},
+			{Domain: "münchen.de", EmailsCount: 3, Share: 0.75},
+		}},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := Import(strings.NewReader(records), "email", WithIDNForm(d.form))
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(*result, d.result) {
+			t.Errorf("should result with: %v, but got %v", d.result, *result)
+		}
+	}
+
+	// test duplicates are detected across representations
+	t.Log("Test duplicates are detected across representations")
+	_, err := Import(strings.NewReader("email\nuser@münchen.de\nuser@xn--mnchen-3ya.de\n"), "email", WithIDNForm(IDNASCII))
+	if err == nil || !strings.Contains(err.Error(), ErrEmailDuplicate.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailDuplicate, err)
+	}
+
+	// test domains which can't be converted are invalid
+	t.Log("Test domains which can't be converted are invalid")
+	_, err = Import(strings.NewReader("email\nuser@xn--a.de\n"), "email", WithIDNForm(IDNUnicode))
+	if err == nil || !strings.Contains(err.Error(), ErrEmailIsNotValid.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailIsNotValid, err)
+	}
+}
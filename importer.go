@@ -0,0 +1,67 @@
+package customerimporter
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrLimitReached is raised by Feed when a limit of WithMaxRows, WithMaxBytes
+// or LimitValidEmails truncated one of the readers fed before
+var ErrLimitReached = errors.New("Limit of the importer is reached")
+
+// Importer counts emails of the field across all readers passed to Feed,
+// e.g. daily exports received by a long-running service. It's not safe for
+// concurrent use.
+type Importer struct {
+	c *CustomerImporter
+}
+
+// New returns importer counting emails of the field across all readers
+// passed to Feed. Options apply to every reader, WithCheckpoint is ignored.
+func New(emailFieldName string, options ...Option) *Importer {
+	c := newCustomerImporter(nil, emailFieldName, options...)
+
+	// fed readers can't be resumed
+	c.checkpointPath, c.checkpointEvery = "", 0
+
+	return &Importer{c: c}
+}
+
+// Feed reads header and records of r and adds its emails to the counts.
+// Emails are deduplicated across all fed readers unless DedupPerFile is used.
+// If error is returned, emails of the records read before it stay counted.
+// Limits apply to all fed readers together, the reader reaching a limit is
+// truncated and readers fed after it are not read, ErrLimitReached is
+// returned for them.
+func (i *Importer) Feed(r io.Reader) error {
+	c := i.c
+	if c.truncated {
+		return ErrLimitReached
+	}
+	if c.dedupPerFile {
+		c.countedEmails = c.newFileDedupStore()
+	}
+
+	// start reading the input from its header
+	c.input, c.reader, c.line = r, nil, 0
+
+	return c.parseInput()
+}
+
+// Result returns counts of all fed readers together with the statistics. If
+// errors are collected, the result is returned together with RowErrors.
+func (i *Importer) Result() (*ImportResult, error) {
+	// check rate of skipped records of all readers
+	if err := i.c.checkErrorRate(); err != nil {
+		return nil, err
+	}
+
+	return i.c.result()
+}
+
+// Close removes temporary files of WithMemoryLimit, the importer can't be
+// fed after it
+func (i *Importer) Close() error {
+	i.c.closeSpill()
+	return nil
+}
@@ -0,0 +1,110 @@
+package customerimporter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFeed(t *testing.T) {
+	data := []struct {
+		name     string
+		inputs   []string
+		options  []Option
+		expected EmailsByDomainQtyList
+		rows     int
+		err      error
+	}{
+		{"across readers", []string{"email\na@a.io\nb@a.io\n", "name,email\nA,a@a.io\nC,a@b.io\n"}, []Option{SkipErrDuplicateEmails()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, 4, nil},
+		{"per reader", []string{"email\na@a.io\nb@a.io\n", "name,email\nA,a@a.io\nC,a@b.io\n"}, []Option{DedupPerFile()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 3.0 / 4},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 4},
+		}, 4, nil},
+		{"without header", []string{"a@a.io\n", "a@b.io\n"}, []Option{WithColumnIndex(0)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+		}, 2, nil},
+		{"nothing fed", nil, nil, nil, 0, ErrNoValidEmailsFound},
+		{"error rate of all readers", []string{"email\na@a.io\n", "email\ninvalid\n"}, []Option{WithMaxErrorRate(10), SkipErrInvalidEmails()}, nil, 0, ErrTooManyErrors},
+	}
+
+	t.Log("Should accumulate counts of all fed readers")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		importer := New("email", d.options...)
+		for _, input := range d.inputs {
+			if err := importer.Feed(strings.NewReader(input)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		result, err := importer.Result()
+		if d.err != nil {
+			if !errors.Is(err, d.err) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Domains, d.expected) {
+			t.Errorf("should return %v, but got %v", d.expected, result.Domains)
+		}
+		if result.RowsRead != d.rows {
+			t.Errorf("should read %v records, but got %v", d.rows, result.RowsRead)
+		}
+	}
+}
+
+func TestFeedError(t *testing.T) {
+	t.Log("Should keep counts of readers fed before the failed one")
+
+	importer := New("email")
+	if err := importer.Feed(strings.NewReader("email\na@a.io\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := importer.Feed(strings.NewReader("name\nA\n")); err == nil || !strings.Contains(err.Error(), ErrFieldNotExists.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrFieldNotExists, err)
+	}
+	if err := importer.Feed(strings.NewReader("email\na@b.io\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := importer.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ValidEmails != 2 || result.DistinctDomains != 2 {
+		t.Errorf("should count 2 emails of 2 domains, but got %+v", result)
+	}
+}
+
+func TestFeedLimit(t *testing.T) {
+	t.Log("Should raise error for readers fed after a limit is reached")
+
+	importer := New("email", LimitValidEmails(2))
+	if err := importer.Feed(strings.NewReader("email\na@a.io\nb@a.io\nc@a.io\n")); err != nil {
+		t.Fatal(err)
+	}
+	err := importer.Feed(strings.NewReader("email\na@b.io\n"))
+	if !errors.Is(err, ErrLimitReached) {
+		t.Errorf("should raise error: %v, but got error %v", ErrLimitReached, err)
+	}
+	if code := Code(err); code != CodeLimitReached {
+		t.Errorf("should return code %v, but got %v", CodeLimitReached, code)
+	}
+
+	result, err := importer.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ValidEmails != 2 || !result.Truncated {
+		t.Errorf("should count 2 emails of the first reader, but got %+v", result)
+	}
+}
@@ -0,0 +1,116 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrTooManyRows is raised when the input has more records than WithMaxRows
+var ErrTooManyRows = errors.New("Too many rows")
+
+// ErrInputTooLarge is raised when the input is larger than WithMaxBytes
+var ErrInputTooLarge = errors.New("Input is too large")
+
+// errTruncated ends reading of the input at a limit if TruncateAtLimits is
+// used, the reader of the records marks the import as truncated
+var errTruncated = errors.New("Input truncated")
+
+// Read at most n records of all inputs of the import, the header excluded,
+// the import is aborted with ErrTooManyRows when there are more records
+// unless TruncateAtLimits is used. Files are imported sequentially.
+func WithMaxRows(n int) Option { return func(f *CustomerImporter) { f.maxRows = n } }
+
+// Read at most n bytes of all csv inputs of the import after decompression,
+// so a small compressed upload can't expand without bounds. The import is
+// aborted with ErrInputTooLarge when the input is larger unless
+// TruncateAtLimits is used. Files are imported sequentially.
+func WithMaxBytes(n int64) Option { return func(f *CustomerImporter) { f.maxBytes = n } }
+
+// Stop reading the input when a limit of WithMaxRows or WithMaxBytes is
+// reached and return result of the records read so far instead of error. The
+// result is marked as truncated. With WithMaxBytes the last record may be
+// cut, it's dropped.
+func TruncateAtLimits() Option { return func(f *CustomerImporter) { f.truncateAtLimits = true } }
+
+// Stop reading the input when n valid unique emails are counted and return
+// result of the records read so far marked as truncated, e.g. for a quick
+// preview of an upload. Emails of additional email fields of the last record
+// beyond the limit are not counted. Files are imported sequentially.
+func LimitValidEmails(n int) Option { return func(f *CustomerImporter) { f.maxValidEmails = n } }
+
+// reports whether emails limit of LimitValidEmails is reached, the import is
+// truncated then
+func (c *CustomerImporter) enoughEmails() bool {
+	if c.maxValidEmails <= 0 || c.validEmails < c.maxValidEmails {
+		return false
+	}
+	c.truncated = true
+	return true
+}
+
+// reports whether err is raised by a limit of the input
+func isLimitError(err error) bool {
+	return errors.Is(err, ErrTooManyRows) || errors.Is(err, ErrInputTooLarge)
+}
+
+// returns reader of records counting them against WithMaxRows
+func (c *CustomerImporter) limitRows(rr RecordReader) RecordReader {
+	if c.maxRows <= 0 {
+		return rr
+	}
+	return &rowLimitReader{RecordReader: rr, c: c, header: !c.headerless}
+}
+
+// returns reader counting bytes against WithMaxBytes
+func (c *CustomerImporter) limitBytes(r io.Reader) io.Reader {
+	if c.maxBytes <= 0 {
+		return r
+	}
+	return &byteLimitReader{r: r, c: c}
+}
+
+// rowLimitReader raises ErrTooManyRows when rows of all inputs exceed the
+// limit
+type rowLimitReader struct {
+	RecordReader
+	c      *CustomerImporter
+	header bool // the next record is the header, which is not counted
+}
+
+func (r *rowLimitReader) Read() ([]string, error) {
+	record, err := r.RecordReader.Read()
+	if record == nil {
+		return record, err
+	}
+	if r.header {
+		r.header = false
+		return record, err
+	}
+	if r.c.inputRows >= r.c.maxRows {
+		return nil, fmt.Errorf("%w: more than %d records", ErrTooManyRows, r.c.maxRows)
+	}
+	r.c.inputRows++
+	return record, err
+}
+
+// byteLimitReader raises ErrInputTooLarge when bytes of all inputs exceed
+// the limit
+type byteLimitReader struct {
+	r io.Reader
+	c *CustomerImporter
+}
+
+func (r *byteLimitReader) Read(p []byte) (int, error) {
+	// read one byte over the limit to find out whether the input continues
+	if remaining := r.c.maxBytes - r.c.inputBytes + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := r.r.Read(p)
+	r.c.inputBytes += int64(n)
+	if over := r.c.inputBytes - r.c.maxBytes; over > 0 {
+		r.c.inputBytes -= over
+		return n - int(over), fmt.Errorf("%w: more than %d bytes", ErrInputTooLarge, r.c.maxBytes)
+	}
+	return n, err
+}
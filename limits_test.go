@@ -0,0 +1,179 @@
+package customerimporter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInputLimits(t *testing.T) {
+	records := "name,email\nA,a@a.io\nB,b@a.io\nC,c@b.io\n"
+	all := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	truncated := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Share: 1}}
+
+	data := []struct {
+		name      string
+		options   []Option
+		domains   EmailsByDomainQtyList
+		truncated bool
+		err       error
+	}{
+		{"rows within limit", []Option{WithMaxRows(3)}, all, false, nil},
+		{"bytes within limit", []Option{WithMaxBytes(int64(len(records)))}, all, false, nil},
+		{"too many rows", []Option{WithMaxRows(2)}, nil, false, ErrTooManyRows},
+		{"too many bytes", []Option{WithMaxBytes(int64(len(records)) - 1)}, nil, false, ErrInputTooLarge},
+		{"truncated rows", []Option{WithMaxRows(2), TruncateAtLimits()}, truncated, true, nil},
+		{"truncated bytes", []Option{WithMaxBytes(int64(len(records)) - 3), TruncateAtLimits()}, truncated, true, nil},
+		{"truncated with workers", []Option{WithMaxRows(2), TruncateAtLimits(), WithWorkers(4)}, truncated, true, nil},
+	}
+
+	t.Log("Should stop reading the input at the limits")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := ImportWithStats(strings.NewReader(records), "email", d.options...)
+		if !errors.Is(err, d.err) || (d.err == nil) != (err == nil) {
+			t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			continue
+		}
+		if result == nil {
+			continue
+		}
+		if !reflect.DeepEqual(result.Domains, d.domains) || result.Truncated != d.truncated {
+			t.Errorf("should result with: %v truncated %v, but got %v truncated %v", d.domains, d.truncated, result.Domains, result.Truncated)
+		}
+	}
+
+	t.Log("Should count the first record of input without header")
+	domains, err := Import(strings.NewReader("a@a.io\nb@a.io\nc@b.io\n"), "", WithColumnIndex(0), WithMaxRows(2), TruncateAtLimits())
+	if err != nil || !reflect.DeepEqual(*domains, truncated) {
+		t.Errorf("should result with: %v, but got %v, %v", truncated, domains, err)
+	}
+}
+
+func TestInputLimitsFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, records := range map[string]string{"a.csv": "email\na@a.io\nb@a.io\n", "b.csv": "email\nc@b.io\nd@b.io\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(records), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := []string{filepath.Join(dir, "*.csv")}
+
+	t.Log("Should count rows of all files")
+	result, err := ImportFromFilesWithStats(paths, "email", WithMaxRows(3), TruncateAtLimits())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if result.RowsRead != 3 || !result.Truncated {
+		t.Errorf("should read 3 rows, but got %+v", result)
+	}
+
+	t.Log("Should skip files after the truncated one")
+	result, err = ImportFromFilesWithStats(paths, "email", WithMaxBytes(20), TruncateAtLimits())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if result.RowsRead != 2 || !result.Truncated {
+		t.Errorf("should read 2 rows, but got %+v", result)
+	}
+}
+
+func TestLimitValidEmails(t *testing.T) {
+	records := "email,backup\na@a.io,\ninvalid,\nb@a.io,c@b.io\na@a.io,\nd@b.io,\ne@c.io,\n"
+
+	data := []struct {
+		name     string
+		options  []Option
+		expected EmailsByDomainQtyList
+		rows     int
+	}{
+		{"stops at limit", []Option{LimitValidEmails(2)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 1},
+		}, 3},
+		{"duplicates not counted", []Option{LimitValidEmails(3)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, 5},
+		{"additional fields beyond limit", []Option{LimitValidEmails(2), WithEmailFields("backup")}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 1},
+		}, 3},
+		{"with workers", []Option{LimitValidEmails(3), WithWorkers(2)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, 5},
+		{"with workers and rows limit", []Option{LimitValidEmails(3), WithWorkers(2), WithMaxRows(4), TruncateAtLimits()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 1},
+		}, 4},
+		{"limit not reached", []Option{LimitValidEmails(10)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 0.5},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.25},
+			{Domain: "c.io", EmailsCount: 1, Share: 0.25},
+		}, 6},
+	}
+
+	t.Log("Should stop reading when enough emails are counted")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		options := append([]Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails()}, d.options...)
+		result, err := ImportWithStats(strings.NewReader(records), "email", options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Domains, d.expected) {
+			t.Errorf("should return %v, but got %v", d.expected, result.Domains)
+		}
+		if result.RowsRead != d.rows || result.Truncated != (d.rows < 6) {
+			t.Errorf("should read %v rows, but got %v, truncated %v", d.rows, result.RowsRead, result.Truncated)
+		}
+	}
+
+	t.Log("Should not read the following files")
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.csv"), filepath.Join(dir, "second.csv")
+	if err := os.WriteFile(first, []byte("email\na@a.io\nb@a.io\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("email\na@b.io\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ImportFromFilesWithStats([]string{first, second}, "email", LimitValidEmails(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ValidEmails != 2 || !result.Truncated {
+		t.Errorf("should count 2 emails of the first file, but got %+v", result)
+	}
+
+	t.Log("Should stop at the first record without header")
+	result, err = ImportWithStats(strings.NewReader("a@a.io\nb@a.io\n"), "", WithColumnIndex(0), LimitValidEmails(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RowsRead != 1 || !result.Truncated {
+		t.Errorf("should read 1 row, but got %v", result.RowsRead)
+	}
+
+	t.Log("Should stop workers at the emails limit while rows limit is reached")
+	var b strings.Builder
+	b.WriteString("email\n")
+	for i := 0; i < 4*pipelineBatchSize; i++ {
+		fmt.Fprintf(&b, "user%d@a.io\n", i)
+	}
+	options := []Option{LimitValidEmails(10), WithWorkers(2), WithMaxRows(3 * pipelineBatchSize), TruncateAtLimits()}
+	result, err = ImportWithStats(strings.NewReader(b.String()), "email", options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ValidEmails != 10 || !result.Truncated {
+		t.Errorf("should count 10 emails, but got %v, truncated %v", result.ValidEmails, result.Truncated)
+	}
+}
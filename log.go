@@ -0,0 +1,18 @@
+package customerimporter
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Log detected header, skipped records at debug level and statistics of the
+// finished import at info level.
+func WithLogger(logger *slog.Logger) Option { return func(f *CustomerImporter) { f.logger = logger } }
+
+// logs message if logger is set
+func (c *CustomerImporter) log(level slog.Level, msg string, args ...any) {
+	if c.logger == nil {
+		return
+	}
+	c.logger.Log(context.Background(), level, msg, args...)
+}
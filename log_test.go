@@ -0,0 +1,51 @@
+package customerimporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	records := "name,email\nA,a@a.io\nB,invalid\nC,a@a.io\nD,b@b.io\n"
+
+	var b bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, err := Import(strings.NewReader(records), "email",
+		WithLogger(logger), SkipErrInvalidEmails(), SkipErrDuplicateEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+
+	// decode logged entries
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("should log JSON, but got %v", line)
+		}
+		delete(entry, "time")
+		delete(entry, "elapsed")
+		entries = append(entries, entry)
+	}
+
+	expected := []map[string]any{
+		{"level": "DEBUG", "msg": "email column detected", "field": "email", "column": 1.0},
+		{"level": "DEBUG", "msg": "record skipped", "line": 3.0, "column": 1.0, "value": "invalid", "reason": "Email is not valid"},
+		{"level": "DEBUG", "msg": "record skipped", "line": 4.0, "column": 1.0, "value": "a@a.io", "reason": "Email already added"},
+		{"level": "INFO", "msg": "import finished", "rows": 4.0, "valid_emails": 2.0, "invalid_emails": 1.0, "duplicate_emails": 1.0, "domains": 2.0},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("should log %v entries, but got %v", len(expected), entries)
+	}
+	for i := range expected {
+		for key, value := range expected[i] {
+			if entries[i][key] != value {
+				t.Errorf("entry %v: %v should be %v, but got %v", i, key, value, entries[i][key])
+			}
+		}
+	}
+}
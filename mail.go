@@ -0,0 +1,264 @@
+package customerimporter
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// fields of the records of mail messages read by MboxReader and
+// ImportFromEMLDir, MailFrom is the email field
+const (
+	MailFrom    = "from"    // address of the sender
+	MailName    = "name"    // display name of the sender
+	MailSubject = "subject" // subject of the message
+	MailDate    = "date"    // date header of the message
+)
+
+// header of the records of mail messages
+var mailHeader = []string{MailFrom, MailName, MailSubject, MailDate}
+
+// MboxReader reads messages of mbox mailbox as records with MailFrom,
+// MailName, MailSubject and MailDate fields, the first record is the header.
+// Only headers of the messages are kept in memory. Address of the envelope
+// From line is used when the message has no From header.
+type MboxReader struct {
+	closer io.Closer // file opened by OpenMbox
+	br     *bufio.Reader
+	header bool   // header is read
+	from   string // From line of the next message, empty before the first one
+}
+
+// imports addresses of the senders of mbox mailbox and returns emails counted
+// by their domain
+func ImportFromMbox(fileName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	r, err := OpenMbox(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ImportRecords(r, MailFrom, options...)
+}
+
+// imports addresses of the senders of mbox mailbox and returns result with
+// statistics
+func ImportFromMboxWithStats(fileName string, options ...Option) (*ImportResult, error) {
+	r, err := OpenMbox(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ImportRecordsWithStats(r, MailFrom, options...)
+}
+
+// OpenMbox opens mbox mailbox, .gz and .zst files are decompressed
+func OpenMbox(fileName string) (*MboxReader, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	compression, _ := compressionByExtension(fileName)
+	if compression == CompressionAuto {
+		compression = CompressionNone
+	}
+	r, err := decompressWith(file, compression)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	mr := NewMboxReader(r)
+	mr.closer = multiCloser{r, file}
+	return mr, nil
+}
+
+// NewMboxReader returns reader of mbox mailbox read from r
+func NewMboxReader(r io.Reader) *MboxReader {
+	return &MboxReader{br: bufio.NewReader(r)}
+}
+
+// Read returns record of the next message
+func (r *MboxReader) Read() ([]string, error) {
+	if !r.header {
+		r.header = true
+		return slices.Clone(mailHeader), nil
+	}
+
+	// find From line of the first message
+	for r.from == "" {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(line, "From ") {
+			r.from = line
+		}
+	}
+
+	// read header of the message and skip its body up to the next From line
+	from := r.from
+	var header bytes.Buffer
+	inHeader := true
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			r.from = ""
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(line, "From ") {
+			r.from = line
+			break
+		}
+		if inHeader {
+			if line == "" {
+				inHeader = false
+				continue
+			}
+			header.WriteString(line)
+			header.WriteString("\r\n")
+		}
+	}
+
+	// envelope sender is the first word of the From line
+	envelope := strings.Fields(from)
+	sender := ""
+	if len(envelope) > 1 {
+		sender = envelope[1]
+	}
+	return mailRecord(&header, sender), nil
+}
+
+// returns the next line without line break, io.EOF at the end of the mailbox
+func (r *MboxReader) readLine() (string, error) {
+	line, err := r.br.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Close closes the file opened by OpenMbox
+func (r *MboxReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// imports addresses of the senders of .eml files of the directory and returns
+// emails counted by their domain
+func ImportFromEMLDir(dir string, options ...Option) (*EmailsByDomainQtyList, error) {
+	// import and get result
+	result, err := ImportFromEMLDirWithStats(dir, options...)
+	if result == nil {
+		return nil, err
+	}
+
+	return &result.Domains, err
+}
+
+// imports addresses of the senders of .eml files of the directory and returns
+// result with statistics. Files are read in the order of their names, records
+// have the fields of MboxReader.
+func ImportFromEMLDirWithStats(dir string, options ...Option) (*ImportResult, error) {
+	fileNames, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		return nil, err
+	}
+	return ImportRecordsWithStats(&emlReader{fileNames: fileNames}, MailFrom, options...)
+}
+
+// emlReader reads .eml files as records of mail messages
+type emlReader struct {
+	fileNames []string // unread files
+	header    bool     // header is read
+}
+
+func (r *emlReader) Read() ([]string, error) {
+	if !r.header {
+		r.header = true
+		return slices.Clone(mailHeader), nil
+	}
+	if len(r.fileNames) == 0 {
+		return nil, io.EOF
+	}
+	fileName := r.fileNames[0]
+	r.fileNames = r.fileNames[1:]
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// read the header only
+	var header bytes.Buffer
+	br := bufio.NewReader(file)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		header.WriteString(line)
+		header.WriteString("\r\n")
+		if err == io.EOF {
+			break
+		}
+	}
+	return mailRecord(&header, ""), nil
+}
+
+// returns record of the message header, sender is used when the header has
+// no From field. Unparsable From field is kept as is, so it's reported as
+// invalid email.
+func mailRecord(header *bytes.Buffer, sender string) []string {
+	header.WriteString("\r\n")
+	msg, err := mail.ReadMessage(header)
+	if err != nil {
+		return []string{sender, "", "", ""}
+	}
+
+	record := []string{sender, "", "", msg.Header.Get("Date")}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); err == nil {
+		record[2] = subject
+	}
+	if from := msg.Header.Get("From"); from != "" {
+		record[0] = from
+		if addr, err := mail.ParseAddress(from); err == nil {
+			record[0], record[1] = addr.Address, addr.Name
+		}
+	}
+	return record
+}
+
+// multiCloser closes all closers and returns the first error
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var err error
+	for _, c := range m {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package customerimporter
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mailbox of three messages, the last one has no From header
+const testMbox = "From a@a.io Mon Jan  1 00:00:00 2024\r\n" +
+	"From: Alice <a@a.io>\r\n" +
+	"Subject: =?UTF-8?Q?Caf=C3=A9?=\r\n" +
+	"Date: Mon, 1 Jan 2024 00:00:00 +0000\r\n" +
+	"\r\n" +
+	"Hello\r\n" +
+	">From the body\r\n" +
+	"\r\n" +
+	"From b@b.io Tue Jan  2 00:00:00 2024\n" +
+	"From: b@b.io\n" +
+	"Subject: Order\n" +
+	"\n" +
+	"From: not a header\n" +
+	"\n" +
+	"From c@a.io Wed Jan  3 00:00:00 2024\n" +
+	"Subject: No sender\n" +
+	"\n" +
+	"Body\n"
+
+func TestMboxReader(t *testing.T) {
+	t.Log("Should read senders of the messages")
+
+	expected := [][]string{
+		mailHeader,
+		{"a@a.io", "Alice", "Café", "Mon, 1 Jan 2024 00:00:00 +0000"},
+		{"b@b.io", "", "Order", ""},
+		{"c@a.io", "", "No sender", ""},
+	}
+
+	r := NewMboxReader(strings.NewReader(testMbox))
+	for i, want := range expected {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(record, want) {
+			t.Errorf("record %v should be %q, but got %q", i, want, record)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("should return %v, but got %v", io.EOF, err)
+	}
+}
+
+func TestImportFromMbox(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "support.mbox")
+	if err := os.WriteFile(fileName, []byte(testMbox), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	compressed := filepath.Join(dir, "support.mbox.gz")
+	if err := os.WriteFile(compressed, gzipData(t, testMbox), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	data := []struct {
+		fileName string
+		err      error
+	}{
+		{fileName, nil},
+		{compressed, nil},
+		{filepath.Join(dir, "missing.mbox"), os.ErrNotExist},
+	}
+
+	t.Log("Should count domains of the senders")
+	for _, d := range data {
+		t.Logf("Case: %v", filepath.Base(d.fileName))
+
+		result, err := ImportFromMbox(d.fileName)
+		if d.err != nil {
+			if !errors.Is(err, d.err) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("should return %v, but got %v", expected, *result)
+		}
+	}
+}
+
+func TestImportFromEMLDir(t *testing.T) {
+	t.Log("Should count domains of the senders of .eml files")
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"1.eml":     "From: Alice <a@a.io>\r\nSubject: Hi\r\n\r\nBody\r\n",
+		"2.eml":     "From: \"Bob\" <b@b.io>\nSubject: Order\n",
+		"3.eml":     "From: invalid\n\nBody\n",
+		"notes.txt": "From: c@c.io\n\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := ImportFromEMLDirWithStats(dir, SkipErrInvalidEmails())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+		{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+	}
+	if !reflect.DeepEqual(result.Domains, expected) || result.InvalidEmails != 1 {
+		t.Errorf("should return %v and 1 invalid email, but got %v and %v", expected, result.Domains, result.InvalidEmails)
+	}
+}
@@ -0,0 +1,323 @@
+package customerimporter
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"sort"
+)
+
+// estimated memory usage of entries of the in-memory maps
+const (
+	dedupEntrySize  = 48 // digest of counted email
+	domainEntrySize = 96 // domain name and its count
+)
+
+// amount of digests of a spilled run indexed by its first digest
+const dedupRunBlock = 256
+
+// Limit memory used by counted emails and the domain counter to about n
+// bytes. When the limit is exceeded, the larger of them is spilled to a
+// temporary file: counted emails as a sorted run of digests, which is searched
+// for duplicates for the rest of the import, and domain counts, which are
+// merged back when the result is returned. Emails are compared by 128 bits of
+// their SHA-256 digest like in FileDedupStore. Runs are merged as they pile
+// up, so every email is looked up in a few files. Spilled domains may be
+// reported by EventNewDomain again and checkpoints are not supported. The
+// option replaces the dedup store like WithDedupStore, if the store is set
+// by a later option, only the domain counter is limited. Importers created by
+// New should be closed to remove the temporary files.
+func WithMemoryLimit(n int64) Option {
+	return func(f *CustomerImporter) {
+		f.memoryLimit = n
+		f.countedEmails = &spillDedupStore{emails: make(map[emailDigest]struct{})}
+	}
+}
+
+// returns empty store of counted emails of the next file for DedupPerFile
+func (c *CustomerImporter) newFileDedupStore() DedupStore {
+	if store, ok := c.countedEmails.(*spillDedupStore); ok {
+		store.close()
+		return &spillDedupStore{emails: make(map[emailDigest]struct{})}
+	}
+	return make(MemoryDedupStore, 10)
+}
+
+// spills counted emails or domain counter to disk when they exceed the
+// memory limit
+func (c *CustomerImporter) limitMemory() error {
+	if c.memoryLimit <= 0 {
+		return nil
+	}
+
+	store, _ := c.countedEmails.(*spillDedupStore)
+	var emailsSize int64
+	if store != nil {
+		emailsSize = int64(len(store.emails)) * dedupEntrySize
+	}
+	domainsSize := int64(len(c.domainCounter)) * domainEntrySize
+	if emailsSize+domainsSize <= c.memoryLimit {
+		return nil
+	}
+
+	if emailsSize >= domainsSize {
+		return store.spill()
+	}
+	return c.spillDomains()
+}
+
+// writes domain counter to a temporary file and clears it
+func (c *CustomerImporter) spillDomains() error {
+	file, err := os.CreateTemp("", "customerimporter-domains-*")
+	if err != nil {
+		return err
+	}
+	c.spilledDomains = append(c.spilledDomains, file)
+
+	w := bufio.NewWriter(file)
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64)
+	for domain, count := range c.domainCounter {
+		buf = binary.AppendUvarint(binary.AppendUvarint(buf[:0], uint64(len(domain))), uint64(count))
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(domain); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	c.domainCounter = make(map[string]int, 10)
+	return nil
+}
+
+// adds counts of the spilled domains to the domain counter and removes their
+// files
+func (c *CustomerImporter) mergeSpilledDomains() error {
+	defer c.closeSpilledDomains()
+
+	for _, file := range c.spilledDomains {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r := bufio.NewReader(file)
+		for {
+			length, err := binary.ReadUvarint(r)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			count, err := binary.ReadUvarint(r)
+			if err != nil {
+				return err
+			}
+			domain := make([]byte, length)
+			if _, err := io.ReadFull(r, domain); err != nil {
+				return err
+			}
+			c.domainCounter[string(domain)] += int(count)
+		}
+	}
+	return nil
+}
+
+// removes files of the spilled domains
+func (c *CustomerImporter) closeSpilledDomains() {
+	for _, file := range c.spilledDomains {
+		removeTemp(file)
+	}
+	c.spilledDomains = nil
+}
+
+// removes temporary files of the memory limit
+func (c *CustomerImporter) closeSpill() {
+	c.closeSpilledDomains()
+	if store, ok := c.countedEmails.(*spillDedupStore); ok {
+		store.close()
+	}
+}
+
+// closes and removes temporary file
+func removeTemp(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
+
+// emailDigest is 128 bits of SHA-256 digest of email
+type emailDigest [16]byte
+
+// spillDedupStore is a set of email digests, which are spilled to sorted runs
+// in temporary files when the memory limit is exceeded
+type spillDedupStore struct {
+	emails map[emailDigest]struct{} // digests added since the last spill
+	runs   []*dedupRun              // spilled runs, larger ones first
+	block  []byte                   // buffer of the read digests
+}
+
+func (s *spillDedupStore) Add(email string) (bool, error) {
+	sum := sha256.Sum256([]byte(email))
+	digest := emailDigest(sum[:16])
+	if _, ok := s.emails[digest]; ok {
+		return true, nil
+	}
+
+	for _, run := range s.runs {
+		found, err := run.contains(digest, s.buffer())
+		if err != nil || found {
+			return found, err
+		}
+	}
+
+	s.emails[digest] = struct{}{}
+	return false, nil
+}
+
+// returns buffer of a block of digests
+func (s *spillDedupStore) buffer() []byte {
+	if s.block == nil {
+		s.block = make([]byte, dedupRunBlock*len(emailDigest{}))
+	}
+	return s.block
+}
+
+// writes digests in memory to a sorted run and merges runs of similar size,
+// so there are at most log2 of all digests runs
+func (s *spillDedupStore) spill() error {
+	digests := make([]emailDigest, 0, len(s.emails))
+	for digest := range s.emails {
+		digests = append(digests, digest)
+	}
+	slices.SortFunc(digests, func(a, b emailDigest) int { return bytes.Compare(a[:], b[:]) })
+
+	run, err := writeDedupRun(slices.Values(digests))
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, run)
+	s.emails = make(map[emailDigest]struct{})
+
+	for n := len(s.runs); n > 1 && s.runs[n-2].count <= 2*s.runs[n-1].count; n = len(s.runs) {
+		merged, err := mergeDedupRuns(s.runs[n-2], s.runs[n-1])
+		if err != nil {
+			return err
+		}
+		s.runs = append(s.runs[:n-2], merged)
+	}
+	return nil
+}
+
+// removes files of the spilled runs
+func (s *spillDedupStore) close() {
+	for _, run := range s.runs {
+		removeTemp(run.file)
+	}
+	s.runs = nil
+}
+
+// dedupRun is a temporary file of sorted digests
+type dedupRun struct {
+	file  *os.File      // file of the digests
+	count int           // amount of digests
+	index []emailDigest // first digest of every block
+}
+
+// writes sorted digests to a new run
+func writeDedupRun(digests iter.Seq[emailDigest]) (*dedupRun, error) {
+	file, err := os.CreateTemp("", "customerimporter-dedup-*")
+	if err != nil {
+		return nil, err
+	}
+	run := &dedupRun{file: file}
+
+	w := bufio.NewWriter(file)
+	for digest := range digests {
+		if run.count%dedupRunBlock == 0 {
+			run.index = append(run.index, digest)
+		}
+		if _, err := w.Write(digest[:]); err != nil {
+			removeTemp(file)
+			return nil, err
+		}
+		run.count++
+	}
+	if err := w.Flush(); err != nil {
+		removeTemp(file)
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// merges two runs into a new one and removes them
+func mergeDedupRuns(a, b *dedupRun) (*dedupRun, error) {
+	var err error
+	next := func(r *bufio.Reader, digest *emailDigest) bool {
+		if err != nil {
+			return false
+		}
+		_, e := io.ReadFull(r, digest[:])
+		if e != nil && e != io.EOF {
+			err = e
+		}
+		return e == nil
+	}
+
+	ra := bufio.NewReader(io.NewSectionReader(a.file, 0, int64(a.count*len(emailDigest{}))))
+	rb := bufio.NewReader(io.NewSectionReader(b.file, 0, int64(b.count*len(emailDigest{}))))
+	merged, mergeErr := writeDedupRun(func(yield func(emailDigest) bool) {
+		var da, db emailDigest
+		okA, okB := next(ra, &da), next(rb, &db)
+		for okA || okB {
+			if !okB || okA && bytes.Compare(da[:], db[:]) < 0 {
+				if !yield(da) {
+					return
+				}
+				okA = next(ra, &da)
+			} else {
+				if !yield(db) {
+					return
+				}
+				okB = next(rb, &db)
+			}
+		}
+	})
+	if mergeErr == nil && err != nil {
+		removeTemp(merged.file)
+		mergeErr = err
+	}
+	if mergeErr != nil {
+		return nil, mergeErr
+	}
+
+	removeTemp(a.file)
+	removeTemp(b.file)
+	return merged, nil
+}
+
+// reports whether the run contains the digest, the block which may contain
+// it is read into buf
+func (r *dedupRun) contains(digest emailDigest, buf []byte) (bool, error) {
+	block := sort.Search(len(r.index), func(i int) bool { return bytes.Compare(r.index[i][:], digest[:]) > 0 }) - 1
+	if block < 0 {
+		return false, nil
+	}
+
+	size := len(emailDigest{})
+	n, err := r.file.ReadAt(buf, int64(block*dedupRunBlock*size))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	digests := buf[:n/size*size]
+	_, found := sort.Find(len(digests)/size, func(i int) int { return bytes.Compare(digest[:], digests[i*size:(i+1)*size]) })
+	return found, nil
+}
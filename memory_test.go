@@ -0,0 +1,132 @@
+package customerimporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithMemoryLimit(t *testing.T) {
+	records := generateRecords(5000)
+	options := []Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails(), CountOccurrences()}
+
+	expected, err := ImportWithStats(strings.NewReader(records), "email", options...)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+
+	t.Log("Should return the same result with spilled emails and domains")
+	for _, limit := range []int64{1, 1000, 50000, 1 << 30} {
+		t.Logf("Case: %v", limit)
+
+		tmp := t.TempDir()
+		t.Setenv("TMPDIR", tmp)
+		result, err := ImportWithStats(strings.NewReader(records), "email", append(options, WithMemoryLimit(limit))...)
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		result.Elapsed = expected.Elapsed
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("should result with: %+v, but got %+v", expected, result)
+		}
+
+		// temporary files are removed
+		if files, _ := os.ReadDir(tmp); len(files) > 0 {
+			t.Errorf("should remove temporary files, but got %v", files)
+		}
+	}
+
+	t.Log("Should raise error on duplicates in spilled emails")
+	_, err = Import(strings.NewReader("email\na@a.io\nb@b.io\nc@c.io\na@a.io\n"), "email", WithMemoryLimit(1))
+	if err == nil || !strings.Contains(err.Error(), ErrEmailDuplicate.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailDuplicate, err)
+	}
+}
+
+func TestWithMemoryLimitFeed(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	c := New("email", WithMemoryLimit(200), SkipErrDuplicateEmails())
+	for i := 0; i < 3; i++ {
+		if err := c.Feed(strings.NewReader("email\na@a.io\nb@b.io\nc@a.io\n")); err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+	}
+	result, err := c.Result()
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if result.ValidEmails != 3 || result.DuplicateEmails != 6 || len(result.Domains) != 2 {
+		t.Errorf("should count 3 emails and 6 duplicates, but got %+v", result)
+	}
+
+	t.Log("Should remove temporary files when closed")
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(tmp); len(files) > 0 {
+		t.Errorf("should remove temporary files, but got %v", files)
+	}
+}
+
+func TestSpillDedupStore(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	s := &spillDedupStore{emails: make(map[emailDigest]struct{})}
+	defer s.close()
+
+	// spill every 100 emails, runs are merged as they pile up
+	const n = 2000
+	for i := 0; i < n; i++ {
+		if present, err := s.Add(fmt.Sprintf("user%d@example.com", i)); present || err != nil {
+			t.Fatalf("email %v should be added, but got %v, %v", i, present, err)
+		}
+		if i%100 == 99 {
+			if err := s.spill(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if len(s.runs) > 5 {
+		t.Errorf("should merge runs, but got %v runs", len(s.runs))
+	}
+
+	t.Log("Should find emails of all runs")
+	for i := 0; i < n; i++ {
+		if present, err := s.Add(fmt.Sprintf("user%d@example.com", i)); !present || err != nil {
+			t.Fatalf("email %v should be present, but got %v, %v", i, present, err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if present, err := s.Add(fmt.Sprintf("other%d@example.com", i)); present || err != nil {
+			t.Fatalf("email %v should be added, but got %v, %v", i, present, err)
+		}
+	}
+}
+
+func TestWithMemoryLimitFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", t.TempDir())
+	for _, name := range []string{"a.csv", "b.csv"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("email\na@a.io\nb@a.io\nc@b.io\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Log("Should deduplicate emails within each file")
+	result, err := ImportFromFilesWithStats([]string{filepath.Join(dir, "*.csv")}, "email", WithMemoryLimit(1), DedupPerFile())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 4, Share: 4.0 / 6},
+		{Domain: "b.io", EmailsCount: 2, Share: 2.0 / 6},
+	}
+	if !reflect.DeepEqual(result.Domains, expected) {
+		t.Errorf("should result with: %v, but got %v", expected, result.Domains)
+	}
+}
@@ -0,0 +1,169 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// default limits of MX lookups
+const (
+	defaultMXConcurrency = 8
+	defaultMXTimeout     = 5 * time.Second
+)
+
+// MXStatus tells whether domain can receive mail
+type MXStatus int
+
+const (
+	MXNotVerified  MXStatus = iota // domain wasn't verified
+	MXFound                        // domain has MX records
+	MXNotFound                     // domain has no MX records or null MX
+	MXLookupFailed                 // lookup failed, e.g. by timeout
+)
+
+// String returns human readable name of the status
+func (s MXStatus) String() string {
+	switch s {
+	case MXNotVerified:
+		return "not verified"
+	case MXFound:
+		return "found"
+	case MXNotFound:
+		return "not found"
+	case MXLookupFailed:
+		return "lookup failed"
+	}
+	return "unknown"
+}
+
+// MarshalText encodes the status as its name
+func (s MXStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes the status from its name
+func (s *MXStatus) UnmarshalText(text []byte) error {
+	for status := MXNotVerified; status <= MXLookupFailed; status++ {
+		if status.String() == string(text) {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid MX status %q", text)
+}
+
+// MXResolver looks up MX records of the domain, *net.Resolver implements it
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// MXVerifier looks up MX records of counted domains and caches results, so it
+// may be shared by several imports to avoid repeated lookups
+type MXVerifier struct {
+	Resolver    MXResolver    // resolver, net.DefaultResolver if nil
+	Concurrency int           // max amount of concurrent lookups, 8 if < 1
+	Timeout     time.Duration // timeout of a single lookup, 5s if < 1
+
+	mu    sync.Mutex
+	cache map[string]mxResult
+}
+
+// mxResult is the cached result of MX lookup of a domain
+type mxResult struct {
+	status   MXStatus
+	provider MailProvider
+}
+
+// Look up MX records of every counted domain with net.DefaultResolver and set
+// MX status of the result entries.
+func VerifyMX() Option { return VerifyMXWith(&MXVerifier{}) }
+
+// Look up MX records of every counted domain with the verifier and set MX
+// status of the result entries.
+func VerifyMXWith(v *MXVerifier) Option { return func(f *CustomerImporter) { f.mxVerifier = v } }
+
+// sets MX status of the result entries and their provider if providers are
+// detected, lookups of uncached domains run concurrently
+func (v *MXVerifier) verify(ctx context.Context, result EmailsByDomainQtyList, providers bool) {
+	concurrency := v.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultMXConcurrency
+	}
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i := range result {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(e *EmailsByDomainQty) {
+			defer wg.Done()
+			r := v.lookup(ctx, e.Domain)
+			e.MX = r.status
+			if providers {
+				e.Provider = r.provider
+			}
+			<-semaphore
+		}(&result[i])
+	}
+	wg.Wait()
+}
+
+// returns cached MX lookup result of the domain or looks it up
+func (v *MXVerifier) lookup(ctx context.Context, domain string) mxResult {
+	v.mu.Lock()
+	r, ok := v.cache[domain]
+	v.mu.Unlock()
+	if ok {
+		return r
+	}
+
+	resolver := v.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	timeout := v.Timeout
+	if timeout < 1 {
+		timeout = defaultMXTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	records, err := resolver.LookupMX(ctx, domain)
+	r = mxResult{status: mxStatus(records, err)}
+	if r.status == MXFound {
+		r.provider = mxProvider(domain, records)
+	}
+
+	// don't cache failures, they may be temporary
+	if r.status != MXLookupFailed {
+		v.mu.Lock()
+		if v.cache == nil {
+			v.cache = make(map[string]mxResult)
+		}
+		v.cache[domain] = r
+		v.mu.Unlock()
+	}
+
+	return r
+}
+
+// returns MX status by lookup result
+func mxStatus(records []*net.MX, err error) MXStatus {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return MXNotFound
+	}
+	if err != nil {
+		return MXLookupFailed
+	}
+
+	// null MX (RFC 7505) means domain doesn't accept mail
+	if len(records) == 0 || (len(records) == 1 && records[0].Host == ".") {
+		return MXNotFound
+	}
+	return MXFound
+}
@@ -0,0 +1,123 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeResolver returns predefined MX records and counts lookups
+type fakeResolver struct {
+	mu      sync.Mutex
+	records map[string][]*net.MX
+	lookups map[string]int
+}
+
+func (r *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups[name]++
+
+	if name == "timeout.io" {
+		return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestVerifyMX(t *testing.T) {
+	resolver := &fakeResolver{
+		records: map[string][]*net.MX{
+			"a.io":    {{Host: "mx.a.io.", Pref: 10}},
+			"null.io": {{Host: ".", Pref: 0}},
+		},
+		lookups: map[string]int{},
+	}
+	verifier := &MXVerifier{Resolver: resolver, Concurrency: 2}
+
+	records := "email\na@a.io\nb@a.io\na@b.io\na@null.io\na@timeout.io\n"
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 0.4, MX: MXFound},
+		{Domain: "b.io", EmailsCount: 1, Share: 0.2, MX: MXNotFound},
+		{Domain: "null.io", EmailsCount: 1, Share: 0.2, MX: MXNotFound},
+		{Domain: "timeout.io", EmailsCount: 1, Share: 0.2, MX: MXLookupFailed},
+	}
+
+	// import twice to check caching
+	for i := 0; i < 2; i++ {
+		result, err := Import(strings.NewReader(records), "email", VerifyMXWith(verifier))
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("should result with: %v, but got %v", expected, *result)
+		}
+	}
+
+	// failed lookups are not cached
+	for domain, lookups := range map[string]int{"a.io": 1, "b.io": 1, "null.io": 1, "timeout.io": 2} {
+		if resolver.lookups[domain] != lookups {
+			t.Errorf("%v should be looked up %v times, but was looked up %v times", domain, lookups, resolver.lookups[domain])
+		}
+	}
+
+	// MX status is not set without the option
+	t.Log("Test MX status is not set without the option")
+	result, err := Import(strings.NewReader(records), "email")
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	for _, e := range *result {
+		if e.MX != MXNotVerified {
+			t.Errorf("%v should not be verified, but got %v", e.Domain, e.MX)
+		}
+	}
+}
+
+func TestMXStatus(t *testing.T) {
+	data := []struct {
+		records []*net.MX
+		err     error
+		status  MXStatus
+	}{
+		{[]*net.MX{{Host: "mx.a.io."}}, nil, MXFound},
+		{[]*net.MX{{Host: "."}}, nil, MXNotFound},
+		{nil, nil, MXNotFound},
+		{nil, &net.DNSError{IsNotFound: true}, MXNotFound},
+		{nil, &net.DNSError{IsTimeout: true}, MXLookupFailed},
+		{nil, errors.New("failure"), MXLookupFailed},
+	}
+
+	for testNumber, d := range data {
+		if status := mxStatus(d.records, d.err); status != d.status {
+			t.Errorf("case %v: should be %v, but got %v", testNumber, d.status, status)
+		}
+	}
+}
+
+func TestMXStatusText(t *testing.T) {
+	t.Log("Should encode and decode status by its name")
+	for status := MXNotVerified; status <= MXLookupFailed; status++ {
+		text, err := status.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded MXStatus
+		if err := decoded.UnmarshalText(text); err != nil || decoded != status {
+			t.Errorf("should decode %v, but got %v, error %v", status, decoded, err)
+		}
+	}
+
+	var decoded MXStatus
+	if err := decoded.UnmarshalText([]byte("maybe")); err == nil {
+		t.Error("should raise error for unknown status, but got nil")
+	}
+}
@@ -0,0 +1,155 @@
+package customerimporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// WriteCSV writes the result as csv with domain,count header. If withShare is
+// set, percentage of all counted emails is written in the third column.
+func (p EmailsByDomainQtyList) WriteCSV(w io.Writer, withShare bool) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"domain", "count"}
+	if withShare {
+		header = append(header, "percentage")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, e := range p {
+		record := []string{e.Domain, strconv.Itoa(e.EmailsCount)}
+		if withShare {
+			record = append(record, strconv.FormatFloat(e.Share*100, 'f', 2, 64))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the result as {"domains":[...],"total":N}, where total is
+// the sum of emails of all domains. If pretty is set, the JSON is indented.
+func (p EmailsByDomainQtyList) WriteJSON(w io.Writer, pretty bool) error {
+	return writeJSON(w, pretty, struct {
+		Domains EmailsByDomainQtyList `json:"domains"`
+		Total   int                   `json:"total"`
+	}{p.nonNil(), p.total()})
+}
+
+// WriteJSON writes the result as {"domains":[...],"total":N} followed by the
+// statistics of the import. If pretty is set, the JSON is indented.
+func (r *ImportResult) WriteJSON(w io.Writer, pretty bool) error {
+	return writeJSON(w, pretty, struct {
+		Domains EmailsByDomainQtyList `json:"domains"`
+		Total   int                   `json:"total"`
+		*ImportResult
+	}{r.Domains.nonNil(), r.Domains.total(), r})
+}
+
+// functions of the templates of RenderTemplate
+var templateFuncs = template.FuncMap{
+	"percent": func(share float64) string { return strconv.FormatFloat(share*100, 'f', 2, 64) + "%" },
+	"total":   func(p EmailsByDomainQtyList) int { return p.total() },
+}
+
+// RenderTemplate writes the result by the text/template, e.g. a Slack message
+// or wiki markup. The template is executed with the result, so .Domains and
+// statistics like .ValidEmails are available. Function percent formats share
+// as percentage, e.g. {{percent .Share}}, and total sums emails of the
+// domains, e.g. {{total .Domains}}.
+func (r *ImportResult) RenderTemplate(w io.Writer, tmpl string) error {
+	t, err := template.New("result").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, r)
+}
+
+// returns sum of emails of all domains
+func (p EmailsByDomainQtyList) total() int {
+	total := 0
+	for _, e := range p {
+		total += e.EmailsCount
+	}
+	return total
+}
+
+// returns empty list instead of nil, so it's encoded as [] rather than null
+func (p EmailsByDomainQtyList) nonNil() EmailsByDomainQtyList {
+	if p == nil {
+		return EmailsByDomainQtyList{}
+	}
+	return p
+}
+
+// writes v as JSON followed by newline
+func writeJSON(w io.Writer, pretty bool, v any) error {
+	encoder := json.NewEncoder(w)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
+
+// WriteTable writes the result as a text table aligned by tabwriter. If
+// withShare is set, percentage of all counted emails is written in the third
+// column.
+func (p EmailsByDomainQtyList) WriteTable(w io.Writer, withShare bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := "DOMAIN\tCOUNT"
+	if withShare {
+		header += "\tPERCENTAGE"
+	}
+	if _, err := fmt.Fprintln(tw, header); err != nil {
+		return err
+	}
+
+	for _, e := range p {
+		row := fmt.Sprintf("%s\t%d", e.Domain, e.EmailsCount)
+		if withShare {
+			row += fmt.Sprintf("\t%.2f%%", e.Share*100)
+		}
+		if _, err := fmt.Fprintln(tw, row); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// WriteMarkdown writes the result as a Markdown table. If withShare is set,
+// percentage of all counted emails is written in the third column.
+func (p EmailsByDomainQtyList) WriteMarkdown(w io.Writer, withShare bool) error {
+	header, delimiter := "| Domain | Count |", "| --- | ---: |"
+	if withShare {
+		header, delimiter = header+" Percentage |", delimiter+" ---: |"
+	}
+	if _, err := fmt.Fprintf(w, "%s\n%s\n", header, delimiter); err != nil {
+		return err
+	}
+
+	// pipes would split the cell
+	escaper := strings.NewReplacer(`|`, `\|`)
+	for _, e := range p {
+		row := fmt.Sprintf("| %s | %d |", escaper.Replace(e.Domain), e.EmailsCount)
+		if withShare {
+			row += fmt.Sprintf(" %.2f%% |", e.Share*100)
+		}
+		if _, err := fmt.Fprintln(w, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package customerimporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writer failing on every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+func TestWriteCSV(t *testing.T) {
+	result := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: `b,"c".io`, EmailsCount: 1, Share: 1.0 / 3},
+	}
+
+	data := []struct {
+		withShare bool
+		expected  string
+	}{
+		{false, "domain,count\na.io,2\n\"b,\"\"c\"\".io\",1\n"},
+		{true, "domain,count,percentage\na.io,2,66.67\n\"b,\"\"c\"\".io\",1,33.33\n"},
+	}
+
+	t.Log("Should write result as csv")
+	for _, d := range data {
+		t.Logf("Case: %v", d.withShare)
+
+		var buf bytes.Buffer
+		if err := result.WriteCSV(&buf, d.withShare); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != d.expected {
+			t.Errorf("should write %q, but got %q", d.expected, buf.String())
+		}
+	}
+
+	t.Log("Should return error of the writer")
+	if err := result.WriteCSV(failingWriter{}, false); err == nil {
+		t.Error("should raise error, but got nil")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	result := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 3, Share: 0.75, MX: MXFound},
+		{Domain: "b.io", EmailsCount: 1, Share: 0.25},
+	}
+
+	data := []struct {
+		result   EmailsByDomainQtyList
+		pretty   bool
+		expected string
+	}{
+		{result, false, `{"domains":[{"domain":"a.io","count":3,"share":0.75,"mx":"found"},{"domain":"b.io","count":1,"share":0.25}],"total":4}` + "\n"},
+		{result[1:], true, "{\n  \"domains\": [\n    {\n      \"domain\": \"b.io\",\n      \"count\": 1,\n      \"share\": 0.25\n    }\n  ],\n  \"total\": 1\n}\n"},
+		{nil, false, `{"domains":[],"total":0}` + "\n"},
+	}
+
+	t.Log("Should write result as JSON")
+	for _, d := range data {
+		t.Logf("Case: %v %v", d.result, d.pretty)
+
+		var buf bytes.Buffer
+		if err := d.result.WriteJSON(&buf, d.pretty); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != d.expected {
+			t.Errorf("should write %q, but got %q", d.expected, buf.String())
+		}
+	}
+}
+
+func TestImportResultWriteJSON(t *testing.T) {
+	t.Log("Should write result with statistics as JSON")
+
+	result, err := ImportWithStats(strings.NewReader("email\na@a.io\ninvalid\nb@b.io\n"), "email", CollectErrors())
+	if result == nil {
+		t.Fatal(err)
+	}
+	result.Elapsed = time.Second
+
+	var buf bytes.Buffer
+	if err := result.WriteJSON(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"domains":[{"domain":"a.io","count":1,"share":0.5},{"domain":"b.io","count":1,"share":0.5}],"total":2,` +
+		`"rows_read":3,"valid_emails":2,"invalid_emails":1,"invalid_by_reason":{"Email is not valid":1},"duplicate_emails":0,"filtered_rows":0,"malformed_rows":0,"distinct_domains":2,"elapsed_ns":1000000000,` +
+		`"errors":[{"line":3,"column":0,"value":"invalid","error":"Email is not valid","code":"E_INVALID_EMAIL"}]}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("should write %q, but got %q", expected, buf.String())
+	}
+
+	t.Log("Should decode written result")
+	var decoded ImportResult
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Domains, result.Domains) || decoded.Errors[0].Error() != result.Errors[0].Error() {
+		t.Errorf("should decode %+v, but got %+v", result, decoded)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	result := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 12, Share: 0.75},
+		{Domain: "long.example.com", EmailsCount: 4, Share: 0.25},
+	}
+
+	data := []struct {
+		withShare bool
+		expected  string
+	}{
+		{false, "" +
+			"DOMAIN            COUNT\n" +
+			"a.io              12\n" +
+			"long.example.com  4\n"},
+		{true, "" +
+			"DOMAIN            COUNT  PERCENTAGE\n" +
+			"a.io              12     75.00%\n" +
+			"long.example.com  4      25.00%\n"},
+	}
+
+	t.Log("Should write result as aligned table")
+	for _, d := range data {
+		t.Logf("Case: %v", d.withShare)
+
+		var buf bytes.Buffer
+		if err := result.WriteTable(&buf, d.withShare); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != d.expected {
+			t.Errorf("should write %q, but got %q", d.expected, buf.String())
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	result := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 3, Share: 0.75},
+		{Domain: "b|c.io", EmailsCount: 1, Share: 0.25},
+	}
+
+	data := []struct {
+		withShare bool
+		expected  string
+	}{
+		{false, "| Domain | Count |\n| --- | ---: |\n| a.io | 3 |\n| b\\|c.io | 1 |\n"},
+		{true, "| Domain | Count | Percentage |\n| --- | ---: | ---: |\n| a.io | 3 | 75.00% |\n| b\\|c.io | 1 | 25.00% |\n"},
+	}
+
+	t.Log("Should write result as Markdown table")
+	for _, d := range data {
+		t.Logf("Case: %v", d.withShare)
+
+		var buf bytes.Buffer
+		if err := result.WriteMarkdown(&buf, d.withShare); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != d.expected {
+			t.Errorf("should write %q, but got %q", d.expected, buf.String())
+		}
+	}
+
+	t.Log("Should return error of the writer")
+	if err := result.WriteMarkdown(failingWriter{}, false); err == nil {
+		t.Error("should raise error, but got nil")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	result := &ImportResult{
+		Domains: EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.75},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.25},
+		},
+		ValidEmails:   4,
+		InvalidEmails: 2,
+	}
+
+	data := []struct {
+		name     string
+		tmpl     string
+		expected string
+	}{
+		{"slack", "*{{.ValidEmails}} emails* ({{.InvalidEmails}} invalid)\n{{range .Domains}}• {{.Domain}}: {{.EmailsCount}} ({{percent .Share}})\n{{end}}",
+			"*4 emails* (2 invalid)\n• a.io: 3 (75.00%)\n• b.io: 1 (25.00%)\n"},
+		{"wiki", "{| class=\"wikitable\"\n{{range .Domains}}|-\n| {{.Domain}} || {{.EmailsCount}}\n{{end}}|}\nTotal: {{total .Domains}}\n",
+			"{| class=\"wikitable\"\n|-\n| a.io || 3\n|-\n| b.io || 1\n|}\nTotal: 4\n"},
+	}
+
+	t.Log("Should write result by the template")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		var buf bytes.Buffer
+		if err := result.RenderTemplate(&buf, d.tmpl); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != d.expected {
+			t.Errorf("should write %q, but got %q", d.expected, buf.String())
+		}
+	}
+
+	t.Log("Should raise error of invalid template")
+	for _, tmpl := range []string{"{{range .Domains}}", "{{.Unknown}}"} {
+		if err := result.RenderTemplate(&bytes.Buffer{}, tmpl); err == nil {
+			t.Errorf("should raise error of %q, but got nil", tmpl)
+		}
+	}
+
+	t.Log("Should return error of the writer")
+	if err := result.RenderTemplate(failingWriter{}, "{{.ValidEmails}}"); err == nil {
+		t.Error("should raise error, but got nil")
+	}
+}
@@ -0,0 +1,184 @@
+package customerimporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// amount of values read from a page at once
+const parquetBatchSize = 512
+
+// ParquetReader reads values of a column of Parquet file as records. The first
+// record is the header with the column name, then every value is read as a
+// record of one field. Row groups are read one page at a time, so only the
+// selected column of the current page is kept in memory.
+type ParquetReader struct {
+	closer    io.Closer           // file opened by OpenParquet
+	column    string              // name of the column
+	rowGroups []parquet.RowGroup  // row groups of the file
+	index     int                 // index of the column chunk in row groups
+	header    bool                // whether the header was read
+	pages     parquet.Pages       // pages of the current row group
+	page      parquet.Page        // current page
+	values    parquet.ValueReader // values of the current page
+	buffer    []parquet.Value     // buffer of values read from the page
+	buffered  []parquet.Value     // values of the buffer not read yet
+}
+
+// imports from the column of Parquet file
+func ImportFromParquet(fileName, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	r, err := OpenParquet(fileName, emailFieldName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ImportRecords(r, emailFieldName, options...)
+}
+
+// imports from the column of Parquet file and returns result with statistics
+func ImportFromParquetWithStats(fileName, emailFieldName string, options ...Option) (*ImportResult, error) {
+	r, err := OpenParquet(fileName, emailFieldName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ImportRecordsWithStats(r, emailFieldName, options...)
+}
+
+// OpenParquet opens the column of Parquet file, nested columns are separated by
+// dots, e.g. contact.email
+func OpenParquet(fileName, column string) (*ParquetReader, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r, err := NewParquetReader(file, info.Size(), column)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.closer = file
+
+	return r, nil
+}
+
+// NewParquetReader returns reader of the column of Parquet file read from r
+func NewParquetReader(r io.ReaderAt, size int64, column string) (*ParquetReader, error) {
+	file, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, ok := file.Schema().Lookup(strings.Split(column, ".")...)
+	if !ok {
+		return nil, fmt.Errorf("%w %s field", ErrFieldNotExists, column)
+	}
+
+	return &ParquetReader{
+		column:    column,
+		rowGroups: file.RowGroups(),
+		index:     leaf.ColumnIndex,
+		buffer:    make([]parquet.Value, parquetBatchSize),
+	}, nil
+}
+
+// Read returns the header and then values of the column one by one, null
+// values are read as empty fields
+func (r *ParquetReader) Read() ([]string, error) {
+	if !r.header {
+		r.header = true
+		return []string{r.column}, nil
+	}
+
+	for len(r.buffered) == 0 {
+		if err := r.fill(); err != nil {
+			return nil, err
+		}
+	}
+
+	v := r.buffered[0]
+	r.buffered = r.buffered[1:]
+	if v.IsNull() {
+		return []string{""}, nil
+	}
+	if v.Kind() == parquet.ByteArray || v.Kind() == parquet.FixedLenByteArray {
+		return []string{string(v.ByteArray())}, nil
+	}
+	return []string{v.String()}, nil
+}
+
+// Close closes pages of the current row group and the file opened by
+// OpenParquet
+func (r *ParquetReader) Close() error {
+	err := r.closePages()
+	if r.closer != nil {
+		if closeErr := r.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// reads next values into the buffer moving on to the next page and row group
+func (r *ParquetReader) fill() error {
+	if r.values != nil {
+		n, err := r.values.ReadValues(r.buffer)
+		r.buffered = r.buffer[:n]
+		if err == io.EOF {
+			r.releasePage()
+			return nil
+		}
+		return err
+	}
+
+	if r.pages != nil {
+		page, err := r.pages.ReadPage()
+		if err == io.EOF {
+			return r.closePages()
+		}
+		if err != nil {
+			return err
+		}
+		r.page = page
+		r.values = page.Values()
+		return nil
+	}
+
+	if len(r.rowGroups) == 0 {
+		return io.EOF
+	}
+	r.pages = r.rowGroups[0].ColumnChunks()[r.index].Pages()
+	r.rowGroups = r.rowGroups[1:]
+	return nil
+}
+
+// releases the current page
+func (r *ParquetReader) releasePage() {
+	if r.page != nil {
+		parquet.Release(r.page)
+	}
+	r.page, r.values = nil, nil
+}
+
+// closes pages of the current row group
+func (r *ParquetReader) closePages() error {
+	r.releasePage()
+	if r.pages == nil {
+		return nil
+	}
+	err := r.pages.Close()
+	r.pages = nil
+	return err
+}
@@ -0,0 +1,112 @@
+package customerimporter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetCustomer is a row of Parquet file used in tests
+type parquetCustomer struct {
+	Name    string  `parquet:"name"`
+	Email   *string `parquet:"email,optional"`
+	Contact struct {
+		Email string `parquet:"email"`
+	} `parquet:"contact"`
+}
+
+// writes customers into Parquet file with row groups of two rows
+func parquetData(t *testing.T, emails ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := parquet.NewGenericWriter[parquetCustomer](&buf, parquet.MaxRowsPerRowGroup(2))
+	for _, email := range emails {
+		row := parquetCustomer{Name: "A"}
+		if email != "" {
+			row.Email = &email
+		}
+		row.Contact.Email = strings.Replace(email, "a@", "contact@", 1)
+		if _, err := w.Write([]parquetCustomer{row}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParquetReader(t *testing.T) {
+	t.Log("Should read values of the column across row groups")
+
+	data := parquetData(t, "a@a.io", "", "a@b.io", "a@c.io", "a@d.io")
+	r, err := NewParquetReader(bytes.NewReader(data), int64(len(data)), "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var records [][]string
+	for {
+		record, err := r.Read()
+		if err != nil {
+			break
+		}
+		records = append(records, record)
+	}
+
+	expected := [][]string{{"email"}, {"a@a.io"}, {""}, {"a@b.io"}, {"a@c.io"}, {"a@d.io"}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("should read records %v, but got %v", expected, records)
+	}
+}
+
+func TestImportFromParquet(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "customers.parquet")
+	if err := os.WriteFile(fileName, parquetData(t, "a@a.io", "a@b.io", "a@a.io", ""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		column   string
+		options  []Option
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{"email", []Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+		}, nil},
+		{"contact.email", []Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+		}, nil},
+		{"email", nil, nil, ErrEmailDuplicate},
+		{"phone", nil, nil, ErrFieldNotExists},
+	}
+
+	t.Log("Should import emails from the column of Parquet file")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.column)
+
+		result, err := ImportFromParquet(fileName, test.column, test.options...)
+		if test.err != nil {
+			if err == nil || !strings.Contains(err.Error(), test.err.Error()) {
+				t.Errorf("should raise error: %v, but got error %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, test.expected) {
+			t.Errorf("should return %v, but got %v", test.expected, *result)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package customerimporter
+
+import (
+	"io"
+	"runtime"
+	"sync"
+)
+
+// amount of records passed to a worker at once
+const pipelineBatchSize = 512
+
+// Parse records by n goroutines. Records are still read by a single goroutine
+// and counted in the original order, so the result, events and errors are the
+// same as with sequential processing. If n < 1, runtime.GOMAXPROCS(0) is used.
+func WithWorkers(n int) Option {
+	return func(f *CustomerImporter) {
+		if n < 1 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		f.workers = n
+	}
+}
+
+// pipelineBatch holds records processed by a worker at once
+type pipelineBatch struct {
+	seq       int            // sequence number of the batch
+	lines     []int          // lines of the records
+	offsets   []int64        // offsets of the input after the records, see WithCheckpoint
+	records   [][]string     // records read
+	malformed []bool         // record is skipped by SkipMalformedRows
+	parsed    []parsedRecord // records parsed by a worker
+	err       error          // read error which happened after the records
+	truncated bool           // input is truncated at a limit after the records
+}
+
+// parses records by the producer/consumer pipeline: one goroutine reads
+// batches of records, workers parse them and the calling goroutine merges
+// parsed batches in order and updates the counter
+func (c *CustomerImporter) parseConcurrently() error {
+	// done is closed on return to stop all goroutines, the producer is
+	// awaited as it updates state of the importer returned by partial result
+	var producer sync.WaitGroup
+	defer producer.Wait()
+	done := make(chan struct{})
+	defer close(done)
+
+	// tokens limit amount of batches in flight, so memory usage is bounded
+	// even if a worker is slow and the following batches wait to be merged
+	tokens := make(chan struct{}, 2*c.workers)
+	batches := make(chan *pipelineBatch, c.workers)
+	results := make(chan *pipelineBatch, c.workers)
+
+	// producer reads records, the line is tracked locally as c.line is owned
+	// by the merger
+	producer.Add(1)
+	go func() {
+		defer producer.Done()
+		defer close(batches)
+
+		line := c.line
+		for seq := 0; ; seq++ {
+			b := &pipelineBatch{seq: seq}
+			eof := false
+			for len(b.records) < pipelineBatchSize {
+				record, err := c.nextRecord(&line)
+				if err == io.EOF || err == errTruncated {
+					b.truncated, eof = err == errTruncated, true
+					break
+				}
+				malformed := c.malformed(err)
+				if err != nil && !malformed {
+					b.err = err
+					break
+				}
+				b.lines = append(b.lines, line)
+				b.offsets = append(b.offsets, c.readOffset())
+				b.records = append(b.records, record)
+				b.malformed = append(b.malformed, malformed)
+			}
+
+			select {
+			case tokens <- struct{}{}:
+			case <-done:
+				return
+			}
+			select {
+			case batches <- b:
+			case <-done:
+				return
+			}
+
+			if eof || b.err != nil {
+				return
+			}
+		}
+	}()
+
+	// workers parse records
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				b.parsed = make([]parsedRecord, len(b.records))
+				for i, record := range b.records {
+					if b.malformed[i] {
+						b.parsed[i] = parsedRecord{line: b.lines[i], malformed: true, record: record}
+						continue
+					}
+					b.parsed[i] = c.parseRecord(b.lines[i], record)
+				}
+
+				select {
+				case results <- b:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// merge batches in order
+	pending := make(map[int]*pipelineBatch)
+	next := 0
+	for b := range results {
+		pending[b.seq] = b
+		for {
+			b, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+
+			for i, r := range b.parsed {
+				c.line, c.offset = r.line, b.offsets[i]
+				if r.malformed {
+					if err := c.skipMalformed(r.line, r.record); err != nil {
+						return err
+					}
+					continue
+				}
+				c.rowsRead++
+				if err := c.updateDomainCounter(r); err != nil {
+					return err
+				}
+				if c.enoughEmails() {
+					return nil
+				}
+				if err := c.checkpoint(); err != nil {
+					return err
+				}
+			}
+			if b.truncated {
+				c.truncated = true
+			}
+			if b.err != nil {
+				return b.err
+			}
+
+			<-tokens
+		}
+	}
+
+	return nil
+}
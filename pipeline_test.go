@@ -0,0 +1,82 @@
+package customerimporter
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// generates csv with n records containing duplicate and invalid emails
+func generateRecords(n int) string {
+	b := bytes.NewBufferString("id,email\n")
+	for i := 0; i < n; i++ {
+		switch {
+		case i%97 == 0:
+			fmt.Fprintf(b, "%d,invalid%d\n", i, i)
+		case i%89 == 0:
+			fmt.Fprintf(b, "%d,user%d@domain%d.io\n", i, i-1, (i-1)%13)
+		default:
+			fmt.Fprintf(b, "%d,user%d@domain%d.io\n", i, i, i%13)
+		}
+	}
+	return b.String()
+}
+
+func TestWithWorkers(t *testing.T) {
+	records := generateRecords(10 * pipelineBatchSize)
+
+	// test pipeline produces the same result as sequential processing
+	t.Log("Test pipeline produces the same result")
+	options := []Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails()}
+	expected, err := ImportWithStats(strings.NewReader(records), "email", options...)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+
+	for _, workers := range []int{0, 1, 2, 8} {
+		t.Logf("Case: %v workers", workers)
+		result, err := ImportWithStats(strings.NewReader(records), "email", append(options, WithWorkers(workers))...)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		result.Elapsed = expected.Elapsed
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("should result with: %+v, but got %+v", expected, result)
+		}
+	}
+
+	// test pipeline emits events in order
+	t.Log("Test pipeline emits events in order")
+	var lines []int
+	err = ImportStream(strings.NewReader(records), "email", func(ev Event) error {
+		lines = append(lines, ev.Line)
+		return nil
+	}, append(options, WithWorkers(4))...)
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] < lines[i-1] {
+			t.Fatalf("events should be ordered by line, but line %v follows %v", lines[i], lines[i-1])
+		}
+	}
+
+	// test pipeline fails on the first error
+	t.Log("Test pipeline fails on the first error")
+	for _, option := range []Option{SkipErrInvalidEmails(), SkipErrDuplicateEmails()} {
+		_, expected := Import(strings.NewReader(records), "email", option)
+		_, err := Import(strings.NewReader(records), "email", option, WithWorkers(4))
+		if expected == nil || err == nil || err.Error() != expected.Error() {
+			t.Errorf("should raise error: %v, but got error %v", expected, err)
+		}
+	}
+
+	// test pipeline returns read errors
+	t.Log("Test pipeline returns read errors")
+	_, err = Import(strings.NewReader(records+"1,2,3\n"), "email", append(options, WithWorkers(4))...)
+	if err == nil || !strings.Contains(err.Error(), "wrong number of fields") {
+		t.Errorf("should raise wrong number of fields error, but got error %v", err)
+	}
+}
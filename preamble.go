@@ -0,0 +1,67 @@
+package customerimporter
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// prefix of the Excel directive setting the delimiter, e.g. sep=;
+const sepDirectivePrefix = "sep="
+
+// Skip n lines before the header, e.g. title of a report. Excel sep= directive
+// is recognized among skipped lines and right after them, its delimiter is
+// used unless WithDelimiter is set.
+func SkipRows(n int) Option { return func(f *CustomerImporter) { f.skipRows = n } }
+
+// skips preamble lines of the input and returns delimiter of Excel sep=
+// directive, 0 if there is none
+func (c *CustomerImporter) skipPreamble(br *bufio.Reader) (rune, error) {
+	var sep rune
+
+	// skip lines, they don't have to be valid csv
+	for i := 0; i < c.skipRows; i++ {
+		line, err := br.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		c.line++
+		if r, ok := sepDirective(line); ok {
+			sep = r
+		}
+	}
+
+	// directive is the first line of the data
+	peeked, _ := br.Peek(len(sepDirectivePrefix) + 2*utf8.UTFMax + 2)
+	if i := strings.IndexByte(string(peeked), '\n'); i >= 0 {
+		if r, ok := sepDirective(string(peeked[:i+1])); ok {
+			br.Discard(i + 1)
+			c.line++
+			sep = r
+		}
+	}
+
+	return sep, nil
+}
+
+// returns delimiter of Excel sep= directive line, which may be quoted
+func sepDirective(line string) (rune, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) > 1 && line[0] == '"' && line[len(line)-1] == '"' {
+		line = line[1 : len(line)-1]
+	}
+	if !strings.HasPrefix(line, sepDirectivePrefix) {
+		return 0, false
+	}
+
+	sep := strings.TrimPrefix(line, sepDirectivePrefix)
+	r, size := utf8.DecodeRuneInString(sep)
+	if r == utf8.RuneError || size != len(sep) {
+		return 0, false
+	}
+	return r, true
+}
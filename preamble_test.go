@@ -0,0 +1,93 @@
+package customerimporter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSkipRows(t *testing.T) {
+	data := []struct {
+		name     string
+		records  string
+		options  []Option
+		expected EmailsByDomainQtyList
+	}{
+		{"skip title", "Customers report\n2024-01-01\nname,email\nA,a@a.io\n", []Option{SkipRows(2)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}},
+		{"sep directive", "sep=;\nname;email\nA;a@a.io\n", nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}},
+		{"quoted sep directive with CRLF", "\"sep=|\"\r\nname|email\r\nA|a@a.io\r\n", nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}},
+		{"sep directive after skipped rows", "Customers report\nsep=;\nname;email\nA;a@a.io\n", []Option{SkipRows(1)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}},
+		{"sep directive among skipped rows", "sep=;\nCustomers report\nname;email\nA;a@a.io\n", []Option{SkipRows(2)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}},
+		{"delimiter option wins", "sep=;\nname,email\nA,a@a.io\n", []Option{WithDelimiter(',')}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}},
+		{"sep directive with BOM", "\xef\xbb\xbfsep=;\nname;email\nA;a@a.io\n", []Option{WithWorkers(2)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}},
+	}
+
+	t.Log("Should skip preamble and use delimiter of sep= directive")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := ImportWithStats(strings.NewReader(d.records), "email", d.options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Domains, d.expected) {
+			t.Errorf("should return %v, but got %v", d.expected, result.Domains)
+		}
+	}
+
+	t.Log("Should raise error if all lines are skipped")
+	if _, err := Import(strings.NewReader("name,email\nA,a@a.io\n"), "email", SkipRows(5)); !errors.Is(err, ErrEmptyFile) {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmptyFile, err)
+	}
+}
+
+func TestSkipRowsLines(t *testing.T) {
+	t.Log("Should report lines of the input including skipped rows")
+
+	records := "sep=;\nCustomers report\nname;email\nA;invalid\nB;b@b.io\n"
+	_, err := ImportWithStats(strings.NewReader(records), "email", SkipRows(2), CollectErrors())
+	var rowErrors RowErrors
+	if !errors.As(err, &rowErrors) || len(rowErrors) != 1 || rowErrors[0].Line != 4 {
+		t.Errorf("should report invalid email on line 4, but got %v", err)
+	}
+}
+
+func TestSepDirective(t *testing.T) {
+	data := []struct {
+		line string
+		sep  rune
+		ok   bool
+	}{
+		{"sep=;\n", ';', true},
+		{"sep=\t\r\n", '\t', true},
+		{"\"sep=|\"\n", '|', true},
+		{"sep=;;\n", 0, false},
+		{"sep=\n", 0, false},
+		{"name,email\n", 0, false},
+	}
+
+	t.Log("Should recognize Excel sep= directive")
+	for _, d := range data {
+		t.Logf("Case: %q", d.line)
+
+		sep, ok := sepDirective(d.line)
+		if sep != d.sep || ok != d.ok {
+			t.Errorf("should return %q %v, but got %q %v", d.sep, d.ok, sep, ok)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package customerimporter
+
+import (
+	"net"
+	"strings"
+)
+
+// MailProvider is the mail service hosting mailboxes of the domain
+type MailProvider string
+
+// providers inferred from MX hosts
+const (
+	ProviderGoogle     MailProvider = "Google Workspace"
+	ProviderMicrosoft  MailProvider = "Microsoft 365"
+	ProviderZoho       MailProvider = "Zoho"
+	ProviderProton     MailProvider = "Proton"
+	ProviderFastmail   MailProvider = "Fastmail"
+	ProviderYahoo      MailProvider = "Yahoo"
+	ProviderICloud     MailProvider = "iCloud"
+	ProviderAmazon     MailProvider = "Amazon WorkMail"
+	ProviderMimecast   MailProvider = "Mimecast"
+	ProviderProofpoint MailProvider = "Proofpoint"
+	ProviderSelfHosted MailProvider = "self-hosted" // MX host is under the domain itself
+	ProviderOther      MailProvider = "other"       // MX host of unknown provider
+)
+
+// mxProviders maps domains of MX hosts to their providers, subdomains match
+var mxProviders = []struct {
+	host     string
+	provider MailProvider
+}{
+	{"google.com", ProviderGoogle},
+	{"googlemail.com", ProviderGoogle},
+	{"outlook.com", ProviderMicrosoft},
+	{"zoho.com", ProviderZoho},
+	{"zoho.eu", ProviderZoho},
+	{"zoho.in", ProviderZoho},
+	{"zohomail.com", ProviderZoho},
+	{"protonmail.ch", ProviderProton},
+	{"messagingengine.com", ProviderFastmail},
+	{"yahoodns.net", ProviderYahoo},
+	{"icloud.com", ProviderICloud},
+	{"awsapps.com", ProviderAmazon},
+	{"mimecast.com", ProviderMimecast},
+	{"pphosted.com", ProviderProofpoint},
+}
+
+// Infer mail provider of every counted domain, e.g. Google Workspace or
+// Microsoft 365, by host of its most preferred MX record and set provider of
+// the result entries. MX records are looked up like by VerifyMX, the
+// verifier set by VerifyMXWith is used if any. Domains without MX records
+// have no provider.
+func DetectProviders() Option {
+	return func(f *CustomerImporter) {
+		if f.mxVerifier == nil {
+			f.mxVerifier = &MXVerifier{}
+		}
+		f.detectProviders = true
+	}
+}
+
+// returns provider of the domain by its MX records sorted by preference
+func mxProvider(domain string, records []*net.MX) MailProvider {
+	if len(records) == 0 {
+		return ""
+	}
+	host := normalizeDomain(records[0].Host)
+	for _, p := range mxProviders {
+		if host == p.host || strings.HasSuffix(host, "."+p.host) {
+			return p.provider
+		}
+	}
+	if host == domain || strings.HasSuffix(host, "."+domain) {
+		return ProviderSelfHosted
+	}
+	return ProviderOther
+}
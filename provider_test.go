@@ -0,0 +1,81 @@
+package customerimporter
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMXProvider(t *testing.T) {
+	tests := []struct {
+		domain   string
+		hosts    []string
+		provider MailProvider
+	}{
+		{"a.io", []string{"aspmx.l.google.com.", "alt1.aspmx.l.google.com."}, ProviderGoogle},
+		{"a.io", []string{"smtp.google.com."}, ProviderGoogle},
+		{"a.io", []string{"a-io.mail.protection.outlook.com."}, ProviderMicrosoft},
+		{"a.io", []string{"MX.ZOHO.EU."}, ProviderZoho},
+		{"a.io", []string{"mail.protonmail.ch."}, ProviderProton},
+		{"a.io", []string{"mx0a-001.pphosted.com."}, ProviderProofpoint},
+		{"a.io", []string{"mail.a.io.", "aspmx.l.google.com."}, ProviderSelfHosted},
+		{"a.io", []string{"a.io."}, ProviderSelfHosted},
+		{"a.io", []string{"mx.hosting.net."}, ProviderOther},
+		{"a.io", []string{"notgoogle.com."}, ProviderOther},
+		{"a.io", nil, ""},
+	}
+
+	t.Log("Should infer provider by the most preferred MX host")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.hosts)
+
+		var records []*net.MX
+		for i, host := range test.hosts {
+			records = append(records, &net.MX{Host: host, Pref: uint16(10 * (i + 1))})
+		}
+		if provider := mxProvider(test.domain, records); provider != test.provider {
+			t.Errorf("should infer %q, but got %q", test.provider, provider)
+		}
+	}
+}
+
+func TestDetectProviders(t *testing.T) {
+	resolver := &fakeResolver{
+		records: map[string][]*net.MX{
+			"a.io": {{Host: "aspmx.l.google.com.", Pref: 1}},
+			"b.io": {{Host: "mail.b.io.", Pref: 10}},
+		},
+		lookups: map[string]int{},
+	}
+	verifier := &MXVerifier{Resolver: resolver}
+	records := "email\na@a.io\na@b.io\na@c.io\n"
+
+	t.Log("Should set provider of domains with MX records")
+	result, err := Import(strings.NewReader(records), "email", DetectProviders(), VerifyMXWith(verifier))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 1, Share: 1.0 / 3, MX: MXFound, Provider: ProviderGoogle},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3, MX: MXFound, Provider: ProviderSelfHosted},
+		{Domain: "c.io", EmailsCount: 1, Share: 1.0 / 3, MX: MXNotFound},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should result with: %v, but got %v", expected, *result)
+	}
+
+	t.Log("Should not set provider of verified domains without the option")
+	result, err = Import(strings.NewReader(records), "email", VerifyMXWith(verifier))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	for _, e := range *result {
+		if e.Provider != "" {
+			t.Errorf("%v should not have provider, but got %v", e.Domain, e.Provider)
+		}
+	}
+	if resolver.lookups["a.io"] != 1 {
+		t.Errorf("should look up a.io once, but got %v lookups", resolver.lookups["a.io"])
+	}
+}
@@ -0,0 +1,53 @@
+package customerimporter
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// name of the column appended to rejected records
+const rejectReasonField = "reason"
+
+// Write records skipped because of invalid or duplicate email and malformed
+// records skipped by SkipMalformedRows to w as csv with the reason appended
+// in the last column, e.g. to hand the records back to be fixed. The header
+// with the reason field is written before the first record unless
+// WithColumnIndex is used. A record is written once for every skipped email.
+func WithRejectWriter(w io.Writer) Option {
+	return func(f *CustomerImporter) { f.rejectWriter = w }
+}
+
+// rejects holds csv writer of the rejected records
+type rejects struct {
+	writer  *csv.Writer // writer of the records
+	started bool        // header is written
+}
+
+// writes the skipped record with the reason if WithRejectWriter is used
+func (c *CustomerImporter) reject(record []string, reason error) error {
+	if c.rejectWriter == nil {
+		return nil
+	}
+
+	// initialize writer and write the header
+	if c.rejects.writer == nil {
+		c.rejects.writer = csv.NewWriter(c.rejectWriter)
+		if c.delimiter != 0 {
+			c.rejects.writer.Comma = c.delimiter
+		}
+	}
+	if !c.rejects.started && c.header != nil {
+		if err := c.rejects.writer.Write(append(append([]string(nil), c.header...), rejectReasonField)); err != nil {
+			return err
+		}
+	}
+	c.rejects.started = true
+
+	if err := c.rejects.writer.Write(append(append([]string(nil), record...), reason.Error())); err != nil {
+		return err
+	}
+
+	// skipped records are rare, so they are written immediately
+	c.rejects.writer.Flush()
+	return c.rejects.writer.Error()
+}
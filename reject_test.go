@@ -0,0 +1,68 @@
+package customerimporter
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithRejectWriter(t *testing.T) {
+	records := "name;email\n" +
+		"A;a@a.io\n" +
+		"B;invalid\n" +
+		"C;a@a.io\n" +
+		"D\n" +
+		"E;b@b.io\n"
+
+	data := []struct {
+		name     string
+		options  []Option
+		expected string
+	}{
+		{"sequential", nil, "name;email;reason\n" +
+			"B;invalid;Email is not valid\n" +
+			"C;a@a.io;Email already added\n" +
+			"D;wrong number of fields\n"},
+		{"with workers", []Option{WithWorkers(2)}, "name;email;reason\n" +
+			"B;invalid;Email is not valid\n" +
+			"C;a@a.io;Email already added\n" +
+			"D;wrong number of fields\n"},
+	}
+
+	t.Log("Should write skipped records with the reason")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		var rejected bytes.Buffer
+		options := append([]Option{WithDelimiter(';'), WithRejectWriter(&rejected), SkipErrInvalidEmails(), SkipErrDuplicateEmails(), SkipMalformedRows()}, d.options...)
+		result, err := ImportWithStats(strings.NewReader(records), "email", options...)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		if result.ValidEmails != 2 {
+			t.Errorf("should count 2 emails, but got %v", result.ValidEmails)
+		}
+		if rejected.String() != d.expected {
+			t.Errorf("should write %q, but got %q", d.expected, rejected.String())
+		}
+	}
+
+	t.Log("Should write records without header as they are")
+	var rejected bytes.Buffer
+	if _, err := Import(strings.NewReader("a@a.io,A\ninvalid,B\n"), "", WithColumnIndex(0), WithRejectWriter(&rejected), SkipErrInvalidEmails()); err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if expected := "invalid,B,Email is not valid\n"; rejected.String() != expected {
+		t.Errorf("should write %q, but got %q", expected, rejected.String())
+	}
+
+	t.Log("Should not write records aborting the import")
+	rejected.Reset()
+	if _, err := Import(strings.NewReader(records), "email", WithDelimiter(';'), WithRejectWriter(&rejected)); !errors.Is(err, ErrEmailIsNotValid) {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailIsNotValid, err)
+	}
+	if rejected.Len() != 0 {
+		t.Errorf("should not write records, but got %q", rejected.String())
+	}
+}
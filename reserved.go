@@ -0,0 +1,72 @@
+package customerimporter
+
+import (
+	"slices"
+	"strings"
+)
+
+// ReservedDomain is the name of the entry which sums emails of reserved
+// domains and synthetic addresses bucketed by BucketReservedDomains
+const ReservedDomain = "reserved"
+
+// handling of emails of reserved domains and synthetic addresses
+const (
+	keepReserved = iota
+	dropReserved
+	bucketReserved
+)
+
+// reservedDomains are second-level domains reserved for documentation by
+// RFC 2606, their subdomains are reserved too
+var reservedDomains = []string{"example.com", "example.net", "example.org"}
+
+// reservedTLDs are top-level domains reserved by RFC 2606 and RFC 6761 and
+// localhost
+var reservedTLDs = []string{"test", "example", "invalid", "localhost", "local"}
+
+// syntheticDomains are registered domains which are commonly made up in test
+// data instead of real addresses
+var syntheticDomains = []string{"test.com", "fake.com", "domain.com", "foo.com", "foobar.com", "asdf.com", "noemail.com", "nomail.com"}
+
+// syntheticLocalParts are local parts of made up addresses, digits following
+// them are ignored, e.g. test123@company.com
+var syntheticLocalParts = []string{"test", "tester", "testing", "asdf", "qwerty", "fake", "dummy", "noemail", "nomail", "none", "null", "xxx"}
+
+// Don't count emails of domains reserved by RFC 2606 and RFC 6761, e.g.
+// john@example.com, a@b.test or root@localhost, and obviously synthetic
+// addresses, e.g. test123@company.com or john@fake.com, which are often left
+// in exports by QA. Dropped emails are neither validated for duplicates nor
+// counted as valid, they are counted in ImportResult.ReservedEmails.
+func DropReservedDomains() Option {
+	return func(f *CustomerImporter) { f.reservedHandling = dropReserved }
+}
+
+// Count emails of domains reserved by RFC 2606 and RFC 6761 and obviously
+// synthetic addresses like DropReservedDomains drops them, but sum them into
+// the ReservedDomain entry instead of their domains. They are also counted in
+// ImportResult.ReservedEmails.
+func BucketReservedDomains() Option {
+	return func(f *CustomerImporter) { f.reservedHandling = bucketReserved }
+}
+
+// IsReservedEmail reports whether the normalized email is of a reserved domain
+// or an obviously synthetic address
+func IsReservedEmail(email string) bool {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false
+	}
+	local, domain := strings.ToLower(email[:at]), email[at+1:]
+	return isReservedDomain(domain) || slices.Contains(syntheticDomains, domain) ||
+		slices.Contains(syntheticLocalParts, strings.TrimRight(local, "0123456789"))
+}
+
+// reports whether the normalized domain is reserved or under a reserved
+// top-level domain
+func isReservedDomain(domain string) bool {
+	if matchesDomain(domain, reservedDomains) {
+		return true
+	}
+	tld := domain[strings.LastIndexByte(domain, '.')+1:]
+	return slices.Contains(reservedTLDs, tld)
+}
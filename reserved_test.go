@@ -0,0 +1,71 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsReservedEmail(t *testing.T) {
+	tests := []struct {
+		email    string
+		reserved bool
+	}{
+		{"john@example.com", true},
+		{"john@mail.example.org", true},
+		{"john@shop.test", true},
+		{"john@a.invalid", true},
+		{"root@localhost", true},
+		{"john@fake.com", true},
+		{"test@company.com", true},
+		{"Test123@company.com", true},
+		{"qwerty@company.com", true},
+		{"john@company.com", false},
+		{"john@example.com.pl", false},
+		{"john@myexample.com", false},
+		{"tester.john@company.com", false},
+		{"invalid", false},
+	}
+
+	t.Log("Should report emails of reserved domains and synthetic addresses")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.email)
+
+		if reserved := IsReservedEmail(test.email); reserved != test.reserved {
+			t.Errorf("should return %v, but got %v", test.reserved, reserved)
+		}
+	}
+}
+
+func TestReservedDomains(t *testing.T) {
+	records := "email\na@a.io\nb@example.com\ntest@a.io\nc@example.com\nb@example.com\n"
+
+	t.Log("Should drop emails of reserved domains before deduplication")
+	result, err := ImportWithStats(strings.NewReader(records), "email", DropReservedDomains())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1, Share: 1}}
+	if !reflect.DeepEqual(result.Domains, expected) {
+		t.Errorf("should count %+v, but got %+v", expected, result.Domains)
+	}
+	if result.ValidEmails != 1 || result.ReservedEmails != 4 || result.DuplicateEmails != 0 {
+		t.Errorf("should count dropped emails as reserved, but got %+v", result)
+	}
+
+	t.Log("Should count emails of reserved domains in a separate entry")
+	result, err = ImportWithStats(strings.NewReader(records), "email", BucketReservedDomains(), SkipErrDuplicateEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected = EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 1, Share: 0.25},
+		{Domain: ReservedDomain, EmailsCount: 3, Share: 0.75},
+	}
+	if !reflect.DeepEqual(result.Domains, expected) {
+		t.Errorf("should count %+v, but got %+v", expected, result.Domains)
+	}
+	if result.DuplicateEmails != 1 {
+		t.Errorf("should deduplicate bucketed emails, but got %d", result.DuplicateEmails)
+	}
+}
@@ -0,0 +1,146 @@
+package customerimporter
+
+import "sort"
+
+// OtherDomain is the name of the entry which sums emails of collapsed domains
+const OtherDomain = "other"
+
+// Return only n domains with the most emails. If collapseRest is set, emails
+// of the rest domains are summed into the OtherDomain entry appended to the
+// end of the result. If n < 1 all domains are returned.
+func TopN(n int, collapseRest bool) Option {
+	return func(f *CustomerImporter) {
+		f.topN = n
+		f.collapseRest = collapseRest
+	}
+}
+
+// Return only domains with at least n emails, e.g. to leave the long tail of
+// single email domains out of reports. If collapseBelow is set, emails of the
+// dropped domains are summed into the OtherDomain entry appended to the end of
+// the result. If n < 2 all domains are returned. Together with TopN, the
+// domains are limited by count first.
+func MinCount(n int, collapseBelow bool) Option {
+	return func(f *CustomerImporter) {
+		f.minCount = n
+		f.collapseBelow = collapseBelow
+	}
+}
+
+// keeps domains of the result according to MinCount and TopN options and
+// returns the entry of collapsed domains, if any
+func (c *CustomerImporter) keepDomains(result EmailsByDomainQtyList) (EmailsByDomainQtyList, *EmailsByDomainQty) {
+	limitCount := c.minCount > 1
+	limitTop := c.topN > 0 && len(result) > c.topN
+	if !limitCount && !limitTop {
+		return result, nil
+	}
+
+	// domains with equal count are selected by name to be deterministic
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].EmailsCount == result[j].EmailsCount {
+			return result[i].Domain < result[j].Domain
+		}
+		return result[i].EmailsCount > result[j].EmailsCount
+	})
+
+	// domains are sorted by count, so the dropped ones are the tail
+	var collapsed EmailsByDomainQtyList
+	if limitCount {
+		n := sort.Search(len(result), func(i int) bool { return result[i].EmailsCount < c.minCount })
+		if c.collapseBelow {
+			collapsed = append(collapsed, result[n:]...)
+		}
+		result = result[:n]
+	}
+	if c.topN > 0 && len(result) > c.topN {
+		if c.collapseRest {
+			collapsed = append(collapsed, result[c.topN:]...)
+		}
+		result = result[:c.topN]
+	}
+	if len(collapsed) == 0 {
+		return result, nil
+	}
+
+	other := &EmailsByDomainQty{Domain: OtherDomain}
+	for _, e := range collapsed {
+		other.EmailsCount += e.EmailsCount
+		other.Occurrences += e.Occurrences
+		other.RoleAccounts += e.RoleAccounts
+		other.Breakdown = mergeBreakdown(other.Breakdown, e.Breakdown)
+		other.Series = mergeBreakdown(other.Series, e.Series)
+		for _, s := range e.Samples {
+			if len(other.Samples) < c.samplesPerDomain {
+				other.Samples = append(other.Samples, s)
+			}
+		}
+	}
+	return result, other
+}
+
+// Merge returns emails counted in p and other summed by domain and sorted by
+// domain name, occurrences, role accounts, breakdowns and series are summed
+// too, samples are concatenated. Shares are computed of all merged emails. MX
+// status of p is kept unless only other is verified.
+func (p EmailsByDomainQtyList) Merge(other EmailsByDomainQtyList) EmailsByDomainQtyList {
+	merged := make(map[string]EmailsByDomainQty, len(p)+len(other))
+	for _, list := range []EmailsByDomainQtyList{p, other} {
+		for _, e := range list {
+			m := merged[e.Domain]
+			m.Domain = e.Domain
+			m.EmailsCount += e.EmailsCount
+			m.Occurrences += e.Occurrences
+			m.RoleAccounts += e.RoleAccounts
+			m.Breakdown = mergeBreakdown(m.Breakdown, e.Breakdown)
+			m.Series = mergeBreakdown(m.Series, e.Series)
+			m.Samples = append(m.Samples, e.Samples...)
+			if m.MX == MXNotVerified {
+				m.MX = e.MX
+			}
+			merged[e.Domain] = m
+		}
+	}
+
+	result := make(EmailsByDomainQtyList, 0, len(merged))
+	total := 0
+	for _, e := range merged {
+		result = append(result, e)
+		total += e.EmailsCount
+	}
+	sort.Sort(result)
+
+	// compute fraction of all merged emails and of role accounts
+	for i := range result {
+		if total > 0 {
+			result[i].Share = float64(result[i].EmailsCount) / float64(total)
+		}
+		if result[i].RoleAccounts > 0 {
+			result[i].RoleShare = float64(result[i].RoleAccounts) / float64(result[i].EmailsCount)
+		}
+	}
+	return result
+}
+
+// Diff returns change of emails count of every domain from other to p sorted
+// by domain name, e.g. this week's result diffed with the last week's one.
+// EmailsCount of the returned entries is the difference, negative if emails
+// were removed, domains with unchanged count are omitted. Shares are not set.
+func (p EmailsByDomainQtyList) Diff(other EmailsByDomainQtyList) EmailsByDomainQtyList {
+	delta := make(map[string]int, len(p)+len(other))
+	for _, e := range p {
+		delta[e.Domain] += e.EmailsCount
+	}
+	for _, e := range other {
+		delta[e.Domain] -= e.EmailsCount
+	}
+
+	var result EmailsByDomainQtyList
+	for domain, count := range delta {
+		if count != 0 {
+			result = append(result, EmailsByDomainQty{Domain: domain, EmailsCount: count})
+		}
+	}
+	sort.Sort(result)
+	return result
+}
@@ -0,0 +1,201 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTopN(t *testing.T) {
+	records := "email\n" +
+		"a@a.io\nb@a.io\nc@a.io\n" +
+		"a@b.io\n" +
+		"a@c.io\nb@c.io\n" +
+		"a@d.io\n" +
+		"a@e.io\n"
+
+	data := []struct {
+		options []Option
+		result  EmailsByDomainQtyList
+	}{
+		// domains with equal count are selected by name, result is sorted by name
+		{[]Option{TopN(3, false)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.375},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.125},
+			{Domain: "c.io", EmailsCount: 2, Share: 0.25},
+		}},
+
+		// rest domains are collapsed into the last entry
+		{[]Option{TopN(2, true), SortByCount(), SortDescending()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.375},
+			{Domain: "c.io", EmailsCount: 2, Share: 0.25},
+			{Domain: OtherDomain, EmailsCount: 3, Share: 0.375},
+		}},
+
+		// all domains are returned if n is large enough
+		{[]Option{TopN(5, true)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.375},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.125},
+			{Domain: "c.io", EmailsCount: 2, Share: 0.25},
+			{Domain: "d.io", EmailsCount: 1, Share: 0.125},
+			{Domain: "e.io", EmailsCount: 1, Share: 0.125},
+		}},
+
+		// limit is disabled if n < 1
+		{[]Option{TopN(0, true)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.375},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.125},
+			{Domain: "c.io", EmailsCount: 2, Share: 0.25},
+			{Domain: "d.io", EmailsCount: 1, Share: 0.125},
+			{Domain: "e.io", EmailsCount: 1, Share: 0.125},
+		}},
+	}
+
+	for testNumber, d := range data {
+		t.Logf("Case: %v", testNumber)
+
+		result, err := ImportWithStats(strings.NewReader(records), "email", d.options...)
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(result.Domains, d.result) {
+			t.Errorf("should result with: %v, but got %v", d.result, result.Domains)
+		}
+		if result.DistinctDomains != 5 {
+			t.Errorf("should count 5 distinct domains, but got %v", result.DistinctDomains)
+		}
+	}
+}
+
+func TestMinCount(t *testing.T) {
+	records := "email\n" +
+		"a@a.io\nb@a.io\nc@a.io\n" +
+		"a@b.io\n" +
+		"a@c.io\nb@c.io\n" +
+		"a@d.io\n" +
+		"a@e.io\n"
+
+	data := []struct {
+		name    string
+		options []Option
+		result  EmailsByDomainQtyList
+	}{
+		{"drop", []Option{MinCount(2, false)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.375},
+			{Domain: "c.io", EmailsCount: 2, Share: 0.25},
+		}},
+		{"collapse", []Option{MinCount(2, true)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.375},
+			{Domain: "c.io", EmailsCount: 2, Share: 0.25},
+			{Domain: OtherDomain, EmailsCount: 3, Share: 0.375},
+		}},
+		{"all dropped", []Option{MinCount(4, false)}, EmailsByDomainQtyList{}},
+		{"all collapsed", []Option{MinCount(4, true)}, EmailsByDomainQtyList{
+			{Domain: OtherDomain, EmailsCount: 8, Share: 1},
+		}},
+		{"with top domains", []Option{MinCount(2, true), TopN(1, true)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.375},
+			{Domain: OtherDomain, EmailsCount: 5, Share: 0.625},
+		}},
+		{"only dropped domains collapsed", []Option{MinCount(2, false), TopN(1, true)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.375},
+			{Domain: OtherDomain, EmailsCount: 2, Share: 0.25},
+		}},
+		{"disabled", []Option{MinCount(1, true)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.375},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.125},
+			{Domain: "c.io", EmailsCount: 2, Share: 0.25},
+			{Domain: "d.io", EmailsCount: 1, Share: 0.125},
+			{Domain: "e.io", EmailsCount: 1, Share: 0.125},
+		}},
+	}
+
+	t.Log("Should return only domains with enough emails")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := ImportWithStats(strings.NewReader(records), "email", d.options...)
+		if err != nil {
+			t.Errorf("should pass the test, but got error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(result.Domains, d.result) {
+			t.Errorf("should result with: %v, but got %v", d.result, result.Domains)
+		}
+		if result.DistinctDomains != 5 {
+			t.Errorf("should count 5 distinct domains, but got %v", result.DistinctDomains)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	data := []struct {
+		a, b   EmailsByDomainQtyList
+		result EmailsByDomainQtyList
+	}{
+		// counts are summed by domain
+		{
+			EmailsByDomainQtyList{{Domain: "b.io", EmailsCount: 1}, {Domain: "a.io", EmailsCount: 2, MX: MXFound}},
+			EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1}, {Domain: "c.io", EmailsCount: 4, MX: MXNotFound}},
+			EmailsByDomainQtyList{
+				{Domain: "a.io", EmailsCount: 3, Share: 0.375, MX: MXFound},
+				{Domain: "b.io", EmailsCount: 1, Share: 0.125},
+				{Domain: "c.io", EmailsCount: 4, Share: 0.5, MX: MXNotFound},
+			},
+		},
+
+		// empty lists
+		{
+			nil,
+			EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1}},
+			EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1, Share: 1}},
+		},
+		{nil, nil, EmailsByDomainQtyList{}},
+	}
+
+	t.Log("Should merge results")
+	for _, d := range data {
+		t.Logf("Case: %v %v", d.a, d.b)
+
+		result := d.a.Merge(d.b)
+		if !reflect.DeepEqual(result, d.result) {
+			t.Errorf("should return %v, but got %v", d.result, result)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	data := []struct {
+		a, b   EmailsByDomainQtyList
+		result EmailsByDomainQtyList
+	}{
+		// added, removed and changed domains, unchanged are omitted
+		{
+			EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 3}, {Domain: "b.io", EmailsCount: 1}, {Domain: "d.io", EmailsCount: 2}},
+			EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1}, {Domain: "c.io", EmailsCount: 4}, {Domain: "d.io", EmailsCount: 2}},
+			EmailsByDomainQtyList{
+				{Domain: "a.io", EmailsCount: 2},
+				{Domain: "b.io", EmailsCount: 1},
+				{Domain: "c.io", EmailsCount: -4},
+			},
+		},
+
+		// equal results
+		{
+			EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1}},
+			EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1}},
+			nil,
+		},
+	}
+
+	t.Log("Should return changed counts")
+	for _, d := range data {
+		t.Logf("Case: %v %v", d.a, d.b)
+
+		result := d.a.Diff(d.b)
+		if !reflect.DeepEqual(result, d.result) {
+			t.Errorf("should return %v, but got %v", d.result, result)
+		}
+	}
+}
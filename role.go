@@ -0,0 +1,43 @@
+package customerimporter
+
+import "strings"
+
+// roleLocalParts are local parts of mailboxes of a role or a department
+// rather than a person
+var roleLocalParts = map[string]bool{
+	"info": true, "contact": true, "hello": true, "office": true, "team": true, "mail": true,
+	"admin": true, "administrator": true, "root": true, "webmaster": true, "postmaster": true, "hostmaster": true,
+	"abuse": true, "security": true, "support": true, "help": true, "helpdesk": true, "service": true,
+	"sales": true, "marketing": true, "billing": true, "accounts": true, "finance": true, "orders": true,
+	"hr": true, "jobs": true, "careers": true, "press": true, "media": true, "newsletter": true,
+	"enquiries": true, "inquiries": true, "feedback": true, "privacy": true, "legal": true,
+	"noreply": true, "no-reply": true, "no_reply": true, "donotreply": true, "do-not-reply": true,
+}
+
+// Don't count emails of role accounts like info@, admin@, sales@ or noreply@,
+// which are mailboxes of a role rather than a person. Excluded emails are
+// neither validated for duplicates nor counted as valid, they are counted in
+// ImportResult.RoleAccounts.
+func ExcludeRoleAccounts() Option { return func(f *CustomerImporter) { f.excludeRoleAccounts = true } }
+
+// Count emails of role accounts like info@, admin@, sales@ or noreply@ by
+// domain in EmailsByDomainQty RoleAccounts and their fraction of the emails of
+// the domain in RoleShare. All counted role accounts are summed in
+// ImportResult.RoleAccounts.
+func ReportRoleAccounts() Option { return func(f *CustomerImporter) { f.reportRoleAccounts = true } }
+
+// IsRoleAccount reports whether the local part of the email is a role account
+// like info@ or noreply@, case and subaddress are ignored, e.g. Sales+eu@
+func IsRoleAccount(email string) bool {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false
+	}
+	local, _, _ := strings.Cut(email[:at], "+")
+	return roleLocalParts[strings.ToLower(local)]
+}
+
+// reports whether the email is checked and is a role account
+func (c *CustomerImporter) isRoleAccount(r parsedRecord) bool {
+	return (c.excludeRoleAccounts || c.reportRoleAccounts) && r.err == nil && !c.countValues && IsRoleAccount(r.email)
+}
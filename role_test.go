@@ -0,0 +1,64 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsRoleAccount(t *testing.T) {
+	tests := []struct {
+		email string
+		role  bool
+	}{
+		{"info@a.io", true},
+		{"Sales@a.io", true},
+		{"no-reply@a.io", true},
+		{"support+eu@a.io", true},
+		{"john@a.io", false},
+		{"info.john@a.io", false},
+		{"invalid", false},
+	}
+
+	t.Log("Should report emails of role accounts")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.email)
+
+		if role := IsRoleAccount(test.email); role != test.role {
+			t.Errorf("should return %v, but got %v", test.role, role)
+		}
+	}
+}
+
+func TestRoleAccounts(t *testing.T) {
+	records := "email\ninfo@a.io\njohn@a.io\nadmin@a.io\nann@a.io\nsales@b.io\ninfo@a.io\n"
+
+	t.Log("Should exclude emails of role accounts before deduplication")
+	result, err := ImportWithStats(strings.NewReader(records), "email", ExcludeRoleAccounts())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Share: 1}}
+	if !reflect.DeepEqual(result.Domains, expected) {
+		t.Errorf("should count %+v, but got %+v", expected, result.Domains)
+	}
+	if result.RoleAccounts != 4 || result.DuplicateEmails != 0 {
+		t.Errorf("should count excluded role accounts, but got %+v", result)
+	}
+
+	t.Log("Should report role accounts of domains")
+	result, err = ImportWithStats(strings.NewReader(records), "email", ReportRoleAccounts(), SkipErrDuplicateEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected = EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 4, RoleAccounts: 2, RoleShare: 0.5, Share: 0.8},
+		{Domain: "b.io", EmailsCount: 1, RoleAccounts: 1, RoleShare: 1, Share: 0.2},
+	}
+	if !reflect.DeepEqual(result.Domains, expected) {
+		t.Errorf("should count %+v, but got %+v", expected, result.Domains)
+	}
+	if result.RoleAccounts != 3 {
+		t.Errorf("should count 3 role accounts, but got %d", result.RoleAccounts)
+	}
+}
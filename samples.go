@@ -0,0 +1,21 @@
+package customerimporter
+
+// Keep up to n first counted emails of every domain with their location, so
+// records of an unexpected domain are found quickly. The samples are returned
+// in EmailsByDomainQty Samples.
+func WithSamples(n int) Option { return func(f *CustomerImporter) { f.samplesPerDomain = n } }
+
+// Sample is a counted email and its location in the input
+type Sample struct {
+	Email string `json:"email" yaml:"email"`                   // email field as is, masked by HashEmails
+	File  string `json:"file,omitempty" yaml:"file,omitempty"` // name of the file, set by ImportFromFiles
+	Line  int    `json:"line" yaml:"line"`                     // line of the record
+}
+
+// keeps the counted email as a sample of its domain unless there are enough
+func (c *CustomerImporter) sample(r parsedRecord) {
+	if len(c.samples[r.domain]) >= c.samplesPerDomain {
+		return
+	}
+	c.samples[r.domain] = append(c.samples[r.domain], Sample{Email: c.display(r.value), File: c.fileName, Line: r.line})
+}
@@ -0,0 +1,65 @@
+package customerimporter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithSamples(t *testing.T) {
+	records := "email\n" +
+		"a@a.io\n" +
+		"invalid\n" +
+		"B@A.io\n" +
+		"c@a.io\n" +
+		"a@b.io\n"
+
+	data := []struct {
+		name     string
+		options  []Option
+		expected EmailsByDomainQtyList
+	}{
+		{"first emails", nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.75, Samples: []Sample{{Email: "a@a.io", Line: 2}, {Email: "B@A.io", Line: 4}}},
+			{Domain: "b.io", EmailsCount: 1, Share: 0.25, Samples: []Sample{{Email: "a@b.io", Line: 6}}},
+		}},
+		{"collapsed domains", []Option{TopN(1, true), SortByCount(), SortDescending()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.75, Samples: []Sample{{Email: "a@a.io", Line: 2}, {Email: "B@A.io", Line: 4}}},
+			{Domain: OtherDomain, EmailsCount: 1, Share: 0.25, Samples: []Sample{{Email: "a@b.io", Line: 6}}},
+		}},
+	}
+
+	t.Log("Should keep first counted emails of every domain")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		options := append([]Option{WithSamples(2), SkipErrInvalidEmails()}, d.options...)
+		result, err := Import(strings.NewReader(records), "email", options...)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		if !reflect.DeepEqual(*result, d.expected) {
+			t.Errorf("should result with: %v, but got %v", d.expected, *result)
+		}
+	}
+}
+
+func TestWithSamplesFiles(t *testing.T) {
+	t.Log("Should keep file name of the samples")
+
+	fileName := filepath.Join(t.TempDir(), "customers.csv")
+	if err := os.WriteFile(fileName, []byte("email\na@a.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ImportFromFiles([]string{fileName}, "email", WithSamples(1))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := []Sample{{Email: "a@a.io", File: fileName, Line: 2}}
+	if !reflect.DeepEqual((*result)[0].Samples, expected) {
+		t.Errorf("should keep %v, but got %v", expected, (*result)[0].Samples)
+	}
+}
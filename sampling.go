@@ -0,0 +1,38 @@
+package customerimporter
+
+import (
+	"math"
+	"strings"
+)
+
+// Count only a deterministic sample of the records, e.g. to profile domains
+// of an enormous file quickly. Record is selected by hash of its email field
+// and the seed, so every run with the same seed selects the same records and
+// duplicates of a selected email are selected too. Records out of the sample
+// are neither validated nor counted, they're reported in SampledOutRows of
+// ImportResult, EstimatedEmails extrapolates the counted emails to the whole
+// input. Rate is the selected fraction of the emails, sampling is disabled if
+// it's not between 0 and 1.
+func SampleRate(rate float64, seed uint64) Option {
+	return func(f *CustomerImporter) {
+		f.sampleRate, f.sampleSeed = 0, seed
+		if rate > 0 && rate < 1 {
+			f.sampleRate = rate
+		}
+	}
+}
+
+// reports whether the record of the email field value is in the sample
+func (c *CustomerImporter) sampled(value string) bool {
+	h := mix64(fnv1a(strings.ToLower(strings.TrimSpace(value))) ^ c.sampleSeed)
+	return float64(h) < c.sampleRate*math.MaxUint64
+}
+
+// returns amount of valid emails of the whole input estimated by the sample,
+// 0 without sampling
+func (c *CustomerImporter) extrapolate(validEmails int) int {
+	if c.sampleRate == 0 {
+		return 0
+	}
+	return int(math.Round(float64(validEmails) / c.sampleRate))
+}
@@ -0,0 +1,74 @@
+package customerimporter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSampleRate(t *testing.T) {
+	// 10000 emails of 4 domains, a.io has 40% of them
+	var b strings.Builder
+	b.WriteString("email\n")
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&b, "user%d@%c.io\n", i, "aabcd"[i%5])
+	}
+	records := b.String()
+
+	t.Log("Should count sample of the emails and extrapolate their total")
+	sample, err := ImportWithStats(strings.NewReader(records), "email", SampleRate(0.1, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.ValidEmails < 850 || sample.ValidEmails > 1150 {
+		t.Errorf("should count about 1000 emails, but got %v", sample.ValidEmails)
+	}
+	if sample.ValidEmails+sample.SampledOutRows != 10000 || sample.RowsRead != 10000 {
+		t.Errorf("should read 10000 rows, but got %v counted and %v sampled out of %v", sample.ValidEmails, sample.SampledOutRows, sample.RowsRead)
+	}
+	if sample.SampleRate != 0.1 || sample.EstimatedEmails != int(math.Round(float64(sample.ValidEmails)/0.1)) {
+		t.Errorf("should estimate %v emails, but got %v", sample.ValidEmails*10, sample.EstimatedEmails)
+	}
+	if share := sample.Domains[0].Share; sample.Domains[0].Domain != "a.io" || share < 0.35 || share > 0.45 {
+		t.Errorf("should keep share of a.io about 0.4, but got %v", sample.Domains[0])
+	}
+
+	t.Log("Should select the same sample with the same seed")
+	again, err := ImportWithStats(strings.NewReader(records), "email", SampleRate(0.1, 42), WithWorkers(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again.Domains, sample.Domains) {
+		t.Errorf("should return %v, but got %v", sample.Domains, again.Domains)
+	}
+	other, err := ImportWithStats(strings.NewReader(records), "email", SampleRate(0.1, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(other.Domains, sample.Domains) {
+		t.Errorf("should select other sample with other seed, but got %v", other.Domains)
+	}
+
+	t.Log("Should select duplicates of sampled emails regardless of case")
+	duplicates := "email\n" + strings.Repeat("a@a.io\nA@A.IO\n b@a.io\nb@a.io\n", 50)
+	result, err := ImportWithStats(strings.NewReader(duplicates), "email", SampleRate(0.5, 1), SkipErrDuplicateEmails(), CaseInsensitiveEmails(), TrimEmailField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counted := result.ValidEmails + result.DuplicateEmails; counted%100 != 0 {
+		t.Errorf("should select all records of an email, but got %v", counted)
+	}
+
+	t.Log("Should not sample if rate isn't between 0 and 1")
+	for _, rate := range []float64{0, 1, 1.5, -0.5} {
+		result, err := ImportWithStats(strings.NewReader(records), "email", SampleRate(rate, 42))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ValidEmails != 10000 || result.SampleRate != 0 || result.EstimatedEmails != 0 {
+			t.Errorf("should count all emails with rate %v, but got %v, %v", rate, result.ValidEmails, result.EstimatedEmails)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package customerimporter
+
+import (
+	"errors"
+	"io"
+	"iter"
+)
+
+// returned by the handler of ImportSeq when the loop is stopped
+var errStopIteration = errors.New("iteration stopped")
+
+// All returns iterator over domains and their emails count in the order of
+// the list
+func (p EmailsByDomainQtyList) All() iter.Seq2[string, int] {
+	return func(yield func(string, int) bool) {
+		for _, e := range p {
+			if !yield(e.Domain, e.EmailsCount) {
+				return
+			}
+		}
+	}
+}
+
+// imports from reader as the returned iterator is ranged over, yielding
+// events of ImportStream as records are parsed. Breaking the loop stops the
+// import without reading the rest of the input. Error aborting the import is
+// yielded last with empty event.
+func ImportSeq(r io.Reader, emailFieldName string, options ...Option) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		err := ImportStream(r, emailFieldName, func(ev Event) error {
+			if !yield(ev, nil) {
+				return errStopIteration
+			}
+			return nil
+		}, options...)
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(Event{}, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package customerimporter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	list := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2},
+		{Domain: "b.io", EmailsCount: 1},
+		{Domain: "c.io", EmailsCount: 3},
+	}
+
+	t.Log("Should iterate over domains in the order of the list")
+	var domains []string
+	total := 0
+	for domain, count := range list.All() {
+		domains = append(domains, domain)
+		total += count
+	}
+	if strings.Join(domains, ",") != "a.io,b.io,c.io" || total != 6 {
+		t.Errorf("should iterate over all domains, but got %v with %v emails", domains, total)
+	}
+
+	t.Log("Should stop iteration early")
+	domains = nil
+	for domain := range list.All() {
+		domains = append(domains, domain)
+		break
+	}
+	if len(domains) != 1 {
+		t.Errorf("should stop after the first domain, but got %v", domains)
+	}
+}
+
+func TestImportSeq(t *testing.T) {
+	records := "email\na@a.io\nb@a.io\ninvalid\na@b.io\n"
+
+	t.Log("Should yield events of parsed records")
+	counts := map[string]int{}
+	for ev, err := range ImportSeq(strings.NewReader(records), "email", SkipErrInvalidEmails()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Type == EventValidEmail {
+			counts[ev.Domain]++
+		}
+	}
+	if counts["a.io"] != 2 || counts["b.io"] != 1 {
+		t.Errorf("should count emails of all records, but got %v", counts)
+	}
+
+	t.Log("Should stop the import when the loop is broken")
+	events := 0
+	for _, err := range ImportSeq(strings.NewReader(records), "email") {
+		if err != nil {
+			t.Fatalf("should not yield error after break, but got error %v", err)
+		}
+		events++
+		break
+	}
+	if events != 1 {
+		t.Errorf("should yield 1 event, but got %v", events)
+	}
+
+	t.Log("Should yield error aborting the import")
+	var last error
+	for _, err := range ImportSeq(strings.NewReader(records), "email") {
+		last = err
+	}
+	if !errors.Is(last, ErrEmailIsNotValid) {
+		t.Errorf("should yield error: %v, but got error %v", ErrEmailIsNotValid, last)
+	}
+}
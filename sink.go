@@ -0,0 +1,65 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrUnknownSink is raised when no sink is registered for the URL scheme
+var ErrUnknownSink = errors.New("Sink is not registered for scheme")
+
+// Sink stores results of imports, e.g. in a database table, so scheduled
+// imports build a history which can be queried
+type Sink interface {
+	// Write stores the result of the import described by the run
+	Write(ctx context.Context, run Run, result *ImportResult) error
+	// Close releases resources of the sink
+	Close() error
+}
+
+// Run describes the import whose result is written to Sink
+type Run struct {
+	Input      string    // name of the imported input, e.g. path of the file
+	EmailField string    // name of the email field
+	Time       time.Time // time the import finished
+}
+
+// SinkOpener returns Sink of the URL
+type SinkOpener func(u *url.URL) (Sink, error)
+
+// sinks registered by URL scheme
+var (
+	sinksMu sync.RWMutex
+	sinks   = map[string]SinkOpener{}
+)
+
+// RegisterSink makes sinks of the URL scheme available to OpenSink. It is
+// called by init of packages implementing sinks, e.g. sink/sqldb.
+func RegisterSink(scheme string, opener SinkOpener) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+
+	sinks[scheme] = opener
+}
+
+// OpenSink returns Sink of the URL by its scheme, e.g.
+// postgres://user@host/db when sink/sqldb is imported
+func OpenSink(rawURL string) (Sink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	sinksMu.RLock()
+	opener, ok := sinks[u.Scheme]
+	sinksMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownSink, u.Scheme)
+	}
+
+	return opener(u)
+}
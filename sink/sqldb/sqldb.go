@@ -0,0 +1,200 @@
+// Package sqldb provides sink writing results of imports to a SQL database.
+//
+// Importing the package registers postgres://, postgresql:// and sqlite://
+// URLs for customerimporter.OpenSink, e.g. postgres://user@host/db or
+// sqlite:///var/lib/imports.db. Results are written to the import_runs and
+// domain_counts tables, which are created if they don't exist, other names
+// can be set by runs_table and table query parameters.
+package sqldb
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sync"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// ErrInvalidTable is raised when the table name isn't a plain SQL identifier
+var ErrInvalidTable = errors.New("Invalid table name")
+
+// default names of the tables
+const (
+	DefaultRunsTable = "import_runs"
+	DefaultTable     = "domain_counts"
+)
+
+// identifier matches table names which are safe to use without quoting
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func init() {
+	customerimporter.RegisterSink("postgres", open)
+	customerimporter.RegisterSink("postgresql", open)
+	customerimporter.RegisterSink("sqlite", open)
+}
+
+// Sink writes every result as a row of the runs table with the statistics of
+// the import and rows of the domains table with the emails count of every
+// domain, which reference the run by its id
+type Sink struct {
+	DB        *sql.DB // database of the tables
+	RunsTable string  // name of the table of runs, DefaultRunsTable if empty
+	Table     string  // name of the table of domain counts, DefaultTable if empty
+
+	mu      sync.Mutex // guards created
+	created bool       // tables are created before the first successful write
+	owned   bool       // DB is opened by the sink and closed with it
+}
+
+// Write stores the result in a transaction, so a failed write leaves no
+// partial run
+func (s *Sink) Write(ctx context.Context, run customerimporter.Run, result *customerimporter.ImportResult) error {
+	runs, domains, err := s.tables()
+	if err != nil {
+		return err
+	}
+	if err := s.createTables(ctx, runs, domains); err != nil {
+		return err
+	}
+
+	id, err := newRunID()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (id, input, email_field, imported_at, rows_read, valid_emails,
+		invalid_emails, duplicate_emails, distinct_domains, elapsed_ms) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`, runs),
+		id, run.Input, run.EmailField, run.Time.UTC(), result.RowsRead, result.ValidEmails,
+		result.InvalidEmails, result.DuplicateEmails, result.DistinctDomains, result.Elapsed.Milliseconds())
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s (run_id, domain, emails_count, share) VALUES ($1, $2, $3, $4)`, domains))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range result.Domains {
+		if _, err := stmt.ExecContext(ctx, id, e.Domain, e.EmailsCount, e.Share); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Close closes the database if it was opened by OpenSink
+func (s *Sink) Close() error {
+	if !s.owned {
+		return nil
+	}
+	return s.DB.Close()
+}
+
+// returns names of the tables, defaults if they are not set
+func (s *Sink) tables() (string, string, error) {
+	runs, domains := s.RunsTable, s.Table
+	if runs == "" {
+		runs = DefaultRunsTable
+	}
+	if domains == "" {
+		domains = DefaultTable
+	}
+	for _, table := range []string{runs, domains} {
+		if !identifier.MatchString(table) {
+			return "", "", fmt.Errorf("%w %q", ErrInvalidTable, table)
+		}
+	}
+	return runs, domains, nil
+}
+
+// creates the tables if they don't exist, types are understood by both
+// PostgreSQL and SQLite. Failed creation is retried by the next write.
+func (s *Sink) createTables(ctx context.Context, runs, domains string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.created {
+		return nil
+	}
+
+	_, err := s.DB.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		input TEXT NOT NULL,
+		email_field TEXT NOT NULL,
+		imported_at TIMESTAMP NOT NULL,
+		rows_read BIGINT NOT NULL,
+		valid_emails BIGINT NOT NULL,
+		invalid_emails BIGINT NOT NULL,
+		duplicate_emails BIGINT NOT NULL,
+		distinct_domains BIGINT NOT NULL,
+		elapsed_ms BIGINT NOT NULL
+	)`, runs))
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		run_id TEXT NOT NULL REFERENCES %s (id),
+		domain TEXT NOT NULL,
+		emails_count BIGINT NOT NULL,
+		share DOUBLE PRECISION NOT NULL,
+		PRIMARY KEY (run_id, domain)
+	)`, domains, runs))
+	if err != nil {
+		return err
+	}
+	s.created = true
+	return nil
+}
+
+// returns random id of a run
+func newRunID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// returns sink of postgres:// or sqlite:// URL, runs_table and table query
+// parameters set names of the tables and aren't passed to the driver
+func open(u *url.URL) (customerimporter.Sink, error) {
+	query := u.Query()
+	s := &Sink{RunsTable: query.Get("runs_table"), Table: query.Get("table"), owned: true}
+	if _, _, err := s.tables(); err != nil {
+		return nil, err
+	}
+	query.Del("runs_table")
+	query.Del("table")
+
+	u.RawQuery = query.Encode()
+	driver, dsn := "pgx", u.String()
+	if u.Scheme == "sqlite" {
+		driver, dsn = "sqlite", u.Host+u.Path
+		if u.RawQuery != "" {
+			dsn += "?" + u.RawQuery
+		}
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	s.DB = db
+	return s, nil
+}
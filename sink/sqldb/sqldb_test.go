@@ -0,0 +1,112 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		url       string
+		runsTable string
+		table     string
+		err       error
+	}{
+		{"postgres://user@localhost/imports", "", "", nil},
+		{"postgresql://user@localhost/imports?sslmode=disable&table=counts", "", "counts", nil},
+		{"sqlite:///var/lib/imports.db?runs_table=runs", "runs", "", nil},
+		{"sqlite://imports.db?table=counts%20drop", "", "", ErrInvalidTable},
+	}
+
+	t.Log("Should return sink of the URL")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.url)
+
+		sink, err := customerimporter.OpenSink(test.url)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("should raise error: %v, but got error %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := sink.(*Sink)
+		if s.RunsTable != test.runsTable || s.Table != test.table {
+			t.Errorf("should set tables %q and %q, but got %q and %q", test.runsTable, test.table, s.RunsTable, s.Table)
+		}
+		sink.Close()
+	}
+}
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "imports.db")
+	sink, err := customerimporter.OpenSink("sqlite://" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	t.Log("Should retry creating the tables after failed write")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sink.Write(canceled, customerimporter.Run{}, &customerimporter.ImportResult{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("should raise error: %v, but got error %v", context.Canceled, err)
+	}
+
+	t.Log("Should write runs and their domain counts")
+	result, err := customerimporter.ImportWithStats(strings.NewReader("email\na@a.io\nb@a.io\na@b.io\n"), "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	finished := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, input := range []string{"monday.csv", "tuesday.csv"} {
+		run := customerimporter.Run{Input: input, EmailField: "email", Time: finished}
+		if err := sink.Write(context.Background(), run, result); err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query(`SELECT r.input, r.valid_emails, d.domain, d.emails_count FROM import_runs r
+		JOIN domain_counts d ON d.run_id = r.id ORDER BY r.input, d.domain`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	type row struct {
+		input  string
+		valid  int
+		domain string
+		count  int
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.input, &r.valid, &r.domain, &r.count); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	expected := []row{
+		{"monday.csv", 3, "a.io", 2}, {"monday.csv", 3, "b.io", 1},
+		{"tuesday.csv", 3, "a.io", 2}, {"tuesday.csv", 3, "b.io", 1},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("should write %v, but got %v", expected, got)
+	}
+}
@@ -0,0 +1,39 @@
+package customerimporter
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// memorySink keeps written results in memory, it's used in tests
+type memorySink struct {
+	name    string
+	results []*ImportResult
+}
+
+func (s *memorySink) Write(_ context.Context, _ Run, result *ImportResult) error {
+	s.results = append(s.results, result)
+	return nil
+}
+func (s *memorySink) Close() error { return nil }
+
+func TestOpenSink(t *testing.T) {
+	RegisterSink("mem", func(u *url.URL) (Sink, error) { return &memorySink{name: u.Host}, nil })
+
+	t.Log("Should return sink by the URL scheme")
+	sink, err := OpenSink("mem://results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := sink.(*memorySink); !ok || s.name != "results" {
+		t.Errorf("should return sink of the URL, but got %#v", sink)
+	}
+
+	t.Log("Should raise error of unknown scheme")
+	_, err = OpenSink("mysql://localhost/db")
+	if err == nil || !strings.Contains(err.Error(), ErrUnknownSink.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrUnknownSink, err)
+	}
+}
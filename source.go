@@ -0,0 +1,162 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrUnknownScheme is raised when no source is registered for the URL scheme
+var ErrUnknownScheme = errors.New("Source is not registered for scheme")
+
+// Source is input of the import, e.g. a local file or an object in object
+// store
+type Source interface {
+	// Open returns reader of the input
+	Open(ctx context.Context) (io.ReadCloser, error)
+	// Name returns name of the input, its extension is used to detect compression
+	Name() string
+}
+
+// SourceOpener returns Source of the URL
+type SourceOpener func(u *url.URL) (Source, error)
+
+// sources registered by URL scheme
+var (
+	sourcesMu sync.RWMutex
+	sources   = map[string]SourceOpener{
+		"file":  func(u *url.URL) (Source, error) { return FileSource(u.Path), nil },
+		"http":  func(u *url.URL) (Source, error) { return URLSource(u.String(), nil), nil },
+		"https": func(u *url.URL) (Source, error) { return URLSource(u.String(), nil), nil },
+	}
+)
+
+// RegisterSource makes sources of the URL scheme available to OpenSource. It
+// is called by init of packages implementing sources, e.g. source/s3.
+func RegisterSource(scheme string, opener SourceOpener) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+
+	sources[scheme] = opener
+}
+
+// OpenSource returns Source of the URL by its scheme, paths without scheme are
+// local files and "-" is standard input
+func OpenSource(rawURL string) (Source, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// not URL or Windows drive letter
+		return FileSource(rawURL), nil
+	}
+
+	sourcesMu.RLock()
+	opener, ok := sources[u.Scheme]
+	sourcesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownScheme, u.Scheme)
+	}
+
+	return opener(u)
+}
+
+// imports from the source
+func ImportFromSource(ctx context.Context, src Source, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	// import and get result
+	result, err := ImportFromSourceWithStats(ctx, src, emailFieldName, options...)
+	if result == nil {
+		return nil, err
+	}
+
+	return &result.Domains, err
+}
+
+// imports from the source and returns ImportResult with statistics.
+// Compression is detected by extension of the source name unless
+// WithCompression is used.
+func ImportFromSourceWithStats(ctx context.Context, src Source, emailFieldName string, options ...Option) (*ImportResult, error) {
+	// detect compression by extension, explicit option takes precedence
+	if compression, ok := compressionByExtension(src.Name()); ok {
+		options = append([]Option{WithCompression(compression)}, options...)
+	}
+
+	return newCustomerImporter(nil, emailFieldName, options...).runSource(ctx, src)
+}
+
+// opens the source and parses its records
+func (c *CustomerImporter) runSource(ctx context.Context, src Source) (result *ImportResult, err error) {
+	if c.traceCtx == nil {
+		c.traceCtx = ctx
+	}
+	endImport := c.traceImport()
+	defer func() { endImport(result, err) }()
+
+	span := c.startSpan("customerimporter.Open", attribute.String("customerimporter.input", src.Name()))
+	r, err := src.Open(ctx)
+	endSpan(span, err)
+	if err != nil {
+		c.notifyCompletion(nil, err)
+		return nil, err
+	}
+	defer r.Close()
+
+	c.input = r
+	return c.run()
+}
+
+// FileSource returns Source of the local file, "-" is standard input
+func FileSource(fileName string) Source {
+	return fileSource(fileName)
+}
+
+// fileSource is a local file
+type fileSource string
+
+func (s fileSource) Open(context.Context) (io.ReadCloser, error) { return openFile(string(s)) }
+func (s fileSource) Name() string                                { return string(s) }
+
+// URLSource returns Source downloading the URL by the client, the default
+// client is used if client is nil
+func URLSource(rawURL string, client *http.Client) Source {
+	return &urlSource{url: rawURL, client: client}
+}
+
+// urlSource is a file downloaded over HTTP(S)
+type urlSource struct {
+	url    string
+	client *http.Client
+}
+
+func (s *urlSource) Open(ctx context.Context) (io.ReadCloser, error) {
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w %s", ErrUnexpectedStatus, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (s *urlSource) Name() string {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return s.url
+	}
+	return u.Path
+}
@@ -0,0 +1,69 @@
+// Package azblob provides source of blobs stored in Azure Blob Storage.
+//
+// Importing the package registers azblob://container/blob URLs for
+// customerimporter.OpenSource. The storage account is read from
+// AZURE_STORAGE_ACCOUNT and credentials by the default Azure credential chain.
+package azblob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// ErrAccountNotSet is raised when the storage account of azblob URL is unknown
+var ErrAccountNotSet = errors.New("AZURE_STORAGE_ACCOUNT is not set")
+
+func init() {
+	customerimporter.RegisterSource("azblob", open)
+}
+
+// Source is a blob of Azure storage container
+type Source struct {
+	Client    *azblob.Client // client of the storage account
+	Container string         // name of the container
+	Blob      string         // name of the blob
+}
+
+// Open returns content of the blob
+func (s *Source) Open(ctx context.Context) (io.ReadCloser, error) {
+	resp, err := s.Client.DownloadStream(ctx, s.Container, s.Blob, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Name returns name of the blob
+func (s *Source) Name() string {
+	return s.Blob
+}
+
+// returns source of azblob://container/blob URL in the account of
+// AZURE_STORAGE_ACCOUNT
+func open(u *url.URL) (customerimporter.Source, error) {
+	account := os.Getenv("AZURE_STORAGE_ACCOUNT")
+	if account == "" {
+		return nil, ErrAccountNotSet
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, err
+	}
+	client, err := azblob.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net/", account), cred, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{Client: client, Container: u.Host, Blob: strings.TrimPrefix(u.Path, "/")}, nil
+}
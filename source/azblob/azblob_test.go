@@ -0,0 +1,63 @@
+package azblob
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func TestOpen(t *testing.T) {
+	t.Log("Should raise error if the storage account is not set")
+	t.Setenv("AZURE_STORAGE_ACCOUNT", "")
+
+	_, err := customerimporter.OpenSource("azblob://container/customers.csv")
+	if !errors.Is(err, ErrAccountNotSet) {
+		t.Errorf("should raise error: %v, but got error %v", ErrAccountNotSet, err)
+	}
+}
+
+func TestImportFromSource(t *testing.T) {
+	t.Log("Should import emails from the blob")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/container/customers.csv" {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("email\na@a.io\nb@a.io\na@b.io\n"))
+	}))
+	defer server.Close()
+
+	client, err := azblob.NewClientWithNoCredential(server.URL+"/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := customerimporter.ImportFromSource(context.Background(),
+		&Source{Client: client, Container: "container", Blob: "customers.csv"}, "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := customerimporter.EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should return %v, but got %v", expected, *result)
+	}
+
+	t.Log("Should raise error if the blob doesn't exist")
+	_, err = customerimporter.ImportFromSource(context.Background(),
+		&Source{Client: client, Container: "container", Blob: "missing.csv"}, "email")
+	if err == nil {
+		t.Error("should raise error, but got nil")
+	}
+}
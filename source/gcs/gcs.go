@@ -0,0 +1,75 @@
+// Package gcs provides source of objects stored in Google Cloud Storage.
+//
+// Importing the package registers gs://bucket/object URLs for
+// customerimporter.OpenSource. Credentials are read by Application Default
+// Credentials, e.g. from GOOGLE_APPLICATION_CREDENTIALS.
+package gcs
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/storage"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func init() {
+	customerimporter.RegisterSource("gs", open)
+}
+
+// Source is an object of Cloud Storage bucket
+type Source struct {
+	Client *storage.Client // client of Cloud Storage, created with default credentials if nil
+	Bucket string          // name of the bucket
+	Object string          // name of the object
+}
+
+// Open returns content of the object
+func (s *Source) Open(ctx context.Context) (io.ReadCloser, error) {
+	client := s.Client
+	if client == nil {
+		var err error
+		if client, err = storage.NewClient(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := client.Bucket(s.Bucket).Object(s.Object).NewReader(ctx)
+	if err != nil {
+		if s.Client == nil {
+			client.Close()
+		}
+		return nil, err
+	}
+	if s.Client == nil {
+		return &clientReader{Reader: r, client: client}, nil
+	}
+	return r, nil
+}
+
+// Name returns name of the object
+func (s *Source) Name() string {
+	return s.Object
+}
+
+// clientReader closes the client created by Open together with the reader
+type clientReader struct {
+	*storage.Reader
+	client *storage.Client
+}
+
+func (r *clientReader) Close() error {
+	err := r.Reader.Close()
+	if closeErr := r.client.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// returns source of gs://bucket/object URL
+func open(u *url.URL) (customerimporter.Source, error) {
+	return &Source{Bucket: u.Host, Object: strings.TrimPrefix(u.Path, "/")}, nil
+}
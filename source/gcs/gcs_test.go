@@ -0,0 +1,69 @@
+package gcs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func TestOpen(t *testing.T) {
+	t.Log("Should return source of gs URL")
+
+	src, err := customerimporter.OpenSource("gs://bucket/exports/customers.csv.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Source{Bucket: "bucket", Object: "exports/customers.csv.gz"}
+	if !reflect.DeepEqual(src, expected) {
+		t.Errorf("should return %+v, but got %+v", expected, src)
+	}
+}
+
+func TestImportFromSource(t *testing.T) {
+	t.Log("Should import emails from the object")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/bucket/customers.csv") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("email\na@a.io\nb@a.io\na@b.io\n"))
+	}))
+	defer server.Close()
+
+	client, err := storage.NewClient(context.Background(),
+		option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	result, err := customerimporter.ImportFromSource(context.Background(),
+		&Source{Client: client, Bucket: "bucket", Object: "customers.csv"}, "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := customerimporter.EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should return %v, but got %v", expected, *result)
+	}
+
+	t.Log("Should raise error if the object doesn't exist")
+	_, err = customerimporter.ImportFromSource(context.Background(),
+		&Source{Client: client, Bucket: "bucket", Object: "missing.csv"}, "email")
+	if err == nil {
+		t.Error("should raise error, but got nil")
+	}
+}
@@ -0,0 +1,105 @@
+// Package gsheets provides source of ranges of Google Sheets spreadsheets.
+//
+// Importing the package registers gsheets://spreadsheet-id/range URLs for
+// customerimporter.OpenSource, e.g. gsheets://1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms/Customers!A:D.
+// The whole first sheet is read if the range is empty. Credentials are read
+// by Application Default Credentials, e.g. from GOOGLE_APPLICATION_CREDENTIALS,
+// the spreadsheet must be shared with the account.
+package gsheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func init() {
+	customerimporter.RegisterSource("gsheets", open)
+}
+
+// Source is a range of a spreadsheet
+type Source struct {
+	Service       *sheets.Service // service of Sheets API, created with default credentials if nil
+	SpreadsheetID string          // id of the spreadsheet in its URL
+	Range         string          // range in A1 notation, e.g. Customers!A:D, the first sheet if empty
+}
+
+// Open returns values of the range as csv, the first row is the header. Rows
+// shorter than the longest one are padded with empty fields.
+func (s *Source) Open(ctx context.Context) (io.ReadCloser, error) {
+	service := s.Service
+	if service == nil {
+		var err error
+		if service, err = sheets.NewService(ctx, option.WithScopes(sheets.SpreadsheetsReadonlyScope)); err != nil {
+			return nil, err
+		}
+	}
+
+	// range of the whole first sheet
+	readRange := s.Range
+	if readRange == "" {
+		spreadsheet, err := service.Spreadsheets.Get(s.SpreadsheetID).Fields("sheets.properties.title").Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		if len(spreadsheet.Sheets) == 0 {
+			return nil, fmt.Errorf("%w %s", customerimporter.ErrSheetNotExists, s.SpreadsheetID)
+		}
+		readRange = quoteSheet(spreadsheet.Sheets[0].Properties.Title)
+	}
+
+	values, err := service.Spreadsheets.Values.Get(s.SpreadsheetID, readRange).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	// encode rows as csv
+	width := 0
+	for _, row := range values.Values {
+		width = max(width, len(row))
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, row := range values.Values {
+		record := make([]string, width)
+		for i, value := range row {
+			record[i] = fmt.Sprint(value)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(&buf), nil
+}
+
+// Name returns id of the spreadsheet and the range
+func (s *Source) Name() string {
+	if s.Range == "" {
+		return s.SpreadsheetID
+	}
+	return s.SpreadsheetID + "/" + s.Range
+}
+
+// returns sheet name quoted for A1 notation, e.g. 'Q1 Customers'
+func quoteSheet(title string) string {
+	return "'" + strings.ReplaceAll(title, "'", "''") + "'"
+}
+
+// returns source of gsheets://spreadsheet-id/range URL
+func open(u *url.URL) (customerimporter.Source, error) {
+	return &Source{SpreadsheetID: u.Host, Range: strings.TrimPrefix(u.Path, "/")}, nil
+}
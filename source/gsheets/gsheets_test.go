@@ -0,0 +1,84 @@
+package gsheets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func TestOpen(t *testing.T) {
+	t.Log("Should return source of gsheets URL")
+
+	src, err := customerimporter.OpenSource("gsheets://sheet-id/Customers!A:C")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Source{SpreadsheetID: "sheet-id", Range: "Customers!A:C"}
+	if !reflect.DeepEqual(src, expected) {
+		t.Errorf("should return %+v, but got %+v", expected, src)
+	}
+}
+
+func TestImportFromSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v any
+		switch r.URL.Path {
+		case "/v4/spreadsheets/sheet-id":
+			v = map[string]any{"sheets": []any{map[string]any{"properties": map[string]any{"title": "Q1 Customers"}}}}
+		case "/v4/spreadsheets/sheet-id/values/'Q1 Customers'", "/v4/spreadsheets/sheet-id/values/Customers!A:B":
+			v = map[string]any{"values": [][]any{{"name", "email"}, {"A", "a@a.io"}, {"B", "b@a.io"}, {"", "a@b.io"}, {"D"}}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(v)
+	}))
+	defer server.Close()
+
+	service, err := sheets.NewService(context.Background(), option.WithEndpoint(server.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := customerimporter.EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	data := []struct {
+		name string
+		src  *Source
+		err  bool
+	}{
+		{"range", &Source{Service: service, SpreadsheetID: "sheet-id", Range: "Customers!A:B"}, false},
+		{"first sheet", &Source{Service: service, SpreadsheetID: "sheet-id"}, false},
+		{"missing spreadsheet", &Source{Service: service, SpreadsheetID: "missing"}, true},
+	}
+
+	t.Log("Should import emails from the sheet")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		result, err := customerimporter.ImportFromSource(context.Background(), d.src, "email", customerimporter.SkipEmptyEmails())
+		if d.err {
+			if err == nil {
+				t.Error("should raise error, but got nil")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("should return %v, but got %v", expected, *result)
+		}
+	}
+}
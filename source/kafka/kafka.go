@@ -0,0 +1,259 @@
+// Package kafka consumes customer records from a Kafka topic and reports
+// snapshots of emails counted by domain, e.g. to follow the domain
+// distribution of a signup stream in near real time.
+//
+//	err := kafka.Consume(ctx, kafka.Config{
+//		Brokers: []string{"localhost:9092"},
+//		Topic:   "signups",
+//		GroupID: "customerimporter",
+//		Format:  kafka.FormatJSON,
+//		Options: []customerimporter.Option{customerimporter.SkipErrInvalidEmails(), customerimporter.SkipErrDuplicateEmails()},
+//	}, "email", func(s kafka.Snapshot) error {
+//		log.Println(s.ValidEmails, s.Domains)
+//		return nil
+//	})
+//
+// Records of the messages are validated, deduplicated and counted by the
+// options like records of a csv file. The topic isn't a bounded input, so it
+// isn't registered for customerimporter.OpenSource.
+package kafka
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// ErrUnknownMessageFormat is raised when format of the messages isn't
+// FormatCSV or FormatJSON
+var ErrUnknownMessageFormat = errors.New("Unknown message format")
+
+// formats of the messages
+const (
+	FormatCSV  = "csv"  // message is a csv line of the fields without header
+	FormatJSON = "json" // message is a JSON object with the fields as keys
+)
+
+// DefaultSnapshotInterval is time between snapshots of Consume
+const DefaultSnapshotInterval = 10 * time.Second
+
+// MessageReader reads messages of the topic, *kafkago.Reader implements it
+type MessageReader interface {
+	ReadMessage(ctx context.Context) (kafkago.Message, error)
+}
+
+// Config of the consumer
+type Config struct {
+	Brokers          []string                  // addresses of the brokers
+	Topic            string                    // topic of the messages
+	GroupID          string                    // consumer group, offsets are committed as messages are read
+	Reader           MessageReader             // reader of the messages, created from the brokers, topic and group and closed by Consume if nil
+	Format           string                    // FormatCSV or FormatJSON, FormatCSV if empty
+	Fields           []string                  // fields of the records, the email field only if nil
+	SnapshotInterval time.Duration             // time between snapshots, DefaultSnapshotInterval if < 1
+	Options          []customerimporter.Option // options of the import
+}
+
+// Snapshot reports emails counted from the start of Consume
+type Snapshot struct {
+	Time            time.Time                              // time of the snapshot
+	Messages        int                                    // amount of messages read
+	ValidEmails     int                                    // amount of counted emails
+	InvalidEmails   int                                    // amount of skipped invalid emails
+	DuplicateEmails int                                    // amount of skipped duplicate emails
+	Domains         customerimporter.EmailsByDomainQtyList // domains by emails count, the most emails first
+}
+
+// consumes messages of the topic until ctx is done and calls handler with
+// snapshot of the counts every SnapshotInterval and once more when consuming
+// stops. Every message is a record with Fields, a csv message may contain
+// several lines. Errors of the records abort consuming unless they're skipped
+// by the options, e.g. SkipErrInvalidEmails, SkipErrDuplicateEmails and
+// SkipMalformedRows for csv messages with wrong amount of fields. Returned
+// error of the handler stops consuming. Consume returns ctx.Err() when ctx is
+// done.
+func Consume(ctx context.Context, cfg Config, emailFieldName string, handler func(Snapshot) error) error {
+	if cfg.Format == "" {
+		cfg.Format = FormatCSV
+	}
+	if cfg.Format != FormatCSV && cfg.Format != FormatJSON {
+		return fmt.Errorf("%w %s", ErrUnknownMessageFormat, cfg.Format)
+	}
+	if cfg.Fields == nil {
+		cfg.Fields = []string{emailFieldName}
+	}
+	if cfg.SnapshotInterval < 1 {
+		cfg.SnapshotInterval = DefaultSnapshotInterval
+	}
+	if cfg.Reader == nil {
+		reader := kafkago.NewReader(kafkago.ReaderConfig{Brokers: cfg.Brokers, Topic: cfg.Topic, GroupID: cfg.GroupID})
+		defer reader.Close()
+		cfg.Reader = reader
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// import records of the messages in the background, counts are tracked
+	// by events of the import
+	counts := &counter{domains: make(map[string]int)}
+	rr := &recordReader{ctx: ctx, reader: cfg.Reader, format: cfg.Format, fields: cfg.Fields, counts: counts}
+	options := append(slices.Clone(cfg.Options), customerimporter.WithEventHandler(counts.track))
+	done := make(chan error, 1)
+	go func() {
+		_, err := customerimporter.ImportRecordsWithStats(rr, emailFieldName, options...)
+		done <- err
+	}()
+
+	ticker := time.NewTicker(cfg.SnapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := handler(counts.snapshot()); err != nil {
+				cancel()
+				<-done
+				return err
+			}
+		case err := <-done:
+			// report counts of the last messages
+			if handlerErr := handler(counts.snapshot()); handlerErr != nil {
+				return handlerErr
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+	}
+}
+
+// counter counts events of the import
+type counter struct {
+	mu         sync.Mutex
+	messages   int
+	valid      int
+	invalid    int
+	duplicates int
+	domains    map[string]int
+}
+
+// updates counts by the event of the import
+func (c *counter) track(ev customerimporter.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch ev.Type {
+	case customerimporter.EventValidEmail:
+		c.valid++
+		c.domains[ev.Domain]++
+	case customerimporter.EventInvalidEmail:
+		c.invalid++
+	case customerimporter.EventDuplicateEmail:
+		c.duplicates++
+	}
+	return nil
+}
+
+// returns snapshot of the counts
+func (c *counter) snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := Snapshot{
+		Time:            time.Now(),
+		Messages:        c.messages,
+		ValidEmails:     c.valid,
+		InvalidEmails:   c.invalid,
+		DuplicateEmails: c.duplicates,
+		Domains:         make(customerimporter.EmailsByDomainQtyList, 0, len(c.domains)),
+	}
+	for domain, count := range c.domains {
+		s.Domains = append(s.Domains, customerimporter.EmailsByDomainQty{Domain: domain, EmailsCount: count, Share: float64(count) / float64(c.valid)})
+	}
+	slices.SortFunc(s.Domains, func(a, b customerimporter.EmailsByDomainQty) int {
+		if c := cmp.Compare(b.EmailsCount, a.EmailsCount); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Domain, b.Domain)
+	})
+	return s
+}
+
+// recordReader reads records of the messages, the first record is the header
+// of the fields
+type recordReader struct {
+	ctx     context.Context
+	reader  MessageReader
+	format  string
+	fields  []string
+	counts  *counter
+	header  bool       // header is read
+	records [][]string // unread records of the last message
+}
+
+func (r *recordReader) Read() ([]string, error) {
+	if !r.header {
+		r.header = true
+		return r.fields, nil
+	}
+
+	for len(r.records) == 0 {
+		msg, err := r.reader.ReadMessage(r.ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.counts.mu.Lock()
+		r.counts.messages++
+		r.counts.mu.Unlock()
+
+		if r.records, err = r.parse(msg.Value); err != nil {
+			return nil, fmt.Errorf("message %d of partition %d: %w", msg.Offset, msg.Partition, err)
+		}
+	}
+
+	record := r.records[0]
+	r.records = r.records[1:]
+	if len(record) != len(r.fields) {
+		return record, csv.ErrFieldCount
+	}
+	return record, nil
+}
+
+// returns records of the message
+func (r *recordReader) parse(value []byte) ([][]string, error) {
+	if r.format == FormatCSV {
+		reader := csv.NewReader(bytes.NewReader(value))
+		reader.FieldsPerRecord = -1
+		return reader.ReadAll()
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(value, &object); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(r.fields))
+	for i, field := range r.fields {
+		raw, ok := object[field]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		// strings are unquoted, other values are kept as JSON
+		if err := json.Unmarshal(raw, &record[i]); err != nil {
+			record[i] = strings.TrimSpace(string(raw))
+		}
+	}
+	return [][]string{record}, nil
+}
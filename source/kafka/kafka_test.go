@@ -0,0 +1,116 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// fakeReader returns the messages and waits for ctx to be done then
+type fakeReader struct {
+	messages []string
+}
+
+func (r *fakeReader) ReadMessage(ctx context.Context) (kafkago.Message, error) {
+	if len(r.messages) == 0 {
+		<-ctx.Done()
+		return kafkago.Message{}, ctx.Err()
+	}
+	msg := kafkago.Message{Value: []byte(r.messages[0])}
+	r.messages = r.messages[1:]
+	return msg, nil
+}
+
+func TestConsume(t *testing.T) {
+	skip := []customerimporter.Option{customerimporter.SkipErrInvalidEmails(), customerimporter.SkipErrDuplicateEmails(), customerimporter.SkipMalformedRows()}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		messages []string
+		expected Snapshot
+		err      error
+	}{
+		{"csv", Config{Options: skip}, []string{"a@a.io", "b@a.io\na@b.io", "invalid", "a@a.io"}, Snapshot{
+			Messages: 4, ValidEmails: 3, InvalidEmails: 1, DuplicateEmails: 1,
+			Domains: customerimporter.EmailsByDomainQtyList{
+				{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+				{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+			},
+		}, context.DeadlineExceeded},
+		{"csv fields", Config{Fields: []string{"name", "email"}, Options: skip}, []string{"A,a@a.io", "a@b.io", "B,b@b.io"}, Snapshot{
+			Messages: 3, ValidEmails: 2,
+			Domains: customerimporter.EmailsByDomainQtyList{
+				{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+				{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+			},
+		}, context.DeadlineExceeded},
+		{"json", Config{Format: FormatJSON, Fields: []string{"email", "age"}}, []string{`{"email":"a@a.io","age":30}`, `{"email":"b@b.io","age":null}`}, Snapshot{
+			Messages: 2, ValidEmails: 2,
+			Domains: customerimporter.EmailsByDomainQtyList{
+				{Domain: "a.io", EmailsCount: 1, Share: 0.5},
+				{Domain: "b.io", EmailsCount: 1, Share: 0.5},
+			},
+		}, context.DeadlineExceeded},
+		{"invalid json", Config{Format: FormatJSON}, []string{`{"email":"a@a.io"}`, `{"email":`}, Snapshot{
+			Messages: 2, ValidEmails: 1,
+			Domains: customerimporter.EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1, Share: 1}},
+		}, errors.New("message 0 of partition 0")},
+		{"record error", Config{}, []string{"a@a.io", "invalid"}, Snapshot{
+			Messages: 2, ValidEmails: 1, InvalidEmails: 1,
+			Domains: customerimporter.EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 1, Share: 1}},
+		}, customerimporter.ErrEmailIsNotValid},
+		{"unknown format", Config{Format: "avro"}, nil, Snapshot{}, ErrUnknownMessageFormat},
+	}
+
+	t.Log("Should count emails of the messages and report the last snapshot")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.name)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		reader := &fakeReader{messages: test.messages}
+		test.cfg.Reader = reader
+		var last Snapshot
+		err := Consume(ctx, test.cfg, "email", func(s Snapshot) error {
+			last = s
+			return nil
+		})
+		cancel()
+
+		if !errors.Is(err, test.err) && (err == nil || !strings.Contains(err.Error(), test.err.Error())) {
+			t.Errorf("should raise error: %v, but got error %v", test.err, err)
+		}
+		last.Time = time.Time{}
+		if !reflect.DeepEqual(last, test.expected) && test.err != ErrUnknownMessageFormat {
+			t.Errorf("should report %+v, but got %+v", test.expected, last)
+		}
+	}
+}
+
+func TestConsumeSnapshots(t *testing.T) {
+	t.Log("Should report snapshots periodically and stop on error of the handler")
+
+	errStop := errors.New("stop")
+	reader := &fakeReader{messages: []string{"a@a.io", "a@b.io"}}
+	snapshots := 0
+	err := Consume(context.Background(), Config{Reader: reader, SnapshotInterval: 10 * time.Millisecond}, "email", func(s Snapshot) error {
+		snapshots++
+		if snapshots == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("should return %v, but got %v", errStop, err)
+	}
+	if snapshots != 3 {
+		t.Errorf("should report 3 snapshots, but got %v", snapshots)
+	}
+}
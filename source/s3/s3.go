@@ -0,0 +1,58 @@
+// Package s3 provides source of objects stored in Amazon S3.
+//
+// Importing the package registers s3://bucket/key URLs for
+// customerimporter.OpenSource. Credentials and region are read by the default
+// AWS configuration, e.g. from environment variables or shared config files.
+package s3
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func init() {
+	customerimporter.RegisterSource("s3", open)
+}
+
+// Source is an object of S3 bucket
+type Source struct {
+	Client *awss3.Client // client of S3, created from the default configuration if nil
+	Bucket string        // name of the bucket
+	Key    string        // key of the object
+}
+
+// Open returns body of the object
+func (s *Source) Open(ctx context.Context) (io.ReadCloser, error) {
+	client := s.Client
+	if client == nil {
+		cfg, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client = awss3.NewFromConfig(cfg)
+	}
+
+	out, err := client.GetObject(ctx, &awss3.GetObjectInput{Bucket: aws.String(s.Bucket), Key: aws.String(s.Key)})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// Name returns key of the object
+func (s *Source) Name() string {
+	return s.Key
+}
+
+// returns source of s3://bucket/key URL
+func open(u *url.URL) (customerimporter.Source, error) {
+	return &Source{Bucket: u.Host, Key: strings.TrimPrefix(u.Path, "/")}, nil
+}
@@ -0,0 +1,69 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func TestOpen(t *testing.T) {
+	t.Log("Should return source of s3 URL")
+
+	src, err := customerimporter.OpenSource("s3://bucket/exports/customers.csv.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Source{Bucket: "bucket", Key: "exports/customers.csv.gz"}
+	if !reflect.DeepEqual(src, expected) {
+		t.Errorf("should return %+v, but got %+v", expected, src)
+	}
+}
+
+func TestImportFromSource(t *testing.T) {
+	t.Log("Should import emails from the object")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bucket/customers.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("email\na@a.io\nb@a.io\na@b.io\n"))
+	}))
+	defer server.Close()
+
+	client := awss3.New(awss3.Options{
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("id", "secret", ""),
+	})
+
+	result, err := customerimporter.ImportFromSource(context.Background(),
+		&Source{Client: client, Bucket: "bucket", Key: "customers.csv"}, "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := customerimporter.EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should return %v, but got %v", expected, *result)
+	}
+
+	t.Log("Should raise error if the object doesn't exist")
+	_, err = customerimporter.ImportFromSource(context.Background(),
+		&Source{Client: client, Bucket: "bucket", Key: "missing.csv"}, "email")
+	if err == nil {
+		t.Error("should raise error, but got nil")
+	}
+}
@@ -0,0 +1,104 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// memorySource is a source of the string used in tests
+type memorySource struct {
+	name string
+	data string
+}
+
+func (s *memorySource) Open(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(s.data)), nil
+}
+func (s *memorySource) Name() string { return s.name }
+
+func TestOpenSource(t *testing.T) {
+	RegisterSource("mem", func(u *url.URL) (Source, error) {
+		return &memorySource{name: u.Path, data: "email\na@" + u.Host + "\n"}, nil
+	})
+
+	tests := []struct {
+		url      string
+		expected Source
+		err      error
+	}{
+		{"customers.csv", FileSource("customers.csv"), nil},
+		{"/data/customers.csv", FileSource("/data/customers.csv"), nil},
+		{`C:\data\customers.csv`, FileSource(`C:\data\customers.csv`), nil},
+		{"file:///data/customers.csv", FileSource("/data/customers.csv"), nil},
+		{"https://example.com/customers.csv", URLSource("https://example.com/customers.csv", nil), nil},
+		{"mem://a.io/customers.csv", &memorySource{name: "/customers.csv", data: "email\na@a.io\n"}, nil},
+		{"ftp://example.com/customers.csv", nil, ErrUnknownScheme},
+	}
+
+	t.Log("Should return source by the URL scheme")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.url)
+
+		src, err := OpenSource(test.url)
+		if test.err != nil {
+			if err == nil || !strings.Contains(err.Error(), test.err.Error()) {
+				t.Errorf("should raise error: %v, but got error %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(src, test.expected) {
+			t.Errorf("should return %#v, but got %#v", test.expected, src)
+		}
+	}
+}
+
+func TestImportFromSource(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "customers.csv.gz")
+	if err := os.WriteFile(fileName, gzipData(t, "email\na@a.io\nb@a.io\na@b.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		src      Source
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{FileSource(fileName), EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, nil},
+		{&memorySource{name: "customers.csv", data: "email\na@a.io\n"}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}, nil},
+		{FileSource("nonexisting.csv"), nil, os.ErrNotExist},
+	}
+
+	t.Log("Should import emails from the source")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.src.Name())
+
+		result, err := ImportFromSource(context.Background(), test.src, "email")
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("should raise error: %v, but got error %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, test.expected) {
+			t.Errorf("should return %v, but got %v", test.expected, *result)
+		}
+	}
+}
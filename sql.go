@@ -0,0 +1,79 @@
+package customerimporter
+
+import (
+	"context"
+	"database/sql"
+	"io"
+)
+
+// runs the query and imports emails of the column of its rows, e.g. straight
+// from the customers table without exporting csv
+func ImportFromSQL(ctx context.Context, db *sql.DB, query, emailColumn string, options ...Option) (*EmailsByDomainQtyList, error) {
+	// import and get result
+	result, err := ImportFromSQLWithStats(ctx, db, query, emailColumn, options...)
+	if result == nil {
+		return nil, err
+	}
+
+	return &result.Domains, err
+}
+
+// runs the query and returns ImportResult with statistics of its rows. Names
+// of the result columns are the header, values are converted to strings and
+// NULL is an empty field. Canceling ctx aborts the query. Options of the csv
+// format and compression are not applied.
+func ImportFromSQLWithStats(ctx context.Context, db *sql.DB, query, emailColumn string, options ...Option) (*ImportResult, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	return ImportRecordsWithStats(newSQLReader(rows, columns), emailColumn, options...)
+}
+
+// sqlReader reads rows of the query as records, the first record is the
+// header of the column names
+type sqlReader struct {
+	rows    *sql.Rows
+	columns []string
+	values  []sql.NullString
+	dest    []any // pointers to values scanned by the rows
+	header  bool  // header is read
+}
+
+// returns reader of the rows with the columns
+func newSQLReader(rows *sql.Rows, columns []string) *sqlReader {
+	r := &sqlReader{rows: rows, columns: columns, values: make([]sql.NullString, len(columns)), dest: make([]any, len(columns))}
+	for i := range r.values {
+		r.dest[i] = &r.values[i]
+	}
+	return r
+}
+
+func (r *sqlReader) Read() ([]string, error) {
+	if !r.header {
+		r.header = true
+		return r.columns, nil
+	}
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if err := r.rows.Scan(r.dest...); err != nil {
+		return nil, err
+	}
+
+	record := make([]string, len(r.values))
+	for i, v := range r.values {
+		record[i] = v.String
+	}
+	return record, nil
+}
@@ -0,0 +1,73 @@
+package customerimporter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestImportFromSQL(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "customers.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE customers (id INTEGER, name TEXT, email TEXT);
+		INSERT INTO customers VALUES (1, 'A', 'a@a.io'), (2, 'B', 'b@a.io'), (3, 'C', 'a@b.io'), (4, 'D', NULL), (5, 'E', 'invalid')`); err != nil {
+		t.Fatal(err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		query    string
+		column   string
+		options  []Option
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{"rows", context.Background(), "SELECT * FROM customers WHERE id < 4", "email", nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, nil},
+		{"null and invalid skipped", context.Background(), "SELECT name, email FROM customers", "email", []Option{SkipEmptyEmails(), SkipErrInvalidEmails()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, nil},
+		{"column alias", context.Background(), "SELECT lower(email) AS mail FROM customers WHERE id = 1", "mail", nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 1, Share: 1},
+		}, nil},
+		{"null", context.Background(), "SELECT email FROM customers", "email", nil, nil, ErrEmailEmpty},
+		{"missing column", context.Background(), "SELECT name FROM customers", "email", nil, nil, ErrFieldNotExists},
+		{"invalid query", context.Background(), "SELECT * FROM suppliers", "email", nil, nil, errors.New("no such table")},
+		{"canceled", canceled, "SELECT * FROM customers", "email", nil, nil, context.Canceled},
+	}
+
+	t.Log("Should import emails of the query rows")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.name)
+
+		result, err := ImportFromSQL(test.ctx, db, test.query, test.column, test.options...)
+		if test.err != nil {
+			if !errors.Is(err, test.err) && (err == nil || !strings.Contains(err.Error(), test.err.Error())) {
+				t.Errorf("should raise error: %v, but got error %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, test.expected) {
+			t.Errorf("should return %v, but got %v", test.expected, *result)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package customerimporter
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// ErrInvalidState is raised when the state can't be loaded by the importer
+var ErrInvalidState = errors.New("Invalid importer state")
+
+// version of the state format
+const stateVersion = 1
+
+// SaveState writes counts, statistics and counted emails of the fed readers
+// to w, so a new importer can continue by LoadState, e.g. a scheduled job
+// deduplicating emails across daily exports. Counted emails are saved with
+// the default dedup store and WithBloomDedup, other stores raise
+// ErrCheckpointUnsupported.
+func (i *Importer) SaveState(w io.Writer) error {
+	c := i.c
+	if err := c.checkpointSupported(); err != nil {
+		return err
+	}
+
+	cp := c.snapshot()
+	cp.Version = stateVersion
+	switch s := c.countedEmails.(type) {
+	case MemoryDedupStore:
+		cp.Emails = slices.Sorted(maps.Keys(s))
+	case *bloomFilter:
+		cp.Bloom = s.bits
+	}
+	return gob.NewEncoder(w).Encode(cp)
+}
+
+// LoadState restores the state written by SaveState, emails of the readers
+// fed after it are added to the saved counts and deduplicated against the
+// saved emails. The state must be saved by importer of the same email field
+// and dedup store, otherwise ErrInvalidState is raised. The state is loaded
+// before feeding readers, it replaces counts of the readers fed before it.
+func (i *Importer) LoadState(r io.Reader) error {
+	c := i.c
+	if err := c.checkpointSupported(); err != nil {
+		return err
+	}
+
+	var cp checkpoint
+	if err := gob.NewDecoder(r).Decode(&cp); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	if cp.Version != stateVersion {
+		return fmt.Errorf("%w version %d", ErrInvalidState, cp.Version)
+	}
+	if cp.EmailField != c.emailFieldName {
+		return fmt.Errorf("%w of field %q", ErrInvalidState, cp.EmailField)
+	}
+
+	if err := c.restore(&cp); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package customerimporter
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveState(t *testing.T) {
+	data := []struct {
+		name     string
+		options  []Option
+		expected EmailsByDomainQtyList
+		rows     int
+	}{
+		{"memory dedup", []Option{SkipErrDuplicateEmails(), CollectErrors()}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, 5},
+		{"bloom dedup", []Option{SkipErrDuplicateEmails(), CollectErrors(), WithBloomDedup(100, 0.001)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, 5},
+	}
+
+	t.Log("Should continue counting and deduplicating emails of the saved state")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		saved := New("email", d.options...)
+		if err := saved.Feed(strings.NewReader("email\na@a.io\ninvalid\nb@a.io\n")); err != nil {
+			t.Fatal(err)
+		}
+		var state bytes.Buffer
+		if err := saved.SaveState(&state); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded := New("email", d.options...)
+		if err := loaded.LoadState(&state); err != nil {
+			t.Fatal(err)
+		}
+		if err := loaded.Feed(strings.NewReader("email\na@a.io\nc@b.io\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := loaded.Result()
+		var rowErrors RowErrors
+		if !errors.As(err, &rowErrors) || len(rowErrors) != 2 || rowErrors[0].Err.Error() != ErrEmailIsNotValid.Error() {
+			t.Errorf("should return saved error: %v, but got error %v", ErrEmailIsNotValid, err)
+		}
+		if result == nil {
+			t.Fatal("should return result")
+		}
+		if !reflect.DeepEqual(result.Domains, d.expected) {
+			t.Errorf("should return %v, but got %v", d.expected, result.Domains)
+		}
+		if result.RowsRead != d.rows {
+			t.Errorf("should read %v records, but got %v", d.rows, result.RowsRead)
+		}
+		if result.DuplicateEmails != 1 {
+			t.Errorf("should count %v duplicate emails, but got %v", 1, result.DuplicateEmails)
+		}
+	}
+}
+
+func TestLoadStateError(t *testing.T) {
+	saved := New("email")
+	if err := saved.Feed(strings.NewReader("email\na@a.io\n")); err != nil {
+		t.Fatal(err)
+	}
+	var state bytes.Buffer
+	if err := saved.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		name     string
+		state    []byte
+		importer *Importer
+		err      error
+	}{
+		{"other field", state.Bytes(), New("mail"), ErrInvalidState},
+		{"other dedup store", state.Bytes(), New("email", WithBloomDedup(100, 0.001)), ErrInvalidState},
+		{"memory limit", state.Bytes(), New("email", WithMemoryLimit(1<<20)), ErrCheckpointUnsupported},
+		{"not a state", []byte("email\na@a.io\n"), New("email"), ErrInvalidState},
+	}
+
+	t.Log("Should raise error when the state can't be loaded")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		err := d.importer.LoadState(bytes.NewReader(d.state))
+		if !errors.Is(err, d.err) {
+			t.Errorf("should raise error: %v, but got error %v", d.err, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package redis provides dedup store and domain counter kept in Redis, so
+// several workers importing shards of the input build one aggregate.
+//
+// Every worker imports its shard with the shared DedupStore, so an email is
+// counted by the first worker reading it only, and counts emails in the shared
+// Counter, which returns the counts of all shards:
+//
+//	client := goredis.NewClient(&goredis.Options{Addr: "localhost:6379"})
+//	store := &redis.DedupStore{Client: client, Key: "import:2024-05-01:emails"}
+//	counter := &redis.Counter{Client: client, Key: "import:2024-05-01:domains"}
+//	result, err := customerimporter.ImportFromFile(shard, "email",
+//		customerimporter.WithDedupStore(store), customerimporter.WithCounterStore(counter))
+//
+// Workers may also count emails in memory and add the counts of their result
+// to the Counter at once by AddCounts, which saves a round trip to Redis for
+// every email.
+package redis
+
+import (
+	"context"
+	"strconv"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+// DedupStore is a set of counted emails in Redis shared by several imports.
+// Every email is a round trip to Redis, so the set is slower than the
+// in-memory one.
+type DedupStore struct {
+	Client goredis.UniversalClient // client of Redis
+	Key    string                  // key of the set of emails
+}
+
+// Add adds email to the set and reports whether it was added before by any
+// import
+func (s *DedupStore) Add(email string) (bool, error) {
+	added, err := s.Client.SAdd(context.Background(), s.Key, email).Result()
+	if err != nil {
+		return false, err
+	}
+	return added == 0, nil
+}
+
+// Counter sums emails count of domains of several imports in a Redis hash, it
+// implements customerimporter.CounterStore
+type Counter struct {
+	Client goredis.UniversalClient // client of Redis
+	Key    string                  // key of the hash of emails count by domain
+}
+
+// Add adds n emails to the shared count of the domain and returns its new
+// count
+func (c *Counter) Add(domain string, n int) (int, error) {
+	count, err := c.Client.HIncrBy(context.Background(), c.Key, domain, int64(n)).Result()
+	return int(count), err
+}
+
+// Counts returns the shared emails count of every domain
+func (c *Counter) Counts() (map[string]int, error) {
+	values, err := c.Client.HGetAll(context.Background(), c.Key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(values))
+	for domain, value := range values {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		counts[domain] = count
+	}
+	return counts, nil
+}
+
+// AddCounts adds emails counts of the domains to the shared counts in a
+// transaction, so counts of an import are added entirely or not at all.
+// Entries collapsed by TopN are added as OtherDomain.
+func (c *Counter) AddCounts(ctx context.Context, domains customerimporter.EmailsByDomainQtyList) error {
+	_, err := c.Client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, e := range domains {
+			pipe.HIncrBy(ctx, c.Key, e.Domain, int64(e.EmailsCount))
+		}
+		return nil
+	})
+	return err
+}
+
+// Result returns the shared counts sorted by domain name with shares of all
+// counted emails
+func (c *Counter) Result() (customerimporter.EmailsByDomainQtyList, error) {
+	counts, err := c.Counts()
+	if err != nil {
+		return nil, err
+	}
+
+	var domains customerimporter.EmailsByDomainQtyList
+	for domain, count := range counts {
+		domains = append(domains, customerimporter.EmailsByDomainQty{Domain: domain, EmailsCount: count})
+	}
+	return domains.Merge(nil), nil
+}
@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	customerimporter "github.com/dreadfulangel/tw_t"
+)
+
+func TestDedupStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := &DedupStore{Client: goredis.NewClient(&goredis.Options{Addr: server.Addr()}), Key: "emails"}
+
+	tests := []struct {
+		email string
+		added bool
+	}{
+		{"a@a.io", false},
+		{"b@a.io", false},
+		{"a@a.io", true},
+	}
+
+	t.Log("Should report emails added before")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.email)
+
+		added, err := store.Add(test.email)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if added != test.added {
+			t.Errorf("should return %v, but got %v", test.added, added)
+		}
+	}
+
+	t.Log("Should raise error if Redis is not available")
+	server.Close()
+	if _, err := store.Add("c@a.io"); err == nil {
+		t.Error("should raise error, but got nil")
+	}
+}
+
+func TestCounter(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	store := &DedupStore{Client: client, Key: "emails"}
+	counter := &Counter{Client: client, Key: "domains"}
+	ctx := context.Background()
+
+	t.Log("Should sum counts of shards deduplicated across them")
+	shards := []string{
+		"email\na@a.io\nb@a.io\na@b.io\n",
+		"email\na@a.io\nc@a.io\na@c.io\n",
+	}
+	for _, shard := range shards {
+		result, err := customerimporter.Import(strings.NewReader(shard), "email",
+			customerimporter.WithDedupStore(store), customerimporter.SkipErrDuplicateEmails())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := counter.AddCounts(ctx, *result); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := counter.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := customerimporter.EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 3, Share: 0.6},
+		{Domain: "b.io", EmailsCount: 1, Share: 0.2},
+		{Domain: "c.io", EmailsCount: 1, Share: 0.2},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("should return %v, but got %v", expected, result)
+	}
+
+	t.Log("Should count emails of shards in the counter")
+	server.FlushAll()
+	for _, shard := range shards {
+		_, err := customerimporter.Import(strings.NewReader(shard), "email", customerimporter.WithDedupStore(store),
+			customerimporter.WithCounterStore(counter), customerimporter.SkipErrDuplicateEmails())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err = counter.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("should return %v, but got %v", expected, result)
+	}
+}
@@ -0,0 +1,65 @@
+package customerimporter
+
+import "io"
+
+// EventType describes what happened while processing a record
+type EventType int
+
+const (
+	EventValidEmail     EventType = iota // email is valid and counted
+	EventInvalidEmail                    // email is not valid
+	EventDuplicateEmail                  // email is already counted
+	EventNewDomain                       // domain is counted for the first time
+)
+
+// String returns human readable name of the event type
+func (t EventType) String() string {
+	switch t {
+	case EventValidEmail:
+		return "valid email"
+	case EventInvalidEmail:
+		return "invalid email"
+	case EventDuplicateEmail:
+		return "duplicate email"
+	case EventNewDomain:
+		return "new domain"
+	}
+	return "unknown"
+}
+
+// Event is emitted while records are parsed
+type Event struct {
+	Type   EventType // type of the event
+	Line   int       // line of the record
+	Email  string    // email of the record
+	Domain string    // domain of the email, set for valid emails and new domains
+	Err    error     // reason of invalid and duplicate email events
+}
+
+// EventHandler is called for every event, returned error aborts the import
+type EventHandler func(ev Event) error
+
+// imports from reader calling handler as records are parsed instead of
+// buffering the result. Invalid and duplicate emails are reported to handler
+// before the options decide whether to skip them or abort.
+func ImportStream(r io.Reader, emailFieldName string, handler EventHandler, options ...Option) error {
+	c := newCustomerImporter(r, emailFieldName, options...)
+	c.handler = handler
+
+	return c.parse()
+}
+
+// Call handler for every event of the import, e.g. to report progress of
+// ImportWithStats. Returned error aborts the import. Files aren't split by
+// WithChunks when the handler is set.
+func WithEventHandler(handler EventHandler) Option {
+	return func(f *CustomerImporter) { f.handler = handler }
+}
+
+// calls event handler if it's set
+func (c *CustomerImporter) emit(ev Event) error {
+	if c.handler == nil {
+		return nil
+	}
+	return c.handler(ev)
+}
@@ -0,0 +1,90 @@
+package customerimporter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportStream(t *testing.T) {
+	records := "email\n" +
+		"a@a.io\n" +
+		"b@a.io\n" +
+		"a@a.io\n" +
+		"invalid\n" +
+		"a@b.io\n"
+
+	// collect all emitted events
+	var events []Event
+	err := ImportStream(strings.NewReader(records), "email", func(ev Event) error {
+		events = append(events, Event{Type: ev.Type, Line: ev.Line, Email: ev.Email, Domain: ev.Domain})
+		return nil
+	}, SkipErrDuplicateEmails(), SkipErrInvalidEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+
+	expected := []Event{
+		{Type: EventValidEmail, Line: 2, Email: "a@a.io", Domain: "a.io"},
+		{Type: EventNewDomain, Line: 2, Email: "a@a.io", Domain: "a.io"},
+		{Type: EventValidEmail, Line: 3, Email: "b@a.io", Domain: "a.io"},
+		{Type: EventDuplicateEmail, Line: 4, Email: "a@a.io"},
+		{Type: EventInvalidEmail, Line: 5, Email: "invalid"},
+		{Type: EventValidEmail, Line: 6, Email: "a@b.io", Domain: "b.io"},
+		{Type: EventNewDomain, Line: 6, Email: "a@b.io", Domain: "b.io"},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("should emit: %v, but got %v", expected, events)
+	}
+
+	// test abort by handler error
+	t.Log("Test abort by handler error")
+	errStop := errors.New("stop")
+	calls := 0
+	err = ImportStream(strings.NewReader(records), "email", func(ev Event) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("should raise error: %v, but got error %v", errStop, err)
+	}
+	if calls != 1 {
+		t.Errorf("handler should be called once, but was called %v times", calls)
+	}
+
+	// test invalid email is reported before abort
+	t.Log("Test invalid email is reported before abort")
+	var last Event
+	err = ImportStream(strings.NewReader("email\ninvalid\n"), "email", func(ev Event) error {
+		last = ev
+		return nil
+	})
+	if !errors.Is(err, ErrEmailIsNotValid) {
+		t.Errorf("should raise error: %v, but got error %v", ErrEmailIsNotValid, err)
+	}
+	if last.Type != EventInvalidEmail || !errors.Is(last.Err, ErrEmailIsNotValid) {
+		t.Errorf("should report invalid email, but got %v", last)
+	}
+}
+
+func TestWithEventHandler(t *testing.T) {
+	t.Log("Should emit events of the import returning result")
+
+	var types []EventType
+	result, err := ImportWithStats(strings.NewReader("email\na@a.io\ninvalid\n"), "email", WithEventHandler(func(ev Event) error {
+		types = append(types, ev.Type)
+		return nil
+	}), SkipErrInvalidEmails())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []EventType{EventValidEmail, EventNewDomain, EventInvalidEmail}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("should emit: %v, but got %v", expected, types)
+	}
+	if result.ValidEmails != 1 {
+		t.Errorf("should count %v valid emails, but got %v", 1, result.ValidEmails)
+	}
+}
@@ -0,0 +1,84 @@
+package customerimporter
+
+import (
+	"bufio"
+	"crypto"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"io"
+	"strings"
+	"sync"
+)
+
+// hash algorithms of suppressed emails by length of their hex digest
+var suppressionHashes = map[int]crypto.Hash{
+	2 * md5.Size:    crypto.MD5,
+	2 * sha256.Size: crypto.SHA256,
+	2 * sha512.Size: crypto.SHA512,
+}
+
+// Don't count emails found in the suppression list read from r, e.g. bounced
+// or unsubscribed addresses, and count them in ImportResult.Suppressed.
+// The list has an email or a hex MD5, SHA-256 or SHA-512 digest of a lower
+// case email on every line, other lines like a header are ignored. Emails are
+// compared case-insensitively. The list is read once when the first import
+// with the option starts, so the option can be reused by several imports.
+// Suppressed emails are neither validated for duplicates nor counted as valid.
+func WithSuppressionList(r io.Reader) Option {
+	list := &suppressionList{input: r}
+	return func(f *CustomerImporter) { f.suppression = list }
+}
+
+// suppressionList is a set of suppressed emails and digests of emails
+type suppressionList struct {
+	input  io.Reader                           // list to read
+	once   sync.Once                           // the list is read once
+	err    error                               // error of reading the list
+	emails map[string]struct{}                 // lower case emails
+	hashes map[crypto.Hash]map[string]struct{} // hex digests by hash algorithm
+}
+
+// reads the list unless it was read, error of the first read is returned
+func (s *suppressionList) load() error {
+	s.once.Do(func() {
+		s.emails = make(map[string]struct{})
+		s.hashes = make(map[crypto.Hash]map[string]struct{})
+
+		scanner := bufio.NewScanner(s.input)
+		for scanner.Scan() {
+			line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+			if strings.Contains(line, "@") {
+				s.emails[line] = struct{}{}
+				continue
+			}
+			hash, ok := suppressionHashes[len(line)]
+			if _, err := hex.DecodeString(line); !ok || err != nil {
+				continue
+			}
+			if s.hashes[hash] == nil {
+				s.hashes[hash] = make(map[string]struct{})
+			}
+			s.hashes[hash][line] = struct{}{}
+		}
+		s.err = scanner.Err()
+	})
+	return s.err
+}
+
+// reports whether the email is suppressed
+func (s *suppressionList) contains(email string) bool {
+	email = strings.ToLower(email)
+	if _, ok := s.emails[email]; ok {
+		return true
+	}
+	for hash, digests := range s.hashes {
+		h := hash.New()
+		h.Write([]byte(email))
+		if _, ok := digests[hex.EncodeToString(h.Sum(nil))]; ok {
+			return true
+		}
+	}
+	return false
+}
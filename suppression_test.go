@@ -0,0 +1,44 @@
+package customerimporter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestWithSuppressionList(t *testing.T) {
+	records := "email\na@a.io\nB@a.io\nc@a.io\nd@b.io\ne@b.io\n"
+	list := "email\nb@a.io\n" +
+		"714029fdea36b3d7b29e4f888dea64aa\n" + // MD5 of c@a.io
+		"8cfb9c4c3b4c0e2ac5c1e4f3c76e4d2f1b46c3cd2f9c3f3b41e2b2e1f8bd8a60\n" + // unknown SHA-256
+		"not an email\n"
+
+	t.Log("Should skip suppressed emails and digests")
+	result, err := ImportWithStats(strings.NewReader(records), "email", WithSuppressionList(strings.NewReader(list)))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if result.ValidEmails != 3 || result.Suppressed != 2 {
+		t.Errorf("should suppress 2 emails, but got %+v", result)
+	}
+
+	t.Log("Should reuse the list by imports with the option")
+	option := WithSuppressionList(strings.NewReader("d@b.io\n"))
+	for range 2 {
+		result, err := ImportWithStats(strings.NewReader(records), "email", option)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		if result.Suppressed != 1 {
+			t.Errorf("should suppress 1 email, but got %d", result.Suppressed)
+		}
+	}
+
+	t.Log("Should return error of reading the list")
+	readErr := errors.New("read failed")
+	_, err = ImportWithStats(strings.NewReader(records), "email", WithSuppressionList(iotest.ErrReader(readErr)))
+	if !errors.Is(err, readErr) {
+		t.Errorf("should return %v, but got %v", readErr, err)
+	}
+}
@@ -0,0 +1,101 @@
+package customerimporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Period is the length of the periods of time series of WithTimeSeries
+type Period int
+
+const (
+	PeriodDay   Period = iota // periods are days, e.g. 2024-01-31
+	PeriodWeek                // periods are ISO weeks, e.g. 2024-W05
+	PeriodMonth               // periods are months, e.g. 2024-01
+)
+
+// UnknownPeriod is the period of emails with empty or unparsable timestamp
+const UnknownPeriod = "unknown"
+
+// layouts of timestamps parsed by WithTimeSeries if none are given, dates
+// with slashes are ambiguous, so they need an explicit layout
+var defaultTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// String returns human readable name of the period
+func (p Period) String() string {
+	switch p {
+	case PeriodDay:
+		return "day"
+	case PeriodWeek:
+		return "week"
+	case PeriodMonth:
+		return "month"
+	}
+	return "unknown"
+}
+
+// Count emails of every domain also by period of the timestamp field, e.g.
+// "created_at", to follow growth of the domains over time in one pass. The
+// counts are returned in EmailsByDomainQty Series keyed by the period. The
+// timestamp is parsed by the first matching layout, RFC 3339 and ISO 8601
+// dates with or without time, e.g. "2024-01-31 10:00:00", are tried if none
+// are given. Dates like 03/04/2024 are day-first or month-first depending on
+// the export, so they are parsed only by explicit layout, e.g. "01/02/2006".
+// Surrounding spaces of the timestamp are ignored, emails with empty or
+// unparsable timestamp are counted in UnknownPeriod. The option requires the
+// header, so it's ignored together with WithColumnIndex.
+func WithTimeSeries(fieldName string, period Period, layouts ...string) Option {
+	return func(f *CustomerImporter) {
+		f.timeField, f.period, f.timeLayouts = fieldName, period, layouts
+		if len(layouts) == 0 {
+			f.timeLayouts = defaultTimeLayouts
+		}
+	}
+}
+
+// determine index of the timestamp column by WithTimeSeries
+func (c *CustomerImporter) determineTimeColumnIndex(headerRecord []string) error {
+	c.timeColumnIndex = -1
+	if c.timeField == "" {
+		return nil
+	}
+
+	index, err := c.fieldIndex(headerRecord, c.timeField)
+	if err != nil {
+		return err
+	}
+	c.timeColumnIndex = index
+	return nil
+}
+
+// returns period of the timestamp, UnknownPeriod if it can't be parsed
+func (c *CustomerImporter) periodOf(timestamp string) string {
+	timestamp = strings.TrimSpace(timestamp)
+	for _, layout := range c.timeLayouts {
+		t, err := time.Parse(layout, timestamp)
+		if err != nil {
+			continue
+		}
+		switch c.period {
+		case PeriodWeek:
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%04d-W%02d", year, week)
+		case PeriodMonth:
+			return t.Format("2006-01")
+		default:
+			return t.Format("2006-01-02")
+		}
+	}
+	return UnknownPeriod
+}
+
+// increments count of the domain by period of the timestamp field
+func (c *CustomerImporter) countSeries(r parsedRecord) {
+	counts := c.series[r.domain]
+	if counts == nil {
+		counts = make(map[string]int)
+		c.series[r.domain] = counts
+	}
+	counts[r.period]++
+}
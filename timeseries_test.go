@@ -0,0 +1,99 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithTimeSeries(t *testing.T) {
+	records := "email,created_at\n" +
+		"a@a.io,2024-01-30T10:00:00Z\n" +
+		"b@a.io,2024-02-01 08:30:00\n" +
+		"c@a.io,2024-02-05\n" +
+		"a@b.io,\n" +
+		"b@b.io,yesterday\n"
+
+	data := []struct {
+		name     string
+		period   Period
+		layouts  []string
+		options  []Option
+		expected EmailsByDomainQtyList
+	}{
+		{"by day", PeriodDay, nil, nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6, Series: map[string]int{"2024-01-30": 1, "2024-02-01": 1, "2024-02-05": 1}},
+			{Domain: "b.io", EmailsCount: 2, Share: 0.4, Series: map[string]int{UnknownPeriod: 2}},
+		}},
+		{"by week", PeriodWeek, nil, nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6, Series: map[string]int{"2024-W05": 2, "2024-W06": 1}},
+			{Domain: "b.io", EmailsCount: 2, Share: 0.4, Series: map[string]int{UnknownPeriod: 2}},
+		}},
+		{"by month", PeriodMonth, nil, nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6, Series: map[string]int{"2024-01": 1, "2024-02": 2}},
+			{Domain: "b.io", EmailsCount: 2, Share: 0.4, Series: map[string]int{UnknownPeriod: 2}},
+		}},
+		{"by layout", PeriodMonth, []string{"2006-01-02"}, nil, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6, Series: map[string]int{"2024-02": 1, UnknownPeriod: 2}},
+			{Domain: "b.io", EmailsCount: 2, Share: 0.4, Series: map[string]int{UnknownPeriod: 2}},
+		}},
+		{"collapsed domains", PeriodMonth, nil, []Option{TopN(1, true)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6, Series: map[string]int{"2024-01": 1, "2024-02": 2}},
+			{Domain: OtherDomain, EmailsCount: 2, Share: 0.4, Series: map[string]int{UnknownPeriod: 2}},
+		}},
+		{"with workers", PeriodMonth, nil, []Option{WithWorkers(2)}, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 3, Share: 0.6, Series: map[string]int{"2024-01": 1, "2024-02": 2}},
+			{Domain: "b.io", EmailsCount: 2, Share: 0.4, Series: map[string]int{UnknownPeriod: 2}},
+		}},
+	}
+
+	t.Log("Should count emails of every domain by period of the timestamp")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		options := append([]Option{WithTimeSeries("created_at", d.period, d.layouts...)}, d.options...)
+		result, err := Import(strings.NewReader(records), "email", options...)
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		if !reflect.DeepEqual(*result, d.expected) {
+			t.Errorf("should result with: %v, but got %v", d.expected, *result)
+		}
+	}
+
+	t.Log("Should parse ambiguous dates with slashes only by explicit layout")
+	dates := "email,created_at\na@a.io,03/04/2024\n"
+	layouts := []struct {
+		layouts  []string
+		expected map[string]int
+	}{
+		{nil, map[string]int{UnknownPeriod: 1}},
+		{[]string{"01/02/2006"}, map[string]int{"2024-03": 1}},
+		{[]string{"02/01/2006"}, map[string]int{"2024-04": 1}},
+	}
+	for _, d := range layouts {
+		t.Logf("Case: %v", d.layouts)
+
+		result, err := Import(strings.NewReader(dates), "email", WithTimeSeries("created_at", PeriodMonth, d.layouts...))
+		if err != nil {
+			t.Fatalf("should pass the test, but got error %v", err)
+		}
+		if series := (*result)[0].Series; !reflect.DeepEqual(series, d.expected) {
+			t.Errorf("should result with: %v, but got %v", d.expected, series)
+		}
+	}
+
+	t.Log("Should ignore spaces around the timestamp")
+	result, err := Import(strings.NewReader("email,created_at\na@a.io, 2024-01-31 \n"), "email", WithTimeSeries("created_at", PeriodMonth))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if expected := map[string]int{"2024-01": 1}; !reflect.DeepEqual((*result)[0].Series, expected) {
+		t.Errorf("should result with: %v, but got %v", expected, (*result)[0].Series)
+	}
+
+	t.Log("Should raise error if the field doesn't exist")
+	if _, err := Import(strings.NewReader(records), "email", WithTimeSeries("updated_at", PeriodDay)); err == nil || !strings.Contains(err.Error(), ErrFieldNotExists.Error()) {
+		t.Errorf("should raise error: %v, but got error %v", ErrFieldNotExists, err)
+	}
+}
@@ -0,0 +1,95 @@
+package customerimporter
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// name of the tracer of the importer spans
+const tracerName = "github.com/dreadfulangel/tw_t"
+
+// Trace the import by spans of the provider: the whole import, opening of
+// the input, header detection, parsing of the records and assembly of the
+// result. Counts of the records are set as attributes of the spans. Spans
+// are children of the span of ctx passed to ImportFromSource and
+// ImportFromURL or of WithTraceParent.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(f *CustomerImporter) { f.tracer = tp.Tracer(tracerName) }
+}
+
+// Start spans of WithTracerProvider as children of the span of ctx, e.g. of
+// the request importing the file
+func WithTraceParent(ctx context.Context) Option {
+	return func(f *CustomerImporter) { f.traceCtx = ctx }
+}
+
+// starts span of the import step as child of the import span
+func (c *CustomerImporter) startSpan(name string, attrs ...attribute.KeyValue) trace.Span {
+	tracer := c.tracer
+	if tracer == nil {
+		tracer = noop.Tracer{}
+	}
+	ctx := c.traceCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, span := tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return span
+}
+
+// ends span of the step, error is recorded unless it's end of input
+func endSpan(span trace.Span, err error, attrs ...attribute.KeyValue) {
+	span.SetAttributes(attrs...)
+	if err != nil && err != io.EOF {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// starts span of the whole import unless it's started, the steps are traced
+// as its children. Returned function ends the span with counts of the result.
+func (c *CustomerImporter) traceImport() func(*ImportResult, error) {
+	if c.tracer == nil || c.traced {
+		return func(*ImportResult, error) {}
+	}
+	c.traced = true
+
+	ctx := c.traceCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := c.tracer.Start(ctx, "customerimporter.Import",
+		trace.WithAttributes(attribute.String("customerimporter.email_field", c.emailFieldName)))
+	c.traceCtx = ctx
+
+	return func(result *ImportResult, err error) {
+		if result == nil {
+			endSpan(span, err, c.countAttributes()...)
+			return
+		}
+		endSpan(span, err,
+			attribute.Int("customerimporter.rows_read", result.RowsRead),
+			attribute.Int("customerimporter.valid_emails", result.ValidEmails),
+			attribute.Int("customerimporter.invalid_emails", result.InvalidEmails),
+			attribute.Int("customerimporter.duplicate_emails", result.DuplicateEmails),
+			attribute.Int("customerimporter.distinct_domains", result.DistinctDomains),
+		)
+	}
+}
+
+// returns attributes of the records counted so far
+func (c *CustomerImporter) countAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int("customerimporter.rows_read", c.rowsRead),
+		attribute.Int("customerimporter.valid_emails", c.validEmails),
+		attribute.Int("customerimporter.invalid_emails", c.invalidEmails),
+		attribute.Int("customerimporter.duplicate_emails", c.duplicateEmails),
+	}
+}
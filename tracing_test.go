@@ -0,0 +1,99 @@
+package customerimporter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// returns attribute of the span
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestWithTracerProvider(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "customers.csv")
+	if err := os.WriteFile(fileName, []byte("email\na@a.io\ninvalid\nb@b.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		name   string
+		run    func(options ...Option) error
+		spans  []string
+		status codes.Code
+	}{
+		{"file", func(options ...Option) error {
+			_, err := ImportFromFileWithStats(fileName, "email", append(options, SkipErrInvalidEmails())...)
+			return err
+		}, []string{"customerimporter.Open", "customerimporter.ParseHeader", "customerimporter.Parse", "customerimporter.Result", "customerimporter.Import"}, codes.Unset},
+		{"reader", func(options ...Option) error {
+			_, err := ImportWithStats(strings.NewReader("email\na@a.io\ninvalid\nb@b.io\n"), "email", append(options, SkipErrInvalidEmails())...)
+			return err
+		}, []string{"customerimporter.ParseHeader", "customerimporter.Parse", "customerimporter.Result", "customerimporter.Import"}, codes.Unset},
+		{"files", func(options ...Option) error {
+			_, err := ImportFromFilesWithStats([]string{fileName}, "email", append(options, SkipErrInvalidEmails())...)
+			return err
+		}, []string{"customerimporter.Open", "customerimporter.ParseHeader", "customerimporter.Parse", "customerimporter.Result", "customerimporter.Import"}, codes.Unset},
+		{"failed", func(options ...Option) error {
+			_, err := ImportFromFileWithStats(fileName, "email", options...)
+			return err
+		}, []string{"customerimporter.Open", "customerimporter.ParseHeader", "customerimporter.Parse", "customerimporter.Import"}, codes.Error},
+		{"missing file", func(options ...Option) error {
+			_, err := ImportFromFileWithStats(filepath.Join(dir, "missing.csv"), "email", options...)
+			return err
+		}, []string{"customerimporter.Open", "customerimporter.Import"}, codes.Error},
+	}
+
+	t.Log("Should trace steps of the import as children of the import span")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		recorder := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		parentCtx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+		d.run(WithTracerProvider(tp), WithTraceParent(parentCtx))
+		parent.End()
+
+		spans := recorder.Ended()
+		var names []string
+		for _, span := range spans[:len(spans)-1] {
+			names = append(names, span.Name())
+		}
+		if !slices.Equal(names, d.spans) {
+			t.Errorf("should end spans %v, but got %v", d.spans, names)
+			continue
+		}
+
+		// steps are children of the import span, which is child of the parent
+		root := spans[len(spans)-2]
+		for _, span := range spans[:len(spans)-2] {
+			if span.Parent().SpanID() != root.SpanContext().SpanID() {
+				t.Errorf("span %v should be child of the import span", span.Name())
+			}
+		}
+		if root.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Error("import span should be child of the parent span")
+		}
+		if root.Status().Code != d.status {
+			t.Errorf("should end import span with status %v, but got %v", d.status, root.Status())
+		}
+		if d.status == codes.Unset && spanAttribute(root, "customerimporter.valid_emails").AsInt64() != 2 {
+			t.Errorf("should set counts of the result, but got %v", root.Attributes())
+		}
+	}
+}
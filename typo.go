@@ -0,0 +1,166 @@
+package customerimporter
+
+import (
+	"slices"
+	"strings"
+)
+
+// typoDomains are well-known domains checked for typos by default
+var typoDomains = []string{
+	"gmail.com", "yahoo.com", "hotmail.com", "outlook.com", "icloud.com", "aol.com",
+	"live.com", "msn.com", "googlemail.com", "protonmail.com", "yandex.ru", "mail.ru",
+	"gmx.de", "web.de", "comcast.net", "verizon.net",
+}
+
+// DomainTypo is a counted domain which is likely a typo of a well-known one
+type DomainTypo struct {
+	Domain      string `json:"domain"`     // domain as written in emails
+	Suggestion  string `json:"suggestion"` // well-known domain it's likely a typo of
+	EmailsCount int    `json:"count"`      // amount of counted emails of the domain
+}
+
+// Report domains which are likely typos of well-known domains, e.g.
+// gmial.com or yaho.com, in ImportResult.Typos. A domain is a typo if it
+// differs from a well-known one by a single inserted, deleted, replaced or
+// swapped character, or by two of them if the well-known domain has at least
+// 10 characters. The well-known domains are the given ones or popular free
+// mail providers if none are given, they and other free mail providers are
+// never reported themselves.
+func DetectTypos(domains ...string) Option {
+	return func(f *CustomerImporter) { f.typos = newTypoDetector(domains) }
+}
+
+// Count emails of the domains reported by DetectTypos by the suggested domain,
+// e.g. john@gmial.com is counted and deduplicated as john@gmail.com. Popular
+// free mail providers are checked unless DetectTypos sets other domains.
+func CorrectTypos() Option {
+	return func(f *CustomerImporter) {
+		if f.typos == nil {
+			f.typos = newTypoDetector(nil)
+		}
+		f.correctTypos = true
+	}
+}
+
+// typoDetector finds typos of well-known domains
+type typoDetector struct {
+	domains     []string          // well-known domains
+	suggestions map[string]string // suggested domain by checked domain, empty if it's not a typo
+	counts      map[string]int    // counted emails by typo domain
+}
+
+// returns detector of typos of the domains, popular ones if none are given
+func newTypoDetector(domains []string) *typoDetector {
+	if len(domains) == 0 {
+		domains = typoDomains
+	}
+	normalized := make([]string, len(domains))
+	for i, domain := range domains {
+		normalized[i] = normalizeDomain(domain)
+	}
+	return &typoDetector{domains: normalized, suggestions: make(map[string]string), counts: make(map[string]int)}
+}
+
+// returns well-known domain the domain is likely a typo of, empty if it's not
+// a typo, results are cached
+func (d *typoDetector) suggest(domain string) string {
+	suggestion, ok := d.suggestions[domain]
+	if ok {
+		return suggestion
+	}
+
+	if !slices.Contains(d.domains, domain) && !freemailDomains[domain] {
+		best := 0
+		for _, known := range d.domains {
+			limit := 1
+			if len(known) >= 10 {
+				limit = 2
+			}
+			if distance := editDistance(domain, known, limit); distance <= limit && (suggestion == "" || distance < best) {
+				suggestion, best = known, distance
+			}
+		}
+	}
+
+	d.suggestions[domain] = suggestion
+	return suggestion
+}
+
+// checks domain of the valid email for typos and corrects the email if typos
+// are corrected, returns the domain as written if it's a typo
+func (c *CustomerImporter) checkTypo(r *parsedRecord) string {
+	at := strings.LastIndexByte(r.email, '@')
+	domain := r.email[at+1:]
+	suggestion := c.typos.suggest(domain)
+	if suggestion == "" {
+		return ""
+	}
+
+	if c.correctTypos {
+		r.email = r.email[:at+1] + suggestion
+		r.domain = suggestion
+		if c.groupBy != nil {
+			r.domain = c.groupBy(suggestion)
+		}
+	}
+	return domain
+}
+
+// restores counts of typo domains saved by checkpoint, suggestions are checked
+// again
+func (d *typoDetector) restore(counts map[string]int) {
+	d.counts = make(map[string]int, len(counts))
+	for domain, count := range counts {
+		d.counts[domain] = count
+		d.suggest(domain)
+	}
+}
+
+// returns typos sorted by domain, nil if typos are not detected
+func (d *typoDetector) result() []DomainTypo {
+	if d == nil {
+		return nil
+	}
+	var typos []DomainTypo
+	for domain, count := range d.counts {
+		typos = append(typos, DomainTypo{Domain: domain, Suggestion: d.suggestions[domain], EmailsCount: count})
+	}
+	slices.SortFunc(typos, func(a, b DomainTypo) int { return strings.Compare(a.Domain, b.Domain) })
+	return typos
+}
+
+// returns optimal string alignment distance of a and b, distances greater
+// than limit are returned as limit+1
+func editDistance(a, b string, limit int) int {
+	if len(a)-len(b) > limit || len(b)-len(a) > limit {
+		return limit + 1
+	}
+
+	// rows of distances of the prefixes of a to the prefixes of b
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return min(prev[len(b)], limit+1)
+}
@@ -0,0 +1,127 @@
+package customerimporter
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		limit    int
+		distance int
+	}{
+		{"gmail.com", "gmail.com", 1, 0},
+		{"gmial.com", "gmail.com", 1, 1},
+		{"gmai.com", "gmail.com", 1, 1},
+		{"gmaill.com", "gmail.com", 1, 1},
+		{"gnail.com", "gmail.com", 1, 1},
+		{"gmal.con", "gmail.com", 1, 2},
+		{"gmal.con", "gmail.com", 2, 2},
+		{"mail.com", "gmail.com", 0, 1},
+		{"", "abc", 5, 3},
+		{"yahoo.com", "gmail.com", 2, 3},
+	}
+
+	t.Log("Should return distance of inserted, deleted, replaced and swapped characters")
+	for _, test := range tests {
+		t.Logf("Case: %v %v", test.a, test.b)
+
+		if distance := editDistance(test.a, test.b, test.limit); distance != test.distance {
+			t.Errorf("should return %d, but got %d", test.distance, distance)
+		}
+	}
+}
+
+func TestTypoDetectorSuggest(t *testing.T) {
+	tests := []struct {
+		domain     string
+		suggestion string
+	}{
+		{"gmial.com", "gmail.com"},
+		{"gmail.co", "gmail.com"},
+		{"yaho.com", "yahoo.com"},
+		{"hotmial.com", "hotmail.com"},
+		{"hotmal.con", "hotmail.com"},
+		{"gmail.com", ""},
+		{"mail.com", ""},
+		{"example.com", ""},
+	}
+	detector := newTypoDetector(nil)
+
+	t.Log("Should suggest well-known domain of typos")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.domain)
+
+		if suggestion := detector.suggest(test.domain); suggestion != test.suggestion {
+			t.Errorf("should suggest %q, but got %q", test.suggestion, suggestion)
+		}
+	}
+}
+
+func TestDetectTypos(t *testing.T) {
+	records := "email\na@gmail.com\nb@gmial.com\na@gmial.com\nc@gmial.com\nd@exmaple.com\ne@example.com\n"
+
+	t.Log("Should report typos of well-known domains without changing counts")
+	result, err := ImportWithStats(strings.NewReader(records), "email", DetectTypos())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := []DomainTypo{{Domain: "gmial.com", Suggestion: "gmail.com", EmailsCount: 3}}
+	if !reflect.DeepEqual(result.Typos, expected) {
+		t.Errorf("should report %+v, but got %+v", expected, result.Typos)
+	}
+	if len(result.Domains) != 4 || result.DuplicateEmails != 0 {
+		t.Errorf("should count typos as written, but got %+v", result)
+	}
+
+	t.Log("Should report typos of the given domains")
+	result, err = ImportWithStats(strings.NewReader(records), "email", DetectTypos("example.com"))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected = []DomainTypo{{Domain: "exmaple.com", Suggestion: "example.com", EmailsCount: 1}}
+	if !reflect.DeepEqual(result.Typos, expected) {
+		t.Errorf("should report %+v, but got %+v", expected, result.Typos)
+	}
+
+	t.Log("Should count and deduplicate typos by the suggested domain")
+	result, err = ImportWithStats(strings.NewReader(records), "email", CorrectTypos(), SkipErrDuplicateEmails())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected = []DomainTypo{{Domain: "gmial.com", Suggestion: "gmail.com", EmailsCount: 2}}
+	if !reflect.DeepEqual(result.Typos, expected) {
+		t.Errorf("should report %+v, but got %+v", expected, result.Typos)
+	}
+	domains := EmailsByDomainQtyList{
+		{Domain: "example.com", EmailsCount: 1, Share: 0.2},
+		{Domain: "exmaple.com", EmailsCount: 1, Share: 0.2},
+		{Domain: "gmail.com", EmailsCount: 3, Share: 0.6},
+	}
+	if !reflect.DeepEqual(result.Domains, domains) {
+		t.Errorf("should count %+v, but got %+v", domains, result.Domains)
+	}
+	if result.DuplicateEmails != 1 {
+		t.Errorf("should count corrected email as duplicate, but got %d", result.DuplicateEmails)
+	}
+
+	t.Log("Should report typos counted before the checkpoint of resumed import")
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+	interrupted := io.MultiReader(strings.NewReader("email\na@gmail.com\nb@gmial.com\n"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	if _, err := Import(interrupted, "email", DetectTypos(), WithCheckpoint(path, 1)); err == nil {
+		t.Fatal("should raise error of interrupted import")
+	}
+	result, err = ImportWithStats(strings.NewReader(records), "email", DetectTypos(), WithCheckpoint(path, 1))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected = []DomainTypo{{Domain: "gmial.com", Suggestion: "gmail.com", EmailsCount: 3}}
+	if !reflect.DeepEqual(result.Typos, expected) {
+		t.Errorf("should report %+v, but got %+v", expected, result.Typos)
+	}
+}
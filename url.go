@@ -0,0 +1,47 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrUnexpectedStatus is raised when the server doesn't respond with 200 OK
+var ErrUnexpectedStatus = errors.New("Unexpected HTTP status")
+
+// sets HTTP client used by ImportFromURL and WithCompletionWebhook, its
+// Timeout and CheckRedirect configure the timeout and redirect policy of the
+// download. The default client is used otherwise.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *CustomerImporter) {
+		c.httpClient = client
+	}
+}
+
+// imports from the URL
+func ImportFromURL(ctx context.Context, rawURL string, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	// import and get result
+	result, err := ImportFromURLWithStats(ctx, rawURL, emailFieldName, options...)
+	if result == nil {
+		return nil, err
+	}
+
+	return &result.Domains, err
+}
+
+// imports from the URL and returns ImportResult with statistics. The body is
+// parsed while it is downloaded. Compressed bodies are detected by the path
+// extension or by magic bytes unless WithCompression is used.
+func ImportFromURLWithStats(ctx context.Context, rawURL string, emailFieldName string, options ...Option) (*ImportResult, error) {
+	src := &urlSource{url: rawURL}
+
+	// detect compression by extension, explicit option takes precedence
+	if compression, ok := compressionByExtension(src.Name()); ok {
+		options = append([]Option{WithCompression(compression)}, options...)
+	}
+	c := newCustomerImporter(nil, emailFieldName, options...)
+	src.client = c.httpClient
+
+	// download and import
+	return c.runSource(ctx, src)
+}
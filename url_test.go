@@ -0,0 +1,76 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportFromURL(t *testing.T) {
+	csvData := "email\na@a.io\nb@a.io\na@b.io\n"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/customers.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(csvData))
+	})
+	mux.HandleFunc("/customers.csv.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(gzipData(t, csvData))
+	})
+	mux.HandleFunc("/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(gzipData(t, csvData))
+	})
+	mux.Handle("/redirect", http.RedirectHandler("/customers.csv", http.StatusFound))
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	noRedirects := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	expected := EmailsByDomainQtyList{
+		{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+		{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+	}
+
+	tests := []struct {
+		path     string
+		options  []Option
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{"/customers.csv", nil, expected, nil},
+		{"/customers.csv.gz", nil, expected, nil},
+		{"/export", nil, expected, nil},
+		{"/redirect", nil, expected, nil},
+		{"/redirect", []Option{WithHTTPClient(noRedirects)}, nil, ErrUnexpectedStatus},
+		{"/missing.csv", nil, nil, ErrUnexpectedStatus},
+		{"/slow", []Option{WithHTTPClient(&http.Client{Timeout: 10 * time.Millisecond})}, nil, context.DeadlineExceeded},
+	}
+
+	t.Log("Should import emails from the URL")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.path)
+
+		result, err := ImportFromURL(context.Background(), server.URL+test.path, "email", test.options...)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("should raise error: %v, but got error %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, test.expected) {
+			t.Errorf("should return %v, but got %v", test.expected, *result)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package customerimporter
+
+// Parse and validate all records without counting domains, e.g. as a check
+// of the data quality before a big import. Invalid and duplicate emails are
+// skipped and counted, the result contains only statistics.
+func ValidateOnly() Option {
+	return func(f *CustomerImporter) {
+		f.validateOnly = true
+		f.skipErrInvalidEmails = true
+		f.skipErrDupEmails = true
+	}
+}
@@ -0,0 +1,44 @@
+package customerimporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateOnly(t *testing.T) {
+	records := "name,email\n" +
+		"A,a@a.io\n" +
+		"B,invalid\n" +
+		"C,a@a.io\n" +
+		"D\n" +
+		"E,b@b.io\n" +
+		"F,\n"
+
+	t.Log("Should validate all records without counting domains")
+	result, err := ImportWithStats(strings.NewReader(records), "email", ValidateOnly(), SkipMalformedRows(), WithSamples(1))
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	expected := &ImportResult{
+		RowsRead:        5,
+		ValidEmails:     2,
+		InvalidEmails:   2,
+		InvalidByReason: map[string]int{ErrEmailIsNotValid.Error(): 1, ErrEmailEmpty.Error(): 1},
+		DuplicateEmails: 1,
+		MalformedRows:   1,
+	}
+	result.Elapsed = 0
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("should return %+v, but got %+v", expected, result)
+	}
+
+	t.Log("Should return statistics if no email is valid")
+	result, err = ImportWithStats(strings.NewReader("email\ninvalid\n"), "email", ValidateOnly())
+	if err != nil {
+		t.Fatalf("should pass the test, but got error %v", err)
+	}
+	if result.InvalidEmails != 1 || result.Domains != nil {
+		t.Errorf("should count 1 invalid email, but got %+v", result)
+	}
+}
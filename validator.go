@@ -1,14 +1,167 @@
 package customerimporter
 
-import "regexp"
+import (
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
+// length limits of RFC 5321 in bytes
 const (
-	// emailRegexString fastest regex from go-playground/validator
-	emailRegexString = "^(?:(?:(?:(?:[a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_`{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+(?:\\.([a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_`{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+)*)|(?:(?:\\x22)(?:(?:(?:(?:\\x20|\\x09)*(?:\\x0d\\x0a))?(?:\\x20|\\x09)+)?(?:(?:[\\x01-\\x08\\x0b\\x0c\\x0e-\\x1f\\x7f]|\\x21|[\\x23-\\x5b]|[\\x5d-\\x7e]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:\\(?:[\\x01-\\x09\\x0b\\x0c\\x0d-\\x7f]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}]))))*(?:(?:(?:\\x20|\\x09)*(?:\\x0d\\x0a))?(\\x20|\\x09)+)?(?:\\x22)))@(?:(?:(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])(?:[a-zA-Z]|\\d|-|\\.|_|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*(?:[a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.)+(?:(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(?:(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])(?:[a-zA-Z]|\\d|-|\\.|_|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*(?:[a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.?$"
+	maxEmailLength  = 254 // forward-path without angle brackets
+	maxLocalLength  = 64
+	maxDomainLength = 253 // without the trailing dot
+	maxLabelLength  = 63
 )
 
-var emailRegex = regexp.MustCompile(emailRegexString)
+// ValidationLevel defines how strictly emails are validated
+type ValidationLevel int
+
+const (
+	ValidationStandard ValidationLevel = iota // practical subset of RFC 5321 and 5322
+	ValidationLenient                         // single @ followed by dotted domain
+	ValidationStrict                          // RFC 5322 addr-spec as parsed by net/mail
+)
 
+// IsValidEmail validates email with the standard level: dot-atom or quoted
+// local part, host name of at least two labels with letters, digits and
+// hyphens, optionally fully qualified, and length limits of RFC 5321.
+// Non-ASCII letters are allowed as internationalized emails of RFC 6531.
 func IsValidEmail(email string) bool {
-	return emailRegex.MatchString(email)
+	at := strings.LastIndexByte(email, '@')
+	if at < 1 || len(email) > maxEmailLength || !utf8.ValidString(email) {
+		return false
+	}
+	return isLocalPart(email[:at]) && isHostName(email[at+1:])
+}
+
+// reports whether local part is dot-atom or quoted string
+func isLocalPart(local string) bool {
+	if len(local) > maxLocalLength {
+		return false
+	}
+	if local[0] == '"' {
+		return isQuotedString(local)
+	}
+	return isDotAtom(local)
+}
+
+// reports whether local part is dot-atom of RFC 5322, atoms of atext separated
+// by single dots
+func isDotAtom(local string) bool {
+	if local == "" || local[0] == '.' || local[len(local)-1] == '.' || strings.Contains(local, "..") {
+		return false
+	}
+	for _, r := range local {
+		if r != '.' && !isAtext(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// reports whether local part is quoted string of RFC 5321, printable
+// characters and spaces, quotes and backslashes are escaped by backslash
+func isQuotedString(local string) bool {
+	if len(local) < 2 || local[len(local)-1] != '"' {
+		return false
+	}
+	escaped := false
+	for _, r := range local[1 : len(local)-1] {
+		switch {
+		case escaped:
+			escaped = false
+			if r < ' ' || r == 0x7f {
+				return false
+			}
+		case r == '\\':
+			escaped = true
+		case r == '"' || r < ' ' || r == 0x7f:
+			return false
+		}
+	}
+	return !escaped
+}
+
+// reports whether domain is host name of at least two labels, the last label
+// begins with a letter
+func isHostName(domain string) bool {
+	domain = strings.TrimSuffix(domain, ".")
+	if len(domain) > maxDomainLength {
+		return false
+	}
+	dot := strings.LastIndexByte(domain, '.')
+	if dot < 0 {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(domain[dot+1:]); !unicode.IsLetter(r) {
+		return false
+	}
+	for {
+		label, rest, found := strings.Cut(domain, ".")
+		if !isLabel(label) {
+			return false
+		}
+		if !found {
+			return true
+		}
+		domain = rest
+	}
+}
+
+// reports whether label contains letters, digits and hyphens, which aren't
+// at its beginning or end
+func isLabel(label string) bool {
+	if label == "" || len(label) > maxLabelLength || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, r := range label {
+		if r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// reports whether r is atext character of RFC 5322 or non-ASCII character of
+// RFC 6532
+func isAtext(r rune) bool {
+	if r >= utf8.RuneSelf {
+		return unicode.IsGraphic(r) && !unicode.IsSpace(r)
+	}
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+		strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", r)
+}
+
+// IsValidEmail validates email according to the level
+func (level ValidationLevel) IsValidEmail(email string) bool {
+	switch level {
+	case ValidationLenient:
+		return isLenientEmail(email)
+	case ValidationStrict:
+		return isStrictEmail(email)
+	}
+	return IsValidEmail(email)
+}
+
+// requires single @ with non-empty local part and dotted domain
+func isLenientEmail(email string) bool {
+	at := strings.IndexByte(email, '@')
+	if at < 1 || strings.Count(email, "@") != 1 {
+		return false
+	}
+	domain := email[at+1:]
+	dot := strings.IndexByte(domain, '.')
+	return dot > 0 && !strings.HasSuffix(domain, ".")
+}
+
+// requires bare address accepted by net/mail, quoted local parts are allowed
+// but display names, angle brackets and surrounding whitespace are not
+func isStrictEmail(email string) bool {
+	if strings.TrimSpace(email) != email || strings.ContainsAny(email, "<>") {
+		return false
+	}
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Name == ""
 }
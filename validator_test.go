@@ -1,6 +1,7 @@
 package customerimporter
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -20,3 +21,97 @@ func TestIsValidEmail(t *testing.T) {
 		}
 	}
 }
+
+func TestValidationLevel(t *testing.T) {
+	data := []struct {
+		email    string
+		lenient  bool
+		standard bool
+		strict   bool
+	}{
+		{"email@example.com", true, true, true},
+		{"first.last+tag@sub.example.co.uk", true, true, true},
+		{"emailexample.com", false, false, false},
+		{"", false, false, false},
+		{"@example.com", false, false, false},
+		{"email@@example.com", false, false, false},
+		{"email@example", false, false, true},
+		{"email@example.", false, false, false},
+		{"email@.com", false, false, false},
+		{"a b@example.com", true, false, false},
+		{`"john doe"@example.com`, true, true, true},
+		{`"john@doe"@example.com`, false, true, true},
+		{"John Doe <john@example.com>", true, false, false},
+		{" email@example.com", true, false, false},
+	}
+
+	for testNumber, d := range data {
+		results := []struct {
+			level    ValidationLevel
+			expected bool
+		}{
+			{ValidationLenient, d.lenient},
+			{ValidationStandard, d.standard},
+			{ValidationStrict, d.strict},
+		}
+		for _, r := range results {
+			if isEmail := r.level.IsValidEmail(d.email); isEmail != r.expected {
+				t.Errorf("case %v: level %v should validate %q as %v, but got %v", testNumber, r.level, d.email, r.expected, isEmail)
+			}
+		}
+	}
+}
+
+func TestIsValidEmailRFC(t *testing.T) {
+	data := []struct {
+		email   string
+		isEmail bool
+	}{
+		// local part
+		{"!#$%&'*+-/=?^_`{|}~@example.com", true},
+		{"first.last@example.com", true},
+		{".first@example.com", false},
+		{"first.@example.com", false},
+		{"first..last@example.com", false},
+		{`"first..last"@example.com`, true},
+		{`"first\"last"@example.com`, true},
+		{`"first"last"@example.com`, false},
+		{`"first\"@example.com`, false},
+		{`"first`, false},
+		{"jörg@example.com", true},
+		{"a\tb@example.com", false},
+
+		// domain
+		{"email@sub.example.co.uk", true},
+		{"email@example.com.", true},
+		{"email@example.com..", false},
+		{"email@my-example.com", true},
+		{"email@-example.com", false},
+		{"email@example-.com", false},
+		{"email@exa_mple.com", false},
+		{"email@example..com", false},
+		{"email@example.c0m", true},
+		{"email@example.123", false},
+		{"email@123.example.com", true},
+		{"email@münchen.de", true},
+		{"email@xn--mnchen-3ya.de", true},
+
+		// length limits
+		{strings.Repeat("a", 64) + "@example.com", true},
+		{strings.Repeat("a", 65) + "@example.com", false},
+		{"email@" + strings.Repeat("a", 63) + ".com", true},
+		{"email@" + strings.Repeat("a", 64) + ".com", false},
+		{"email@" + strings.Repeat("a.", 122) + "com", true},
+		{"email@" + strings.Repeat("a.", 123) + "com", false},
+		{"\xff@example.com", false},
+	}
+
+	t.Log("Should validate emails according to RFC 5321 and 5322")
+	for _, d := range data {
+		t.Logf("Case: %q", d.email)
+
+		if isEmail := IsValidEmail(d.email); isEmail != d.isEmail {
+			t.Errorf("should validate as %v, but got %v", d.isEmail, isEmail)
+		}
+	}
+}
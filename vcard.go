@@ -0,0 +1,229 @@
+package customerimporter
+
+import (
+	"bufio"
+	"io"
+	"mime/quotedprintable"
+	"os"
+	"slices"
+	"strings"
+)
+
+// fields of the records of contacts read by VCardReader, VCardEmail is the
+// email field
+const (
+	VCardEmail = "email" // address of the EMAIL property
+	VCardName  = "name"  // formatted name of the contact
+	VCardOrg   = "org"   // organization of the contact
+)
+
+// header of the records of contacts
+var vcardHeader = []string{VCardEmail, VCardName, VCardOrg}
+
+// VCardReader reads contacts of vCard file, e.g. export of an address book,
+// as records with VCardEmail, VCardName and VCardOrg fields, the first record
+// is the header. Contact is read as a record for each of its EMAIL properties,
+// contacts without email are skipped. Versions 2.1, 3.0 and 4.0 are read.
+type VCardReader struct {
+	closer  io.Closer // file opened by OpenVCard
+	br      *bufio.Reader
+	header  bool       // header is read
+	next    string     // line read ahead while unfolding
+	records [][]string // unread records of the last contact
+}
+
+// imports emails of contacts of vCard file and returns emails counted by their
+// domain
+func ImportFromVCard(fileName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	r, err := OpenVCard(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ImportRecords(r, VCardEmail, options...)
+}
+
+// imports emails of contacts of vCard file and returns result with statistics
+func ImportFromVCardWithStats(fileName string, options ...Option) (*ImportResult, error) {
+	r, err := OpenVCard(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ImportRecordsWithStats(r, VCardEmail, options...)
+}
+
+// OpenVCard opens vCard file
+func OpenVCard(fileName string) (*VCardReader, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewVCardReader(file)
+	r.closer = file
+	return r, nil
+}
+
+// NewVCardReader returns reader of vCard contacts read from r
+func NewVCardReader(r io.Reader) *VCardReader {
+	return &VCardReader{br: bufio.NewReader(r)}
+}
+
+// Read returns record of the next email of the contacts
+func (r *VCardReader) Read() ([]string, error) {
+	if !r.header {
+		r.header = true
+		return slices.Clone(vcardHeader), nil
+	}
+
+	for len(r.records) == 0 {
+		if err := r.readContact(); err != nil {
+			return nil, err
+		}
+	}
+	record := r.records[0]
+	r.records = r.records[1:]
+	return record, nil
+}
+
+// Close closes the file opened by OpenVCard
+func (r *VCardReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// reads properties of the next contact up to its END line and sets records of
+// its emails
+func (r *VCardReader) readContact() error {
+	var emails []string
+	var name, org string
+	inContact := false
+	for {
+		line, err := r.readLine()
+		if err == io.EOF && inContact {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		property, value, ok := vcardProperty(line)
+		switch {
+		case !ok:
+		case property == "BEGIN" && strings.EqualFold(value, "VCARD"):
+			inContact = true
+		case !inContact:
+		case property == "END" && strings.EqualFold(value, "VCARD"):
+			for _, email := range emails {
+				r.records = append(r.records, []string{email, name, org})
+			}
+			return nil
+		case property == "EMAIL":
+			emails = append(emails, value)
+		case property == "FN":
+			name = value
+		case property == "ORG":
+			// organization units are separated by semicolons
+			org, _, _ = strings.Cut(value, ";")
+		}
+	}
+}
+
+// returns the next line with its continuation lines unfolded
+func (r *VCardReader) readLine() (string, error) {
+	line := r.next
+	r.next = ""
+	if line == "" {
+		var err error
+		if line, err = r.readRawLine(); err != nil {
+			return "", err
+		}
+	}
+
+	// lines beginning with whitespace continue the previous line, quoted
+	// printable lines are continued after soft line break
+	for {
+		next, err := r.readRawLine()
+		if err == io.EOF {
+			return line, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case next != "" && (next[0] == ' ' || next[0] == '\t'):
+			line += next[1:]
+		case strings.HasSuffix(line, "=") && strings.Contains(strings.ToUpper(line), "QUOTED-PRINTABLE"):
+			line += "\r\n" + next
+		default:
+			r.next = next
+			return line, nil
+		}
+	}
+}
+
+// returns the next line without line break, empty lines are skipped
+func (r *VCardReader) readRawLine() (string, error) {
+	for {
+		line, err := r.br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return "", io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			return line, nil
+		}
+	}
+}
+
+// returns upper case name and decoded value of the content line, e.g.
+// item1.EMAIL;TYPE=work:a@a.io, group of the property is dropped
+func vcardProperty(line string) (string, string, bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+	params := strings.Split(head, ";")
+	name := strings.ToUpper(params[0])
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	for _, param := range params[1:] {
+		if strings.EqualFold(param, "ENCODING=QUOTED-PRINTABLE") || strings.EqualFold(param, "QUOTED-PRINTABLE") {
+			if decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(value))); err == nil {
+				value = string(decoded)
+			}
+		}
+	}
+	return name, strings.TrimSpace(vcardUnescape(value)), true
+}
+
+// unescapes backslash escapes of the value
+func vcardUnescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			switch value[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(value[i])
+			}
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package customerimporter
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// contacts of vCard 2.1, 3.0 and 4.0, the last contact has no email
+const testVCard = "BEGIN:VCARD\r\n" +
+	"VERSION:3.0\r\n" +
+	"FN:Alice Smith\r\n" +
+	"ORG:Acme;Sales\r\n" +
+	"EMAIL;TYPE=INTERNET,WORK:a@a.io\r\n" +
+	"item1.EMAIL;TYPE=INTERNET:alice@\r\n" +
+	" b.io\r\n" +
+	"END:VCARD\r\n" +
+	"\r\n" +
+	"BEGIN:VCARD\n" +
+	"VERSION:2.1\n" +
+	"FN;ENCODING=QUOTED-PRINTABLE;CHARSET=UTF-8:Jos=C3=A9 Gar=\n" +
+	"c=C3=ADa\n" +
+	"EMAIL;INTERNET:b@a.io\n" +
+	"END:VCARD\n" +
+	"BEGIN:VCARD\n" +
+	"VERSION:4.0\n" +
+	"FN:Bob\\, Jr.\n" +
+	"TEL:+1 555 0100\n" +
+	"END:VCARD\n"
+
+func TestVCardReader(t *testing.T) {
+	t.Log("Should read a record for every email of the contacts")
+
+	expected := [][]string{
+		vcardHeader,
+		{"a@a.io", "Alice Smith", "Acme"},
+		{"alice@b.io", "Alice Smith", "Acme"},
+		{"b@a.io", "José García", ""},
+	}
+
+	r := NewVCardReader(strings.NewReader(testVCard))
+	for i, want := range expected {
+		record, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(record, want) {
+			t.Errorf("record %v should be %q, but got %q", i, want, record)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("should return %v, but got %v", io.EOF, err)
+	}
+}
+
+func TestImportFromVCard(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "contacts.vcf")
+	if err := os.WriteFile(fileName, []byte(testVCard), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	truncated := filepath.Join(dir, "truncated.vcf")
+	if err := os.WriteFile(truncated, []byte("BEGIN:VCARD\nEMAIL:a@a.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		fileName string
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{fileName, EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, nil},
+		{truncated, nil, io.ErrUnexpectedEOF},
+		{filepath.Join(dir, "missing.vcf"), nil, os.ErrNotExist},
+	}
+
+	t.Log("Should count domains of the contact emails")
+	for _, d := range data {
+		t.Logf("Case: %v", filepath.Base(d.fileName))
+
+		result, err := ImportFromVCard(d.fileName)
+		if d.err != nil {
+			if !errors.Is(err, d.err) {
+				t.Errorf("should raise error: %v, but got error %v", d.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, d.expected) {
+			t.Errorf("should return %v, but got %v", d.expected, *result)
+		}
+	}
+}
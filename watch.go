@@ -0,0 +1,228 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultSettleDelay is time without writes before Watch imports a new file
+const DefaultSettleDelay = time.Second
+
+// WatchUpdate reports records imported by Watch or Tail
+type WatchUpdate struct {
+	File   string        // file the records were imported from
+	Result *ImportResult // emails counted across all imported records so far
+	Err    error         // error of the file or collected errors of the skipped records
+}
+
+// WatchHandler is called for every update of Watch and Tail, returned error
+// stops watching
+type WatchHandler func(update WatchUpdate) error
+
+// Import a file by Watch once nothing is written to it for the delay, so
+// files uploaded slowly aren't read before they're complete. DefaultSettleDelay
+// is used by default.
+func WithSettleDelay(delay time.Duration) Option {
+	return func(f *CustomerImporter) { f.settleDelay = delay }
+}
+
+// watches the directory and imports files matching the glob pattern as they
+// arrive, e.g. to count emails of files uploaded to a drop folder. Files in the
+// directory are imported first, then every new file is imported once after
+// WithSettleDelay. Emails are counted and deduplicated across all files,
+// handler receives counts of all files after every imported file. A file
+// failing the import is reported by Err of the update, its records read before
+// the error stay counted. Limits of WithMaxRows and LimitValidEmails apply
+// across all files, files arriving once the import is truncated aren't read
+// and are reported with ErrLimitReached. Empty pattern matches all files, subdirectories
+// aren't watched. Watch returns ctx.Err() when ctx is done.
+func Watch(ctx context.Context, dir, pattern, emailFieldName string, handler WatchHandler, options ...Option) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return err
+	}
+
+	c := newCustomerImporter(nil, emailFieldName, options...)
+	defer c.closeSpill()
+	if c.settleDelay <= 0 {
+		c.settleDelay = DefaultSettleDelay
+	}
+
+	// watch before listing, so files created meanwhile aren't missed
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		return err
+	}
+
+	// time of the last write by name of the files waiting for import, files
+	// in the directory are ready
+	pending := make(map[string]time.Time)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if matchFile(pattern, e.Name()) {
+			pending[e.Name()] = time.Time{}
+		}
+	}
+
+	imported := make(map[string]bool)
+	compression := c.compression
+	ticker := time.NewTicker(max(c.settleDelay/4, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		// import files without writes for the settle delay by name
+		for _, name := range slices.Sorted(maps.Keys(pending)) {
+			if time.Since(pending[name]) < c.settleDelay {
+				continue
+			}
+			delete(pending, name)
+
+			fileName := filepath.Join(dir, name)
+			info, err := os.Stat(fileName)
+			if imported[name] || err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			imported[name] = true
+
+			c.compression = compression
+			update := WatchUpdate{File: fileName}
+			if c.truncated {
+				// limits apply across all files, the file isn't read
+				update.Result, _ = c.result()
+				update.Err = fmt.Errorf("%s: %w", fileName, ErrLimitReached)
+			} else if err := c.parseEntry(entry{name: fileName, open: func() (io.ReadCloser, error) { return os.Open(fileName) }}); err != nil {
+				update.Err = fmt.Errorf("%s: %w", fileName, err)
+			} else {
+				update.Result, update.Err = c.result()
+			}
+			if err := handler(update); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-watcher.Errors:
+			return err
+		case ev := <-watcher.Events:
+			if ev.Has(fsnotify.Create) || ev.Has(fsnotify.Write) {
+				if name := filepath.Base(ev.Name); matchFile(pattern, name) {
+					pending[name] = time.Now()
+				}
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// imports records of the csv file and keeps reading records appended to it,
+// e.g. to follow a log of sign-ups. Handler receives counts of all records
+// read so far whenever new records are read up to the end of the file.
+// Records are parsed sequentially, WithWorkers and WithChunks are ignored.
+// Truncated or replaced file isn't followed. Tail returns error aborting the
+// import or ctx.Err() when ctx is done.
+func Tail(ctx context.Context, fileName, emailFieldName string, handler WatchHandler, options ...Option) error {
+	c := newCustomerImporter(nil, emailFieldName, options...)
+	defer c.closeSpill()
+	c.workers, c.chunks = 0, 0
+
+	// watch before opening, so appended records aren't missed
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(fileName)); err != nil {
+		return err
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// report counts when records read up to the end of the file changed them
+	rowsRead := -1
+	caughtUp := func() error {
+		if c.rowsRead == rowsRead {
+			return nil
+		}
+		rowsRead = c.rowsRead
+
+		// nothing to report until a valid email is counted
+		result, err := c.result()
+		if errors.Is(err, ErrNoValidEmailsFound) {
+			return nil
+		}
+		return handler(WatchUpdate{File: fileName, Result: result, Err: err})
+	}
+
+	c.input = &followReader{ctx: ctx, file: file, name: filepath.Clean(fileName), watcher: watcher, caughtUp: caughtUp}
+	c.fileName = fileName
+	if err := c.parseInput(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	return nil
+}
+
+// reports whether the name matches the glob pattern, empty pattern matches
+// all names
+func matchFile(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := filepath.Match(pattern, name)
+	return ok
+}
+
+// followReader reads the file and waits for writes to it at the end of the
+// file instead of returning io.EOF
+type followReader struct {
+	ctx      context.Context
+	file     *os.File
+	name     string            // cleaned name of the file in events of the watcher
+	watcher  *fsnotify.Watcher // watcher of the directory of the file
+	caughtUp func() error      // called when the end of the file is reached
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.file.Read(p)
+		if n > 0 || err != io.EOF {
+			return n, err
+		}
+		if err := r.caughtUp(); err != nil {
+			return 0, err
+		}
+
+		// wait for a write to the file
+		for waiting := true; waiting; {
+			select {
+			case <-r.ctx.Done():
+				return 0, r.ctx.Err()
+			case err := <-r.watcher.Errors:
+				return 0, err
+			case ev := <-r.watcher.Events:
+				waiting = filepath.Clean(ev.Name) != r.name
+			}
+		}
+	}
+}
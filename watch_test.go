@@ -0,0 +1,174 @@
+package customerimporter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// returns the next update or fails after timeout
+func nextUpdate(t *testing.T, updates <-chan WatchUpdate) WatchUpdate {
+	t.Helper()
+	select {
+	case update := <-updates:
+		return update
+	case <-time.After(5 * time.Second):
+		t.Fatal("should report update, but got none")
+		return WatchUpdate{}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "day1.csv"), []byte("email\na@a.io\nb@a.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan WatchUpdate, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, dir, "*.csv", "email", func(update WatchUpdate) error {
+			updates <- update
+			return nil
+		}, WithSettleDelay(20*time.Millisecond))
+	}()
+
+	t.Log("Should import files in the directory")
+	update := nextUpdate(t, updates)
+	if update.Err != nil || update.File != filepath.Join(dir, "day1.csv") || update.Result.ValidEmails != 2 {
+		t.Errorf("should report 2 emails of day1.csv, but got %+v", update)
+	}
+
+	t.Log("Should import new files matching the pattern with counts of all files")
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("email\nc@c.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "day2.csv"), []byte("email\na@b.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	update = nextUpdate(t, updates)
+	if update.Err != nil || update.File != filepath.Join(dir, "day2.csv") || update.Result.ValidEmails != 3 || update.Result.DistinctDomains != 2 {
+		t.Errorf("should report 3 emails of 2 domains, but got %+v", update)
+	}
+
+	t.Log("Should report error of the file and keep watching")
+	if err := os.WriteFile(filepath.Join(dir, "day3.csv"), []byte("email\na@a.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	update = nextUpdate(t, updates)
+	if !errors.Is(update.Err, ErrEmailDuplicate) || update.Result != nil {
+		t.Errorf("should report error: %v, but got %+v", ErrEmailDuplicate, update)
+	}
+
+	t.Log("Should stop when context is canceled")
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("should return %v, but got %v", context.Canceled, err)
+	}
+}
+
+func TestWatchLimit(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "day1.csv"), []byte("email\na@a.io\nb@a.io\nc@a.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan WatchUpdate, 10)
+	go Watch(ctx, dir, "*.csv", "email", func(update WatchUpdate) error {
+		updates <- update
+		return nil
+	}, WithSettleDelay(20*time.Millisecond), LimitValidEmails(2))
+
+	t.Log("Should truncate the import at the limit")
+	update := nextUpdate(t, updates)
+	if update.Err != nil || update.Result == nil || update.Result.ValidEmails != 2 || !update.Result.Truncated {
+		t.Errorf("should report 2 emails of truncated import, but got %+v", update)
+	}
+
+	t.Log("Should report files arriving after truncation with limit error")
+	if err := os.WriteFile(filepath.Join(dir, "day2.csv"), []byte("email\na@b.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	update = nextUpdate(t, updates)
+	if !errors.Is(update.Err, ErrLimitReached) || update.File != filepath.Join(dir, "day2.csv") || update.Result == nil || update.Result.ValidEmails != 2 {
+		t.Errorf("should report error: %v with 2 emails, but got %+v", ErrLimitReached, update)
+	}
+}
+
+func TestWatchErrors(t *testing.T) {
+	handler := func(WatchUpdate) error { return nil }
+
+	t.Log("Should fail on invalid pattern and missing directory")
+	if err := Watch(context.Background(), t.TempDir(), "[", "email", handler); err == nil {
+		t.Error("should raise error of the pattern, but got nil")
+	}
+	if err := Watch(context.Background(), filepath.Join(t.TempDir(), "missing"), "", "email", handler); err == nil {
+		t.Error("should raise error of the directory, but got nil")
+	}
+}
+
+func TestTail(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "signups.csv")
+	if err := os.WriteFile(fileName, []byte("email\na@a.io\nb@b.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan WatchUpdate, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- Tail(ctx, fileName, "email", func(update WatchUpdate) error {
+			updates <- update
+			return nil
+		}, SkipErrDuplicateEmails())
+	}()
+
+	t.Log("Should report records of the file")
+	update := nextUpdate(t, updates)
+	if update.Err != nil || update.File != fileName || update.Result.ValidEmails != 2 {
+		t.Errorf("should report 2 emails, but got %+v", update)
+	}
+
+	t.Log("Should report counts of the appended records")
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString("a@b.io\na@a.io\n"); err != nil {
+		t.Fatal(err)
+	}
+	update = nextUpdate(t, updates)
+	if update.Result.ValidEmails != 3 || update.Result.DuplicateEmails != 1 || update.Result.RowsRead != 4 {
+		t.Errorf("should report 3 emails and 1 duplicate of 4 records, but got %+v", update.Result)
+	}
+
+	t.Log("Should stop when context is canceled")
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("should return %v, but got %v", context.Canceled, err)
+	}
+}
+
+func TestTailHandlerError(t *testing.T) {
+	t.Log("Should stop on error of the handler")
+
+	fileName := filepath.Join(t.TempDir(), "signups.csv")
+	if err := os.WriteFile(fileName, []byte("email\na@a.io\nb@b.io\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	errStop := errors.New("stop")
+	err := Tail(context.Background(), fileName, "email", func(WatchUpdate) error { return errStop })
+	if !errors.Is(err, errStop) {
+		t.Errorf("should return %v, but got %v", errStop, err)
+	}
+}
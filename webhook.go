@@ -0,0 +1,143 @@
+package customerimporter
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// status of the import posted by WithCompletionWebhook
+const (
+	CompletionSucceeded = "succeeded"
+	CompletionFailed    = "failed"
+)
+
+// max duration of posting to the webhook and amount of domains of the summary
+const (
+	webhookTimeout    = 30 * time.Second
+	webhookTopDomains = 10
+)
+
+// CompletionSummary is JSON posted by WithCompletionWebhook
+type CompletionSummary struct {
+	Status          string                `json:"status"`           // CompletionSucceeded or CompletionFailed
+	RowsRead        int                   `json:"rows_read"`        // amount of records read, header excluded
+	ValidEmails     int                   `json:"valid_emails"`     // amount of counted emails
+	InvalidEmails   int                   `json:"invalid_emails"`   // amount of skipped invalid emails
+	DuplicateEmails int                   `json:"duplicate_emails"` // amount of skipped duplicate emails
+	MalformedRows   int                   `json:"malformed_rows"`   // amount of records skipped by SkipMalformedRows
+	SkippedRows     int                   `json:"skipped_rows"`     // amount of records skipped with collected errors
+	DistinctDomains int                   `json:"distinct_domains"` // amount of distinct domains
+	TopDomains      EmailsByDomainQtyList `json:"top_domains"`      // domains of the most emails
+	Elapsed         time.Duration         `json:"elapsed_ns"`       // time spent on import
+	Error           string                `json:"error,omitempty"`  // error of the failed import
+	Code            ErrorCode             `json:"code,omitempty"`   // code of the error
+	FinishedAt      time.Time             `json:"finished_at"`      // time the import finished
+}
+
+// Post CompletionSummary of the import as JSON to the URL when the import
+// finishes or fails, so downstream systems can react without polling. The
+// import doesn't fail when the webhook can't be posted, the error is logged.
+// Imports with partial results are reported as failed. The client set by
+// WithHTTPClient is used to post. Imports by New are not reported.
+func WithCompletionWebhook(url string) Option {
+	return func(f *CustomerImporter) { f.webhookURL = url }
+}
+
+// posts summary of the finished import to the webhook, if it's set
+func (c *CustomerImporter) notifyCompletion(result *ImportResult, err error) {
+	if c.webhookURL == "" {
+		return
+	}
+
+	if err := c.postSummary(c.completionSummary(result, err)); err != nil {
+		c.log(slog.LevelWarn, "webhook not posted", "url", c.webhookURL, "error", err)
+	}
+}
+
+// returns summary of the result, statistics of the importer are used when
+// the import failed without result
+func (c *CustomerImporter) completionSummary(result *ImportResult, err error) CompletionSummary {
+	status := CompletionSucceeded
+	if result == nil || result.Partial {
+		status = CompletionFailed
+	}
+	if result == nil {
+		result = c.newResult(nil, 0)
+	}
+
+	summary := CompletionSummary{
+		Status:          status,
+		RowsRead:        result.RowsRead,
+		ValidEmails:     result.ValidEmails,
+		InvalidEmails:   result.InvalidEmails,
+		DuplicateEmails: result.DuplicateEmails,
+		MalformedRows:   result.MalformedRows,
+		SkippedRows:     len(result.Errors),
+		DistinctDomains: result.DistinctDomains,
+		TopDomains:      topDomains(result.Domains, webhookTopDomains),
+		Elapsed:         result.Elapsed,
+		FinishedAt:      time.Now(),
+	}
+
+	// collected errors of the skipped records don't fail the import
+	if status == CompletionFailed && err != nil {
+		summary.Error = err.Error()
+		if code := Code(err); code != CodeUnknown {
+			summary.Code = code
+		}
+	}
+	return summary
+}
+
+// posts the summary as JSON, the webhook must respond with 2xx status
+func (c *CustomerImporter) postSummary(summary CompletionSummary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := c.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%w %s", ErrUnexpectedStatus, resp.Status)
+	}
+	return nil
+}
+
+// returns n domains of the most emails, domains with equal count are sorted
+// by name
+func topDomains(domains EmailsByDomainQtyList, n int) EmailsByDomainQtyList {
+	top := slices.Clone(domains)
+	slices.SortFunc(top, func(a, b EmailsByDomainQty) int {
+		if c := cmp.Compare(b.EmailsCount, a.EmailsCount); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Domain, b.Domain)
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top.nonNil()
+}
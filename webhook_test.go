@@ -0,0 +1,107 @@
+package customerimporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// returns webhook server decoding posted summaries
+func webhookServer(t *testing.T, status int) (*httptest.Server, *[]CompletionSummary) {
+	var summaries []CompletionSummary
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var summary CompletionSummary
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("should post JSON, but got %v %v", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+			t.Error(err)
+		}
+		summaries = append(summaries, summary)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &summaries
+}
+
+func TestWithCompletionWebhook(t *testing.T) {
+	records := "email\na@a.io\nb@a.io\ninvalid\na@b.io\n"
+
+	data := []struct {
+		name    string
+		options []Option
+		status  string
+		valid   int
+		skipped int
+		domains []string
+		code    ErrorCode
+	}{
+		{"succeeded", []Option{SkipErrInvalidEmails()}, CompletionSucceeded, 3, 0, []string{"a.io", "b.io"}, ""},
+		{"collected errors", []Option{CollectErrors()}, CompletionSucceeded, 3, 1, []string{"a.io", "b.io"}, ""},
+		{"failed", nil, CompletionFailed, 2, 0, []string{}, CodeInvalidEmail},
+		{"partial result", []Option{AllowPartialResult()}, CompletionFailed, 2, 0, []string{"a.io"}, CodeInvalidEmail},
+	}
+
+	t.Log("Should post summary of the finished import")
+	for _, d := range data {
+		t.Logf("Case: %v", d.name)
+
+		ts, summaries := webhookServer(t, http.StatusNoContent)
+		ImportWithStats(strings.NewReader(records), "email", append(d.options, WithCompletionWebhook(ts.URL))...)
+
+		if len(*summaries) != 1 {
+			t.Fatalf("should post once, but posted %v times", len(*summaries))
+		}
+		summary := (*summaries)[0]
+		if summary.Status != d.status || summary.ValidEmails != d.valid || summary.SkippedRows != d.skipped || summary.Code != d.code {
+			t.Errorf("should post status %v with %v valid emails, %v skipped rows and code %q, but got %+v", d.status, d.valid, d.skipped, d.code, summary)
+		}
+		var domains []string
+		for _, e := range summary.TopDomains {
+			domains = append(domains, e.Domain)
+		}
+		if strings.Join(domains, ",") != strings.Join(d.domains, ",") {
+			t.Errorf("should post top domains %v, but got %v", d.domains, domains)
+		}
+	}
+}
+
+func TestWithCompletionWebhookFiles(t *testing.T) {
+	t.Log("Should post summary of all files once")
+
+	dir := t.TempDir()
+	for i, data := range []string{"email\na@a.io\n", "email\nb@a.io\n"} {
+		if err := os.WriteFile(filepath.Join(dir, string(rune('a'+i))+".csv"), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ts, summaries := webhookServer(t, http.StatusOK)
+	if _, err := ImportFromFilesWithStats([]string{filepath.Join(dir, "*.csv")}, "email", WithCompletionWebhook(ts.URL)); err != nil {
+		t.Fatal(err)
+	}
+	if len(*summaries) != 1 || (*summaries)[0].ValidEmails != 2 {
+		t.Errorf("should post summary of all files once, but got %+v", *summaries)
+	}
+}
+
+func TestWithCompletionWebhookError(t *testing.T) {
+	t.Log("Should log webhook error without failing the import")
+
+	var b bytes.Buffer
+	ts, _ := webhookServer(t, http.StatusInternalServerError)
+	_, err := ImportWithStats(strings.NewReader("email\na@a.io\n"), "email",
+		WithCompletionWebhook(ts.URL), WithLogger(slog.New(slog.NewTextHandler(&b, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "webhook not posted") || !strings.Contains(b.String(), "500") {
+		t.Errorf("should log webhook error, but got %v", b.String())
+	}
+}
@@ -0,0 +1,352 @@
+package customerimporter
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrSheetNotExists is raised when the workbook doesn't contain the sheet
+var ErrSheetNotExists = errors.New("Workbook doesn't contain sheet")
+
+// parts of the workbook package
+const (
+	xlsxWorkbookPath      = "xl/workbook.xml"
+	xlsxWorkbookRelsPath  = "xl/_rels/workbook.xml.rels"
+	xlsxSharedStringsPath = "xl/sharedStrings.xml"
+)
+
+// XLSXReader reads rows of an Excel worksheet as records. Only shared strings
+// are kept in memory, rows are decoded as they are read. Rows shorter than the
+// first one are padded with empty fields.
+type XLSXReader struct {
+	closer        io.Closer     // file opened by OpenXLSX
+	sheet         io.ReadCloser // worksheet part of the package
+	decoder       *xml.Decoder  // decoder of the worksheet
+	sharedStrings []string      // shared strings of the workbook
+	width         int           // amount of fields of the first row
+}
+
+// imports from the sheet of Excel workbook, the first sheet is used if sheet
+// is empty
+func ImportFromXLSX(fileName, sheet, emailFieldName string, options ...Option) (*EmailsByDomainQtyList, error) {
+	r, err := OpenXLSX(fileName, sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ImportRecords(r, emailFieldName, options...)
+}
+
+// imports from the sheet of Excel workbook and returns result with statistics
+func ImportFromXLSXWithStats(fileName, sheet, emailFieldName string, options ...Option) (*ImportResult, error) {
+	r, err := OpenXLSX(fileName, sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ImportRecordsWithStats(r, emailFieldName, options...)
+}
+
+// OpenXLSX opens the sheet of Excel workbook, the first sheet is used if sheet
+// is empty
+func OpenXLSX(fileName, sheet string) (*XLSXReader, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r, err := NewXLSXReader(file, info.Size(), sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.closer = file
+
+	return r, nil
+}
+
+// NewXLSXReader returns reader of the sheet of Excel workbook read from r, the
+// first sheet is used if sheet is empty
+func NewXLSXReader(r io.ReaderAt, size int64, sheet string) (*XLSXReader, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// find worksheet part
+	sheetPath, err := xlsxSheetPath(z, sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	// read shared strings, the part is optional
+	sharedStrings, err := xlsxSharedStrings(z)
+	if err != nil {
+		return nil, err
+	}
+
+	part, err := z.Open(sheetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &XLSXReader{sheet: part, decoder: xml.NewDecoder(part), sharedStrings: sharedStrings}, nil
+}
+
+// Read returns the next non-empty row of the sheet
+func (r *XLSXReader) Read() ([]string, error) {
+	for {
+		record, err := r.readRow()
+		if err != nil {
+			return nil, err
+		}
+		if len(record) == 0 {
+			continue
+		}
+
+		// pad short rows
+		if r.width == 0 {
+			r.width = len(record)
+		}
+		for len(record) < r.width {
+			record = append(record, "")
+		}
+		return record, nil
+	}
+}
+
+// Close closes the sheet and the file opened by OpenXLSX
+func (r *XLSXReader) Close() error {
+	err := r.sheet.Close()
+	if r.closer != nil {
+		if closeErr := r.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// decodes the next row of the sheet
+func (r *XLSXReader) readRow() ([]string, error) {
+	var (
+		record     []string
+		inRow      bool
+		cellType   string
+		column     int
+		value      strings.Builder
+		inValue    bool // inside <v> or inline <t>
+		inPhonetic bool
+	)
+
+	for {
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				inRow = true
+			case "c":
+				cellType = xlsxAttr(t, "t")
+				if ref := xlsxAttr(t, "r"); ref != "" {
+					column = xlsxColumnIndex(ref)
+				} else {
+					column = len(record)
+				}
+				value.Reset()
+			case "v":
+				inValue = true
+			case "t":
+				inValue = !inPhonetic
+			case "rPh":
+				inPhonetic = true
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "rPh":
+				inPhonetic = false
+			case "c":
+				for len(record) <= column {
+					record = append(record, "")
+				}
+				record[column] = r.cellValue(cellType, value.String())
+			case "row":
+				if inRow {
+					return record, nil
+				}
+			}
+		}
+	}
+}
+
+// returns value of the cell by its type
+func (r *XLSXReader) cellValue(cellType, value string) string {
+	switch cellType {
+	case "s":
+		i, err := strconv.Atoi(value)
+		if err != nil || i < 0 || i >= len(r.sharedStrings) {
+			return ""
+		}
+		return r.sharedStrings[i]
+	case "b":
+		if value == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return value
+}
+
+// returns path of the worksheet part by sheet name
+func xlsxSheetPath(z *zip.Reader, sheet string) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xlsxDecodePart(z, xlsxWorkbookPath, &workbook); err != nil {
+		return "", err
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xlsxDecodePart(z, xlsxWorkbookRelsPath, &rels); err != nil {
+		return "", err
+	}
+
+	for _, s := range workbook.Sheets {
+		if sheet != "" && s.Name != sheet {
+			continue
+		}
+		for _, rel := range rels.Relationships {
+			if rel.ID != s.ID {
+				continue
+			}
+			// targets are relative to the workbook part unless absolute
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join(path.Dir(xlsxWorkbookPath), rel.Target), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w %s", ErrSheetNotExists, sheet)
+}
+
+// returns shared strings of the workbook, rich text runs are concatenated
+func xlsxSharedStrings(z *zip.Reader) ([]string, error) {
+	part, err := z.Open(xlsxSharedStringsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer part.Close()
+
+	var (
+		sharedStrings []string
+		value         strings.Builder
+		inText        bool
+		inPhonetic    bool
+	)
+	decoder := xml.NewDecoder(part)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return sharedStrings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				value.Reset()
+			case "t":
+				inText = !inPhonetic
+			case "rPh":
+				inPhonetic = true
+			}
+		case xml.CharData:
+			if inText {
+				value.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonetic = false
+			case "si":
+				sharedStrings = append(sharedStrings, value.String())
+			}
+		}
+	}
+}
+
+// decodes XML part of the package
+func xlsxDecodePart(z *zip.Reader, name string, v any) error {
+	part, err := z.Open(name)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	return xml.NewDecoder(part).Decode(v)
+}
+
+// returns attribute value of the element
+func xlsxAttr(e xml.StartElement, name string) string {
+	for _, attr := range e.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// returns column index of the cell reference, e.g. 27 for AB12
+func xlsxColumnIndex(ref string) int {
+	index := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		index = index*26 + int(ch-'A'+1)
+	}
+	return index - 1
+}
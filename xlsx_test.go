@@ -0,0 +1,162 @@
+package customerimporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// builds minimal workbook with Customers and Other sheets
+func xlsxData(t *testing.T) []byte {
+	t.Helper()
+
+	parts := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Other" sheetId="1" r:id="rId2"/><sheet name="Customers" sheetId="2" r:id="rId1"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>first_name</t></si>
+<si><t>email</t></si>
+<si><r><t>a@</t></r><r><t>a.io</t></r><rPh><t>phonetic</t></rPh></si>
+</sst>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="str"><v>active</v></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>Ann</t></is></c><c r="B2" t="s"><v>2</v></c><c r="C2" t="b"><v>1</v></c></row>
+<row r="3"/>
+<row r="4"><c r="B4" t="inlineStr"><is><t>b@b.io</t></is></c></row>
+<row r="5"><c r="A5"><v>42</v></c><c r="B5" t="str"><v>c@a.io</v></c><c r="C5" t="b"><v>0</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>email</t></is></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>d@d.io</t></is></c></row>
+</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXReader(t *testing.T) {
+	t.Log("Should read rows of the sheet")
+
+	data := xlsxData(t)
+	r, err := NewXLSXReader(bytes.NewReader(data), int64(len(data)), "Customers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var records [][]string
+	for {
+		record, err := r.Read()
+		if err != nil {
+			break
+		}
+		records = append(records, record)
+	}
+
+	expected := [][]string{
+		{"first_name", "email", "active"},
+		{"Ann", "a@a.io", "TRUE"},
+		{"", "b@b.io", ""},
+		{"42", "c@a.io", "FALSE"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("should read records %v, but got %v", expected, records)
+	}
+}
+
+func TestImportFromXLSX(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "customers.xlsx")
+	if err := os.WriteFile(fileName, xlsxData(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sheet    string
+		expected EmailsByDomainQtyList
+		err      error
+	}{
+		{"Customers", EmailsByDomainQtyList{
+			{Domain: "a.io", EmailsCount: 2, Share: 2.0 / 3},
+			{Domain: "b.io", EmailsCount: 1, Share: 1.0 / 3},
+		}, nil},
+		{"", EmailsByDomainQtyList{{Domain: "d.io", EmailsCount: 1, Share: 1}}, nil},
+		{"Missing", nil, ErrSheetNotExists},
+	}
+
+	t.Log("Should import emails from the sheet of workbook")
+	for _, test := range tests {
+		t.Logf("Case: %v", test.sheet)
+
+		result, err := ImportFromXLSX(fileName, test.sheet, "email")
+		if test.err != nil {
+			if err == nil || !strings.Contains(err.Error(), test.err.Error()) {
+				t.Errorf("should raise error: %v, but got error %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*result, test.expected) {
+			t.Errorf("should return %v, but got %v", test.expected, *result)
+		}
+	}
+}
+
+func TestImportRecords(t *testing.T) {
+	t.Log("Should import emails from record reader")
+
+	rr := &sliceRecordReader{records: [][]string{{"email"}, {"a@a.io"}, {"b@a.io"}}}
+	result, err := ImportRecords(rr, "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := EmailsByDomainQtyList{{Domain: "a.io", EmailsCount: 2, Share: 1}}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("should return %v, but got %v", expected, *result)
+	}
+}
+
+// sliceRecordReader reads records from slice
+type sliceRecordReader struct {
+	records [][]string
+}
+
+func (r *sliceRecordReader) Read() ([]string, error) {
+	if len(r.records) == 0 {
+		return nil, io.EOF
+	}
+	record := r.records[0]
+	r.records = r.records[1:]
+	return record, nil
+}